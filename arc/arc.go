@@ -363,7 +363,7 @@ func Arc_data_r( /* ARGUMENTS                                     */
 
 /****************************************************************************/
 
-/* find_arc_length
+/* Find_arc_length
 
 Returned Value: double (length of path between start and end points)
 
@@ -373,6 +373,7 @@ Called by:
 inverse_time_rate_arc
 inverse_time_rate_arc2
 inverse_time_rate_as
+external programs
 
 This calculates the length of the path that will be made relative to
 the XYZ axes for a motion in which the X,Y,Z, motion is a circular or
@@ -391,7 +392,7 @@ This is written as though it is only for arcs whose axis is parallel to
 the Z-axis, but it will serve also for arcs whose axis is parallel
 to the X-axis or Y-axis, with suitable permutation of the arguments.
 
-This works correctly when turn is zero (find_turn returns 0 in that
+This works correctly when turn is zero (Find_turn returns 0 in that
 case).
 
 */
@@ -410,7 +411,7 @@ func Find_arc_length( /* ARGUMENTS                          */
 	radius := math.Hypot((center_x - x1), (center_y - y1))
 
 	/* amount of turn of arc in radians */
-	theta := find_turn(x1, y1, center_x, center_y, turn, x2, y2)
+	theta := Find_turn(x1, y1, center_x, center_y, turn, x2, y2)
 	if z2 == z1 {
 		return (radius * math.Abs(theta))
 	} else {
@@ -476,19 +477,24 @@ func Find_straight_length( /* ARGUMENTS   */
 
 /****************************************************************************/
 
-/* find_turn
+/* Find_turn
 
 Returned Value: double (angle in radians between two radii of a circle)
 
 Side effects: none
 
-Called by: find_arc_length
+Called by:
+Find_arc_length
+Find_arc_extents
+external programs
 
-All angles are in radians.
+All angles are in radians. The turn is that of ARC_FEED: positive for
+counterclockwise, negative for clockwise, and the number of full or
+partial circles.
 
 */
 
-func find_turn( /* ARGUMENTS                          */
+func Find_turn( /* ARGUMENTS                          */
 	x1, /* X-coordinate of start point        */
 	y1, /* Y-coordinate of start point        */
 	center_x, /* X-coordinate of arc center         */
@@ -520,3 +526,88 @@ func find_turn( /* ARGUMENTS                          */
 	}
 	return theta
 }
+
+/****************************************************************************/
+
+/* Find_chord_count
+
+Returned Value: int (number of straight segments, at least one)
+
+Side effects: none
+
+Called by: external programs
+
+This finds how many equal chords an arc of the given radius turning
+through theta radians must be cut into so that no chord is farther than
+tolerance from the arc. A chord of an arc turning through phi radians is
+radius * (1 - cos(phi / 2)) from the arc at its middle.
+
+If tolerance is not less than the radius, any chord of a half circle or
+less will do.
+
+*/
+
+func Find_chord_count( /* ARGUMENTS                     */
+	radius, /* radius of the arc                 */
+	theta, /* amount of turn of the arc, radians */
+	tolerance float64) int { /* greatest chord error allowed       */
+
+	step := math.Pi
+	if (tolerance > 0.0) && (tolerance < radius) {
+		step = 2.0 * math.Acos(1.0-(tolerance/radius))
+	}
+	count := int(math.Ceil(math.Abs(theta) / step))
+	return If(count < 1, 1, count).(int)
+}
+
+/****************************************************************************/
+
+/* Find_arc_extents
+
+Returned Value: the least and greatest X and Y of the points on an arc
+
+Side effects: none
+
+Called by: external programs
+
+The arc is that of ARC_FEED, turning as in Find_turn. Its extents are
+those of its end points and of those of the four points of the circle
+farthest in X or Y (at 0, 90, 180 and 270 degrees) which it passes, so
+they are the true extents of the arc, not just of its ends. The circle
+has the radius of the arc at its start.
+
+*/
+
+func Find_arc_extents( /* ARGUMENTS                          */
+	x1, /* X-coordinate of start point        */
+	y1, /* Y-coordinate of start point        */
+	center_x, /* X-coordinate of arc center         */
+	center_y float64, /* Y-coordinate of arc center         */
+	turn int, /* no. of full or partial circles CCW */
+	x2, /* X-coordinate of end point          */
+	y2 float64) (min_x, min_y, max_x, max_y float64) { /* Y-coordinate of end point */
+
+	var (
+		radius, /* radius of the arc at its start      */
+		low, /* least angle the arc passes         */
+		high float64 /* greatest angle the arc passes      */
+	)
+	min_x, max_x = math.Min(x1, x2), math.Max(x1, x2)
+	min_y, max_y = math.Min(y1, y2), math.Max(y1, y2)
+	radius = math.Hypot((x1 - center_x), (y1 - center_y))
+	low = math.Atan2((y1 - center_y), (x1 - center_x))
+	high = low + Find_turn(x1, y1, center_x, center_y, turn, x2, y2)
+	if high < low {
+		low, high = high, low
+	}
+	if (high - low) > inc.TWO_PI {
+		high = low + inc.TWO_PI
+	}
+	for k := math.Ceil(low / (math.Pi / 2.0)); k <= math.Floor(high/(math.Pi/2.0)); k++ {
+		x := center_x + (radius * math.Cos(k*(math.Pi/2.0)))
+		y := center_y + (radius * math.Sin(k*(math.Pi/2.0)))
+		min_x, max_x = math.Min(min_x, x), math.Max(max_x, x)
+		min_y, max_y = math.Min(min_y, y), math.Max(max_y, y)
+	}
+	return min_x, min_y, max_x, max_y
+}
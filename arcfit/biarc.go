@@ -0,0 +1,180 @@
+package arcfit
+
+import "math"
+
+/* biarc.go
+
+   A biarc is two arcs meeting at a point with the same direction there,
+   which go from one point in a given direction to another point in a
+   given direction. Of all the biarcs between two points, the one made
+   here is the one whose joint is as far from the start along the
+   direction there as it is from the end against the direction there.
+   An arc which comes out nearly straight is made a line.
+
+   Points are in the plane: first and second are the coordinates along
+   the first and second axes of the plane, as for ARC_FEED, so a positive
+   sweep is counterclockwise.
+
+*/
+
+// point_t is a point, or a direction, in the plane.
+type point_t struct {
+	first, second float64
+}
+
+func (p point_t) plus(q point_t) point_t     { return point_t{p.first + q.first, p.second + q.second} }
+func (p point_t) minus(q point_t) point_t    { return point_t{p.first - q.first, p.second - q.second} }
+func (p point_t) times(f float64) point_t    { return point_t{p.first * f, p.second * f} }
+func (p point_t) dot(q point_t) float64      { return (p.first * q.first) + (p.second * q.second) }
+func (p point_t) cross(q point_t) float64    { return (p.first * q.second) - (p.second * q.first) }
+func (p point_t) length() float64            { return math.Hypot(p.first, p.second) }
+func (p point_t) distance(q point_t) float64 { return p.minus(q).length() }
+
+// unit returns p made one long, or p if it has no length.
+func (p point_t) unit() point_t {
+	if l := p.length(); l > 0.0 {
+		return p.times(1.0 / l)
+	}
+	return p
+}
+
+// arc_t is an arc, or a line if sweep is 0.
+type arc_t struct {
+	from, to point_t
+	center   point_t
+	sweep    float64 // radians, counterclockwise positive
+}
+
+/* arc_from
+
+   Returned Value: the arc from from, going in direction (a unit
+   vector), to to; a line if the arc would be no more than flat from its
+   chord
+
+   Side effects: none
+
+   Called by: biarc
+
+   The arc turns through twice the angle between its direction at the
+   start and its chord.
+
+*/
+
+func arc_from(from, direction, to point_t, flat float64) arc_t {
+	chord := to.minus(from)
+	a := arc_t{from: from, to: to}
+	sweep := 2.0 * math.Atan2(direction.cross(chord), direction.dot(chord))
+	if chord.length() == 0.0 {
+		return a
+	}
+	radius := chord.length() / (2.0 * math.Abs(math.Sin(sweep/2.0)))
+	if math.IsInf(radius, 1) || (radius*(1.0-math.Cos(sweep/2.0)) <= flat) {
+		return a
+	}
+	left := point_t{-direction.second, direction.first}
+	a.center = from.plus(left.times(sign(sweep) * radius))
+	a.sweep = sweep
+	return a
+}
+
+// sign returns 1 for a positive number and -1 otherwise.
+func sign(f float64) float64 {
+	if f > 0.0 {
+		return 1.0
+	}
+	return -1.0
+}
+
+/* biarc
+
+   Returned Value: the two arcs from one going in direction start to two
+   arriving in direction end (unit vectors), and whether there are such
+   arcs
+
+   Side effects: none
+
+   Called by: Fitter_t.fit
+
+   The arcs meet at the middle of one + d * start and two - d * end,
+   where d is found from those points being 2 * d apart, which is a
+   quadratic in d with one positive root (see Ryan Juckett, Biarc
+   Interpolation, 2010). The second arc is made backward from two.
+
+*/
+
+func biarc(one, start, two, end point_t, flat float64) (arcs [2]arc_t, ok bool) {
+	v := two.minus(one)
+	t := start.plus(end)
+	vt := v.dot(t)
+	denominator := 2.0 * (1.0 - start.dot(end))
+	var d float64
+	if denominator < 1e-12 {
+		if v.dot(end) <= 0.0 {
+			return arcs, false
+		}
+		d = v.dot(v) / (4.0 * v.dot(end))
+	} else {
+		d = (-vt + math.Sqrt((vt*vt)+(denominator*v.dot(v)))) / denominator
+	}
+	if !(d > 0.0) || math.IsInf(d, 1) {
+		return arcs, false
+	}
+	joint := one.plus(two).plus(start.minus(end).times(d)).times(0.5)
+	arcs[0] = arc_from(one, start, joint, flat)
+	arcs[1] = arc_from(two, end.times(-1.0), joint, flat).reverse()
+	return arcs, true
+}
+
+// reverse returns a going the other way.
+func (a arc_t) reverse() arc_t {
+	return arc_t{from: a.to, to: a.from, center: a.center, sweep: -a.sweep}
+}
+
+// at returns the point a fraction f (0 to 1) of the way along a.
+func (a arc_t) at(f float64) point_t {
+	if a.sweep == 0.0 {
+		return a.from.plus(a.to.minus(a.from).times(f))
+	}
+	s, c := math.Sincos(a.sweep * f)
+	r := a.from.minus(a.center)
+	return a.center.plus(point_t{(r.first * c) - (r.second * s), (r.first * s) + (r.second * c)})
+}
+
+// to_segment returns how far p is from the line from from to to.
+func to_segment(p, from, to point_t) float64 {
+	along := to.minus(from)
+	f := 0.0
+	if l := along.dot(along); l > 0.0 {
+		f = math.Max(0.0, math.Min(1.0, p.minus(from).dot(along)/l))
+	}
+	return p.distance(from.plus(along.times(f)))
+}
+
+/* distance
+
+   Returned Value: how far p is from a
+
+   Side effects: none
+
+   Called by: Fitter_t.fits
+
+   If p is in the sector of the arc, this is how far it is from the
+   circle, otherwise how far it is from the nearer end.
+
+*/
+
+func (a arc_t) distance(p point_t) float64 {
+	if a.sweep == 0.0 {
+		return to_segment(p, a.from, a.to)
+	}
+	r := a.from.minus(a.center)
+	q := p.minus(a.center)
+	angle := math.Atan2(r.cross(q), r.dot(q)) * sign(a.sweep)
+	if angle < 0.0 {
+		angle = angle + (2.0 * math.Pi)
+	}
+	if angle <= math.Abs(a.sweep) {
+		return math.Abs(q.length() - r.length())
+	}
+	return math.Min(p.distance(a.from), p.distance(a.to))
+}
@@ -0,0 +1,379 @@
+package arcfit
+
+import (
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/mux"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* fitter.go
+
+   Fitter_t is a middleware (see mux) which replaces runs of short
+   STRAIGHT_FEEDs, such as CAM programs are made of, by ARC_FEEDs, so
+   programs are smaller and controllers run them more smoothly. It is
+   the other way around from linearize.
+
+   Straight feeds in the selected plane, which do not move along the
+   axis of the plane or the rotary axes, are held. When something else
+   comes, or Window feeds are held, the path through them is fitted
+   with biarcs (see biarc.go), from the start: the longest run of at
+   least two feeds which one biarc fits is replaced by it, or, if
+   there is no such run, the first feed is passed on as it is. A biarc
+   fits if every point of the feeds is within the tolerance of it, and
+   every point of it is within the tolerance of the feeds.
+
+   The direction of the path at a point where it bends smoothly is that
+   of the circle through the point and the ones on either side, so
+   biarcs meeting there meet smoothly. Where the path turns through more
+   than corner_angle, it is taken to be a corner, and the directions in
+   and out are those of the feeds there.
+
+   Every other command, but those which only change the feed rate to
+   what it is, is passed on after the feeds held, so the order of the
+   commands is kept. Feeds which do not move are dropped. In inverse
+   time feed mode, where each feed has its own time, nothing is fitted
+   (see inc.Feed_mode_i).
+
+   The start of the first feed held, the plane, the length units and the
+   feed rate are read from the next Canon_i (see mux.Filter_t). While
+   feeds are held, the position read back is the end of the last, and
+   the queue is not empty.
+
+*/
+
+// TOLERANCE_DEFAULT is the fitting tolerance in millimeters if none is
+// given.
+const TOLERANCE_DEFAULT = 0.005
+
+// WINDOW_DEFAULT is the number of feeds held if Window is 0.
+const WINDOW_DEFAULT = 500
+
+// corner_angle is the least turn, in radians, taken to be a corner.
+const corner_angle = math.Pi / 6.0
+
+type Fitter_t struct {
+	mux.Filter_t
+
+	Tolerance float64 // fitting tolerance in millimeters
+	Window    int     // feeds held, 0 for WINDOW_DEFAULT
+
+	mode   inc.FeedMode
+	points []inc.CANON_POSITION // the start of the feeds held and their ends
+}
+
+var _ inc.Canon_i = &Fitter_t{}
+var _ inc.Canon_ext_i = &Fitter_t{}
+var _ inc.Canon_machine_i = &Fitter_t{}
+var _ inc.Comp_i = &Fitter_t{}
+var _ inc.Feed_mode_i = &Fitter_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Fitter_t passing its output to next, fitting within
+   tolerance millimeters, TOLERANCE_DEFAULT if tolerance is not positive
+
+   Side effects: none
+
+   Called by: external programs, Middleware
+
+*/
+
+func New(next inc.Canon_i, tolerance float64) *Fitter_t {
+	if tolerance <= 0.0 {
+		tolerance = TOLERANCE_DEFAULT
+	}
+	return &Fitter_t{Filter_t: mux.Filter_t{Canon_i: next}, Tolerance: tolerance, mode: inc.UNITS_PER_MINUTE}
+}
+
+/* Middleware
+
+   Returned Value: a mux.Middleware_f making a Fitter_t with tolerance
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func Middleware(tolerance float64) mux.Middleware_f {
+	return func(next inc.Canon_i) inc.Canon_i {
+		return New(next, tolerance)
+	}
+}
+
+/* Flush
+
+   Returned Value: none
+
+   Side effects: the feeds held are fitted and passed on.
+
+   Called by: external programs, STRAIGHT_FEED, and the other commands
+   of Fitter_t
+
+   A program which ends without PROGRAM_END should be followed by a
+   call to this.
+
+*/
+
+func (f *Fitter_t) Flush() {
+	points := f.points
+	f.points = nil
+	if len(points) < 2 {
+		return
+	}
+	plane := f.Canon_i.GET_EXTERNAL_PLANE()
+	tolerance := f.Tolerance
+	switch f.Canon_i.GET_EXTERNAL_LENGTH_UNIT_TYPE() {
+	case inc.CANON_UNITS_INCHES:
+		tolerance = tolerance / 25.4
+	case inc.CANON_UNITS_CM:
+		tolerance = tolerance / 10.0
+	}
+
+	path := make([]point_t, len(points))
+	for n := range points {
+		path[n].first, path[n].second, _ = toolpath.Plane_point(plane, points[n])
+	}
+	in, out := directions(path)
+	for i := 0; i < len(path)-1; {
+		var arcs [2]arc_t
+		j := i + 1
+		for k := i + 2; k < len(path); k++ {
+			fitted, ok := fit(path[i:k+1], out[i], in[k], tolerance)
+			if !ok {
+				break
+			}
+			arcs, j = fitted, k
+		}
+		if j == i+1 {
+			end := points[j]
+			f.Canon_i.STRAIGHT_FEED(end.X, end.Y, end.Z, end.A, end.B, end.C)
+		} else {
+			f.arc_feed(plane, points[j], arcs[0])
+			f.arc_feed(plane, points[j], arcs[1])
+		}
+		i = j
+	}
+}
+
+/* arc_feed
+
+   Returned Value: none
+
+   Side effects: a STRAIGHT_FEED or ARC_FEED along a is passed on.
+
+   Called by: Flush
+
+   The position along the axis of the plane, and of the rotary axes, is
+   that of like, the end of the run.
+
+*/
+
+func (f *Fitter_t) arc_feed(plane inc.CANON_PLANE, like inc.CANON_POSITION, a arc_t) {
+	_, _, axis := toolpath.Plane_point(plane, like)
+	if a.sweep == 0.0 {
+		end := toolpath.Plane_position(plane, a.to.first, a.to.second, axis)
+		f.Canon_i.STRAIGHT_FEED(end.X, end.Y, end.Z, like.A, like.B, like.C)
+		return
+	}
+	f.Canon_i.ARC_FEED(a.to.first, a.to.second, a.center.first, a.center.second,
+		inc.If(a.sweep > 0.0, 1, -1).(int), axis, like.A, like.B, like.C)
+}
+
+/* directions
+
+   Returned Value: the direction (a unit vector) of the path into, and
+   out of, each of its points
+
+   Side effects: none
+
+   Called by: Flush
+
+   At a smooth point, this is the direction of the circle through the
+   points on either side, which is |c|^2 a + |a|^2 c for a the feed in
+   and c the feed out. At the ends, it is that of the circle through the
+   next two points, if the next is smooth, found by reflecting the
+   direction there in the feed between them.
+
+*/
+
+func directions(path []point_t) (in, out []point_t) {
+	last := len(path) - 1
+	in, out = make([]point_t, len(path)), make([]point_t, len(path))
+	smooth := make([]bool, len(path))
+	for n := 1; n < last; n++ {
+		a, c := path[n].minus(path[n-1]), path[n+1].minus(path[n])
+		in[n], out[n] = a.unit(), c.unit()
+		if in[n].dot(out[n]) >= math.Cos(corner_angle) {
+			smooth[n] = true
+			in[n] = a.times(c.dot(c)).plus(c.times(a.dot(a))).unit()
+			out[n] = in[n]
+		}
+	}
+	reflect := func(direction, feed point_t) point_t {
+		return feed.times(2.0 * direction.dot(feed)).minus(direction)
+	}
+	out[0] = path[1].minus(path[0]).unit()
+	if smooth[1] {
+		out[0] = reflect(in[1], out[0])
+	}
+	in[last] = path[last].minus(path[last-1]).unit()
+	if smooth[last-1] {
+		in[last] = reflect(out[last-1], in[last])
+	}
+	return in, out
+}
+
+/* fit
+
+   Returned Value: the biarc from the first point of path going in
+   direction start to the last arriving in direction end, and whether it
+   fits path within tolerance
+
+   Side effects: none
+
+   Called by: Flush
+
+   The points of path, and the middles of the feeds, are checked against
+   the biarc, and points along the biarc, at least every 1/32 of a turn,
+   against the feeds.
+
+*/
+
+func fit(path []point_t, start, end point_t, tolerance float64) (arcs [2]arc_t, ok bool) {
+	last := len(path) - 1
+	if arcs, ok = biarc(path[0], start, path[last], end, tolerance/4.0); !ok {
+		return arcs, false
+	}
+	near := func(p point_t) bool {
+		return math.Min(arcs[0].distance(p), arcs[1].distance(p)) <= tolerance
+	}
+	for n := 0; n < last; n++ {
+		if ((n > 0) && !near(path[n])) || !near(path[n].plus(path[n+1]).times(0.5)) {
+			return arcs, false
+		}
+	}
+	for _, a := range arcs {
+		samples := 4 + int(math.Abs(a.sweep)/(math.Pi/16.0))
+		for m := 1; m < samples; m++ {
+			p := a.at(float64(m) / float64(samples))
+			away := math.Inf(1)
+			for n := 0; n < last; n++ {
+				away = math.Min(away, to_segment(p, path[n], path[n+1]))
+			}
+			if away > tolerance {
+				return arcs, false
+			}
+		}
+	}
+	return arcs, true
+}
+
+/***********************************************************************/
+
+/* STRAIGHT_FEED
+
+   Side effects: the feed is held, or the feeds held are passed on and
+   then this one.
+
+   Called by: the interpreter
+
+*/
+
+func (f *Fitter_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	end := inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+	if len(f.points) == 0 {
+		f.points = append(f.points, f.position())
+	}
+	plane := f.Canon_i.GET_EXTERNAL_PLANE()
+	start := f.points[0]
+	_, _, axis1 := toolpath.Plane_point(plane, start)
+	_, _, axis2 := toolpath.Plane_point(plane, end)
+	if (f.mode == inc.INVERSE_TIME) || (axis1 != axis2) || (start.A != a) || (start.B != b) || (start.C != c) {
+		f.Flush()
+		f.Canon_i.STRAIGHT_FEED(x, y, z, a, b, c)
+		return
+	}
+	if end != f.points[len(f.points)-1] {
+		f.points = append(f.points, end)
+	}
+	if len(f.points) > inc.If(f.Window > 0, f.Window, WINDOW_DEFAULT).(int) {
+		f.Flush()
+	}
+}
+
+// position returns where the next Canon_i is.
+func (f *Fitter_t) position() inc.CANON_POSITION {
+	next := f.Canon_i
+	return inc.CANON_POSITION{X: next.GET_EXTERNAL_POSITION_X(), Y: next.GET_EXTERNAL_POSITION_Y(),
+		Z: next.GET_EXTERNAL_POSITION_Z(), A: next.GET_EXTERNAL_POSITION_A(),
+		B: next.GET_EXTERNAL_POSITION_B(), C: next.GET_EXTERNAL_POSITION_C()}
+}
+
+func (f *Fitter_t) SET_FEED_MODE(mode inc.FeedMode) {
+	f.Flush()
+	f.mode = mode
+	if feed, ok := f.Canon_i.(inc.Feed_mode_i); ok {
+		feed.SET_FEED_MODE(mode)
+	}
+}
+
+func (f *Fitter_t) SET_FEED_RATE(rate float64) {
+	if rate != f.Canon_i.GET_EXTERNAL_FEED_RATE() {
+		f.Flush()
+	}
+	f.Canon_i.SET_FEED_RATE(rate)
+}
+
+func (f *Fitter_t) GET_EXTERNAL_QUEUE_EMPTY() int {
+	if len(f.points) > 1 {
+		return 0
+	}
+	return f.Canon_i.GET_EXTERNAL_QUEUE_EMPTY()
+}
+
+func (f *Fitter_t) GET_EXTERNAL_POSITION_A() float64 {
+	if len(f.points) > 0 {
+		return f.points[len(f.points)-1].A
+	}
+	return f.Canon_i.GET_EXTERNAL_POSITION_A()
+}
+
+func (f *Fitter_t) GET_EXTERNAL_POSITION_B() float64 {
+	if len(f.points) > 0 {
+		return f.points[len(f.points)-1].B
+	}
+	return f.Canon_i.GET_EXTERNAL_POSITION_B()
+}
+
+func (f *Fitter_t) GET_EXTERNAL_POSITION_C() float64 {
+	if len(f.points) > 0 {
+		return f.points[len(f.points)-1].C
+	}
+	return f.Canon_i.GET_EXTERNAL_POSITION_C()
+}
+
+func (f *Fitter_t) GET_EXTERNAL_POSITION_X() float64 {
+	if len(f.points) > 0 {
+		return f.points[len(f.points)-1].X
+	}
+	return f.Canon_i.GET_EXTERNAL_POSITION_X()
+}
+
+func (f *Fitter_t) GET_EXTERNAL_POSITION_Y() float64 {
+	if len(f.points) > 0 {
+		return f.points[len(f.points)-1].Y
+	}
+	return f.Canon_i.GET_EXTERNAL_POSITION_Y()
+}
+
+func (f *Fitter_t) GET_EXTERNAL_POSITION_Z() float64 {
+	if len(f.points) > 0 {
+		return f.points[len(f.points)-1].Z
+	}
+	return f.Canon_i.GET_EXTERNAL_POSITION_Z()
+}
@@ -0,0 +1,251 @@
+package arcfit
+
+import "github.com/flyingyizi/rs274ngc/inc"
+
+/* The other canonical commands of Fitter_t
+
+   Each is passed on after the feeds held. The canonical extensions are
+   passed on as mux.Filter_t passes them.
+
+*/
+
+func (f *Fitter_t) COMMENT(s string) {
+	f.Flush()
+	f.Canon_i.COMMENT(s)
+}
+
+func (f *Fitter_t) DISABLE_FEED_OVERRIDE() {
+	f.Flush()
+	f.Canon_i.DISABLE_FEED_OVERRIDE()
+}
+
+func (f *Fitter_t) DISABLE_SPEED_OVERRIDE() {
+	f.Flush()
+	f.Canon_i.DISABLE_SPEED_OVERRIDE()
+}
+
+func (f *Fitter_t) ENABLE_FEED_OVERRIDE() {
+	f.Flush()
+	f.Canon_i.ENABLE_FEED_OVERRIDE()
+}
+
+func (f *Fitter_t) ENABLE_SPEED_OVERRIDE() {
+	f.Flush()
+	f.Canon_i.ENABLE_SPEED_OVERRIDE()
+}
+
+func (f *Fitter_t) INIT_CANON() {
+	f.Flush()
+	f.Canon_i.INIT_CANON()
+}
+
+func (f *Fitter_t) MESSAGE(s []byte) {
+	f.Flush()
+	f.Canon_i.MESSAGE(s)
+}
+
+func (f *Fitter_t) PALLET_SHUTTLE() {
+	f.Flush()
+	f.Canon_i.PALLET_SHUTTLE()
+}
+
+func (f *Fitter_t) OPTIONAL_PROGRAM_STOP() {
+	f.Flush()
+	f.Canon_i.OPTIONAL_PROGRAM_STOP()
+}
+
+func (f *Fitter_t) PROGRAM_END() {
+	f.Flush()
+	f.Canon_i.PROGRAM_END()
+}
+
+func (f *Fitter_t) PROGRAM_STOP() {
+	f.Flush()
+	f.Canon_i.PROGRAM_STOP()
+}
+
+func (f *Fitter_t) SELECT_PLANE(plane inc.CANON_PLANE) {
+	f.Flush()
+	f.Canon_i.SELECT_PLANE(plane)
+}
+
+func (f *Fitter_t) SET_FEED_REFERENCE(reference inc.CANON_FEED_REFERENCE) {
+	f.Flush()
+	f.Canon_i.SET_FEED_REFERENCE(reference)
+}
+
+func (f *Fitter_t) SET_MOTION_CONTROL_MODE(mode inc.CANON_MOTION_MODE) {
+	f.Flush()
+	f.Canon_i.SET_MOTION_CONTROL_MODE(mode)
+}
+
+func (f *Fitter_t) START_SPEED_FEED_SYNCH() {
+	f.Flush()
+	f.Canon_i.START_SPEED_FEED_SYNCH()
+}
+
+func (f *Fitter_t) STOP_SPEED_FEED_SYNCH() {
+	f.Flush()
+	f.Canon_i.STOP_SPEED_FEED_SYNCH()
+}
+
+func (f *Fitter_t) SET_CUTTER_RADIUS_COMPENSATION(radius float64) {
+	f.Flush()
+	f.Filter_t.SET_CUTTER_RADIUS_COMPENSATION(radius)
+}
+
+func (f *Fitter_t) START_CUTTER_RADIUS_COMPENSATION(side inc.CANON_SIDE) {
+	f.Flush()
+	f.Filter_t.START_CUTTER_RADIUS_COMPENSATION(side)
+}
+
+func (f *Fitter_t) STOP_CUTTER_RADIUS_COMPENSATION() {
+	f.Flush()
+	f.Filter_t.STOP_CUTTER_RADIUS_COMPENSATION()
+}
+
+func (f *Fitter_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+	f.Flush()
+	f.Canon_i.ARC_FEED(first_end, second_end, first_axis, second_axis, rotation, axis_end_point, a, b, c)
+}
+
+func (f *Fitter_t) DWELL(seconds float64) {
+	f.Flush()
+	f.Canon_i.DWELL(seconds)
+}
+
+func (f *Fitter_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	f.Flush()
+	f.Canon_i.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+}
+
+func (f *Fitter_t) USE_LENGTH_UNITS(in_unit inc.CANON_UNITS) {
+	f.Flush()
+	f.Canon_i.USE_LENGTH_UNITS(in_unit)
+}
+
+func (f *Fitter_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	f.Flush()
+	f.Canon_i.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+}
+
+func (f *Fitter_t) ORIENT_SPINDLE(orientation float64, direction inc.CANON_DIRECTION) {
+	f.Flush()
+	f.Canon_i.ORIENT_SPINDLE(orientation, direction)
+}
+
+func (f *Fitter_t) SET_SPINDLE_SPEED(r float64) {
+	f.Flush()
+	f.Canon_i.SET_SPINDLE_SPEED(r)
+}
+
+func (f *Fitter_t) START_SPINDLE_CLOCKWISE() {
+	f.Flush()
+	f.Canon_i.START_SPINDLE_CLOCKWISE()
+}
+
+func (f *Fitter_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	f.Flush()
+	f.Canon_i.START_SPINDLE_COUNTERCLOCKWISE()
+}
+
+func (f *Fitter_t) STOP_SPINDLE_TURNING() {
+	f.Flush()
+	f.Canon_i.STOP_SPINDLE_TURNING()
+}
+
+func (f *Fitter_t) FLOOD_OFF() {
+	f.Flush()
+	f.Canon_i.FLOOD_OFF()
+}
+
+func (f *Fitter_t) FLOOD_ON() {
+	f.Flush()
+	f.Canon_i.FLOOD_ON()
+}
+
+func (f *Fitter_t) MIST_OFF() {
+	f.Flush()
+	f.Canon_i.MIST_OFF()
+}
+
+func (f *Fitter_t) MIST_ON() {
+	f.Flush()
+	f.Canon_i.MIST_ON()
+}
+
+func (f *Fitter_t) CHANGE_TOOL(slot int) {
+	f.Flush()
+	f.Canon_i.CHANGE_TOOL(slot)
+}
+
+func (f *Fitter_t) SELECT_TOOL(i int) {
+	f.Flush()
+	f.Canon_i.SELECT_TOOL(i)
+}
+
+func (f *Fitter_t) USE_TOOL_LENGTH_OFFSET(offset float64) {
+	f.Flush()
+	f.Canon_i.USE_TOOL_LENGTH_OFFSET(offset)
+}
+
+func (f *Fitter_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	f.Flush()
+	f.Canon_i.STRAIGHT_PROBE(x, y, z, a, b, c)
+}
+
+func (f *Fitter_t) TURN_PROBE_OFF() {
+	f.Flush()
+	f.Canon_i.TURN_PROBE_OFF()
+}
+
+func (f *Fitter_t) TURN_PROBE_ON() {
+	f.Flush()
+	f.Canon_i.TURN_PROBE_ON()
+}
+
+func (f *Fitter_t) SET_TRAVERSE_RATE(rate float64) {
+	f.Flush()
+	f.Filter_t.SET_TRAVERSE_RATE(rate)
+}
+
+func (f *Fitter_t) STOP() {
+	f.Flush()
+	f.Filter_t.STOP()
+}
+
+func (f *Fitter_t) SET_SPINDLE_TORQUE(torque float64) {
+	f.Flush()
+	f.Filter_t.SET_SPINDLE_TORQUE(torque)
+}
+
+func (f *Fitter_t) SPINDLE_RETRACT() {
+	f.Flush()
+	f.Filter_t.SPINDLE_RETRACT()
+}
+
+func (f *Fitter_t) SPINDLE_RETRACT_TRAVERSE() {
+	f.Flush()
+	f.Filter_t.SPINDLE_RETRACT_TRAVERSE()
+}
+
+func (f *Fitter_t) USE_NO_SPINDLE_FORCE() {
+	f.Flush()
+	f.Filter_t.USE_NO_SPINDLE_FORCE()
+}
+
+func (f *Fitter_t) USE_SPINDLE_FORCE(force float64) {
+	f.Flush()
+	f.Filter_t.USE_SPINDLE_FORCE(force)
+}
+
+func (f *Fitter_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
+	f.Flush()
+	f.Filter_t.CLAMP_AXIS(axis)
+}
+
+func (f *Fitter_t) UNCLAMP_AXIS(axis inc.CANON_AXIS) {
+	f.Flush()
+	f.Filter_t.UNCLAMP_AXIS(axis)
+}
@@ -0,0 +1,119 @@
+package arcfit_test
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/arcfit"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/record"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+// run runs program through a Fitter_t with tolerance in front of next,
+// whose world model is world.
+func run(t *testing.T, next inc.Canon_i, world *inc.Canon_world_t, tolerance float64, program ...string) {
+	cnctest.Run(t, cnctest.Start(t, arcfit.New(next, tolerance), world), program...)
+}
+
+// circle returns lines feeding around a circle of radius about X0 Y0 in
+// steps of step degrees, clockwise from X radius Y0, and ending the
+// program.
+func circle(radius, step float64) []string {
+	program := []string{"g21 g17 g0 x" + fmt.Sprint(radius) + " y0 z-1", "g1 x" + fmt.Sprint(radius) + " y0 f100"}
+	for angle := step; angle <= 360.0; angle = angle + step {
+		s, c := math.Sincos(-angle * math.Pi / 180.0)
+		program = append(program, fmt.Sprintf("x%.6f y%.6f", radius*c, radius*s))
+	}
+	return append(program, "m2")
+}
+
+func TestFitter_circle(t *testing.T) {
+	path := toolpath.New()
+	run(t, path, &path.Canon_world_t, 0.01, circle(10, 5)...)
+	moves := path.Moves[1:]
+	if (len(moves) == 0) || (len(moves) > 8) {
+		t.Fatalf("%d moves for a circle of 72 feeds, want 1 to 8", len(moves))
+	}
+	arcs := 0
+	for n := range moves {
+		move := &moves[n]
+		if move.Kind == toolpath.MOVE_ARC {
+			arcs++
+		}
+		if ((move.Kind == toolpath.MOVE_ARC) && (move.Rotation != -1)) || (move.End.Z != -1) {
+			t.Errorf("move %d = %+v, want a clockwise arc or a feed at Z-1", n, *move)
+		}
+		for f := 0.0; f <= 1.0; f = f + 0.125 {
+			// 0.0095 from the chords to the circle and 0.01 to the fit
+			if p := move.Point_at(f); math.Abs(math.Hypot(p.X, p.Y)-10) > 0.0195 {
+				t.Errorf("move %d is %v from the circle at %v", n, math.Hypot(p.X, p.Y)-10, f)
+			}
+		}
+		if (n > 0) && (move.Start != moves[n-1].End) {
+			t.Errorf("move %d starts at %+v, not where move %d ends", n, move.Start, n-1)
+		}
+	}
+	// A biarc cannot close a circle, so the last feed may be left.
+	if arcs < len(moves)-1 {
+		t.Errorf("%d arcs of %d moves, want all but one", arcs, len(moves))
+	}
+	if end := moves[len(moves)-1].End; (end.X != 10) || (math.Abs(end.Y) > 1e-6) {
+		t.Errorf("ends at X%v Y%v, want X10 Y0", end.X, end.Y)
+	}
+}
+
+func TestFitter_corners(t *testing.T) {
+	// A square with sides of ten feeds keeps its corners.
+	program := []string{"g21 g17 g1 x0 y0 f100", "g91"}
+	for _, side := range [][2]float64{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+		for n := 0; n < 10; n++ {
+			program = append(program, fmt.Sprintf("x%v y%v", side[0], side[1]))
+		}
+	}
+	path := toolpath.New()
+	run(t, path, &path.Canon_world_t, 0.01, append(program, "g90", "m2")...)
+	corners := 0
+	for n := 1; n < len(path.Moves); n++ {
+		move := &path.Moves[n]
+		if move.Kind != toolpath.MOVE_FEED {
+			t.Errorf("move %d = %+v, want a straight feed", n, *move)
+		}
+		x, y := math.Abs(move.End.X-5), math.Abs(move.End.Y-5)
+		if math.Abs(math.Max(x, y)-5) > 1e-9 {
+			t.Errorf("move %d ends at %+v, off the square", n, move.End)
+		}
+		if (x == 5) && (y == 5) {
+			corners++
+		}
+	}
+	if (corners != 4) || (len(path.Moves) > 1+(4*2)) {
+		t.Errorf("%d moves through %d corners, want no more than 8 through 4", len(path.Moves)-1, corners)
+	}
+}
+
+func TestFitter_order(t *testing.T) {
+	// The feeds held go before the spindle starts (the last around the
+	// circle as it is), feeds out of the plane pass, and nothing is fitted in inverse time feed mode.
+	rec := record.New()
+	program := circle(10, 5)
+	program = append(program[:len(program)-1], "m3 s1000", "z-2", "g93 x0 y10 f1", "x10 y0 f1", "m2")
+	run(t, rec, &rec.Canon_world_t, 0.01, program...)
+	names := strings.Join(rec.Names(), " ")
+	if i := strings.Index(names, "START_SPINDLE_CLOCKWISE"); (i < 0) || !strings.Contains(names[:i], "ARC_FEED") ||
+		strings.Contains(names[i:], "ARC_FEED") {
+		t.Errorf("calls %s, want the arcs before the spindle starts", names)
+	}
+	feeds := rec.Filter("STRAIGHT_FEED")
+	if want := []string{
+		"STRAIGHT_FEED(10.0000, 0.0000, -1.0000, 0.0000, 0.0000, 0.0000)",
+		"STRAIGHT_FEED(10.0000, 0.0000, -2.0000, 0.0000, 0.0000, 0.0000)",
+		"STRAIGHT_FEED(0.0000, 10.0000, -2.0000, 0.0000, 0.0000, 0.0000)",
+		"STRAIGHT_FEED(10.0000, 0.0000, -2.0000, 0.0000, 0.0000, 0.0000)",
+	}; strings.Join(record.Strings(feeds), " ") != strings.Join(want, " ") {
+		t.Errorf("straight feeds %v, want %v", record.Strings(feeds), want)
+	}
+}
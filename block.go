@@ -19,8 +19,8 @@ import (
    group 4  - no such group
    group 5  - gez[7]  g93, g94 - feed rate mode
    group 6  - gez[5]  g20, g21 - units
-   group 7  - gez[4]  g40, g41, g42 - cutter radius compensation
-   group 8  - gez[9]  g43, g49 - tool length offset
+   group 7  - gez[4]  g40, g41, g41.1, g42, g42.1 - cutter radius compensation
+   group 8  - gez[9]  g43, g43.1, g43.2, g43.4, g49 - tool length offset
    group 9  - no such group
    group 10 - gez[10] g98, g99 - return mode in canned cycles
    group 11 - no such group
@@ -36,8 +36,8 @@ var _gees map[int]int = map[int]int{ /*key:code, value:group*/
 	900: 3, 910: 3,
 	930: 5, 940: 5,
 	200: 6, 210: 6,
-	400: 7, 410: 7, 420: 7,
-	430: 8, 490: 8,
+	400: 7, 410: 7, 411: 7, 420: 7, 421: 7,
+	430: 8, 431: 8, 432: 8, 434: 8, 490: 8,
 	980: 10, 990: 10,
 	540: 12, 550: 12, 560: 12, 570: 12, 580: 12, 590: 12, 591: 12, 592: 12, 593: 12,
 
@@ -70,10 +70,11 @@ const (
 
    Modal groups and modal group numbers for M codes are not described in
    [Fanuc]. We have used the groups from [NCMS] and added M60, as an
-   extension of the language for pallet shuttle and stop. This version has
-   no codes related to axis clamping.
+   extension of the language for pallet shuttle and stop. Automatic
+   A-axis clamping (m26, m27) is in group 2.
 
    The groups are:
+   group 2 = {m26,m27}          - automatic a-axis clamping
    group 4 = {m0,m1,m2,m30,m60} - stopping
    group 6 = {m6}               - tool change
    group 7 = {m3,m4,m5}         - spindle turning
@@ -83,6 +84,7 @@ const (
 */
 var _ems map[int]int = map[int]int{ /*key:code, value:group*/
 	0: 4, 1: 4, 2: 4, 30: 4, 60: 4,
+	26: 2, 27: 2,
 	6: 6,
 	3: 7, 4: 7, 5: 7,
 	7: 8, 8: 8, 9: 8,
@@ -121,6 +123,7 @@ type Block_t struct {
 
 	comment  string
 	d_number int
+	d_value  float64 // d word as read, the diameter with G41.1 and G42.1
 	f_number float64
 	// g_modes array in the block keeps track of which G modal groups are used on a line of code
 	g_modes  [GModalGroupLen]inc.GCodes
@@ -249,6 +252,8 @@ func (block *Block_t) Init_block() int {
    5. Axis values are given, but there is neither a g-code in the block
    nor an active previously given modal g-code that uses axis values:
    NCE_CANNOT_USE_AXIS_VALUES_WITHOUT_A_G_CODE_THAT_USES_THEM
+   6. A G43.1 or a G43.2 with a z value is in the block and an axis value
+   other than z is given: NCE_ONLY_Z_WORD_ALLOWED_WITH_G43_1_OR_G43_2
 
    Side effects:
    The value of motion_to_be in the block is set.
@@ -261,6 +266,10 @@ func (block *Block_t) Init_block() int {
    g_modes[0]), set motion_to_be to be the last motion saved (in
    settings->motion mode).
 
+   G43.1 always, and G43.2 when there is a z value, use the z value as a
+   tool length offset, so they are treated like the group 0 codes which
+   use axis values.
+
    This also make the checks described above.
 
 */
@@ -271,6 +280,7 @@ func (block *Block_t) Enhance_block(settings *Setup_t) int { /* pointer to machi
 	var (
 		axis_flag             ON_OFF
 		mode_zero_covets_axes bool
+		length_covets_axes    bool
 	)
 
 	axis_flag = ((block.x_flag == ON) ||
@@ -283,10 +293,22 @@ func (block *Block_t) Enhance_block(settings *Setup_t) int { /* pointer to machi
 		(block.g_modes[GCodeMisc] == inc.G_28) ||
 		(block.g_modes[GCodeMisc] == inc.G_30) ||
 		(block.g_modes[GCodeMisc] == inc.G_92))
+	length_covets_axes = ((block.g_modes[GCodeToolLengthOffset] == inc.G_43_1) ||
+		((block.g_modes[GCodeToolLengthOffset] == inc.G_43_2) && (block.z_flag == ON)))
+
+	if length_covets_axes {
+		if mode_zero_covets_axes {
+			return inc.NCE_CANNOT_USE_TWO_G_CODES_THAT_BOTH_USE_AXIS_VALUES
+		}
+		if (block.x_flag == ON) || (block.y_flag == ON) ||
+			(block.a_flag == ON) || (block.b_flag == ON) || (block.c_flag == ON) {
+			return inc.NCE_ONLY_Z_WORD_ALLOWED_WITH_G43_1_OR_G43_2
+		}
+	}
 
 	if block.g_modes[GCodeMotion] != -1 {
 		if block.g_modes[GCodeMotion] == inc.G_80 {
-			if axis_flag == ON && (!mode_zero_covets_axes) {
+			if axis_flag == ON && (!mode_zero_covets_axes) && (!length_covets_axes) {
 				return inc.NCE_CANNOT_USE_AXIS_VALUES_WITH_G80
 			}
 
@@ -294,7 +316,7 @@ func (block *Block_t) Enhance_block(settings *Setup_t) int { /* pointer to machi
 				return inc.NCE_ALL_AXES_MISSING_WITH_G92
 			}
 		} else {
-			if mode_zero_covets_axes {
+			if mode_zero_covets_axes || length_covets_axes {
 				return inc.NCE_CANNOT_USE_TWO_G_CODES_THAT_BOTH_USE_AXIS_VALUES
 			}
 			if !axis_flag {
@@ -302,7 +324,7 @@ func (block *Block_t) Enhance_block(settings *Setup_t) int { /* pointer to machi
 			}
 		}
 		block.motion_to_be = block.g_modes[GCodeMotion]
-	} else if mode_zero_covets_axes { /* other 3 can get by without axes but not G92 */
+	} else if mode_zero_covets_axes || length_covets_axes { /* other 3 can get by without axes but not G92 */
 		if (!axis_flag) && (block.g_modes[GCodeMisc] == inc.G_92) {
 			return inc.NCE_ALL_AXES_MISSING_WITH_G92
 		}
@@ -447,9 +469,15 @@ func (block *Block_t) check_g_codes(settings *Setup_t) int { /* pointer to machi
 func (block *Block_t) Check_items(settings *Setup_t) int {
 	//static char name[] SET_TO "check_items";
 
-	block.check_g_codes(settings)
-	block.check_m_codes()
-	block.check_other_codes()
+	if status := block.check_g_codes(settings); status != inc.RS274NGC_OK {
+		return status
+	}
+	if status := block.check_m_codes(); status != inc.RS274NGC_OK {
+		return status
+	}
+	if status := block.check_other_codes(); status != inc.RS274NGC_OK {
+		return status
+	}
 	return inc.RS274NGC_OK
 }
 
@@ -499,19 +527,21 @@ func (block *Block_t) check_m_codes() int {
    NCE_CANNOT_PUT_A_C_IN_CANNED_CYCLE
    4. A d word is in a block with no cutter_radius_compensation_on command:
    NCE_D_WORD_WITH_NO_G41_OR_G42
-   5. An h_number is in a block with no tool length offset setting:
-   NCE_H_WORD_WITH_NO_G43
+   A d word with G41 or G42 (where it is a tool table index) is not an
+   integer: NCE_NON_INTEGER_VALUE_FOR_INTEGER
+   5. An h_number is in a block with no tool length offset setting
+   (G43, G43.2 or G43.4): NCE_H_WORD_WITH_NO_G43
    6. An i_number is in a block with no G code that uses it:
    NCE_I_WORD_WITH_NO_G2_OR_G3_OR_G87_TO_USE_IT
    7. A j_number is in a block with no G code that uses it:
    NCE_J_WORD_WITH_NO_G2_OR_G3_OR_G87_TO_USE_IT
    8. A k_number is in a block with no G code that uses it:
    NCE_K_WORD_WITH_NO_G2_OR_G3_OR_G87_TO_USE_IT
-   9. A l_number is in a block with no G code that uses it:
-   NCE_L_WORD_WITH_NO_CANNED_CYCLE_OR_G10
-   10. A p_number is in a block with no G code that uses it:
-   NCE_P_WORD_WITH_NO_G4_G10_G82_G86_G88_G89
-   11. A q_number is in a block with no G code that uses it:
+   9. A l_number is in a block with no G code that uses it (a canned
+   cycle, G10, G41.1 or G42.1): NCE_L_WORD_WITH_NO_CANNED_CYCLE_OR_G10
+   10. A p_number is in a block with no G code that uses it (G4, G10,
+   G64, G82, G86, G88 or G89): NCE_P_WORD_WITH_NO_G4_G10_G82_G86_G88_G89
+   11. A q_number is in a block with no G code that uses it (G64 or G83):
    NCE_Q_WORD_WITH_NO_G83
    12. An r_number is in a block with no G code that uses it:
    NCE_R_WORD_WITH_NO_G_CODE_THAT_USES_IT
@@ -551,14 +581,20 @@ func (block *Block_t) check_other_codes() int {
 		}
 	}
 	if block.d_number != -1 {
-		if (block.g_modes[GCodeCutterRadiusCompensation] != inc.G_41) &&
-			(block.g_modes[GCodeCutterRadiusCompensation] != inc.G_42) {
+		comp := block.g_modes[GCodeCutterRadiusCompensation]
+		if (comp != inc.G_41) && (comp != inc.G_42) &&
+			(comp != inc.G_41_1) && (comp != inc.G_42_1) {
 			return inc.NCE_D_WORD_WITH_NO_G41_OR_G42
 		}
-
+		if ((comp == inc.G_41) || (comp == inc.G_42)) &&
+			(math.Abs(block.d_value-float64(block.d_number)) > 0.0001) {
+			return inc.NCE_NON_INTEGER_VALUE_FOR_INTEGER
+		}
 	}
 	if block.h_number != -1 {
-		if block.g_modes[GCodeToolLengthOffset] != inc.G_43 {
+		if (block.g_modes[GCodeToolLengthOffset] != inc.G_43) &&
+			(block.g_modes[GCodeToolLengthOffset] != inc.G_43_2) &&
+			(block.g_modes[GCodeToolLengthOffset] != inc.G_43_4) {
 			return inc.NCE_H_WORD_WITH_NO_G43
 		}
 	}
@@ -585,7 +621,9 @@ func (block *Block_t) check_other_codes() int {
 	}
 	if block.l_number != -1 {
 		if ((motion < inc.G_81) || (motion > inc.G_89)) &&
-			(block.g_modes[GCodeMisc] != inc.G_10) {
+			(block.g_modes[GCodeMisc] != inc.G_10) &&
+			(block.g_modes[GCodeCutterRadiusCompensation] != inc.G_41_1) &&
+			(block.g_modes[GCodeCutterRadiusCompensation] != inc.G_42_1) {
 			return inc.NCE_L_WORD_WITH_NO_CANNED_CYCLE_OR_G10
 		}
 
@@ -593,13 +631,14 @@ func (block *Block_t) check_other_codes() int {
 	if block.p_number != -1.0 {
 		if (block.g_modes[GCodeMisc] != inc.G_10) &&
 			(block.g_modes[GCodeMisc] != inc.G_4) &&
+			(block.g_modes[GCodeControlMode] != inc.G_64) &&
 			(motion != inc.G_82) && (motion != inc.G_86) &&
 			(motion != inc.G_88) && (motion != inc.G_89) {
 			return inc.NCE_P_WORD_WITH_NO_G4_G10_G82_G86_G88_G89
 		}
 	}
 	if block.q_number != -1.0 {
-		if motion != inc.G_83 {
+		if (motion != inc.G_83) && (block.g_modes[GCodeControlMode] != inc.G_64) {
 			return inc.NCE_Q_WORD_WITH_NO_G83
 		}
 	}
@@ -654,74 +693,77 @@ func (block *Block_t) Read_items( /* ARGUMENTS
 		///////////////////////
 		switch line[counter] {
 		case '#':
-			block.read_parameter_setting(l, &counter, parameters)
+			s = block.read_parameter_setting(l, &counter, parameters)
 			break
 		case '(':
-			block.read_comment(l, &counter, parameters)
+			s = block.read_comment(l, &counter, parameters)
 			break
 		case 'a': //A A-axis of machine
-			block.read_a(l, &counter, parameters)
+			s = block.read_a(l, &counter, parameters)
 			break
 		case 'b':
-			block.read_b(l, &counter, parameters)
+			s = block.read_b(l, &counter, parameters)
 			break
 		case 'c':
-			block.read_c(l, &counter, parameters)
+			s = block.read_c(l, &counter, parameters)
 			break
 		case 'd':
-			block.read_d(l, &counter, parameters)
+			s = block.read_d(l, &counter, parameters)
 			break
 		case 'f':
-			block.read_f(l, &counter, parameters)
+			s = block.read_f(l, &counter, parameters)
 			break
 		case 'g':
-			block.read_g(l, &counter, parameters)
+			s = block.read_g(l, &counter, parameters)
 			break
 		case 'h':
-			block.read_h(tool_max, l, &counter, parameters)
+			s = block.read_h(tool_max, l, &counter, parameters)
 			break
 		case 'i':
-			block.read_i(l, &counter, parameters)
+			s = block.read_i(l, &counter, parameters)
 			break
 		case 'j':
-			block.read_j(l, &counter, parameters)
+			s = block.read_j(l, &counter, parameters)
 			break
 		case 'k':
-			block.read_k(l, &counter, parameters)
+			s = block.read_k(l, &counter, parameters)
 			break
 		case 'l':
-			block.read_l(l, &counter, parameters)
+			s = block.read_l(l, &counter, parameters)
 			break
 		case 'm':
-			block.read_m(l, &counter, parameters)
+			s = block.read_m(l, &counter, parameters)
 			break
 		case 'p':
-			block.read_p(l, &counter, parameters)
+			s = block.read_p(l, &counter, parameters)
 			break
 		case 'q':
-			block.read_q(l, &counter, parameters)
+			s = block.read_q(l, &counter, parameters)
 			break
 		case 'r':
-			block.read_r(l, &counter, parameters)
+			s = block.read_r(l, &counter, parameters)
 			break
 		case 's':
-			block.read_s(l, &counter, parameters)
+			s = block.read_s(l, &counter, parameters)
 			break
 		case 't':
-			block.read_t(l, &counter, parameters)
+			s = block.read_t(l, &counter, parameters)
 			break
 		case 'x':
-			block.read_x(l, &counter, parameters)
+			s = block.read_x(l, &counter, parameters)
 			break
 		case 'y':
-			block.read_y(l, &counter, parameters)
+			s = block.read_y(l, &counter, parameters)
 			break
 		case 'z':
-			block.read_z(l, &counter, parameters)
+			s = block.read_z(l, &counter, parameters)
 			break
 		default:
 			return inc.NCE_BAD_CHARACTER_USED
 		}
+		if s != inc.RS274NGC_OK {
+			return s
+		}
 
 		///////////////////////
 	}
@@ -1062,7 +1104,7 @@ func (block *Block_t) read_comment( /* ARGUMENTS
 
 	for ; line[n] != ')'; n++ {
 	}
-	block.comment = string(line[*counter : n+1])
+	block.comment = string(line[(*counter + 1):n])
 	*counter = n + 1
 
 	return inc.RS274NGC_OK
@@ -1073,7 +1115,7 @@ func (block *Block_t) read_comment( /* ARGUMENTS
 /* read_d
 
    Returned Value: int
-   If read_integer_value returns an error code, this returns that code.
+   If read_real_value returns an error code, this returns that code.
    If any of the following errors occur, this returns the error code shown.
    Otherwise, it returns RS274NGC_OK.
    1. The first character read is not d:
@@ -1091,12 +1133,16 @@ func (block *Block_t) read_comment( /* ARGUMENTS
 
    When this function is called, counter is pointing at an item on the
    line that starts with the character 'd', indicating an index into a
-   table of tool diameters.  The function reads characters which give the
-   (integer) value of the index. The value may not be more than
-   _setup.tool_max and may not be negative, but it may be zero. The range
-   is checked here.
+   table of tool diameters, or (with G41.1 and G42.1) the diameter itself.
+   The function reads characters which give the value. The value may not
+   be negative, but it may be zero. The range is checked here.
 
-   read_integer_value allows a minus sign, so a check for a negative value
+   Since the meaning depends on the G code, which may come later on the
+   line, the value is kept as read in d_value and also rounded to the
+   nearest integer in d_number. check_other_codes makes sure the value is
+   an integer when it is used as an index.
+
+   read_real_value allows a minus sign, so a check for a negative value
    is made here, and the parameters argument is also needed.
 
 */
@@ -1106,7 +1152,7 @@ func (block *Block_t) read_d( /* ARGUMENTS
 	counter *int, /* pointer to a counter for position on the line  */
 	parameters []float64) inc.STATUS { /* array of system parameters                     */
 
-	var value int
+	var value float64
 
 	if line[*counter] != 'd' {
 		return inc.NCE_BUG_FUNCTION_SHOULD_NOT_HAVE_BEEN_CALLED
@@ -1116,11 +1162,12 @@ func (block *Block_t) read_d( /* ARGUMENTS
 	if block.d_number > -1 {
 		return inc.NCE_MULTIPLE_D_WORDS_ON_ONE_LINE
 	}
-	block.read_integer_value(line, counter, &value, parameters)
+	block.read_real_value(line, counter, &value, parameters)
 	if value < 0 {
 		return inc.NCE_NEGATIVE_D_WORD_TOOL_RADIUS_INDEX_USED
 	}
-	block.d_number = int(value)
+	block.d_value = value
+	block.d_number = int(math.Floor(value + 0.5))
 
 	return inc.RS274NGC_OK
 
@@ -1376,6 +1423,7 @@ func (block *Block_t) read_i( /* ARGUMENTS
 		return inc.NCE_MULTIPLE_I_WORDS_ON_ONE_LINE
 	}
 	block.read_real_value(line, counter, &value, parameters)
+	block.i_flag = ON
 	block.i_number = value
 
 	return inc.RS274NGC_OK
@@ -2104,6 +2152,7 @@ func (block *Block_t) read_m( /* ARGUMENTS
    P codes are used for:
    1. Dwell time in canned cycles g82, G86, G88, G89 [NCMS pages 98 - 100].
    2. A key with G10 [NCMS, pages 9, 10].
+   3. The path tolerance with G64.
 
 */
 func (block *Block_t) read_p( /* ARGUMENTS                                      */
@@ -2117,15 +2166,15 @@ func (block *Block_t) read_p( /* ARGUMENTS
 		return inc.NCE_BUG_FUNCTION_SHOULD_NOT_HAVE_BEEN_CALLED
 	}
 	*counter = (*counter + 1)
-	if block.s_number > -1.0 {
+	if block.p_number > -1.0 {
 		return inc.NCE_MULTIPLE_P_WORDS_ON_ONE_LINE
 	}
 	block.read_real_value(line, counter, &value, parameters)
 
-	if block.p_number < 0.0 {
+	if value < 0.0 {
 		return inc.NCE_NEGATIVE_P_WORD_USED
 	}
-	block.s_number = value
+	block.p_number = value
 
 	return inc.RS274NGC_OK
 
@@ -2157,8 +2206,9 @@ func (block *Block_t) read_p( /* ARGUMENTS
    value, up to the start of the next item or the end of the line. This
    information is inserted in the block.
 
-   Q is used only in the G87 canned cycle [NCMS, page 98], where it must
-   be positive.
+   Q is used in the G87 canned cycle [NCMS, page 98], where it must
+   be positive, and with G64 as the tolerance for running straight moves
+   as one.
 
 */
 
@@ -2178,7 +2228,7 @@ func (block *Block_t) read_q( /* ARGUMENTS
 	}
 	block.read_real_value(line, counter, &value, parameters)
 
-	if block.q_number <= 0.0 {
+	if value <= 0.0 {
 		return inc.NCE_NEGATIVE_OR_ZERO_Q_VALUE_USED
 	}
 	block.q_number = value
@@ -2289,7 +2339,7 @@ func (block *Block_t) read_s( /* ARGUMENTS
 	}
 	block.read_real_value(line, counter, &value, parameters)
 
-	if block.s_number < 0.0 {
+	if value < 0.0 {
 		return inc.NCE_NEGATIVE_SPINDLE_SPEED_USED
 	}
 	block.s_number = value
@@ -2343,7 +2393,7 @@ func (block *Block_t) read_t( /* ARGUMENTS
 	}
 	block.read_integer_value(line, counter, &value, parameters)
 
-	if block.t_number < 0 {
+	if value < 0 {
 		return inc.NCE_NEGATIVE_TOOL_ID_USED
 	}
 	block.t_number = value
@@ -2531,7 +2581,6 @@ func (block *Block_t) read_z( /* ARGUMENTS
    The number read from the line is put into what integer_ptr points at.
 
    Called by:
-   read_d
    read_l
    read_h
    read_m
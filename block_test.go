@@ -0,0 +1,146 @@
+package rs274ngc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/record"
+)
+
+// The tests of reading a line: Read_text, close_and_downcase, Read_items
+// and the read_X functions it calls.
+
+func TestClose_and_downcase(t *testing.T) {
+	cases := []struct {
+		line, want string
+		status     inc.STATUS
+	}{
+		{"G0 X1\tY 2\n", "g0x1y2", inc.RS274NGC_OK},
+		{"G0 X1 (Side One, Pass 2)", "g0x1(Side One, Pass 2)", inc.RS274NGC_OK},
+		{"(MSG, Change To T2) M0", "(MSG, Change To T2)m0", inc.RS274NGC_OK},
+		{"G0 () X1", "g0()x1", inc.RS274NGC_OK},
+		{"g0 x1 (one (two) three)", "g0 x1 (one (two) three)", inc.NCE_NESTED_COMMENT_FOUND},
+		{"g0 x1 (One", "g0x1(One", inc.NCE_UNCLOSED_COMMENT_FOUND},
+	}
+	for _, c := range cases {
+		got, status := close_and_downcase(c.line)
+		if (got != c.want) || (status != c.status) {
+			t.Errorf("close_and_downcase(%q) = %q, %v, want %q, %v", c.line, got, status, c.want, c.status)
+		}
+	}
+}
+
+func TestCNC_negative_words(t *testing.T) {
+	cases := []struct {
+		line   string
+		status inc.STATUS
+		want   []string
+	}{
+		{"s-100", inc.NCE_NEGATIVE_SPINDLE_SPEED_USED, nil},
+		{"t-1", inc.NCE_NEGATIVE_TOOL_ID_USED, nil},
+		{"s100", inc.RS274NGC_OK, []string{"SET_SPINDLE_SPEED(100.0000)"}},
+		{"t2", inc.RS274NGC_OK, []string{"SELECT_TOOL(2)"}},
+	}
+	for _, c := range cases {
+		rec := record.New()
+		cnc := start(t, rec, rec)
+		if status := cnc.execute(c.line); status != c.status {
+			t.Errorf("%s: status %v, want %v", c.line, status, c.status)
+			continue
+		}
+		got := record.Strings(rec.Filter("SET_SPINDLE_SPEED", "SELECT_TOOL"))
+		if (len(got) != len(c.want)) || ((len(got) != 0) && !reflect.DeepEqual(got, c.want)) {
+			t.Errorf("%s: calls %v, want %v", c.line, got, c.want)
+		}
+	}
+}
+
+func TestCNC_file_lines(t *testing.T) {
+	// Blank lines are skipped, and a last line with no newline is read.
+	cases := []struct {
+		name, text string
+		status     inc.STATUS
+	}{
+		{"program end", "g21\n\ng0 x1\n\nm2", inc.RS274NGC_EXIT},
+		{"percents", "%\ng21\n\ng0 x1\n%", inc.RS274NGC_ENDFILE},
+	}
+	for _, c := range cases {
+		filename := filepath.Join(t.TempDir(), "program.ngc")
+		if err := os.WriteFile(filename, []byte(c.text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		rec := record.New()
+		cnc := start(t, rec, rec)
+		if status := cnc.Open(filename); status != inc.RS274NGC_OK {
+			t.Fatalf("%s: Open() = %v", c.name, status)
+		}
+		status := inc.RS274NGC_OK
+		for n := 0; (status == inc.RS274NGC_OK) && (n < 10); n++ {
+			if status = cnc.Read(nil); status == inc.RS274NGC_OK {
+				status = cnc.Execute()
+			}
+		}
+		cnc.Close()
+		if status != c.status {
+			t.Errorf("%s: status %v, want %v", c.name, status, c.status)
+		}
+		if got := record.Strings(rec.Filter("STRAIGHT_TRAVERSE")); (len(got) != 1) ||
+			(got[0] != "STRAIGHT_TRAVERSE(1.0000, 0.0000, 0.0000, 0.0000, 0.0000, 0.0000)") {
+			t.Errorf("%s: traverses %v, want one to X1", c.name, got)
+		}
+	}
+}
+
+func TestCNC_p_words_and_comments(t *testing.T) {
+	// A P word keeps its value, and a comment keeps its text and case,
+	// whether the line is given or read from a file.
+	cases := []struct {
+		line   string
+		status inc.STATUS
+		want   []string
+	}{
+		{"g4 p2.5", inc.RS274NGC_OK, []string{"DWELL(2.5000)"}},
+		{"g4 p-1", inc.NCE_NEGATIVE_P_WORD_USED, nil},
+		{"g4 p1 p2", inc.NCE_MULTIPLE_P_WORDS_ON_ONE_LINE, nil},
+		{"G0 X1 (Side One, Pass 2)", inc.RS274NGC_OK, []string{"COMMENT(\"Side One, Pass 2\")"}},
+		{"(MSG, Change To T2)", inc.RS274NGC_OK, []string{"MESSAGE(\" Change To T2\")"}},
+		{"(msg,Done)", inc.RS274NGC_OK, []string{"MESSAGE(\"Done\")"}},
+		{"()", inc.RS274NGC_OK, nil},
+		{"(s)", inc.RS274NGC_OK, []string{"COMMENT(\"s\")"}},
+		{"g0 x1 (one (two) three)", inc.NCE_NESTED_COMMENT_FOUND, nil},
+		{"g0 x1 (one", inc.NCE_UNCLOSED_COMMENT_FOUND, nil},
+	}
+	for _, c := range cases {
+		for _, file := range []bool{false, true} {
+			rec := record.New()
+			cnc := start(t, rec, rec)
+			var status inc.STATUS
+			if file {
+				filename := filepath.Join(t.TempDir(), "program.ngc")
+				if err := os.WriteFile(filename, []byte(c.line+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if status = cnc.Open(filename); status != inc.RS274NGC_OK {
+					t.Fatalf("Open() = %v", status)
+				}
+				if status = cnc.Read(nil); status == inc.RS274NGC_OK {
+					status = cnc.Execute()
+				}
+				cnc.Close()
+			} else {
+				status = cnc.execute(c.line)
+			}
+			if status != c.status {
+				t.Errorf("%s (file %v): status %v, want %v", c.line, file, status, c.status)
+				continue
+			}
+			got := record.Strings(rec.Filter("DWELL", "COMMENT", "MESSAGE"))
+			if (len(got) != len(c.want)) || ((len(got) != 0) && !reflect.DeepEqual(got, c.want)) {
+				t.Errorf("%s (file %v): calls %v, want %v", c.line, file, got, c.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package run
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc"
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* run.go
+
+   This has what the commands share for running NC programs.
+
+*/
+
+/* File
+
+   Returned Value: error
+   If rs274ngc_init or rs274ngc_open fails, or reading or executing a
+   line fails, this returns an error naming the file, the line and the
+   error. Otherwise, it returns nil.
+
+   Side effects:
+   The interpreter is initialized and the program in filename is run on
+   it, so its canon is given the canonical commands of the program.
+   The file is closed.
+
+   Called by: the commands
+
+   The canon must have been given to cnc (see SetCanon) before this is
+   called. The program ends with M2 or M30, or, if it uses percents,
+   with the second percent line.
+
+*/
+
+func File(cnc *rs274ngc.Rs274ngc_t, filename string) error {
+	if status := cnc.Init(); status != inc.RS274NGC_OK {
+		return fmt.Errorf("%s: %s", filename, inc.Rs274ngc_error_text(status))
+	}
+	if status := cnc.Open(filename); status != inc.RS274NGC_OK {
+		return fmt.Errorf("%s: %s", filename, inc.Rs274ngc_error_text(status))
+	}
+	defer cnc.Close()
+
+	for {
+		status := cnc.Read(nil)
+		if status == inc.RS274NGC_ENDFILE {
+			return nil
+		}
+		if (status == inc.RS274NGC_OK) || (status == inc.RS274NGC_EXECUTE_FINISH) {
+			status = cnc.Execute()
+		}
+		if status == inc.RS274NGC_EXIT {
+			return nil
+		}
+		if (status != inc.RS274NGC_OK) && (status != inc.RS274NGC_EXECUTE_FINISH) {
+			return fmt.Errorf("%s:%d: %s: %s", filename, cnc.SequenceNumber(),
+				strings.TrimSpace(cnc.LineText()), inc.Rs274ngc_error_text(status))
+		}
+	}
+}
+
+/* Tools
+
+   Returned Value: error (from reading the tool file, or nil)
+
+   Side effects: the tool table of world is set.
+
+   Called by: the commands
+
+   With a filename, the tool table is read from that file (see
+   inc.Canon_world_t.Read_tool_file). Without one, every slot holds a
+   tool of no length and no diameter, so that any T word may be used.
+
+*/
+
+func Tools(world *inc.Canon_world_t, filename string) error {
+	if filename == "" {
+		world.Tool_max = inc.CANON_TOOL_MAX
+		return nil
+	}
+	return world.Read_tool_file(filename)
+}
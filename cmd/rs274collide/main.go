@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc"
+	"github.com/flyingyizi/rs274ngc/cmd/internal/run"
+	"github.com/flyingyizi/rs274ngc/collide"
+)
+
+/************************************************************************/
+
+/* main
+
+   The rs274collide executable runs an NC program dry and reports the
+   traverses into the stock and the moves into the fixtures and clamps
+   (see the collide package). It exits with 0 if the program ran and
+   nothing was reported, with 2 if something was, and with 1 otherwise.
+
+   Each -stock or -fixture is a box, given by six numbers separated by
+   commas, the least X, Y and Z, then the greatest, or a cylinder
+   standing along Z, given by five, the X and Y of its axis, the Z of
+   its bottom, its radius and its height. Both may be given more than
+   once. They are in work coordinates, and the length units of the
+   first move of the program.
+
+   EXAMPLES:
+
+   To check "cds.ngc" with the tools in "rs274ngc.tool_default" against
+   a block of stock and a clamp post, enter:
+
+   rs274collide -tools rs274ngc.tool_default -stock 0,0,-20,100,60,0 -fixture 110,30,-20,8,35 cds.ngc
+
+*/
+
+func main() {
+	var stock, fixtures volumes_t
+	var (
+		tolerance  = flag.Float64("tolerance", 0, "chord tolerance for arcs, 0 for the default")
+		parameters = flag.String("var", rs274ngc.RS274NGC_PARAMETER_FILE_NAME_DEFAULT, "parameter file")
+		tools      = flag.String("tools", "", "tool file")
+	)
+	flag.Var(&stock, "stock", "stock box or cylinder, may be given more than once")
+	flag.Var(&fixtures, "fixture", "fixture or clamp box or cylinder, may be given more than once")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <input file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	for n := range fixtures {
+		fixtures[n].Fixture = true
+	}
+
+	checker := collide.New(append(stock, fixtures...)...)
+	checker.Tolerance = *tolerance
+	checker.Parameter_file_name = *parameters
+	if err := run.Tools(&checker.Canon_world_t, *tools); err != nil {
+		fail(err)
+	}
+	var cnc rs274ngc.Rs274ngc_t
+	cnc.SetCanon(checker)
+	checker.Source = &cnc
+	if err := run.File(&cnc, flag.Arg(0)); err != nil {
+		fail(err)
+	}
+
+	diagnostics := checker.Check()
+	if err := collide.Write(os.Stdout, diagnostics); err != nil {
+		fail(err)
+	}
+	if len(diagnostics) != 0 {
+		os.Exit(2)
+	}
+}
+
+// volumes_t is the volumes given by a flag.
+type volumes_t []collide.Volume_t
+
+func (v *volumes_t) String() string {
+	return fmt.Sprint(len(*v), " volumes")
+}
+
+/* Set
+
+   Returned Value: error
+   nil if text is five or six numbers separated by commas, otherwise an
+   error.
+
+   Side effects: a cylinder or box (see main), named after text, is
+   appended to v.
+
+   Called by: flag.Parse
+
+*/
+
+func (v *volumes_t) Set(text string) error {
+	fields := strings.Split(text, ",")
+	if (len(fields) != 5) && (len(fields) != 6) {
+		return fmt.Errorf("%q is not five or six numbers", text)
+	}
+	values := make([]float64, len(fields))
+	for n, field := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return fmt.Errorf("%q: %v", text, err)
+		}
+		values[n] = value
+	}
+	if len(values) == 5 {
+		*v = append(*v, collide.Cylinder(text, false, values[0], values[1], values[2], values[3], values[4]))
+	} else {
+		*v = append(*v, collide.Box(text, false, [3]float64{values[0], values[1], values[2]},
+			[3]float64{values[3], values[4], values[5]}))
+	}
+	return nil
+}
+
+// fail reports err and exits with 1.
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+	os.Exit(1)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flyingyizi/rs274ngc"
+	"github.com/flyingyizi/rs274ngc/cmd/internal/run"
+	"github.com/flyingyizi/rs274ngc/extents"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/************************************************************************/
+
+/* main
+
+   The rs274extents executable reports how far the tool goes in X, Y and
+   Z when an NC program is run, overall, with each tool, and in each
+   coordinate system, in work and machine coordinates (see the extents
+   package), for setup sheets. It exits with 0 if the program ran and
+   the report was written, and with 1 otherwise.
+
+   EXAMPLES:
+
+   To report the extents of "cds.ngc", enter:
+
+   rs274extents cds.ngc
+
+   To report the extents of cutting only, leaving out traverses, with
+   the tools in "rs274ngc.tool_default", enter:
+
+   rs274extents -feeds -tools rs274ngc.tool_default cds.ngc
+
+*/
+
+func main() {
+	var (
+		feeds      = flag.Bool("feeds", false, "leave out traverses")
+		parameters = flag.String("var", rs274ngc.RS274NGC_PARAMETER_FILE_NAME_DEFAULT, "parameter file")
+		tools      = flag.String("tools", "", "tool file")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <input file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	analyzer := extents.New()
+	analyzer.Parameter_file_name = *parameters
+	if err := run.Tools(&analyzer.Canon_world_t, *tools); err != nil {
+		fail(err)
+	}
+	var cnc rs274ngc.Rs274ngc_t
+	cnc.SetCanon(analyzer)
+	analyzer.Source = &cnc
+	if err := run.File(&cnc, flag.Arg(0)); err != nil {
+		fail(err)
+	}
+
+	moves := analyzer.Moves
+	if *feeds {
+		moves = nil
+		for _, move := range analyzer.Moves {
+			if move.Kind != toolpath.MOVE_TRAVERSE {
+				moves = append(moves, move)
+			}
+		}
+	}
+	if err := extents.Measure(moves).Write(os.Stdout); err != nil {
+		fail(err)
+	}
+}
+
+// fail reports err and exits with 1.
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+	os.Exit(1)
+}
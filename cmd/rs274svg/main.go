@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc"
+	"github.com/flyingyizi/rs274ngc/cmd/internal/run"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/svg"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/************************************************************************/
+
+/* main
+
+   The rs274svg executable draws the tool path of an NC program as an
+   SVG picture, for a look at the program before it is run (see the svg
+   package). It exits with 0 if the program ran and the picture was
+   written, and with 1 otherwise.
+
+   EXAMPLES:
+
+   To draw the top view of "cds.ngc" in "cds.svg", enter:
+
+   rs274svg -o cds.svg cds.ngc
+
+   To draw all four views with the outline of 4 x 4 x 2 inch stock
+   whose top is at Z=2, with the tools in "rs274ngc.tool_default",
+   enter:
+
+   rs274svg -views all -stock 0,0,0,4,4,2 -tools rs274ngc.tool_default -o cds.svg cds.ngc
+
+*/
+
+func main() {
+	var (
+		output     = flag.String("o", "", "write the picture to this file, not standard output")
+		views      = flag.String("views", "top", "views to draw: top, front, side, iso, separated by commas, or all")
+		size       = flag.Float64("size", 400, "width or height of each view in pixels")
+		stock      = flag.String("stock", "", "stock outline as xmin,ymin,zmin,xmax,ymax,zmax")
+		parameters = flag.String("var", rs274ngc.RS274NGC_PARAMETER_FILE_NAME_DEFAULT, "parameter file")
+		tools      = flag.String("tools", "", "tool file")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <input file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var options svg.Options_t
+	var err error
+	if options.Views, err = toolpath.Parse_views(*views); err != nil {
+		fail(err)
+	}
+	options.Size = *size
+	if *stock != "" {
+		if options.Stock, err = parse_box(*stock); err != nil {
+			fail(err)
+		}
+	}
+
+	renderer := svg.New(options)
+	renderer.Parameter_file_name = *parameters
+	if err = run.Tools(&renderer.Canon_world_t, *tools); err != nil {
+		fail(err)
+	}
+	var cnc rs274ngc.Rs274ngc_t
+	cnc.SetCanon(renderer)
+	renderer.Source = &cnc
+	if err = run.File(&cnc, flag.Arg(0)); err != nil {
+		fail(err)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			fail(err)
+		}
+	}
+	if err = renderer.Write(out); err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+/* parse_box
+
+   Returned Value: the box given by six numbers separated by commas,
+   the least X, Y and Z, then the greatest, or an error
+
+   Side effects: none
+
+   Called by: main
+
+*/
+
+func parse_box(text string) (*toolpath.Box_t, error) {
+	fields := strings.Split(text, ",")
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("stock %q is not six numbers", text)
+	}
+	var values [6]float64
+	for n, field := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("stock %q: %v", text, err)
+		}
+		values[n] = value
+	}
+	return &toolpath.Box_t{
+		Min: inc.CANON_POSITION{X: values[0], Y: values[1], Z: values[2]},
+		Max: inc.CANON_POSITION{X: values[3], Y: values[4], Z: values[5]},
+	}, nil
+}
+
+// fail reports err and exits with 1.
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+	os.Exit(1)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc"
+	"github.com/flyingyizi/rs274ngc/cmd/internal/run"
+	"github.com/flyingyizi/rs274ngc/raster"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/************************************************************************/
+
+/* main
+
+   The rs274thumb executable writes a PNG thumbnail of the tool path of
+   each NC program (each file ending in ".ngc") in a directory (see the
+   raster package). The thumbnail of "name.ngc" is "name.png", next to
+   the program or in the directory given with -o. A program which cannot
+   be run is reported and has no thumbnail, and the rest are still drawn.
+   It exits with 0 if every thumbnail was written, and with 1 otherwise.
+
+   EXAMPLES:
+
+   To write 256 x 256 pixel top views of the programs in "programs",
+   enter:
+
+   rs274thumb programs
+
+   To write 128 x 96 pixel isometric views without traverses into
+   "thumbs", with the tools in "rs274ngc.tool_default", enter:
+
+   rs274thumb -width 128 -height 96 -view iso -rapids=false -tools rs274ngc.tool_default -o thumbs programs
+
+*/
+
+func main() {
+	var (
+		output     = flag.String("o", "", "write the thumbnails in this directory, not next to the programs")
+		width      = flag.Int("width", 256, "width of a thumbnail in pixels")
+		height     = flag.Int("height", 256, "height of a thumbnail in pixels")
+		view       = flag.String("view", "top", "view to draw: top, front, side or iso")
+		rapids     = flag.Bool("rapids", true, "draw traverses")
+		parameters = flag.String("var", rs274ngc.RS274NGC_PARAMETER_FILE_NAME_DEFAULT, "parameter file")
+		tools      = flag.String("tools", "", "tool file")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <directory>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	views, err := toolpath.Parse_views(*view)
+	if (err == nil) && (len(views) != 1) {
+		err = fmt.Errorf("one view is drawn, not %q", *view)
+	}
+	if err != nil {
+		fail(err)
+	}
+	options := raster.Options_t{View: views[0], Width: *width, Height: *height, Hide_rapids: !*rapids}
+
+	programs, err := filepath.Glob(filepath.Join(flag.Arg(0), "*.ngc"))
+	if err != nil {
+		fail(err)
+	}
+	sort.Strings(programs)
+	failed := false
+	for _, program := range programs {
+		name := strings.TrimSuffix(program, filepath.Ext(program)) + ".png"
+		if *output != "" {
+			name = filepath.Join(*output, filepath.Base(name))
+		}
+		if err := thumbnail(program, name, options, *parameters, *tools); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+/* thumbnail
+
+   Returned Value: error (the first error running the program or writing
+   the thumbnail, or nil)
+
+   Side effects: program is run on a new interpreter, and the thumbnail
+   of its tool path written in the file called name.
+
+   Called by: main
+
+*/
+
+func thumbnail(program, name string, options raster.Options_t, parameters, tools string) error {
+	renderer := raster.New(options)
+	renderer.Parameter_file_name = parameters
+	if err := run.Tools(&renderer.Canon_world_t, tools); err != nil {
+		return err
+	}
+	var cnc rs274ngc.Rs274ngc_t
+	cnc.SetCanon(renderer)
+	renderer.Source = &cnc
+	if err := run.File(&cnc, program); err != nil {
+		return err
+	}
+
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err = renderer.Write(out); err != nil {
+		out.Close()
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return out.Close()
+}
+
+// fail reports err and exits with 1.
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+	os.Exit(1)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc"
+	"github.com/flyingyizi/rs274ngc/cmd/internal/run"
+	"github.com/flyingyizi/rs274ngc/cycletime"
+)
+
+/************************************************************************/
+
+/* main
+
+   The rs274time executable estimates how long an NC program takes to
+   run, overall, with each tool, and in each operation (the part of the
+   program after a comment), for quoting (see the cycletime package). It
+   exits with 0 if the program ran and the report was written, and with
+   1 otherwise.
+
+   Limits are given for the axes X, Y, Z, A, B and C in that order,
+   separated by commas; an axis left out or given as 0 has no limit.
+   The machine slows for corners as planner.Planner_t plans it to, by
+   junction deviation.
+
+   EXAMPLES:
+
+   To estimate the time of "cds.ngc" with traverses at 5000 mm a minute,
+   enter:
+
+   rs274time -traverse 5000 cds.ngc
+
+   To estimate it on a machine whose X and Y go no faster than 8000 and
+   Z 3000 mm a minute, speeding up at 500 mm a second squared, taking 8
+   seconds to change a tool and 2 to start the spindle, enter:
+
+   rs274time -vmax 8000,8000,3000 -amax 500,500,500 -change 8 -spin 2 cds.ngc
+
+*/
+
+func main() {
+	var (
+		traverse   = flag.Float64("traverse", 0, "traverse rate in length units a minute, 0 for the greatest velocities")
+		velocity   = flag.String("vmax", "", "greatest velocity of each axis in mm or degrees a minute")
+		accel      = flag.String("amax", "", "greatest acceleration of each axis in mm or degrees a second squared")
+		deviation  = flag.Float64("deviation", 0.01, "junction deviation in mm, for corners without a G64 P tolerance")
+		change     = flag.Float64("change", 0, "seconds to change a tool")
+		spin       = flag.Float64("spin", 0, "seconds to start the spindle or change its speed")
+		parameters = flag.String("var", rs274ngc.RS274NGC_PARAMETER_FILE_NAME_DEFAULT, "parameter file")
+		tools      = flag.String("tools", "", "tool file")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <input file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	options := cycletime.Options_t{Tool_change: *change, Spindle_start: *spin}
+	options.Junction_deviation = *deviation
+	var err error
+	if options.Max_velocity, err = axes(*velocity); err != nil {
+		fail(err)
+	}
+	if options.Max_acceleration, err = axes(*accel); err != nil {
+		fail(err)
+	}
+
+	estimator := cycletime.New(options)
+	estimator.Parameter_file_name = *parameters
+	estimator.Traverse_rate = *traverse
+	if err := run.Tools(&estimator.Canon_world_t, *tools); err != nil {
+		fail(err)
+	}
+	var cnc rs274ngc.Rs274ngc_t
+	cnc.SetCanon(estimator)
+	estimator.Source = &cnc
+	if err := run.File(&cnc, flag.Arg(0)); err != nil {
+		fail(err)
+	}
+	if err := estimator.Report().Write(os.Stdout); err != nil {
+		fail(err)
+	}
+}
+
+// axes parses up to six limits separated by commas.
+func axes(text string) ([6]float64, error) {
+	var limits [6]float64
+	if text == "" {
+		return limits, nil
+	}
+	fields := strings.Split(text, ",")
+	if len(fields) > len(limits) {
+		return limits, fmt.Errorf("%q gives more than %d axes", text, len(limits))
+	}
+	for n, field := range fields {
+		limit, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if (err != nil) || (limit < 0.0) {
+			return limits, fmt.Errorf("bad limit %q in %q", field, text)
+		}
+		limits[n] = limit
+	}
+	return limits, nil
+}
+
+// fail reports err and exits with 1.
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+	os.Exit(1)
+}
@@ -0,0 +1,321 @@
+package collide
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* collide.go
+
+   Checker_t is a Canon_i which checks, in a dry run, that the tool does
+   not hit the stock or the fixtures where it should not. It keeps the
+   moves (see toolpath.Path_t) and checks them when Check is called.
+
+   The stock, and the fixtures and clamps, are volumes (see Volume_t):
+   boxes with sides parallel to the axes, or cylinders standing along Z.
+   The tool is a cylinder standing up from its tip, with the diameter
+   and length in the tool table (see GET_EXTERNAL_TOOL_TABLE) of the tool
+   in the spindle; the position of a move is that of the tip, as it is
+   if the program uses tool length offsets. A traverse of the tool into
+   the stock is reported, as is any move (traverse, feed, arc or probe)
+   into a fixture. Touching a volume is not going into it.
+
+   The tool is swept along each straight move exactly. An arc is swept
+   along chords of it, within Tolerance. The stock is taken to be whole
+   all through the program, so a traverse through a pocket already cut
+   is reported too.
+
+   All moves are checked in the coordinates and length units of the
+   first move (see toolpath.Fold), and the volumes, the tolerance and
+   the tool table are taken to be in those too.
+
+*/
+
+// TOLERANCE_DEFAULT is the chord tolerance for arcs if Tolerance is 0.
+const TOLERANCE_DEFAULT = 0.001
+
+// Shape is the shape of a volume.
+type Shape int
+
+const (
+	SHAPE_BOX      Shape = iota + 1 // box with sides parallel to the axes
+	SHAPE_CYLINDER                  // cylinder with its axis along Z
+)
+
+// Volume_t is the stock, or a fixture or clamp.
+type Volume_t struct {
+	Name    string
+	Fixture bool // a fixture or clamp, not stock
+	Shape   Shape
+	Box     toolpath.Box_t     // SHAPE_BOX: the box
+	Center  inc.CANON_POSITION // SHAPE_CYLINDER: the middle of the bottom
+	Radius  float64            // SHAPE_CYLINDER
+	Height  float64            // SHAPE_CYLINDER
+}
+
+// Diagnostic_t is a move into a volume.
+type Diagnostic_t struct {
+	Line   int                // sequence number of the line, 0 if no Source
+	Kind   toolpath.Kind      // of the move
+	Tool   int                // pocket of the tool in the spindle
+	Volume *Volume_t          // gone into
+	At     inc.CANON_POSITION // tip of the tool where it first goes in
+}
+
+type Checker_t struct {
+	toolpath.Path_t
+
+	Volumes   []Volume_t
+	Tolerance float64 // chord tolerance for arcs, 0 for TOLERANCE_DEFAULT
+}
+
+var _ inc.Canon_i = &Checker_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Checker_t of volumes, with no moves, and the world
+   model of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New(volumes ...Volume_t) *Checker_t {
+	return &Checker_t{Volumes: volumes}
+}
+
+/* Box
+
+   Returned Value: a Volume_t which is a box from min to max (the X, Y
+   and Z of the corners), a fixture if fixture is true, else stock
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func Box(name string, fixture bool, min, max [3]float64) Volume_t {
+	return Volume_t{Name: name, Fixture: fixture, Shape: SHAPE_BOX, Box: toolpath.Box_t{
+		Min: inc.CANON_POSITION{X: math.Min(min[0], max[0]), Y: math.Min(min[1], max[1]), Z: math.Min(min[2], max[2])},
+		Max: inc.CANON_POSITION{X: math.Max(min[0], max[0]), Y: math.Max(min[1], max[1]), Z: math.Max(min[2], max[2])}}}
+}
+
+/* Cylinder
+
+   Returned Value: a Volume_t which is a cylinder standing along Z on
+   x, y, bottom, of radius and height, a fixture if fixture is true,
+   else stock
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func Cylinder(name string, fixture bool, x, y, bottom, radius, height float64) Volume_t {
+	return Volume_t{Name: name, Fixture: fixture, Shape: SHAPE_CYLINDER,
+		Center: inc.CANON_POSITION{X: x, Y: y, Z: bottom}, Radius: radius, Height: height}
+}
+
+/* Check
+
+   Returned Value: the moves made so far into the volumes, with the
+   tools in the tool table of the world model
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (c *Checker_t) Check() []Diagnostic_t {
+	return Check(c.Moves, c, c.Volumes, c.Tolerance)
+}
+
+/* Check
+
+   Returned Value: the moves into volumes, with the tools in tools, in
+   the order made; each move is reported once for each volume it goes
+   into
+
+   Side effects: none
+
+   Called by: Checker_t.Check, external programs
+
+*/
+
+func Check(moves []toolpath.Move_t, tools inc.Tool_table_i, volumes []Volume_t,
+	tolerance float64) []Diagnostic_t {
+
+	var found []Diagnostic_t
+	if tolerance <= 0.0 {
+		tolerance = TOLERANCE_DEFAULT
+	}
+	moves = toolpath.Fold(moves)
+	for n := range moves {
+		move := &moves[n]
+		tool := tools.GET_EXTERNAL_TOOL_TABLE(move.Tool)
+		points := move.Points(tolerance)
+		for v := range volumes {
+			volume := &volumes[v]
+			if !volume.Fixture && (move.Kind != toolpath.MOVE_TRAVERSE) {
+				continue
+			}
+			for p := 1; p < len(points); p++ {
+				if at, ok := volume.hit(points[p-1], points[p], tool.Diameter/2.0, tool.Length); ok {
+					found = append(found, Diagnostic_t{Line: move.Line, Kind: move.Kind, Tool: move.Tool,
+						Volume: volume, At: at})
+					break
+				}
+			}
+		}
+	}
+	return found
+}
+
+/***********************************************************************/
+
+/* hit
+
+   Returned Value: where the tip of a tool of radius and length is when
+   it first goes into v, moving straight from one to two, and whether it
+   does
+
+   Side effects: none
+
+   Called by: Check
+
+   The tool is in v when its tip is less than the radius (in X and Y)
+   from the cross section of v, with its tip below the top of v and its
+   top above the bottom. The second is true over a part of the move
+   found from Z alone. Over that part, the distance of the tip from the
+   cross section (less than 0 inside it) changes convexly, so its least
+   is found by ternary search, and the first place it is less than the
+   radius by bisection before that.
+
+*/
+
+func (v *Volume_t) hit(one, two inc.CANON_POSITION, radius, length float64) (at inc.CANON_POSITION, ok bool) {
+	bottom, top := v.Box.Min.Z, v.Box.Max.Z
+	if v.Shape == SHAPE_CYLINDER {
+		bottom, top = v.Center.Z, v.Center.Z+v.Height
+	}
+	low, high := bottom-length, top /* the tip must be between */
+	from, to := 0.0, 1.0
+	if dz := two.Z - one.Z; dz == 0.0 {
+		if !((one.Z > low) && (one.Z < high)) {
+			return at, false
+		}
+	} else {
+		t1, t2 := (low-one.Z)/dz, (high-one.Z)/dz
+		from, to = math.Max(from, math.Min(t1, t2)), math.Min(to, math.Max(t1, t2))
+		if !(to > from) {
+			return at, false
+		}
+	}
+
+	point := func(t float64) inc.CANON_POSITION {
+		return inc.CANON_POSITION{X: one.X + ((two.X - one.X) * t), Y: one.Y + ((two.Y - one.Y) * t),
+			Z: one.Z + ((two.Z - one.Z) * t)}
+	}
+	away := func(t float64) float64 {
+		return v.distance(point(t))
+	}
+	left, right := from, to
+	for n := 0; n < 100; n++ {
+		third := (right - left) / 3.0
+		if away(left+third) <= away(right-third) {
+			right = right - third
+		} else {
+			left = left + third
+		}
+	}
+	closest := (left + right) / 2.0
+	if !(away(closest) < radius) {
+		return at, false
+	}
+	if away(from) < radius {
+		return point(from), true
+	}
+	outside, inside := from, closest
+	for n := 0; n < 60; n++ {
+		middle := (outside + inside) / 2.0
+		if away(middle) < radius {
+			inside = middle
+		} else {
+			outside = middle
+		}
+	}
+	return point(inside), true
+}
+
+/* distance
+
+   Returned Value: how far p is from the cross section of v in X and Y,
+   less than 0 if it is inside
+
+   Side effects: none
+
+   Called by: hit
+
+*/
+
+func (v *Volume_t) distance(p inc.CANON_POSITION) float64 {
+	if v.Shape == SHAPE_CYLINDER {
+		return math.Hypot(p.X-v.Center.X, p.Y-v.Center.Y) - v.Radius
+	}
+	dx := math.Max(v.Box.Min.X-p.X, p.X-v.Box.Max.X)
+	dy := math.Max(v.Box.Min.Y-p.Y, p.Y-v.Box.Max.Y)
+	if (dx < 0.0) && (dy < 0.0) {
+		return math.Max(dx, dy)
+	}
+	return math.Hypot(math.Max(dx, 0.0), math.Max(dy, 0.0))
+}
+
+/***********************************************************************/
+
+/* String
+
+   Returned Value: string
+   The diagnostic in words, for example
+   line 12: traverse with T1 into stock "blank" at X1.0000 Y2.0000 Z-1.0000
+
+   Side effects: none
+
+   Called by: Write, external programs
+
+*/
+
+func (d Diagnostic_t) String() string {
+	return fmt.Sprintf("line %d: %s with T%d into %s %q at X%.4f Y%.4f Z%.4f", d.Line, d.Kind, d.Tool,
+		inc.If(d.Volume.Fixture, "fixture", "stock").(string), d.Volume.Name, d.At.X, d.At.Y, d.At.Z)
+}
+
+/* Write
+
+   Returned Value: error
+   The first error writing to out, or nil.
+
+   Side effects: diagnostics are written to out, one a line.
+
+   Called by: external programs
+
+*/
+
+func Write(out io.Writer, diagnostics []Diagnostic_t) error {
+	for _, d := range diagnostics {
+		if _, err := fmt.Fprintln(out, d.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package collide_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/collide"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+func TestCheck(t *testing.T) {
+	// A block of stock, and a clamp post to the right of it, reaching 15
+	// above it; tool 1 is 10 across and 50 long.
+	checker := collide.New(collide.Box("blank", false, [3]float64{0, 0, -20}, [3]float64{100, 60, 0}),
+		collide.Cylinder("post", true, 110, 30, -20, 8, 35))
+	checker.Tool_max = 4
+	checker.Tools = make([]inc.CANON_TOOL_TABLE, 5)
+	checker.Tools[1].Length, checker.Tools[1].Diameter = 50, 10
+	cnc := cnctest.Start(t, checker, &checker.Canon_world_t)
+	checker.Source = cnc
+	cnctest.Run(t, cnc,
+		"g21 t1 m6",
+		"g0 z5",
+		"x50 y30",
+		"z-5",          // traverse into the stock
+		"g1 z-10 f100", // feeds into the stock are cuts
+		"x90",
+		"z5",
+		"x50 y-20",
+		"g3 x50 y-20 i0 j50", // around through the post
+		"g1 x90 y30",         // 12 clear of the post
+		"x103",               // into the post at X97
+	)
+
+	found := checker.Check()
+	want := []struct {
+		line   int
+		kind   toolpath.Kind
+		volume string
+	}{{4, toolpath.MOVE_TRAVERSE, "blank"}, {9, toolpath.MOVE_ARC, "post"}, {11, toolpath.MOVE_FEED, "post"}}
+	if len(found) != len(want) {
+		t.Fatalf("found %v, want %d", found, len(want))
+	}
+	for n, w := range want {
+		if d := found[n]; (d.Line != w.line) || (d.Kind != w.kind) || (d.Tool != 1) || (d.Volume.Name != w.volume) {
+			t.Errorf("found %v, want line %d %s into %s", d, w.line, w.kind, w.volume)
+		}
+	}
+	if at := found[1].At; math.Abs(math.Hypot(at.X-110, at.Y-30)-13) > 1e-6 {
+		t.Errorf("arc goes into the post at %+v, want 13 from its axis", at)
+	}
+	if at := found[2].At; (math.Abs(at.X-97) > 1e-6) || (at.Y != 30) || (at.Z != 5) {
+		t.Errorf("feed goes into the post at %+v, want X97 Y30 Z5", at)
+	}
+
+	var out bytes.Buffer
+	if err := collide.Write(&out, found); err != nil {
+		t.Fatal(err)
+	}
+	if want := `line 4: traverse with T1 into stock "blank" at X50.0000 Y30.0000 Z0.0000` + "\n"; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("wrote %q, want it to start %q", out.String(), want)
+	}
+}
+
+func TestCheck_volumes(t *testing.T) {
+	// A tool 2 across and 10 long, moving straight from one to two.
+	cases := []struct {
+		name     string
+		volume   collide.Volume_t
+		one, two inc.CANON_POSITION
+		hit      bool
+	}{
+		{"over", collide.Box("b", true, [3]float64{0, 0, 0}, [3]float64{10, 10, 5}),
+			inc.CANON_POSITION{X: -5, Y: 5, Z: 5}, inc.CANON_POSITION{X: 15, Y: 5, Z: 5}, false},
+		{"through", collide.Box("b", true, [3]float64{0, 0, 0}, [3]float64{10, 10, 5}),
+			inc.CANON_POSITION{X: -5, Y: 5, Z: 4}, inc.CANON_POSITION{X: 15, Y: 5, Z: 4}, true},
+		{"beside", collide.Box("b", true, [3]float64{0, 0, 0}, [3]float64{10, 10, 5}),
+			inc.CANON_POSITION{X: -5, Y: 11, Z: 4}, inc.CANON_POSITION{X: 15, Y: 11, Z: 4}, false},
+		{"shank below", collide.Box("b", true, [3]float64{0, 0, 0}, [3]float64{10, 10, 5}),
+			inc.CANON_POSITION{X: -5, Y: 5, Z: -9}, inc.CANON_POSITION{X: 15, Y: 5, Z: -9}, true},
+		{"corner", collide.Box("b", true, [3]float64{0, 0, 0}, [3]float64{10, 10, 5}),
+			inc.CANON_POSITION{X: 10, Y: 11.2, Z: 4}, inc.CANON_POSITION{X: 11.2, Y: 10, Z: 4}, true},
+		{"cylinder", collide.Cylinder("c", true, 0, 0, 0, 3, 5),
+			inc.CANON_POSITION{X: -5, Y: 3.9, Z: 1}, inc.CANON_POSITION{X: 5, Y: 3.9, Z: 1}, true},
+		{"cylinder clear", collide.Cylinder("c", true, 0, 0, 0, 3, 5),
+			inc.CANON_POSITION{X: -5, Y: 4.1, Z: 1}, inc.CANON_POSITION{X: 5, Y: 4.1, Z: 1}, false},
+		{"diving", collide.Cylinder("c", true, 0, 0, 0, 3, 5),
+			inc.CANON_POSITION{X: -10, Y: 0, Z: 20}, inc.CANON_POSITION{X: 0, Y: 0, Z: 10}, false},
+	}
+	tools := collide.New()
+	tools.Tools = make([]inc.CANON_TOOL_TABLE, 2)
+	tools.Tools[1].Length, tools.Tools[1].Diameter = 10, 2
+	for _, c := range cases {
+		move := toolpath.Move_t{Kind: toolpath.MOVE_FEED, Tool: 1, Start: c.one, End: c.two}
+		found := collide.Check([]toolpath.Move_t{move}, tools, []collide.Volume_t{c.volume}, 0)
+		if (len(found) != 0) != c.hit {
+			t.Errorf("%s: found %v, want a hit %v", c.name, found, c.hit)
+		}
+	}
+}
@@ -50,8 +50,11 @@ import (
 
    Called by: convert_motion.
 
-   This converts a helical or circular arc.  The function calls:
-   convert_arc2 (when cutter radius compensation is off) or
+   This converts a helical or circular arc in the selected plane, putting
+   the end point and center offsets in the coordinates of that plane (see
+   plane_current).  The function calls:
+   convert_arc2 (when cutter radius compensation is off or is passed
+   through to the machine) or
    convert_arc_comp1 (when cutter comp is on and this is the first move) or
    convert_arc_comp2 (when cutter comp is on and this is not the first move).
 
@@ -76,7 +79,7 @@ func (cnc *rs274ngc_t) convert_arc( /* ARGUMENTS
 			ON, OFF).(ON_OFF)
 
 	/* flag set ON if this is first move after comp ON */
-	first := (cnc._setup.program_x == inc.UNKNOWN)
+	first := (cnc._setup.program_1 == inc.UNKNOWN)
 
 	if (cnc._setup.block1.r_flag != ON) && (ijk_flag != ON) {
 		return inc.NCE_R_I_J_K_WORDS_ALL_MISSING_FOR_ARC
@@ -151,65 +154,51 @@ func (cnc *rs274ngc_t) convert_arc( /* ARGUMENTS
 	cnc.find_ends(&end_x, &end_y, &end_z, &AA_end, &BB_end, &CC_end)
 	cnc._setup.motion_mode = move
 
+	var offset1, offset2 float64 /* center offsets in the plane */
 	if cnc._setup.plane == inc.CANON_PLANE_XY {
-		if (cnc._setup.cutter_comp_side == inc.CANON_SIDE_OFF) ||
-			(cnc._setup.cutter_comp_radius == 0.0) {
-			status =
-				cnc.convert_arc2(move,
-					&(cnc._setup.current.X), &(cnc._setup.current.Y),
-					&(cnc._setup.current.Z), end_x, end_y,
-					end_z, AA_end, BB_end, CC_end, cnc._setup.block1.i_number,
-					cnc._setup.block1.j_number)
-			//CHP(status)
-			if status != inc.RS274NGC_OK {
-				return status
-			}
-
-		} else if first {
-			status =
-				cnc.convert_arc_comp1(move, end_x, end_y,
-					end_z, AA_end, BB_end, CC_end)
-			//CHP(status)
-			if status != inc.RS274NGC_OK {
-				return status
-			}
-
-		} else {
-			status =
-				cnc.convert_arc_comp2(move, end_x, end_y,
-					end_z, AA_end, BB_end, CC_end)
-			//CHP(status)
-			if status != inc.RS274NGC_OK {
-				return status
-			}
-
-		}
+		offset1, offset2 = cnc._setup.block1.i_number, cnc._setup.block1.j_number
 	} else if cnc._setup.plane == inc.CANON_PLANE_XZ {
+		offset1, offset2 = cnc._setup.block1.k_number, cnc._setup.block1.i_number
+	} else if cnc._setup.plane == inc.CANON_PLANE_YZ {
+		offset1, offset2 = cnc._setup.block1.j_number, cnc._setup.block1.k_number
+	} else {
+		return inc.NCE_BUG_PLANE_NOT_XY_YZ_OR_XZ
+	}
+	current1, current2, current3 := cnc.plane_current()
+	end1, end2, end3 := cnc.plane_point(end_x, end_y, end_z)
+
+	if (cnc._setup.cutter_comp_side == inc.CANON_SIDE_OFF) ||
+		(cnc._setup.cutter_comp_radius == 0.0) ||
+		(cnc._setup.comp_passthrough == ON) { /* machine does comp */
 		status =
-			cnc.convert_arc2(move,
-				&(cnc._setup.current.Z), &(cnc._setup.current.X),
-				&(cnc._setup.current.Y), end_z, end_x,
-				end_y, AA_end, BB_end, CC_end, cnc._setup.block1.k_number,
-				cnc._setup.block1.i_number)
+			cnc.convert_arc2(move, current1, current2, current3,
+				end1, end2, end3, AA_end, BB_end, CC_end, offset1, offset2)
 		//CHP(status)
 		if status != inc.RS274NGC_OK {
 			return status
 		}
 
-	} else if cnc._setup.plane == inc.CANON_PLANE_YZ {
+	} else if first {
+		cnc._setup.comp_block++
 		status =
-			cnc.convert_arc2(move,
-				&(cnc._setup.current.Y), &(cnc._setup.current.Z),
-				&(cnc._setup.current.X), end_y, end_z,
-				end_x, AA_end, BB_end, CC_end, cnc._setup.block1.j_number, cnc._setup.block1.k_number)
+			cnc.convert_arc_comp1(move, end1, end2,
+				end3, AA_end, BB_end, CC_end, offset1, offset2)
 		//CHP(status)
 		if status != inc.RS274NGC_OK {
 			return status
 		}
 
 	} else {
-		return inc.NCE_BUG_PLANE_NOT_XY_YZ_OR_XZ
+		cnc._setup.comp_block++
+		status =
+			cnc.convert_arc_comp2(move, end1, end2,
+				end3, AA_end, BB_end, CC_end, offset1, offset2)
+		//CHP(status)
+		if status != inc.RS274NGC_OK {
+			return status
+		}
 	}
+	cnc._setup.comp_line = cnc._setup.linetext
 
 	return inc.RS274NGC_OK
 }
@@ -231,9 +220,10 @@ func (cnc *rs274ngc_t) convert_arc( /* ARGUMENTS
    Called by: convert_arc.
 
    This function converts a helical or circular arc, generating only one
-   arc. The axis must be parallel to the z-axis. This is called when
-   cutter radius compensation is on and this is the first cut after the
-   turning on.
+   arc. The axis must be perpendicular to the selected plane, and the
+   points and center offsets are given in the coordinates of that plane
+   (see plane_current). This is called when cutter radius compensation
+   is on and this is the first cut after the turning on.
 
    The arc which is generated is derived from a second arc which passes
    through the programmed end point and is tangent to the cutter at its
@@ -244,19 +234,22 @@ func (cnc *rs274ngc_t) convert_arc( /* ARGUMENTS
 
 func (cnc *rs274ngc_t) convert_arc_comp1( /* ARGUMENTS                                   */
 	move inc.GCodes, /* either G_2 (cw arc) or G_3 (ccw arc)             */
-	end_x, /* x-value at end of programmed (then actual) arc   */
-	end_y, /* y-value at end of programmed (then actual) arc   */
-	end_z, /* z-value at end of arc                            */
+	end1, /* coord 1 at end of programmed (then actual) arc   */
+	end2, /* coord 2 at end of programmed (then actual) arc   */
+	end3, /* coord 3 at end of arc                            */
 	AA_end, /* a-value at end of arc                      */ /*AA*/
 	BB_end, /* b-value at end of arc                      */ /*BB*/
-	CC_end float64) inc.STATUS { /* c-value at end of arc                      */ /*CC*/
+	CC_end, /* c-value at end of arc                      */ /*CC*/
+	offset1, /* offset of center from current1               */
+	offset2 float64) inc.STATUS { /* offset of center from current2               */
 
 	var turn int /* 1 for counterclockwise, -1 for clockwise */
 
 	var (
-		center_x, center_y float64
+		center1, center2 float64
 	)
 
+	current1, current2, current3 := cnc.plane_current()
 	/* offset side - right or left              */
 	side := cnc._setup.cutter_comp_side
 	/* always is positive */
@@ -264,43 +257,43 @@ func (cnc *rs274ngc_t) convert_arc_comp1( /* ARGUMENTS
 	/* tolerance for difference of radii        */
 	tolerance := inc.If(cnc._setup.length_units == inc.CANON_UNITS_INCHES, inc.TOLERANCE_INCH, inc.TOLERANCE_MM).(float64)
 
-	if math.Hypot((end_x-cnc._setup.current.X),
-		(end_y-cnc._setup.current.Y)) <= tool_radius {
+	if math.Hypot((end1-*current1),
+		(end2-*current2)) <= tool_radius {
 		return inc.NCE_CUTTER_GOUGING_WITH_CUTTER_RADIUS_COMP
 	}
 
 	if cnc._setup.block1.r_flag {
-		arc.Arc_data_comp_r(move, side, tool_radius, cnc._setup.current.X,
-			cnc._setup.current.Y, end_x, end_y, cnc._setup.block1.r_number,
-			&center_x, &center_y, &turn)
+		arc.Arc_data_comp_r(move, side, tool_radius, *current1,
+			*current2, end1, end2, cnc._setup.block1.r_number,
+			&center1, &center2, &turn)
 	} else {
-		arc.Arc_data_comp_ijk(move, side, tool_radius, cnc._setup.current.X,
-			cnc._setup.current.Y, end_x, end_y,
-			cnc._setup.block1.i_number, cnc._setup.block1.j_number,
-			&center_x, &center_y, &turn, tolerance)
+		arc.Arc_data_comp_ijk(move, side, tool_radius, *current1,
+			*current2, end1, end2, offset1, offset2,
+			&center1, &center2, &turn, tolerance)
 	}
 	gamma :=
 		inc.If(((side == inc.CANON_SIDE_LEFT) && (move == inc.G_3)) || ((side == inc.CANON_SIDE_RIGHT) && (move == inc.G_2)),
-			math.Atan2((center_y-end_y), (center_x-end_x)),
-			math.Atan2((end_y-center_y), (end_x-center_x))).(float64)
+			math.Atan2((center2-end2), (center1-end1)),
+			math.Atan2((end2-center2), (end1-center1))).(float64)
 
-	cnc._setup.program_x = end_x
-	cnc._setup.program_y = end_y
-	/* end_x reset actual */
-	end_x = (end_x + (tool_radius * math.Cos(gamma)))
-	/* end_y reset actual */
-	end_y = (end_y + (tool_radius * math.Sin(gamma)))
+	cnc._setup.program_1 = end1
+	cnc._setup.program_2 = end2
+	/* end1 reset actual */
+	end1 = (end1 + (tool_radius * math.Cos(gamma)))
+	/* end2 reset actual */
+	end2 = (end2 + (tool_radius * math.Sin(gamma)))
 
 	if cnc._setup.feed_mode == inc.INVERSE_TIME {
-		cnc.inverse_time_rate_arc(cnc._setup.current.X, cnc._setup.current.Y,
-			cnc._setup.current.Z, center_x, center_y, turn,
-			end_x, end_y, end_z)
+		cnc.inverse_time_rate_arc(*current1, *current2,
+			*current3, center1, center2, turn,
+			end1, end2, end3)
 	}
 
-	cnc.canon.ARC_FEED(end_x, end_y, center_x, center_y, turn, end_z, AA_end, BB_end, CC_end)
-	cnc._setup.current.X = end_x
-	cnc._setup.current.Y = end_y
-	cnc._setup.current.Z = end_z
+	cnc.comp_hold(cnc.comp_arc(*current1, *current2, end1, end2, center1, center2,
+		turn, end3, AA_end, BB_end, CC_end))
+	*current1 = end1
+	*current2 = end2
+	*current3 = end3
 
 	cnc._setup.current.A = AA_end /*AA*/
 	cnc._setup.current.B = BB_end /*BB*/
@@ -318,12 +311,14 @@ func (cnc *rs274ngc_t) convert_arc_comp1( /* ARGUMENTS
    this returns that code.
    If any of the following errors occurs, this returns the error code shown.
    Otherwise, it returns RS274NGC_OK.
-   1. A concave corner is found: NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP
+   1. If comp_corner or comp_gouge returns an error code, this returns
+   that code.
    2. The tool will not fit inside an arc:
    NCE_TOOL_RADIUS_NOT_LESS_THAN_ARC_RADIUS_WITH_COMP
 
    Side effects:
-   This executes an arc command feed rate. If needed, at also generates
+   This executes an arc command feed rate, or holds it for lookahead.
+   A concave corner is handled by comp_corner. If needed, at also generates
    an arc to go around a convex corner. It also updates the setting of
    the position of the tool point to the end point of the move. If
    inverse time feed rate mode is in effect, the feed rate is reset.
@@ -331,8 +326,10 @@ func (cnc *rs274ngc_t) convert_arc_comp1( /* ARGUMENTS
    Called by: convert_arc.
 
    This function converts a helical or circular arc. The axis must be
-   parallel to the z-axis. This is called when cutter radius compensation
-   is on and this is not the first cut after the turning on.
+   perpendicular to the selected plane, and the points and center offsets
+   are given in the coordinates of that plane (see plane_current). This
+   is called when cutter radius compensation is on and this is not the
+   first cut after the turning on.
 
    If one or more rotary axes is moved in this block and an extra arc is
    required to go around a sharp corner, all the rotary axis motion
@@ -340,63 +337,66 @@ func (cnc *rs274ngc_t) convert_arc_comp1( /* ARGUMENTS
    might be to distribute the rotary axis motion over the extra arc and
    the programmed arc in proportion to their lengths.
 
-   If the Z-axis is moved in this block and an extra arc is required to
-   go around a sharp corner, all the Z-axis motion occurs on the main arc
-   and none on the extra arc.  An alternative might be to distribute the
-   Z-axis motion over the extra arc and the main arc in proportion to
-   their lengths.
+   If the axis perpendicular to the plane (Z in the XY-plane) is moved in
+   this block and an extra arc is required to go around a sharp corner,
+   all the motion along that axis occurs on the main arc and none on the
+   extra arc.  An alternative might be to distribute the motion over the
+   extra arc and the main arc in proportion to their lengths.
 
 */
 
 func (cnc *rs274ngc_t) convert_arc_comp2( /* ARGUMENTS                                 */
 	move inc.GCodes, /* either G_2 (cw arc) or G_3 (ccw arc)           */
-	end_x, /* x-value at end of programmed (then actual) arc */
-	end_y, /* y-value at end of programmed (then actual) arc */
-	end_z, /* z-value at end of arc                          */
+	end1, /* coord 1 at end of programmed (then actual) arc */
+	end2, /* coord 2 at end of programmed (then actual) arc */
+	end3, /* coord 3 at end of arc                          */
 	AA_end, /* a-value at end of arc                    */ /*AA*/
 	BB_end, /* b-value at end of arc                    */ /*BB*/
-	CC_end float64) inc.STATUS { /* c-value at end of arc                    */ /*CC*/
+	CC_end, /* c-value at end of arc                    */ /*CC*/
+	offset1, /* offset of center from program_1             */
+	offset2 float64) inc.STATUS { /* offset of center from program_2             */
 
 	//static char name[] = "convert_arc_comp2";
 	var (
 		alpha, /* direction of tangent to start of arc */
 		arc_radius,
 		beta, /* angle between two tangents above */
-		center_x, /* center of arc */
-		center_y,
+		center1, /* center of arc */
+		center2,
 		delta, /* direction of radius from start of arc to center of arc */
 		gamma, /* direction of perpendicular to arc at end */
-		mid_x,
-		mid_y float64
+		mid1,
+		mid2 float64
 
 		/* angle for testing corners */
 		small = inc.TOLERANCE_CONCAVE_CORNER
 
 		turn int
 	)
-	/* find basic arc data: center_x, center_y, and turn */
+	/* find basic arc data: center1, center2, and turn */
 
-	start_x := cnc._setup.program_x
-	start_y := cnc._setup.program_y
+	current1, current2, current3 := cnc.plane_current()
+	start1 := cnc._setup.program_1
+	start2 := cnc._setup.program_2
 
 	tolerance := inc.If(cnc._setup.length_units == inc.CANON_UNITS_INCHES,
 		inc.TOLERANCE_INCH, inc.TOLERANCE_MM).(float64)
 
 	if cnc._setup.block1.r_flag {
-		arc.Arc_data_r(move, start_x, start_y, end_x, end_y, cnc._setup.block1.r_number, &center_x, &center_y, &turn)
+		arc.Arc_data_r(move, start1, start2, end1, end2, cnc._setup.block1.r_number, &center1, &center2, &turn)
 	} else {
-		arc.Arc_data_ijk(move, start_x, start_y, end_x, end_y,
-			cnc._setup.block1.i_number, cnc._setup.block1.j_number, &center_x, &center_y, &turn, tolerance)
+		arc.Arc_data_ijk(move, start1, start2, end1, end2,
+			offset1, offset2, &center1, &center2, &turn, tolerance)
 	}
 
 	/* compute other data */
 	side := cnc._setup.cutter_comp_side
 	/* always is positive */
 	tool_radius := cnc._setup.cutter_comp_radius
-	arc_radius = math.Hypot((center_x - end_x), (center_y - end_y))
-	theta := math.Atan2(cnc._setup.current.Y-start_y, cnc._setup.current.X-start_x)
+	arc_radius = math.Hypot((center1 - end1), (center2 - end2))
+	theta := math.Atan2(*current2-start2, *current1-start1)
 	theta = inc.If(side == inc.CANON_SIDE_LEFT, (theta - inc.PI2), (theta + inc.PI2)).(float64)
-	delta = math.Atan2(center_y-start_y, center_x-start_x)
+	delta = math.Atan2(center2-start2, center1-start1)
 	alpha = inc.If(move == inc.G_3, (delta - inc.PI2), (delta + inc.PI2)).(float64)
 	beta = inc.If(side == inc.CANON_SIDE_LEFT, (theta - alpha), (alpha - theta)).(float64)
 	beta = inc.If(beta > (1.5*inc.PI), (beta - inc.TWO_PI),
@@ -404,63 +404,84 @@ func (cnc *rs274ngc_t) convert_arc_comp2( /* ARGUMENTS
 
 	if ((side == inc.CANON_SIDE_LEFT) && (move == inc.G_3)) ||
 		((side == inc.CANON_SIDE_RIGHT) && (move == inc.G_2)) {
-		gamma = math.Atan2((center_y - end_y), (center_x - end_x))
+		gamma = math.Atan2((center2 - end2), (center1 - end1))
 		if arc_radius <= tool_radius {
 			return inc.NCE_TOOL_RADIUS_NOT_LESS_THAN_ARC_RADIUS_WITH_COMP
 		}
 	} else {
-		gamma = math.Atan2((end_y - center_y), (end_x - center_x))
+		gamma = math.Atan2((end2 - center2), (end1 - center1))
 		delta = (delta + inc.PI)
 	}
 
-	cnc._setup.program_x = end_x
-	cnc._setup.program_y = end_y
-	/* end_x reset actual */
-	end_x = (end_x + (tool_radius * math.Cos(gamma)))
-	/* end_y reset actual */
-	end_y = (end_y + (tool_radius * math.Sin(gamma)))
+	cnc._setup.program_1 = end1
+	cnc._setup.program_2 = end2
+	/* end1 reset actual */
+	end1 = (end1 + (tool_radius * math.Cos(gamma)))
+	/* end2 reset actual */
+	end2 = (end2 + (tool_radius * math.Sin(gamma)))
 
 	/* check if extra arc needed and insert if so */
 
+	main := cnc.comp_arc(*current1, *current2, end1, end2, center1, center2,
+		turn, end3, AA_end, BB_end, CC_end)
+	main.contour = true
+	main.program_start1, main.program_start2 = start1, start2
+	main.program_end1, main.program_end2 = cnc._setup.program_1, cnc._setup.program_2
+
 	if (beta < -small) || (beta > (inc.PI + small)) {
-		return inc.NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP
+		/* the tool path starts where the offset arc meets the held path */
+		radius := math.Hypot(end1-center1, end2-center2)
+		from := math.Hypot(start1-center1, start2-center2)
+		next := comp_path_t{arc: true,
+			start1: center1 + (radius * (start1 - center1) / from),
+			start2: center2 + (radius * (start2 - center2) / from),
+			end1:   end1, end2: end2, center1: center1, center2: center2, turn: turn}
+		if status := cnc.comp_corner(&next); status != inc.RS274NGC_OK {
+			return status
+		}
+		main.start1, main.start2 = next.start1, next.start2
+		beta = 0 /* corner is now made by the cut back path */
 	}
 
 	if beta > small { /* two arcs needed */
-		mid_x = (start_x + (tool_radius * math.Cos(delta)))
-		mid_y = (start_y + (tool_radius * math.Sin(delta)))
+		mid1 = (start1 + (tool_radius * math.Cos(delta)))
+		mid2 = (start2 + (tool_radius * math.Sin(delta)))
 		if cnc._setup.feed_mode == inc.INVERSE_TIME {
 			if side == inc.CANON_SIDE_LEFT {
-				cnc.inverse_time_rate_arc2(start_x, start_y, -1,
-					mid_x, mid_y, center_x, center_y, turn,
-					end_x, end_y, end_z)
+				cnc.inverse_time_rate_arc2(start1, start2, -1,
+					mid1, mid2, center1, center2, turn,
+					end1, end2, end3)
 			} else {
-				cnc.inverse_time_rate_arc2(start_x, start_y, 1,
-					mid_x, mid_y, center_x, center_y, turn,
-					end_x, end_y, end_z)
+				cnc.inverse_time_rate_arc2(start1, start2, 1,
+					mid1, mid2, center1, center2, turn,
+					end1, end2, end3)
 			}
 		}
 
-		if side == inc.CANON_SIDE_LEFT {
-			cnc.canon.ARC_FEED(mid_x, mid_y, start_x, start_y, -1,
-				cnc._setup.current.Z, AA_end, BB_end, CC_end)
-		} else {
-			cnc.canon.ARC_FEED(mid_x, mid_y, start_x, start_y, 1, cnc._setup.current.Z, AA_end, BB_end, CC_end)
+		corner := cnc.comp_arc(*current1, *current2, mid1, mid2, start1, start2,
+			inc.If(side == inc.CANON_SIDE_LEFT, -1, 1).(int), *current3, AA_end, BB_end, CC_end)
+		main.start1, main.start2 = mid1, mid2
+		if status := cnc.comp_gouge(corner, main); status != inc.RS274NGC_OK {
+			return status
 		}
-		cnc.canon.ARC_FEED(end_x, end_y, center_x, center_y, turn, end_z, AA_end, BB_end, CC_end)
+		cnc.comp_hold(corner)
+		cnc.comp_hold(main)
 	} else { /* one arc needed */
 
 		if cnc._setup.feed_mode == inc.INVERSE_TIME {
-			cnc.inverse_time_rate_arc(cnc._setup.current.X, cnc._setup.current.Y,
-				cnc._setup.current.Z, center_x, center_y, turn,
-				end_x, end_y, end_z)
+			cnc.inverse_time_rate_arc(*current1, *current2,
+				*current3, center1, center2, turn,
+				end1, end2, end3)
 		}
-		cnc.canon.ARC_FEED(end_x, end_y, center_x, center_y, turn, end_z, AA_end, BB_end, CC_end)
+		if status := cnc.comp_gouge(main); status != inc.RS274NGC_OK {
+			return status
+		}
+		cnc.comp_hold(main)
 	}
 
-	cnc._setup.current.X = end_x
-	cnc._setup.current.Y = end_y
-	cnc._setup.current.Z = end_z
+	*current1 = end1
+	*current2 = end2
+	*current3 = end3
 
 	cnc._setup.current.A = AA_end /*AA*/
 
@@ -521,10 +542,9 @@ func (cnc *rs274ngc_t) convert_arc2( /* ARGUMENTS
 			offset2, &center1, &center2, &turn, tolerance)
 	}
 	if cnc._setup.feed_mode == inc.INVERSE_TIME {
-
+		cnc.inverse_time_rate_arc(*current1, *current2, *current3, center1, center2,
+			turn, end1, end2, end3)
 	}
-	cnc.inverse_time_rate_arc(*current1, *current2, *current3, center1, center2,
-		turn, end1, end2, end3)
 	cnc.canon.ARC_FEED(end1, end2, center1, center2, turn, end3, AA_end, BB_end, CC_end)
 	*current1 = end1
 	*current2 = end2
@@ -571,7 +591,9 @@ func (cnc *rs274ngc_t) inverse_time_rate_arc( /* ARGUMENTS
 
 	length := arc.Find_arc_length(x1, y1, z1, cx, cy, turn, x2, y2, z2)
 	rate := math.Max(0.1, (length * cnc._setup.block1.f_number))
-	cnc.canon.SET_FEED_RATE(rate)
+	if !cnc.superfluous(cnc._setup.feed_rate == rate) {
+		cnc.canon.SET_FEED_RATE(rate)
+	}
 	cnc._setup.feed_rate = rate
 
 	return inc.RS274NGC_OK
@@ -594,31 +616,36 @@ func (cnc *rs274ngc_t) inverse_time_rate_arc( /* ARGUMENTS
    All rotary motion is assumed to occur on the extra arc, as done by
    convert_arc_comp2.
 
-   All z motion is assumed to occur on the main arc, as done by
-   convert_arc_comp2.
+   All motion perpendicular to the plane is assumed to occur on the main
+   arc, as done by convert_arc_comp2.
+
+   All points are given in the coordinates of the selected plane.
 
 */
 
 func (cnc *rs274ngc_t) inverse_time_rate_arc2( /* ARGUMENTS */
-	start_x, /* x coord of last program point, extra arc center x */
-	start_y float64, /* y coord of last program point, extra arc center y */
+	start1, /* coord 1 of last program point, extra arc center 1 */
+	start2 float64, /* coord 2 of last program point, extra arc center 2 */
 	turn1 int, /* turn of extra arc                                 */
-	mid_x, /* x coord of end point of extra arc                 */
-	mid_y, /* y coord of end point of extra arc                 */
-	cx, /* x coord of center of main arc                     */
-	cy float64, /* y coord of center of main arc                     */
+	mid1, /* coord 1 of end point of extra arc                 */
+	mid2, /* coord 2 of end point of extra arc                 */
+	c1, /* coord 1 of center of main arc                     */
+	c2 float64, /* coord 2 of center of main arc                     */
 	turn2 int, /* turn of main arc                                  */
-	end_x, /* x coord of end point of main arc                  */
-	end_y, /* y coord of end point of main arc                  */
-	end_z float64) inc.STATUS { /* z coord of end point of main arc                  */
-
-	length := (arc.Find_arc_length(cnc._setup.current.X, cnc._setup.current.Y,
-		cnc._setup.current.Z, start_x, start_y,
-		turn1, mid_x, mid_y, cnc._setup.current.Z) +
-		arc.Find_arc_length(mid_x, mid_y, cnc._setup.current.Z,
-			cx, cy, turn2, end_x, end_y, end_z))
+	end1, /* coord 1 of end point of main arc                  */
+	end2, /* coord 2 of end point of main arc                  */
+	end3 float64) inc.STATUS { /* coord 3 of end point of main arc                  */
+
+	current1, current2, current3 := cnc.plane_current()
+	length := (arc.Find_arc_length(*current1, *current2,
+		*current3, start1, start2,
+		turn1, mid1, mid2, *current3) +
+		arc.Find_arc_length(mid1, mid2, *current3,
+			c1, c2, turn2, end1, end2, end3))
 	rate := math.Max(0.1, (length * cnc._setup.block1.f_number))
-	cnc.canon.SET_FEED_RATE(rate)
+	if !cnc.superfluous(cnc._setup.feed_rate == rate) {
+		cnc.canon.SET_FEED_RATE(rate)
+	}
 	cnc._setup.feed_rate = rate
 
 	return inc.RS274NGC_OK
@@ -0,0 +1,820 @@
+package rs274ngc
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* Cutter radius compensation lookahead
+
+   Without lookahead, a compensated move is sent to the canonical
+   machining functions as soon as it is converted, so the end of a move
+   is fixed before the next move is known. That is why a concave corner
+   is an error (NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP): the tool has
+   already been sent past the point where it should have stopped.
+
+   With a lookahead of N (see SetCompLookahead), the moves of the last N
+   motion blocks are held in _setup.comp_queue. When a concave corner is
+   found, the held path is cut back to where it meets the offset path of
+   the new move. If the held moves of a block are entirely undercut (a
+   segment shorter than the tool can follow), they are removed and the
+   block before is tried. Each new move is also checked against the held
+   moves for gouges: no point of a tool path may come closer than the
+   tool radius to a programmed contour.
+
+   Held moves are sent when the queue holds more than N blocks, when
+   cutter radius compensation is turned off, and before any block that
+   does more than move and set the feed rate. Lookahead is not used in
+   inverse time feed mode, since the feed rates of held moves would
+   change when the moves are cut back.
+
+   When a corner cannot be resolved or a gouge is found, the error text
+   naming both lines is kept and may be read with CompErrorText.
+
+*/
+
+// comp_move_t is a compensated move, or a feed rate setting, held back
+// for lookahead. Coordinates 1 and 2 are those of the selected plane and
+// 3 is along the axis perpendicular to it (see plane_current).
+type comp_move_t struct {
+	motion    inc.GCodes // G_0, G_1, G_2, G_3, or -1 for a feed rate
+	feed_rate float64    // feed rate, if motion is -1
+
+	start1, start2         float64 // start of tool path
+	end1, end2, end3       float64 // end of tool path
+	AA_end, BB_end, CC_end float64 // rotary axes at end /*AA*/ /*BB*/ /*CC*/
+	center1, center2       float64 // center, if an arc
+	turn                   int     // turn, if an arc
+	contour                bool    // whether program_* is a contour to check
+	program_start1         float64 // start of programmed contour
+	program_start2         float64
+	program_end1           float64 // end of programmed contour
+	program_end2           float64
+	line                   string // text of the line the move is from
+	block                  int    // _setup.comp_block of the move
+}
+
+// comp_path_t is a line or an arc in the selected plane.
+type comp_path_t struct {
+	arc              bool
+	start1, start2   float64
+	end1, end2       float64
+	center1, center2 float64
+	turn             int
+}
+
+/****************************************************************************/
+
+/* SetCompLookahead
+
+   Returned Value: none
+
+   Side effects: _setup.comp_lookahead is set.
+
+   Called by: external programs
+
+   This sets the number of motion blocks held back for cutter radius
+   compensation lookahead. Zero, the default, sends each move as soon as
+   it is converted, which is the original behavior.
+
+*/
+
+func (cnc *rs274ngc_t) SetCompLookahead(n int) {
+	if n < 0 {
+		n = 0
+	}
+	cnc._setup.comp_lookahead = n
+}
+
+/****************************************************************************/
+
+/* CompErrorText
+
+   Returned Value: string
+
+   Side effects: none
+
+   Called by: external programs
+
+   This returns a description, naming both lines involved, of the last
+   cutter radius compensation error, or an empty string if the last
+   executed block did not fail that way.
+
+*/
+
+func (cnc *rs274ngc_t) CompErrorText() string {
+	return cnc._setup.comp_error
+}
+
+/****************************************************************************/
+
+/* SetCompPassthrough
+
+   Returned Value: error
+   If on is true and the canon has no cutter radius compensation
+   functions (see inc.Comp_i), this returns an error saying so, and
+   compensation is not passed through. Otherwise it returns nil.
+
+   Side effects: _setup.comp_passthrough is set.
+
+   Called by: external programs
+
+   With on true, the interpreter does not offset the tool path for cutter
+   radius compensation. G41, G42, G41.1 and G42.1 call
+   SET_CUTTER_RADIUS_COMPENSATION and START_CUTTER_RADIUS_COMPENSATION,
+   G40 (and M2 or M30, if compensation is on) calls
+   STOP_CUTTER_RADIUS_COMPENSATION, and moves are given as programmed,
+   for a machine that does its own compensation. The checks made when
+   compensation is on (no G53 moves, no probing, and so on) still apply.
+
+   This should be called while compensation is off, between programs,
+   and after the canon is set, since setting a canon without the
+   functions turns passing through off.
+
+*/
+
+func (cnc *rs274ngc_t) SetCompPassthrough(on bool) error {
+	if on && (cnc.canon_comp == nil) {
+		return fmt.Errorf("canon of type %T has no cutter radius compensation functions", cnc.canon)
+	}
+	cnc._setup.comp_passthrough = ON_OFF(on)
+	return nil
+}
+
+/****************************************************************************/
+
+/* comp_fail
+
+   Returned Value: int (the error code given)
+
+   Side effects: _setup.comp_error is set.
+
+   Called by:
+   comp_corner
+   comp_gouge
+
+   This records which two lines are involved in a cutter radius
+   compensation error.
+
+*/
+
+func (cnc *rs274ngc_t) comp_fail(code inc.STATUS, line string) inc.STATUS {
+	cnc._setup.comp_error = fmt.Sprintf("%s: line %q and line %q",
+		inc.Rs274ngc_error_text(code), line, cnc._setup.linetext)
+	return code
+}
+
+/****************************************************************************/
+
+/* comp_holding
+
+   Returned Value: bool
+
+   Side effects: none
+
+   Called by:
+   comp_corner
+   comp_hold
+
+   This returns true if compensated moves are being held for lookahead.
+
+*/
+
+func (cnc *rs274ngc_t) comp_holding() bool {
+	return (cnc._setup.comp_lookahead > 0) &&
+		(cnc._setup.feed_mode != inc.INVERSE_TIME) &&
+		(cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF)
+}
+
+/****************************************************************************/
+
+/* comp_queueable
+
+   Returned Value: bool
+
+   Side effects: none
+
+   Called by: execute_block
+
+   This returns true if the block in _setup.block1 may be executed while
+   compensated moves are held: it is a straight or arc move that does
+   nothing else but, possibly, set the feed rate. Anything else must
+   reach the canonical machining functions after the held moves, so they
+   are sent first.
+
+*/
+
+func (cnc *rs274ngc_t) comp_queueable() bool {
+	block := &cnc._setup.block1
+
+	if !cnc.comp_holding() {
+		return false
+	}
+	if (block.motion_to_be != inc.G_0) && (block.motion_to_be != inc.G_1) &&
+		(block.motion_to_be != inc.G_2) && (block.motion_to_be != inc.G_3) {
+		return false
+	}
+	for n, g := range block.g_modes {
+		if (n != GCodeMotion) && (g != -1) {
+			return false
+		}
+	}
+	if (cnc._setup.a_axis_clamping == ON) && (block.a_flag == ON) {
+		return false /* the A-axis is unclamped before the move */
+	}
+	return (block.m_count == 0) && (block.s_number == -1.0) &&
+		(block.t_number == -1) && (len(block.comment) == 0)
+}
+
+/****************************************************************************/
+
+/* comp_hold
+
+   Returned Value: none
+
+   Side effects:
+   The move is sent to the canonical machining functions or added to
+   _setup.comp_queue, and the oldest held blocks may be sent.
+
+   Called by:
+   convert_arc_comp1
+   convert_arc_comp2
+   convert_feed_rate
+   convert_straight_comp1
+   convert_straight_comp2
+
+*/
+
+func (cnc *rs274ngc_t) comp_hold(move comp_move_t) {
+	if !cnc.comp_holding() {
+		cnc.comp_flush()
+		cnc.comp_emit(&move)
+		return
+	}
+	cnc._setup.comp_queue = append(cnc._setup.comp_queue, move)
+
+	for {
+		blocks := 0
+		last := -1
+		for n := range cnc._setup.comp_queue {
+			if (cnc._setup.comp_queue[n].motion != -1) && (cnc._setup.comp_queue[n].block != last) {
+				last = cnc._setup.comp_queue[n].block
+				blocks++
+			}
+		}
+		if blocks <= cnc._setup.comp_lookahead {
+			break
+		}
+		cnc.comp_release()
+	}
+}
+
+/****************************************************************************/
+
+/* comp_release
+
+   Returned Value: none
+
+   Side effects:
+   The moves of the oldest held block, and any feed rate held before
+   them, are sent and removed from _setup.comp_queue.
+
+   Called by: comp_hold
+
+*/
+
+func (cnc *rs274ngc_t) comp_release() {
+	queue := cnc._setup.comp_queue
+	block := -1
+	n := 0
+	for ; n < len(queue); n++ {
+		if queue[n].motion != -1 {
+			if block == -1 {
+				block = queue[n].block
+			} else if queue[n].block != block {
+				break
+			}
+		}
+		cnc.comp_emit(&queue[n])
+	}
+	cnc._setup.comp_queue = queue[n:]
+}
+
+/****************************************************************************/
+
+/* comp_flush
+
+   Returned Value: none
+
+   Side effects: All held moves are sent and _setup.comp_queue is emptied.
+
+   Called by:
+   comp_hold
+   convert_cutter_compensation_off
+   convert_stop
+   execute_block
+
+*/
+
+func (cnc *rs274ngc_t) comp_flush() {
+	for n := range cnc._setup.comp_queue {
+		cnc.comp_emit(&cnc._setup.comp_queue[n])
+	}
+	cnc._setup.comp_queue = cnc._setup.comp_queue[:0]
+}
+
+/****************************************************************************/
+
+/* comp_emit
+
+   Returned Value: none
+
+   Side effects: A canonical machining function is called for the move.
+
+   Called by:
+   comp_flush
+   comp_hold
+   comp_release
+
+*/
+
+func (cnc *rs274ngc_t) comp_emit(move *comp_move_t) {
+	if move.motion == -1 {
+		cnc.canon.SET_FEED_RATE(move.feed_rate)
+		return
+	}
+	if (move.motion == inc.G_2) || (move.motion == inc.G_3) {
+		cnc.canon.ARC_FEED(move.end1, move.end2, move.center1, move.center2,
+			move.turn, move.end3, move.AA_end, move.BB_end, move.CC_end)
+		return
+	}
+	x, y, z := cnc.plane_xyz(move.end1, move.end2, move.end3)
+	if move.motion == inc.G_0 {
+		cnc.canon.STRAIGHT_TRAVERSE(x, y, z, move.AA_end, move.BB_end, move.CC_end)
+	} else {
+		cnc.canon.STRAIGHT_FEED(x, y, z, move.AA_end, move.BB_end, move.CC_end)
+	}
+}
+
+/****************************************************************************/
+
+/* comp_straight
+
+   Returned Value: comp_move_t
+
+   Side effects: none
+
+   Called by:
+   convert_straight_comp1
+   convert_straight_comp2
+
+   This makes a straight move of the current block, from (start1, start2)
+   to the end given, in plane coordinates.
+
+*/
+
+func (cnc *rs274ngc_t) comp_straight(move inc.GCodes,
+	start1, start2, end1, end2, end3, AA_end, BB_end, CC_end float64) comp_move_t {
+	return comp_move_t{motion: move, start1: start1, start2: start2,
+		end1: end1, end2: end2, end3: end3,
+		AA_end: AA_end, BB_end: BB_end, CC_end: CC_end,
+		line: cnc._setup.linetext, block: cnc._setup.comp_block}
+}
+
+/****************************************************************************/
+
+/* comp_arc
+
+   Returned Value: comp_move_t
+
+   Side effects: none
+
+   Called by:
+   convert_arc_comp1
+   convert_arc_comp2
+   convert_straight_comp2
+
+   This makes an arc move of the current block, from (start1, start2) to
+   the end given around (center1, center2), in plane coordinates.
+
+*/
+
+func (cnc *rs274ngc_t) comp_arc(start1, start2, end1, end2, center1, center2 float64,
+	turn int, end3, AA_end, BB_end, CC_end float64) comp_move_t {
+	return comp_move_t{motion: inc.If(turn < 0, inc.G_2, inc.G_3).(inc.GCodes),
+		start1: start1, start2: start2, end1: end1, end2: end2, end3: end3,
+		center1: center1, center2: center2, turn: turn,
+		AA_end: AA_end, BB_end: BB_end, CC_end: CC_end,
+		line: cnc._setup.linetext, block: cnc._setup.comp_block}
+}
+
+/****************************************************************************/
+
+/* comp_corner
+
+   Returned Value: int
+   If any of the following errors occur, this returns the error shown.
+   Otherwise, it returns RS274NGC_OK.
+   1. Moves are not being held for lookahead, or no held move meets the
+   new path: NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP
+   2. A held move that would be removed moves the axis perpendicular to
+   the plane or a rotary axis: NCE_CUTTER_GOUGING_BETWEEN_LINES_WITH_COMP
+
+   Side effects:
+   The held path is cut back to where it meets next, and the start of
+   next is moved there. Held moves that are entirely undercut are
+   removed. A move with no motion in the plane (a plunge, say) is kept,
+   but it is moved to the new corner.
+
+   Called by:
+   convert_arc_comp2
+   convert_straight_comp2
+
+   This is called for a concave corner, with next being the first part
+   of the tool path of the new move. The held moves are tried from the
+   newest to the oldest. For each, the points where it meets next are
+   found, and the one furthest along the held move is used.
+
+*/
+
+func (cnc *rs274ngc_t) comp_corner(next *comp_path_t) inc.STATUS {
+	queue := cnc._setup.comp_queue
+
+	if !cnc.comp_holding() || (len(queue) == 0) {
+		return cnc.comp_fail(inc.NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP, cnc._setup.comp_line)
+	}
+
+	const small = 1e-9
+	for k := len(queue) - 1; k >= 0; k-- {
+		held := &queue[k]
+		if (held.motion == -1) || comp_still(held) {
+			continue
+		}
+
+		var (
+			best  float64 = -1
+			point [2]float64
+		)
+		path := held.path()
+		for _, p := range comp_intersections(path, *next) {
+			t := comp_param(path, p[0], p[1])
+			s := comp_param(*next, p[0], p[1])
+			if (t > small) && (t < 1+small) && (s > -small) && (s < 1-small) && (t > best) {
+				best = t
+				point = p
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		/* keep held moves up to k, cut back to point */
+		kept := append([]comp_move_t(nil), queue[:k+1]...)
+		kept[k].end1, kept[k].end2 = point[0], point[1]
+		prior := kept[k]
+		for n := k + 1; n < len(queue); n++ {
+			move := queue[n]
+			if move.motion == -1 {
+				kept = append(kept, move)
+			} else if comp_still(&move) {
+				move.start1, move.start2 = point[0], point[1]
+				move.end1, move.end2 = point[0], point[1]
+				kept = append(kept, move)
+				prior = move
+			} else if (move.end3 != prior.end3) || (move.AA_end != prior.AA_end) ||
+				(move.BB_end != prior.BB_end) || (move.CC_end != prior.CC_end) {
+				return cnc.comp_fail(inc.NCE_CUTTER_GOUGING_BETWEEN_LINES_WITH_COMP, move.line)
+			} /* else undercut, so removed */
+		}
+		cnc._setup.comp_queue = kept
+		next.start1, next.start2 = point[0], point[1]
+		return inc.RS274NGC_OK
+	}
+	return cnc.comp_fail(inc.NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP, cnc._setup.comp_line)
+}
+
+/****************************************************************************/
+
+/* comp_gouge
+
+   Returned Value: int
+   If the tool path of a new move comes closer than the tool radius to
+   the programmed contour of a held move, or the tool path of a held move
+   comes that close to the programmed contour of a new move, this returns
+   NCE_CUTTER_GOUGING_BETWEEN_LINES_WITH_COMP.
+   Otherwise, it returns RS274NGC_OK.
+
+   Side effects: _setup.comp_error is set if a gouge is found.
+
+   Called by:
+   convert_arc_comp2
+   convert_straight_comp2
+
+   This is called with the moves of a block before they are held. A
+   correct offset path never comes closer than the tool radius to the
+   contour it offsets, so a tolerance is all that is allowed.
+
+*/
+
+func (cnc *rs274ngc_t) comp_gouge(moves ...comp_move_t) inc.STATUS {
+	if !cnc.comp_holding() {
+		return inc.RS274NGC_OK
+	}
+
+	tolerance := inc.If(cnc._setup.length_units == inc.CANON_UNITS_INCHES,
+		inc.TOLERANCE_INCH, inc.TOLERANCE_MM).(float64)
+	limit := cnc._setup.cutter_comp_radius - tolerance
+
+	for n := range moves {
+		for k := range cnc._setup.comp_queue {
+			held := &cnc._setup.comp_queue[k]
+			if held.motion == -1 {
+				continue
+			}
+			if held.contour &&
+				(comp_path_distance(moves[n].path(), held.program_path()) < limit) {
+				return cnc.comp_fail(inc.NCE_CUTTER_GOUGING_BETWEEN_LINES_WITH_COMP, held.line)
+			}
+			if moves[n].contour &&
+				(comp_path_distance(held.path(), moves[n].program_path()) < limit) {
+				return cnc.comp_fail(inc.NCE_CUTTER_GOUGING_BETWEEN_LINES_WITH_COMP, held.line)
+			}
+		}
+	}
+	return inc.RS274NGC_OK
+}
+
+/****************************************************************************/
+
+/* comp_still
+
+   Returned Value: bool
+
+   Side effects: none
+
+   Called by: comp_corner
+
+   This returns true if a move has no motion in the plane.
+
+*/
+
+func comp_still(move *comp_move_t) bool {
+	return (move.motion != inc.G_2) && (move.motion != inc.G_3) &&
+		(move.start1 == move.end1) && (move.start2 == move.end2)
+}
+
+// path returns the tool path of a move.
+func (move *comp_move_t) path() comp_path_t {
+	return comp_path_t{arc: (move.motion == inc.G_2) || (move.motion == inc.G_3),
+		start1: move.start1, start2: move.start2, end1: move.end1, end2: move.end2,
+		center1: move.center1, center2: move.center2, turn: move.turn}
+}
+
+// program_path returns the programmed contour of a move.
+func (move *comp_move_t) program_path() comp_path_t {
+	return comp_path_t{arc: (move.motion == inc.G_2) || (move.motion == inc.G_3),
+		start1: move.program_start1, start2: move.program_start2,
+		end1: move.program_end1, end2: move.program_end2,
+		center1: move.center1, center2: move.center2, turn: move.turn}
+}
+
+/****************************************************************************/
+
+/* comp_sweep
+
+   Returned Value: float64 (the angle swept by an arc, in radians,
+   positive counterclockwise)
+
+   Side effects: none
+
+   Called by:
+   comp_param
+   comp_path_distance
+
+*/
+
+func comp_sweep(path comp_path_t) float64 {
+	start := math.Atan2(path.start2-path.center2, path.start1-path.center1)
+	end := math.Atan2(path.end2-path.center2, path.end1-path.center1)
+	sweep := end - start
+	if path.turn < 0 {
+		for sweep >= 0 {
+			sweep -= inc.TWO_PI
+		}
+		return sweep + float64(path.turn+1)*inc.TWO_PI
+	}
+	for sweep <= 0 {
+		sweep += inc.TWO_PI
+	}
+	return sweep + float64(path.turn-1)*inc.TWO_PI
+}
+
+/****************************************************************************/
+
+/* comp_param
+
+   Returned Value: float64
+
+   Side effects: none
+
+   Called by: comp_corner
+
+   This returns where the point (x1, x2), which must be on the line or
+   circle of the path, is along the path: 0 at the start and 1 at the
+   end. Points before the start give negative values, points after the
+   end values over 1. On a circle, a point within a small angle before
+   the start counts as before the start; any other point off the arc
+   counts as after the end.
+
+*/
+
+func comp_param(path comp_path_t, x1, x2 float64) float64 {
+	if !path.arc {
+		d1 := path.end1 - path.start1
+		d2 := path.end2 - path.start2
+		length := (d1 * d1) + (d2 * d2)
+		if length == 0 {
+			return -1
+		}
+		return (((x1 - path.start1) * d1) + ((x2 - path.start2) * d2)) / length
+	}
+
+	sweep := comp_sweep(path)
+	angle := math.Atan2(x2-path.center2, x1-path.center1) -
+		math.Atan2(path.start2-path.center2, path.start1-path.center1)
+	if sweep > 0 {
+		for angle < 0 {
+			angle += inc.TWO_PI
+		}
+		if (inc.TWO_PI - angle) < 1e-9 {
+			angle -= inc.TWO_PI
+		}
+	} else {
+		for angle > 0 {
+			angle -= inc.TWO_PI
+		}
+		if (inc.TWO_PI + angle) < 1e-9 {
+			angle += inc.TWO_PI
+		}
+	}
+	return angle / sweep
+}
+
+/****************************************************************************/
+
+/* comp_intersections
+
+   Returned Value: [][2]float64 (the points where the line or circle of
+   path a meets the line or circle of path b)
+
+   Side effects: none
+
+   Called by: comp_corner
+
+   Whole lines and circles are used; comp_param says whether a point is
+   on a path itself.
+
+*/
+
+func comp_intersections(a, b comp_path_t) [][2]float64 {
+	if a.arc && !b.arc {
+		a, b = b, a
+	}
+
+	if !a.arc && !b.arc {
+		d1 := [2]float64{a.end1 - a.start1, a.end2 - a.start2}
+		d2 := [2]float64{b.end1 - b.start1, b.end2 - b.start2}
+		cross := (d1[0] * d2[1]) - (d1[1] * d2[0])
+		if math.Abs(cross) < 1e-12 {
+			return nil
+		}
+		t := (((b.start1 - a.start1) * d2[1]) - ((b.start2 - a.start2) * d2[0])) / cross
+		return [][2]float64{{a.start1 + (t * d1[0]), a.start2 + (t * d1[1])}}
+	}
+
+	radius := math.Hypot(b.start1-b.center1, b.start2-b.center2)
+	if !a.arc { /* line a, circle b */
+		d1 := a.end1 - a.start1
+		d2 := a.end2 - a.start2
+		f1 := a.start1 - b.center1
+		f2 := a.start2 - b.center2
+		qa := (d1 * d1) + (d2 * d2)
+		qb := 2 * ((d1 * f1) + (d2 * f2))
+		qc := (f1 * f1) + (f2 * f2) - (radius * radius)
+		disc := (qb * qb) - (4 * qa * qc)
+		if (qa == 0) || (disc < 0) {
+			return nil
+		}
+		disc = math.Sqrt(disc)
+		var points [][2]float64
+		for _, t := range []float64{(-qb - disc) / (2 * qa), (-qb + disc) / (2 * qa)} {
+			points = append(points, [2]float64{a.start1 + (t * d1), a.start2 + (t * d2)})
+		}
+		return points
+	}
+
+	/* two circles */
+	radius_a := math.Hypot(a.start1-a.center1, a.start2-a.center2)
+	d1 := b.center1 - a.center1
+	d2 := b.center2 - a.center2
+	distance := math.Hypot(d1, d2)
+	if (distance == 0) || (distance > (radius_a + radius)) ||
+		(distance < math.Abs(radius_a-radius)) {
+		return nil
+	}
+	along := ((radius_a * radius_a) - (radius * radius) + (distance * distance)) / (2 * distance)
+	across := math.Sqrt(math.Max(0, (radius_a*radius_a)-(along*along)))
+	mid1 := a.center1 + (along * d1 / distance)
+	mid2 := a.center2 + (along * d2 / distance)
+	return [][2]float64{
+		{mid1 + (across * d2 / distance), mid2 - (across * d1 / distance)},
+		{mid1 - (across * d2 / distance), mid2 + (across * d1 / distance)},
+	}
+}
+
+/****************************************************************************/
+
+/* comp_point_distance
+
+   Returned Value: float64 (the distance from (x1, x2) to the path)
+
+   Side effects: none
+
+   Called by: comp_path_distance
+
+*/
+
+func comp_point_distance(path comp_path_t, x1, x2 float64) float64 {
+	if !path.arc {
+		t := comp_param(path, x1, x2)
+		t = math.Max(0, math.Min(1, t))
+		return math.Hypot(x1-(path.start1+(t*(path.end1-path.start1))),
+			x2-(path.start2+(t*(path.end2-path.start2))))
+	}
+	t := comp_param(path, x1, x2)
+	if (t >= 0) && (t <= 1) {
+		radius := math.Hypot(path.start1-path.center1, path.start2-path.center2)
+		return math.Abs(math.Hypot(x1-path.center1, x2-path.center2) - radius)
+	}
+	return math.Min(math.Hypot(x1-path.start1, x2-path.start2),
+		math.Hypot(x1-path.end1, x2-path.end2))
+}
+
+/****************************************************************************/
+
+/* comp_path_distance
+
+   Returned Value: float64 (the least distance from the tool path to the
+   contour)
+
+   Side effects: none
+
+   Called by: comp_gouge
+
+   Between two lines this is exact. If either is an arc, the tool path is
+   sampled at one degree of arc or at 1/64 of its length, whichever is
+   finer, which is close enough for finding gouges.
+
+*/
+
+func comp_path_distance(tool, contour comp_path_t) float64 {
+	if !tool.arc && !contour.arc {
+		for _, p := range comp_intersections(tool, contour) {
+			t := comp_param(tool, p[0], p[1])
+			s := comp_param(contour, p[0], p[1])
+			if (t >= 0) && (t <= 1) && (s >= 0) && (s <= 1) {
+				return 0
+			}
+		}
+		return math.Min(
+			math.Min(comp_point_distance(contour, tool.start1, tool.start2),
+				comp_point_distance(contour, tool.end1, tool.end2)),
+			math.Min(comp_point_distance(tool, contour.start1, contour.start2),
+				comp_point_distance(tool, contour.end1, contour.end2)))
+	}
+
+	steps := 64
+	var sweep float64
+	if tool.arc {
+		sweep = comp_sweep(tool)
+		steps = int(math.Max(64, math.Ceil(math.Abs(sweep)/(inc.PI/180))))
+	}
+	least := math.Inf(1)
+	for n := 0; n <= steps; n++ {
+		t := float64(n) / float64(steps)
+		var x1, x2 float64
+		if tool.arc {
+			radius := math.Hypot(tool.start1-tool.center1, tool.start2-tool.center2)
+			angle := math.Atan2(tool.start2-tool.center2, tool.start1-tool.center1) + (t * sweep)
+			x1 = tool.center1 + (radius * math.Cos(angle))
+			x2 = tool.center2 + (radius * math.Sin(angle))
+		} else {
+			x1 = tool.start1 + (t * (tool.end1 - tool.start1))
+			x2 = tool.start2 + (t * (tool.end2 - tool.start2))
+		}
+		least = math.Min(least, comp_point_distance(contour, x1, x2))
+	}
+	return least
+}
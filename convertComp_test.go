@@ -0,0 +1,293 @@
+package rs274ngc
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/example/canon"
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+// comp_canon_t records the moves made by the interpreter, each written
+// in the coordinates of the plane being tested (first, second, axis).
+type comp_canon_t struct {
+	canon.Canon_t
+	plane inc.CANON_PLANE
+	moves []string
+}
+
+func (c *comp_canon_t) GET_EXTERNAL_PARAMETER_FILE_NAME() string {
+	return "example/rs274ngc.var"
+}
+
+func (c *comp_canon_t) straight(name string, x, y, z float64) {
+	if c.plane == inc.CANON_PLANE_YZ {
+		x, y, z = y, z, x
+	} else if c.plane == inc.CANON_PLANE_XZ {
+		x, y, z = z, x, y
+	}
+	c.moves = append(c.moves, fmt.Sprintf("%s(%.4f, %.4f, %.4f)", name, x, y, z))
+}
+
+func (c *comp_canon_t) STRAIGHT_TRAVERSE(x, y, z, a, b, cc float64) {
+	c.straight("STRAIGHT_TRAVERSE", x, y, z)
+}
+
+func (c *comp_canon_t) STRAIGHT_FEED(x, y, z, a, b, cc float64) {
+	c.straight("STRAIGHT_FEED", x, y, z)
+}
+
+func (c *comp_canon_t) SET_CUTTER_RADIUS_COMPENSATION(radius float64) {
+	c.moves = append(c.moves, fmt.Sprintf("SET_CUTTER_RADIUS_COMPENSATION(%.4f)", radius))
+}
+
+func (c *comp_canon_t) START_CUTTER_RADIUS_COMPENSATION(side inc.CANON_SIDE) {
+	c.moves = append(c.moves, fmt.Sprintf("START_CUTTER_RADIUS_COMPENSATION(%v)", side))
+}
+
+func (c *comp_canon_t) STOP_CUTTER_RADIUS_COMPENSATION() {
+	c.moves = append(c.moves, "STOP_CUTTER_RADIUS_COMPENSATION()")
+}
+
+func (c *comp_canon_t) ARC_FEED(first_end, second_end, first_axis, second_axis float64,
+	rotation int, axis_end_point, a, b, cc float64) {
+	c.moves = append(c.moves, fmt.Sprintf("ARC_FEED(%.4f, %.4f, %.4f, %.4f, %d, %.4f)",
+		first_end, second_end, first_axis, second_axis, rotation, axis_end_point))
+}
+
+// run_comp executes lines in which @1, @2 and @3 stand for the first and
+// second axes of the plane and the axis perpendicular to it, and @4 and
+// @5 for the arc center offsets along the first and second axes. It
+// returns the moves made, the status of the last line, and the plane
+// selected at the end.
+func run_comp(plane inc.CANON_PLANE, lines []string) ([]string, inc.STATUS, inc.CANON_PLANE) {
+	moves, status, cnc := run_comp_lookahead(plane, 0, lines)
+	return moves, status, cnc._setup.plane
+}
+
+// run_comp_lookahead is run_comp with a cutter comp lookahead of n
+// blocks. The held moves are sent before the moves are returned.
+func run_comp_lookahead(plane inc.CANON_PLANE, n int, lines []string) ([]string, inc.STATUS, *rs274ngc_t) {
+	return run_comp_with(plane, func(cnc *rs274ngc_t) { cnc.SetCompLookahead(n) }, lines)
+}
+
+// run_comp_with is run_comp with setup called on the interpreter before
+// the lines are executed.
+func run_comp_with(plane inc.CANON_PLANE, setup func(cnc *rs274ngc_t), lines []string) ([]string, inc.STATUS, *rs274ngc_t) {
+	letters := map[inc.CANON_PLANE][5]byte{
+		inc.CANON_PLANE_XY: {'x', 'y', 'z', 'i', 'j'},
+		inc.CANON_PLANE_XZ: {'z', 'x', 'y', 'k', 'i'},
+		inc.CANON_PLANE_YZ: {'y', 'z', 'x', 'j', 'k'},
+	}[plane]
+	gee := map[inc.CANON_PLANE]string{
+		inc.CANON_PLANE_XY: "g17", inc.CANON_PLANE_XZ: "g18", inc.CANON_PLANE_YZ: "g19",
+	}[plane]
+
+	c := &comp_canon_t{plane: plane}
+	cnc := &rs274ngc_t{}
+	cnc.SetCanon(c)
+	cnc.Init()
+	setup(cnc)
+
+	status := inc.RS274NGC_OK
+	for _, line := range append([]string{"g21 g90 f100 " + gee, "g0 x0 y0 z0"}, lines...) {
+		text := []byte(line)
+		for i, ch := range text {
+			if ch >= '1' && ch <= '5' && i > 0 && text[i-1] == '@' {
+				text[i-1] = letters[ch-'1']
+				text[i] = ' '
+			}
+		}
+		if status = cnc.Read(text); status == inc.RS274NGC_OK {
+			status = cnc.Execute()
+		}
+		if status != inc.RS274NGC_OK {
+			break
+		}
+	}
+	cnc.comp_flush()
+	return c.moves[1:], status, cnc
+}
+
+func TestCNC_cutter_comp_planes(t *testing.T) {
+	profile := []string{
+		"g42.1 d2 g1 @1 10 @2 0",
+		"g1 @1 10 @2 10",
+		"g3 @1 0 @2 10 r5",
+		"g2 @1 -10 @2 10 @4 -5 @5 0",
+		"g40 g1 @1 -10 @2 20",
+	}
+	want := []string{
+		"STRAIGHT_FEED(9.9000, -0.9950, 0.0000)",
+		"ARC_FEED(11.0000, 0.0000, 10.0000, 0.0000, 1, 0.0000)",
+		"STRAIGHT_FEED(11.0000, 10.0000, 0.0000)",
+		"ARC_FEED(-1.0000, 10.0000, 5.0000, 10.0000, 1, 0.0000)",
+		"ARC_FEED(-9.0000, 10.0000, -5.0000, 10.0000, -1, 0.0000)",
+		"STRAIGHT_FEED(-10.0000, 20.0000, 0.0000)",
+	}
+	helical := []string{
+		"g41.1 d2 g1 @1 10 @2 0",
+		"g1 @1 20 @3 -1",
+		"g40 g1 @1 20 @2 10",
+	}
+	helical_want := []string{
+		"STRAIGHT_FEED(9.9000, 0.9950, 0.0000)",
+		"ARC_FEED(10.0000, 1.0000, 10.0000, 0.0000, -1, 0.0000)",
+		"STRAIGHT_FEED(20.0000, 1.0000, -1.0000)",
+		"STRAIGHT_FEED(20.0000, 10.0000, -1.0000)",
+	}
+
+	tests := []struct {
+		name  string
+		plane inc.CANON_PLANE
+		lines []string
+		want  []string
+	}{
+		{name: "xy profile", plane: inc.CANON_PLANE_XY, lines: profile, want: want},
+		{name: "xz profile", plane: inc.CANON_PLANE_XZ, lines: profile, want: want},
+		{name: "yz profile", plane: inc.CANON_PLANE_YZ, lines: profile, want: want},
+		{name: "xz axis move", plane: inc.CANON_PLANE_XZ, lines: helical, want: helical_want},
+		{name: "yz axis move", plane: inc.CANON_PLANE_YZ, lines: helical, want: helical_want},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, status, _ := run_comp(tt.plane, tt.lines)
+			if status != inc.RS274NGC_OK {
+				t.Fatalf("status = %v, want %v", status, inc.RS274NGC_OK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("moves = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCNC_cutter_comp_plane_change(t *testing.T) {
+	tests := []struct {
+		name  string
+		plane inc.CANON_PLANE
+		lines []string
+		want  inc.CANON_PLANE
+	}{
+		{name: "g18 reselected", plane: inc.CANON_PLANE_XZ,
+			lines: []string{"g41.1 d2 g1 @1 10", "g18"}, want: inc.CANON_PLANE_XZ},
+		{name: "g17 from xz", plane: inc.CANON_PLANE_XZ,
+			lines: []string{"g41.1 d2 g1 @1 10", "g17"}, want: inc.CANON_PLANE_XZ},
+		{name: "g19 from xz", plane: inc.CANON_PLANE_XZ,
+			lines: []string{"g41.1 d2 g1 @1 10", "g19"}, want: inc.CANON_PLANE_XZ},
+		{name: "g18 from yz", plane: inc.CANON_PLANE_YZ,
+			lines: []string{"g41.1 d2 g1 @1 10", "g18"}, want: inc.CANON_PLANE_YZ},
+		{name: "g17 after g40", plane: inc.CANON_PLANE_YZ,
+			lines: []string{"g41.1 d2 g1 @1 10", "g40", "g17"}, want: inc.CANON_PLANE_XY},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, got := run_comp(tt.plane, tt.lines); got != tt.want {
+				t.Errorf("plane = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCNC_cutter_comp_lookahead(t *testing.T) {
+	tests := []struct {
+		name      string
+		lookahead int
+		lines     []string
+		status    inc.STATUS
+		want      []string
+		text      string
+	}{
+		{name: "concave corner", lookahead: 0,
+			lines:  []string{"g41.1 d2 g1 x10 y0", "g1 x10 y10"},
+			status: inc.NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP,
+			text: `Concave corner with cutter radius comp: ` +
+				`line "g41.1 d2 g1 x10 y0" and line "g1 x10 y10"`},
+		{name: "concave corner cut back", lookahead: 1,
+			lines:  []string{"g41.1 d2 g1 x10 y0", "g1 x10 y10"},
+			status: inc.RS274NGC_OK,
+			want: []string{
+				"STRAIGHT_FEED(9.0000, 0.9045, 0.0000)",
+				"STRAIGHT_FEED(9.0000, 10.0000, 0.0000)",
+			}},
+		{name: "short segment", lookahead: 1,
+			lines:  []string{"g41.1 d2 g1 x10 y0", "g1 x10.1 y-0.1", "g1 x10.1 y10"},
+			status: inc.NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP,
+			text: `Concave corner with cutter radius comp: ` +
+				`line "g1 x10.1 y-0.1" and line "g1 x10.1 y10"`},
+		{name: "short segment removed", lookahead: 2,
+			lines:  []string{"g41.1 d2 g1 x10 y0", "g1 x10.1 y-0.1", "g1 x10.1 y10"},
+			status: inc.RS274NGC_OK,
+			want: []string{
+				"STRAIGHT_FEED(9.1000, 0.9146, 0.0000)",
+				"STRAIGHT_FEED(9.1000, 10.0000, 0.0000)",
+			}},
+		{name: "gouge not seen", lookahead: 0,
+			lines:  []string{"g41.1 d2 g1 x10 y0", "g1 x10 y-3", "g1 x9.5 y-3", "g1 x9.5 y5"},
+			status: inc.RS274NGC_OK},
+		{name: "gouge", lookahead: 3,
+			lines:  []string{"g41.1 d2 g1 x10 y0", "g1 x10 y-3", "g1 x9.5 y-3", "g1 x9.5 y5"},
+			status: inc.NCE_CUTTER_GOUGING_BETWEEN_LINES_WITH_COMP,
+			text: `Cutter gouging between lines with cutter radius comp: ` +
+				`line "g41.1 d2 g1 x10 y0" and line "g1 x9.5 y5"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, status, cnc := run_comp_lookahead(inc.CANON_PLANE_XY, tt.lookahead, tt.lines)
+			if status != tt.status {
+				t.Fatalf("status = %v, want %v", status, tt.status)
+			}
+			if (tt.want != nil) && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("moves = %v, want %v", got, tt.want)
+			}
+			if text := cnc.CompErrorText(); text != tt.text {
+				t.Errorf("CompErrorText() = %q, want %q", text, tt.text)
+			}
+		})
+	}
+}
+
+func TestCNC_cutter_comp_passthrough(t *testing.T) {
+	lines := []string{
+		"g42.1 d2 g1 @1 10 @2 0",
+		"g1 @1 10 @2 10",
+		"g3 @1 0 @2 10 r5",
+		"g40 g1 @1 -10 @2 20",
+		"g40", // comp is off already
+		"g41 d1 g1 @1 0",
+		"m2",
+	}
+	want := []string{
+		"SET_CUTTER_RADIUS_COMPENSATION(1.0000)",
+		"START_CUTTER_RADIUS_COMPENSATION(%v)",
+		"STRAIGHT_FEED(10.0000, 0.0000, 0.0000)",
+		"STRAIGHT_FEED(10.0000, 10.0000, 0.0000)",
+		"ARC_FEED(0.0000, 10.0000, 5.0000, 10.0000, 1, 0.0000)",
+		"STOP_CUTTER_RADIUS_COMPENSATION()",
+		"STRAIGHT_FEED(-10.0000, 20.0000, 0.0000)",
+		"SET_CUTTER_RADIUS_COMPENSATION(%.4f)",
+		"START_CUTTER_RADIUS_COMPENSATION(%v)",
+		"STRAIGHT_FEED(0.0000, 20.0000, 0.0000)",
+		"STOP_CUTTER_RADIUS_COMPENSATION()",
+	}
+
+	for _, plane := range []inc.CANON_PLANE{inc.CANON_PLANE_XY, inc.CANON_PLANE_XZ, inc.CANON_PLANE_YZ} {
+		got, status, _ := run_comp_with(plane, func(cnc *rs274ngc_t) {
+			if err := cnc.SetCompPassthrough(true); err != nil {
+				t.Fatal(err)
+			}
+			cnc._setup.tool_table[1].Diameter = 0.5
+		}, lines)
+		if status != inc.RS274NGC_EXIT {
+			t.Fatalf("plane %v: status = %v, want %v", plane, status, inc.RS274NGC_EXIT)
+		}
+		expect := append([]string(nil), want...)
+		expect[1] = fmt.Sprintf(expect[1], inc.CANON_SIDE_RIGHT)
+		expect[7] = fmt.Sprintf(expect[7], 0.25)
+		expect[8] = fmt.Sprintf(expect[8], inc.CANON_SIDE_LEFT)
+		if !reflect.DeepEqual(got[:len(expect)], expect) {
+			t.Errorf("plane %v: moves = %v, want %v", plane, got, expect)
+		}
+	}
+}
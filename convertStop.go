@@ -162,11 +162,19 @@ func (cnc *rs274ngc_t) convert_stop() inc.STATUS {
 		}
 
 		/*6*/
+		cnc.comp_flush()
+		if (cnc._setup.comp_passthrough == ON) &&
+			(cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF) {
+			cnc.canon_comp.STOP_CUTTER_RADIUS_COMPENSATION()
+		}
 		cnc._setup.cutter_comp_side = inc.CANON_SIDE_OFF
-		cnc._setup.program_x = inc.UNKNOWN
+		cnc._setup.cutter_comp_dynamic = OFF
+		cnc._setup.program_1 = inc.UNKNOWN
 
 		/*7*/
-		cnc.canon.STOP_SPINDLE_TURNING()
+		if !cnc.superfluous(cnc._setup.spindle_turning == inc.CANON_STOPPED) {
+			cnc.canon.STOP_SPINDLE_TURNING()
+		}
 		cnc._setup.spindle_turning = inc.CANON_STOPPED
 
 		/*8*/
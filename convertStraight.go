@@ -33,8 +33,11 @@ import (
    (if move is G_1) or a STRAIGHT_TRAVERSE command (if move is G_0).
    It also updates the setting of the position of the tool point to the
    end point of the move. If cutter radius compensation is on, it may
-   also generate an arc before the straight move. Also, in INVERSE_TIME
+   also generate an arc before the straight move, unless compensation is
+   passed through to the machine, when the programmed move is made. Also, in INVERSE_TIME
    feed mode, SET_FEED_RATE will be called the feed rate setting changed.
+   If superfluous commands are suppressed, no move is made to the point
+   the tool is at.
 
    Called by: convert_motion.
 
@@ -76,33 +79,43 @@ func (cnc *rs274ngc_t) convert_straight( /* ARGUMENTS
 	cnc.find_ends(&end_x, &end_y, &end_z, &AA_end, &BB_end, &CC_end)
 	/* NOT "== ON" */
 	if (cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF) &&
-		(cnc._setup.cutter_comp_radius > 0.0) { /* radius always is >= 0 */
-
-		if cnc._setup.block1.g_modes[0] == inc.G_53 {
-			return inc.NCE_CANNOT_USE_G53_WITH_CUTTER_RADIUS_COMP
-		}
+		(cnc._setup.block1.g_modes[0] == inc.G_53) {
+		return inc.NCE_CANNOT_USE_G53_WITH_CUTTER_RADIUS_COMP
+	}
+	if (cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF) &&
+		(cnc._setup.cutter_comp_radius > 0.0) && /* radius always is >= 0 */
+		(cnc._setup.comp_passthrough == OFF) {
 
-		if cnc._setup.program_x == inc.UNKNOWN {
+		cnc._setup.comp_block++
+		end1, end2, end3 := cnc.plane_point(end_x, end_y, end_z)
+		if cnc._setup.program_1 == inc.UNKNOWN {
 			status =
-				cnc.convert_straight_comp1(move, end_x, end_y,
-					end_z, AA_end, BB_end, CC_end)
+				cnc.convert_straight_comp1(move, end1, end2,
+					end3, AA_end, BB_end, CC_end)
 			if status != inc.RS274NGC_OK {
 				return status
 			}
 			//CHP(status)
 		} else {
 			status =
-				cnc.convert_straight_comp2(move, end_x, end_y,
-					end_z, AA_end, BB_end, CC_end)
+				cnc.convert_straight_comp2(move, end1, end2,
+					end3, AA_end, BB_end, CC_end)
 			//CHP(status)
 			if status != inc.RS274NGC_OK {
 				return status
 			}
 		}
+		cnc._setup.comp_line = cnc._setup.linetext
+	} else if cnc.straight_superfluous(end_x, end_y, end_z, AA_end, BB_end, CC_end) { /* a move to where the tool is */
+		cnc._setup.current.A = AA_end /*AA*/
+		cnc._setup.current.B = BB_end /*BB*/
+		cnc._setup.current.C = CC_end /*CC*/
+		return inc.RS274NGC_OK
 	} else if move == inc.G_0 {
 		cnc.canon.STRAIGHT_TRAVERSE(end_x, end_y, end_z, AA_end, BB_end, CC_end)
 		cnc._setup.current.X = end_x
 		cnc._setup.current.Y = end_y
+		cnc._setup.current.Z = end_z
 	} else if move == inc.G_1 {
 		if cnc._setup.feed_mode == inc.INVERSE_TIME {
 			cnc.inverse_time_rate_straight(end_x, end_y, end_z, AA_end, BB_end, CC_end)
@@ -110,11 +123,11 @@ func (cnc *rs274ngc_t) convert_straight( /* ARGUMENTS
 		cnc.canon.STRAIGHT_FEED(end_x, end_y, end_z, AA_end, BB_end, CC_end)
 		cnc._setup.current.X = end_x
 		cnc._setup.current.Y = end_y
+		cnc._setup.current.Z = end_z
 	} else {
 		return inc.NCE_BUG_CODE_NOT_G0_OR_G1
 	}
 
-	cnc._setup.current.Z = end_z
 	cnc._setup.current.A = AA_end /*AA*/
 	cnc._setup.current.B = BB_end /*BB*/
 	cnc._setup.current.C = CC_end /*CC*/
@@ -123,6 +136,43 @@ func (cnc *rs274ngc_t) convert_straight( /* ARGUMENTS
 
 /****************************************************************************/
 
+/* straight_superfluous
+
+   Returned Value: bool
+   true if the straight move of the block is to be left out: superfluous
+   commands are suppressed (see superfluous), the interpreter is not
+   doing cutter radius compensation, and the move goes to the point the
+   tool is at.
+
+   Side effects: none
+
+   Called by:
+   convert_motion
+   convert_straight
+
+   convert_motion asks before the move, with the end point from
+   block_ends, so the A-axis is not unclamped for a move which is left
+   out; convert_straight asks with the end point it found.
+
+*/
+
+func (cnc *rs274ngc_t) straight_superfluous( /* ARGUMENTS           */
+	end_x, end_y, end_z, /* end point of the move              */
+	AA_end, BB_end, CC_end float64) bool { /* rotary end points      */
+
+	if (cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF) &&
+		(cnc._setup.cutter_comp_radius > 0.0) &&
+		(cnc._setup.comp_passthrough == OFF) {
+		return false /* comp moves are always made */
+	}
+	return cnc.superfluous((end_x == cnc._setup.current.X) &&
+		(end_y == cnc._setup.current.Y) && (end_z == cnc._setup.current.Z) &&
+		(AA_end == cnc._setup.current.A) && (BB_end == cnc._setup.current.B) &&
+		(CC_end == cnc._setup.current.C))
+}
+
+/****************************************************************************/
+
 /* convert_straight_comp1
 
    Returned Value: int
@@ -144,7 +194,7 @@ func (cnc *rs274ngc_t) convert_straight( /* ARGUMENTS
 
    Called by: convert_straight.
 
-   This is called if cutter radius compensation is on and cnc._setup.program_x
+   This is called if cutter radius compensation is on and cnc._setup.program_1
    is UNKNOWN, indicating that this is the first move after cutter radius
    compensation is turned on.
 
@@ -155,25 +205,29 @@ func (cnc *rs274ngc_t) convert_straight( /* ARGUMENTS
    center of a circle of the same radius tangent to the tangent line at
    the destination point.
 
+   The end point is given in the coordinates of the selected plane (see
+   plane_current), so this works the same way in all three planes.
+
 */
 
 func (cnc *rs274ngc_t) convert_straight_comp1( /* ARGUMENTS                       */
 	move inc.GCodes, /* either G_0 or G_1                         */
-	px, /* X coordinate of end point                 */
-	py, /* Y coordinate of end point                 */
-	end_z, /* Z coordinate of end point                 */
+	p1, /* first plane coordinate of end point       */
+	p2, /* second plane coordinate of end point      */
+	end3, /* third coordinate of end point             */
 	AA_end, /* A coordinate of end point           */ /*AA*/
 	BB_end, /* B coordinate of end point           */ /*BB*/
 	CC_end float64) inc.STATUS { /* C coordinate of end point           */ /*CC*/
 
 	//static char name[] = "convert_straight_comp1";
 	side := cnc._setup.cutter_comp_side
-	cx := cnc._setup.current.X /* first current point x then end point x */
-	cy := cnc._setup.current.Y /* first current point y then end point y */
+	current1, current2, current3 := cnc.plane_current()
+	c1 := *current1 /* first current point 1 then end point 1 */
+	c2 := *current2 /* first current point 2 then end point 2 */
 
 	/* always will be positive */
 	radius := cnc._setup.cutter_comp_radius
-	distance := math.Hypot((px - cx), (py - cy))
+	distance := math.Hypot((p1 - c1), (p2 - c2))
 
 	if (side != inc.CANON_SIDE_LEFT) && (side != inc.CANON_SIDE_RIGHT) {
 		return inc.NCE_BUG_SIDE_NOT_RIGHT_OR_LEFT
@@ -183,24 +237,24 @@ func (cnc *rs274ngc_t) convert_straight_comp1( /* ARGUMENTS
 	}
 
 	theta := math.Acos(radius / distance)
-	alpha := inc.If(side == inc.CANON_SIDE_LEFT, (math.Atan2((cy-py), (cx-px)) - theta), (math.Atan2((cy-py), (cx-px)) + theta)).(float64)
-	cx = (px + (radius * math.Cos(alpha))) /* reset to end location */
-	cy = (py + (radius * math.Sin(alpha)))
-	if move == inc.G_0 {
-		cnc.canon.STRAIGHT_TRAVERSE(cx, cy, end_z, AA_end, BB_end, CC_end)
-	} else if move == inc.G_1 {
+	alpha := inc.If(side == inc.CANON_SIDE_LEFT, (math.Atan2((c2-p2), (c1-p1)) - theta), (math.Atan2((c2-p2), (c1-p1)) + theta)).(float64)
+	c1 = (p1 + (radius * math.Cos(alpha))) /* reset to end location */
+	c2 = (p2 + (radius * math.Sin(alpha)))
+	end_x, end_y, end_z := cnc.plane_xyz(c1, c2, end3)
+	if move == inc.G_1 {
 		if cnc._setup.feed_mode == inc.INVERSE_TIME {
-			cnc.inverse_time_rate_straight(cx, cy, end_z, AA_end, BB_end, CC_end)
+			cnc.inverse_time_rate_straight(end_x, end_y, end_z, AA_end, BB_end, CC_end)
 		}
-		cnc.canon.STRAIGHT_FEED(cx, cy, end_z, AA_end, BB_end, CC_end)
-	} else {
+	} else if move != inc.G_0 {
 		return inc.NCE_BUG_CODE_NOT_G0_OR_G1
 	}
+	cnc.comp_hold(cnc.comp_straight(move, *current1, *current2, c1, c2, end3, AA_end, BB_end, CC_end))
 
-	cnc._setup.current.X = cx
-	cnc._setup.current.Y = cy
-	cnc._setup.program_x = px
-	cnc._setup.program_y = py
+	*current1 = c1
+	*current2 = c2
+	*current3 = end3
+	cnc._setup.program_1 = p1
+	cnc._setup.program_2 = p2
 	return inc.RS274NGC_OK
 }
 
@@ -213,12 +267,12 @@ func (cnc *rs274ngc_t) convert_straight_comp1( /* ARGUMENTS
    Otherwise, it returns RS274NGC_OK.
    1. The compensation side is not RIGHT or LEFT:
    NCE_BUG_SIDE_NOT_RIGHT_OR_LEFT
-   2. A concave corner is found:
-   NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP
+   2. If comp_corner or comp_gouge returns an error code, this returns
+   that code.
 
    Side effects:
    This executes a STRAIGHT_FEED command at cutting feed rate
-   or a STRAIGHT_TRAVERSE command.
+   or a STRAIGHT_TRAVERSE command, or holds it for lookahead.
    It also generates an ARC_FEED to go around a corner, if necessary.
    It also updates the setting of the position of the tool point to
    the end point of the move and updates the programmed point.
@@ -228,7 +282,7 @@ func (cnc *rs274ngc_t) convert_straight_comp1( /* ARGUMENTS
    Called by: convert_straight.
 
    This is called if cutter radius compensation is on and
-   cnc._setup.program_x is not UNKNOWN, indicating that this is not the
+   cnc._setup.program_1 is not UNKNOWN, indicating that this is not the
    first move after cutter radius compensation is turned on.
 
    The algorithm used here is:
@@ -247,7 +301,9 @@ func (cnc *rs274ngc_t) convert_straight_comp1( /* ARGUMENTS
 
    This uses an angle tolerance of TOLERANCE_CONCAVE_CORNER (0.01 radian)
    to determine if:
-   1) an illegal concave corner exists (tool will not fit into corner),
+   1) a concave corner exists. Without lookahead this is illegal (the
+   tool will not fit into the corner). With lookahead, comp_corner cuts
+   the held path back to where it meets the new one,
    2) no arc is required to go around the corner (i.e. the current line
    is in the same direction as the end of the previous move), or
    3) an arc is required to go around a convex corner and start off in
@@ -258,16 +314,20 @@ func (cnc *rs274ngc_t) convert_straight_comp1( /* ARGUMENTS
    arc.  An alternative might be to distribute the rotary axis motion
    over the arc and the straight move in proportion to their lengths.
 
-   If the Z-axis is moved in this block and an extra arc is required to
-   go around a sharp corner, all the Z-axis motion occurs on the straight
-   line and none on the extra arc.  An alternative might be to distribute
-   the Z-axis motion over the extra arc and the straight line in
-   proportion to their lengths.
+   If the axis perpendicular to the plane (Z in the XY-plane) is moved in
+   this block and an extra arc is required to go around a sharp corner,
+   all the motion along that axis occurs on the straight line and none
+   on the extra arc.  An alternative might be to distribute the motion
+   over the extra arc and the straight line in proportion to their
+   lengths.
 
    This handles inverse time feed rates by computing the length of the
    compensated path.
 
-   This handles the case of there being no XY motion.
+   This handles the case of there being no motion in the selected plane.
+
+   The end point is given in the coordinates of the selected plane (see
+   plane_current), so this works the same way in all three planes.
 
    This handles G0 moves. Where an arc is inserted to round a corner in a
    G1 move, no arc is inserted for a G0 move; a STRAIGHT_TRAVERSE is made
@@ -278,9 +338,9 @@ func (cnc *rs274ngc_t) convert_straight_comp1( /* ARGUMENTS
 
 func (cnc *rs274ngc_t) convert_straight_comp2( /* ARGUMENTS                       */
 	move inc.GCodes, /* either G_0 or G_1                         */
-	px, /* X coordinate of programmed end point      */
-	py, /* Y coordinate of programmed end point      */
-	end_z, /* Z coordinate of end point                 */
+	p1, /* first plane coordinate of programmed end  */
+	p2, /* second plane coordinate of programmed end */
+	end3, /* third coordinate of end point             */
 	AA_end, /* A coordinate of end point           */ /*AA*/
 	BB_end, /* B coordinate of end point           */ /*BB*/
 	CC_end float64) inc.STATUS { /* C coordinate of end point           */ /*CC*/
@@ -291,36 +351,33 @@ func (cnc *rs274ngc_t) convert_straight_comp2( /* ARGUMENTS
 		theta,
 		alpha,
 		beta,
-		end_x, /* x-coordinate of actual end point */
-		end_y, /* y-coordinate of actual end point */
+		end1, /* first coordinate of actual end point */
+		end2, /* second coordinate of actual end point */
 		gamma,
-		mid_x, /* x-coordinate of end of added arc, if needed */
-		mid_y float64 /* y-coordinate of end of added arc, if needed */
+		mid1, /* first coordinate of end of added arc, if needed */
+		mid2 float64 /* second coordinate of end of added arc, if needed */
 	)
 
-	start_x := cnc._setup.program_x /* programmed beginning point */
-	start_y := cnc._setup.program_y
-	if (py == start_y) && (px == start_x) { /* no XY motion */
-		end_x = cnc._setup.current.X
-		end_y = cnc._setup.current.Y
-		if move == inc.G_0 {
-			cnc.canon.STRAIGHT_TRAVERSE(end_x, end_y, end_z, AA_end, BB_end, CC_end)
-
-		} else if move == inc.G_1 {
-			if cnc._setup.feed_mode == inc.INVERSE_TIME {
-				cnc.inverse_time_rate_straight(end_x, end_y, end_z, AA_end, BB_end, CC_end)
-			}
-			cnc.canon.STRAIGHT_FEED(end_x, end_y, end_z, AA_end, BB_end, CC_end)
-		} else {
-			return inc.NCE_BUG_CODE_NOT_G0_OR_G1
+	current1, current2, current3 := cnc.plane_current()
+	start1 := cnc._setup.program_1 /* programmed beginning point */
+	start2 := cnc._setup.program_2
+	if (move != inc.G_0) && (move != inc.G_1) {
+		return inc.NCE_BUG_CODE_NOT_G0_OR_G1
+	}
+	if (p2 == start2) && (p1 == start1) { /* no motion in the plane */
+		end1 = *current1
+		end2 = *current2
+		if (move == inc.G_1) && (cnc._setup.feed_mode == inc.INVERSE_TIME) {
+			end_x, end_y, end_z := cnc.plane_xyz(end1, end2, end3)
+			cnc.inverse_time_rate_straight(end_x, end_y, end_z, AA_end, BB_end, CC_end)
 		}
+		cnc.comp_hold(cnc.comp_straight(move, end1, end2, end1, end2, end3, AA_end, BB_end, CC_end))
 	} else {
 		side := cnc._setup.cutter_comp_side
 		/* will always be positive */
 		radius := cnc._setup.cutter_comp_radius
-		theta = math.Atan2(cnc._setup.current.Y-start_y,
-			cnc._setup.current.X-start_x)
-		alpha = math.Atan2(py-start_y, px-start_x)
+		theta = math.Atan2(*current2-start2, *current1-start1)
+		alpha = math.Atan2(p2-start2, p1-start1)
 
 		if side == inc.CANON_SIDE_LEFT {
 			if theta < alpha {
@@ -338,52 +395,61 @@ func (cnc *rs274ngc_t) convert_straight_comp2( /* ARGUMENTS
 			return inc.NCE_BUG_SIDE_NOT_RIGHT_OR_LEFT
 		}
 
-		end_x = (px + (radius * math.Cos(alpha+gamma)))
-		end_y = (py + (radius * math.Sin(alpha+gamma)))
-		mid_x = (start_x + (radius * math.Cos(alpha+gamma)))
-		mid_y = (start_y + (radius * math.Sin(alpha+gamma)))
+		end1 = (p1 + (radius * math.Cos(alpha+gamma)))
+		end2 = (p2 + (radius * math.Sin(alpha+gamma)))
+		mid1 = (start1 + (radius * math.Cos(alpha+gamma)))
+		mid2 = (start2 + (radius * math.Sin(alpha+gamma)))
 
+		/* the tool path starts at the current point unless cut back */
+		next := comp_path_t{start1: *current1, start2: *current2, end1: end1, end2: end2}
 		if (beta < -small) || (beta > (inc.PI + small)) {
-			return inc.NCE_CONCAVE_CORNER_WITH_CUTTER_RADIUS_COMP
+			next.start1, next.start2 = mid1, mid2
+			if status := cnc.comp_corner(&next); status != inc.RS274NGC_OK {
+				return status
+			}
+			beta = 0 /* corner is now made by the cut back path */
 		}
 
-		if move == inc.G_0 {
-			cnc.canon.STRAIGHT_TRAVERSE(end_x, end_y, end_z, AA_end, BB_end, CC_end)
-		} else if move == inc.G_1 {
-			if beta > small { /* ARC NEEDED */
-				if cnc._setup.feed_mode == inc.INVERSE_TIME {
-					if side == inc.CANON_SIDE_LEFT {
-						cnc.inverse_time_rate_as(start_x, start_y,
-							-1, mid_x, mid_y, end_x, end_y, end_z, AA_end, BB_end, CC_end)
-					} else {
-						cnc.inverse_time_rate_as(start_x, start_y,
-							1, mid_x, mid_y, end_x, end_y, end_z, AA_end, BB_end, CC_end)
-					}
-				}
+		line := cnc.comp_straight(move, next.start1, next.start2, end1, end2, end3, AA_end, BB_end, CC_end)
+		line.contour = (move == inc.G_1)
+		line.program_start1, line.program_start2 = start1, start2
+		line.program_end1, line.program_end2 = p1, p2
+
+		end_x, end_y, end_z := cnc.plane_xyz(end1, end2, end3)
+		if (move == inc.G_1) && (beta > small) { /* ARC NEEDED */
+			if cnc._setup.feed_mode == inc.INVERSE_TIME {
 				if side == inc.CANON_SIDE_LEFT {
-					cnc.canon.ARC_FEED(mid_x, mid_y, start_x, start_y, -1,
-						cnc._setup.current.Z, AA_end, BB_end, CC_end)
+					cnc.inverse_time_rate_as(start1, start2,
+						-1, mid1, mid2, end1, end2, end3, AA_end, BB_end, CC_end)
 				} else {
-					cnc.canon.ARC_FEED(mid_x, mid_y, start_x, start_y, 1,
-						cnc._setup.current.Z, AA_end, BB_end, CC_end)
-				}
-
-				cnc.canon.STRAIGHT_FEED(end_x, end_y, end_z, AA_end, BB_end, CC_end)
-			} else {
-				if cnc._setup.feed_mode == inc.INVERSE_TIME {
-					cnc.inverse_time_rate_straight(end_x, end_y, end_z, AA_end, BB_end, CC_end)
+					cnc.inverse_time_rate_as(start1, start2,
+						1, mid1, mid2, end1, end2, end3, AA_end, BB_end, CC_end)
 				}
-				cnc.canon.STRAIGHT_FEED(end_x, end_y, end_z, AA_end, BB_end, CC_end)
 			}
+			corner := cnc.comp_arc(*current1, *current2, mid1, mid2, start1, start2,
+				inc.If(side == inc.CANON_SIDE_LEFT, -1, 1).(int), *current3, AA_end, BB_end, CC_end)
+			line.start1, line.start2 = mid1, mid2
+			if status := cnc.comp_gouge(corner, line); status != inc.RS274NGC_OK {
+				return status
+			}
+			cnc.comp_hold(corner)
+			cnc.comp_hold(line)
 		} else {
-			return inc.NCE_BUG_CODE_NOT_G0_OR_G1
+			if (move == inc.G_1) && (cnc._setup.feed_mode == inc.INVERSE_TIME) {
+				cnc.inverse_time_rate_straight(end_x, end_y, end_z, AA_end, BB_end, CC_end)
+			}
+			if status := cnc.comp_gouge(line); status != inc.RS274NGC_OK {
+				return status
+			}
+			cnc.comp_hold(line)
 		}
 	}
 
-	cnc._setup.current.X = end_x
-	cnc._setup.current.Y = end_y
-	cnc._setup.program_x = px
-	cnc._setup.program_y = py
+	*current1 = end1
+	*current2 = end2
+	*current3 = end3
+	cnc._setup.program_1 = p1
+	cnc._setup.program_2 = p2
 	return inc.RS274NGC_OK
 }
 
@@ -420,7 +486,9 @@ func (cnc *rs274ngc_t) inverse_time_rate_straight( /* ARGUMENTS
 		cnc._setup.current.B, cnc._setup.current.C)
 
 	rate := math.Max(0.1, (length * cnc._setup.block1.f_number))
-	cnc.canon.SET_FEED_RATE(rate)
+	if !cnc.superfluous(cnc._setup.feed_rate == rate) {
+		cnc.canon.SET_FEED_RATE(rate)
+	}
 	cnc._setup.feed_rate = rate
 
 	return inc.RS274NGC_OK
@@ -444,32 +512,37 @@ func (cnc *rs274ngc_t) inverse_time_rate_straight( /* ARGUMENTS
    All rotary motion is assumed to occur on the arc, as done by
    convert_straight_comp2.
 
-   All z motion is assumed to occur on the line, as done by
-   convert_straight_comp2.
+   All motion perpendicular to the plane is assumed to occur on the line,
+   as done by convert_straight_comp2.
+
+   All points are given in the coordinates of the selected plane.
 
 */
 
 func (cnc *rs274ngc_t) inverse_time_rate_as( /* ARGUMENTS */
-	start_x, /* x coord of last program point, extra arc center x */
-	start_y float64, /* y coord of last program point, extra arc center y */
+	start1, /* coord 1 of last program point, extra arc center 1 */
+	start2 float64, /* coord 2 of last program point, extra arc center 2 */
 	turn int, /* turn of extra arc                                 */
-	mid_x, /* x coord of end point of extra arc                 */
-	mid_y, /* y coord of end point of extra arc                 */
-	end_x, /* x coord of end point of straight line             */
-	end_y, /* y coord of end point of straight line             */
-	end_z float64, /* z coord of end point of straight line             */
+	mid1, /* coord 1 of end point of extra arc                 */
+	mid2, /* coord 2 of end point of extra arc                 */
+	end1, /* coord 1 of end point of straight line             */
+	end2, /* coord 2 of end point of straight line             */
+	end3 float64, /* coord 3 of end point of straight line             */
 	AA_end, /* A coord of end point of straight line       */ /*AA*/
 	BB_end, /* B coord of end point of straight line       */ /*BB*/
 	CC_end float64) inc.STATUS { /* C coord of end point of straight line       */ /*CC*/
 
-	length := (arc.Find_arc_length(cnc._setup.current.X, cnc._setup.current.Y,
-		cnc._setup.current.Z, start_x, start_y,
-		turn, mid_x, mid_y, cnc._setup.current.Z) +
-		arc.Find_straight_length(end_x, end_y,
-			end_z, AA_end, BB_end, CC_end, mid_x, mid_y,
-			cnc._setup.current.Z, AA_end, BB_end, CC_end))
+	current1, current2, current3 := cnc.plane_current()
+	length := (arc.Find_arc_length(*current1, *current2,
+		*current3, start1, start2,
+		turn, mid1, mid2, *current3) +
+		arc.Find_straight_length(end1, end2,
+			end3, AA_end, BB_end, CC_end, mid1, mid2,
+			*current3, AA_end, BB_end, CC_end))
 	rate := math.Max(0.1, (length * cnc._setup.block1.f_number))
-	cnc.canon.SET_FEED_RATE(rate)
+	if !cnc.superfluous(cnc._setup.feed_rate == rate) {
+		cnc.canon.SET_FEED_RATE(rate)
+	}
 	cnc._setup.feed_rate = rate
 
 	return inc.RS274NGC_OK
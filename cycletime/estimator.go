@@ -0,0 +1,301 @@
+package cycletime
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/planner"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* estimator.go
+
+   Estimator_t is a Canon_i which estimates how long a program takes to
+   run, for quoting. It adds up the time of each move, dwell, tool
+   change and spindle start, by tool and by operation. An operation is
+   the part of the program from one comment to the next, named by the
+   comment; comments made by the interpreter itself ("interpreter: ...")
+   do not start one.
+
+   The moves are timed by a planner.Planner_t, as a machine with the
+   limits in Options_t would run them: the speed along each move follows
+   the planner's profile, the machine slows for corners by junction
+   deviation (G64 P, or Options.Junction_deviation), and it stops where
+   the planner stops it. The time of each segment the planner sends out
+   is charged to the tool and the operation of its first move. With no
+   traverse rate and no greatest velocities, traverses take no time.
+
+   Times are in seconds. The feed and traverse rates, and the positions,
+   are in the length units in force (see inc.Canon_world_t); the planner
+   converts them to millimeters to be compared with the limits.
+
+*/
+
+type Options_t struct {
+	planner.Options_t         // limits on the motion, see planner.Options_t
+	Tool_change       float64 // seconds to change a tool
+	Spindle_start     float64 // seconds for the spindle to start or change speed
+}
+
+// Time_t is time spent, in seconds, by what it was spent on.
+type Time_t struct {
+	Feed        float64 // STRAIGHT_FEED, ARC_FEED and STRAIGHT_PROBE
+	Traverse    float64 // STRAIGHT_TRAVERSE
+	Dwell       float64 // DWELL
+	Tool_change float64 // CHANGE_TOOL
+	Spindle     float64 // spindle starts and speed changes
+}
+
+// Operation_t is the part of a program from a comment to the next.
+type Operation_t struct {
+	Name string // the comment, "" before the first
+	Line int    // sequence number of the line of the comment, 0 if no Source
+	Time Time_t
+
+	move int // number of the first move made in it (see planner.Planner_t.Moves)
+}
+
+// Report_t is the time a program takes.
+type Report_t struct {
+	Total      Time_t
+	Tools      map[int]*Time_t // by pocket of the tool in the spindle
+	Operations []*Operation_t  // in the order run
+}
+
+type Estimator_t struct {
+	planner.Planner_t
+
+	tool_change   float64 // from Options_t
+	spindle_start float64
+	report        Report_t
+}
+
+var _ inc.Canon_i = &Estimator_t{}
+var _ inc.Blend_i = &Estimator_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: an Estimator_t with options, no time spent, no
+   Source, and the world model of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New(options Options_t) *Estimator_t {
+	e := &Estimator_t{tool_change: options.Tool_change, spindle_start: options.Spindle_start}
+	e.Planner_t.Options = options.Options_t
+	e.Output = e.time
+	return e
+}
+
+/* Report
+
+   Returned Value: the time spent so far
+
+   Side effects: the moves held are timed as if the machine stopped after
+   them (see planner.Planner_t.Flush).
+
+   Called by: external programs
+
+*/
+
+func (e *Estimator_t) Report() *Report_t {
+	e.Flush()
+	report := e.report
+	return &report
+}
+
+/* Total
+
+   Returned Value: the sum of the times in t, in seconds
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (t *Time_t) Total() float64 {
+	return t.Feed + t.Traverse + t.Dwell + t.Tool_change + t.Spindle
+}
+
+/***********************************************************************/
+
+/* charge
+
+   Returned Value: none
+
+   Side effects: add is called with the times of all, of the tool in
+   slot, and of operation, which is the current one if nil.
+
+   Called by: time, DWELL, CHANGE_TOOL, spin
+
+*/
+
+func (e *Estimator_t) charge(slot int, operation *Operation_t, add func(t *Time_t)) {
+	if e.report.Tools == nil {
+		e.report.Tools = map[int]*Time_t{}
+	}
+	if _, ok := e.report.Tools[slot]; !ok {
+		e.report.Tools[slot] = &Time_t{}
+	}
+	if operation == nil {
+		operation = e.operation()
+	}
+	add(&e.report.Total)
+	add(e.report.Tools[slot])
+	add(&operation.Time)
+}
+
+// operation returns the current operation, starting the first if need
+// be.
+func (e *Estimator_t) operation() *Operation_t {
+	if len(e.report.Operations) == 0 {
+		e.report.Operations = append(e.report.Operations, &Operation_t{})
+	}
+	return e.report.Operations[len(e.report.Operations)-1]
+}
+
+/* time
+
+   Returned Value: none
+
+   Side effects: the duration of segment is added to the feed or
+   traverse time of its tool and of the operation its first move was
+   made in.
+
+   Called by: the planner, as its Output
+
+   The planner holds moves while comments go by, so the operation is
+   found from the number of the move, not taken to be the current one.
+
+*/
+
+func (e *Estimator_t) time(segment *planner.Segment_t) {
+	e.operation()
+	n := len(e.report.Operations) - 1
+	for (n > 0) && (e.report.Operations[n].move > segment.Move) {
+		n--
+	}
+	e.charge(segment.Tool, e.report.Operations[n], func(t *Time_t) {
+		if segment.Kind == toolpath.MOVE_TRAVERSE {
+			t.Traverse = t.Traverse + segment.Duration
+		} else {
+			t.Feed = t.Feed + segment.Duration
+		}
+	})
+}
+
+/***********************************************************************/
+
+func (e *Estimator_t) DWELL(seconds float64) {
+	e.Planner_t.DWELL(seconds)
+	e.charge(e.Tool_slot, nil, func(t *Time_t) { t.Dwell = t.Dwell + seconds })
+}
+
+func (e *Estimator_t) CHANGE_TOOL(slot int) {
+	e.Planner_t.CHANGE_TOOL(slot)
+	e.Spindle = inc.CANON_STOPPED // as the interpreter takes it to be
+	e.charge(slot, nil, func(t *Time_t) { t.Tool_change = t.Tool_change + e.tool_change })
+}
+
+/* spin
+
+   Returned Value: none
+
+   Side effects: the machine stops, and, if the spindle is to turn and
+   was not turning that way at that speed, the time to start the
+   spindle is added.
+
+   Called by: SET_SPINDLE_SPEED, START_SPINDLE_CLOCKWISE,
+   START_SPINDLE_COUNTERCLOCKWISE
+
+*/
+
+func (e *Estimator_t) spin(direction inc.CANON_DIRECTION, speed float64) {
+	if (direction == e.GET_EXTERNAL_SPINDLE()) && (speed == e.Speed) {
+		return
+	}
+	e.Flush()
+	if (direction != inc.CANON_STOPPED) && (speed != 0.0) {
+		e.charge(e.Tool_slot, nil, func(t *Time_t) { t.Spindle = t.Spindle + e.spindle_start })
+	}
+}
+
+func (e *Estimator_t) SET_SPINDLE_SPEED(r float64) {
+	if e.GET_EXTERNAL_SPINDLE() != inc.CANON_STOPPED {
+		e.spin(e.GET_EXTERNAL_SPINDLE(), r)
+	}
+	e.Planner_t.SET_SPINDLE_SPEED(r)
+}
+
+func (e *Estimator_t) START_SPINDLE_CLOCKWISE() {
+	e.spin(inc.CANON_CLOCKWISE, e.Speed)
+	e.Planner_t.START_SPINDLE_CLOCKWISE()
+}
+
+func (e *Estimator_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	e.spin(inc.CANON_COUNTERCLOCKWISE, e.Speed)
+	e.Planner_t.START_SPINDLE_COUNTERCLOCKWISE()
+}
+
+func (e *Estimator_t) COMMENT(s string) {
+	if strings.HasPrefix(s, "interpreter:") {
+		return
+	}
+	operation := &Operation_t{Name: strings.TrimSpace(s), move: e.Moves}
+	if e.Source != nil {
+		operation.Line = e.Source.SequenceNumber()
+	}
+	e.operation()
+	e.report.Operations = append(e.report.Operations, operation)
+}
+
+/***********************************************************************/
+
+/* Write
+
+   Returned Value: error (the first error writing to out, or nil)
+
+   Side effects: the report is written to out as a table of times in
+   seconds, a line for the whole program, one for each tool in order, and
+   one for each operation which took any time.
+
+   Called by: external programs
+
+*/
+
+func (r *Report_t) Write(out io.Writer) error {
+	var err error
+	line := func(t *Time_t, name string) {
+		if err == nil {
+			_, err = fmt.Fprintf(out, "%10.2f %10.2f %10.2f %10.2f %10.2f %10.2f  %s\n",
+				t.Total(), t.Feed, t.Traverse, t.Dwell, t.Tool_change, t.Spindle, name)
+		}
+	}
+	_, err = fmt.Fprintf(out, "%10s %10s %10s %10s %10s %10s\n",
+		"total", "feed", "traverse", "dwell", "tools", "spindle")
+	line(&r.Total, "all")
+	var slots []int
+	for slot := range r.Tools {
+		slots = append(slots, slot)
+	}
+	sort.Ints(slots)
+	for _, slot := range slots {
+		line(r.Tools[slot], fmt.Sprintf("T%d", slot))
+	}
+	for _, operation := range r.Operations {
+		if operation.Time.Total() > 0.0 {
+			line(&operation.Time, fmt.Sprintf("line %d: %s", operation.Line, operation.Name))
+		}
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package cycletime_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/cycletime"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/planner"
+)
+
+// estimate runs program on an Estimator_t with options and returns its
+// report.
+func estimate(t *testing.T, options cycletime.Options_t, traverse float64, program ...string) *cycletime.Report_t {
+	estimator := cycletime.New(options)
+	estimator.Tool_max = 4
+	estimator.Traverse_rate = traverse
+	cnc := cnctest.Start(t, estimator, &estimator.Canon_world_t)
+	estimator.Source = cnc
+	cnctest.Run(t, cnc, program...)
+	return estimator.Report()
+}
+
+// near reports whether two times are the same to within a microsecond.
+func near(one, two float64) bool {
+	return math.Abs(one-two) < 1e-6
+}
+
+func TestEstimate_moves(t *testing.T) {
+	accel := cycletime.Options_t{Options_t: planner.Options_t{Max_acceleration: [6]float64{100, 100, 100}}}
+	xy := cycletime.Options_t{Options_t: planner.Options_t{Max_velocity: [6]float64{600, 600}}}
+	cases := []struct {
+		name     string
+		options  cycletime.Options_t
+		traverse float64
+		program  []string
+		feed     float64
+		rapid    float64
+	}{
+		// 10 mm/s; speeding up and slowing take 0.1 s and 0.5 mm each.
+		{"no limits", cycletime.Options_t{}, 0, []string{"g21 g1 x100 f600"}, 10, 0},
+		{"acceleration", accel, 0, []string{"g21 g1 x100 f600"}, 10.1, 0},
+		// 25.4 mm/s; speeding up and slowing take 0.254 s and 3.2258 mm each.
+		{"inches", accel, 0, []string{"g20 g1 x1 f60"}, 0.508 + ((25.4 - 6.4516) / 25.4), 0},
+		{"continuous", accel, 0, []string{"g21 g64 g1 x10 f600", "x20"}, 2.1, 0},
+		{"exact stop", accel, 0, []string{"g21 g61.1 g1 x10 f600", "x20"}, 2.2, 0},
+		{"exact path", accel, 0, []string{"g21 g61 g1 x10 f600", "x20"}, 2.1, 0},
+		{"right angle", accel, 0, []string{"g21 g64 g1 x10 f600", "y10"}, 2.2, 0},
+		// Going around a 45 degree corner within 0.1 mm at 100 mm/s/s
+		// allows 11 mm/s, so the machine need not slow; X and Y share
+		// the diagonal, so it stops at 141 mm/s/s.
+		{"junction deviation", accel, 0, []string{"g21 g64 p0.1 g1 x10 f600", "x20 y10"},
+			0.05 + (0.05 / math.Sqrt2) + ((10 + (10 * math.Sqrt2)) / 10), 0},
+		{"traverse", cycletime.Options_t{}, 6000, []string{"g21 g0 x100", "g1 x0 f6000"}, 1, 1},
+		{"no traverse rate", cycletime.Options_t{}, 0, []string{"g21 g0 x100"}, 0, 0},
+		{"traverse limit", xy, 6000, []string{"g21 g0 x100"}, 0, 10},
+		// Half a circle of radius 10 at the 10 mm/s X and Y allow.
+		{"arc", xy, 0, []string{"g21 g17 g2 x20 y0 i10 j0 f6000"}, math.Pi, 0},
+	}
+	for _, c := range cases {
+		report := estimate(t, c.options, c.traverse, c.program...)
+		if !near(report.Total.Feed, c.feed) || !near(report.Total.Traverse, c.rapid) {
+			t.Errorf("%s: feed %v, traverse %v, want %v, %v", c.name, report.Total.Feed,
+				report.Total.Traverse, c.feed, c.rapid)
+		}
+	}
+}
+
+func TestEstimate_operations(t *testing.T) {
+	options := cycletime.Options_t{Tool_change: 5, Spindle_start: 3}
+	report := estimate(t, options, 0,
+		"g21 (rough)",
+		"t1 m6",
+		"s1000 m3",
+		"g1 x10 f600",
+		"g4 p2",
+		"(finish)",
+		"t2 m6",
+		"m3",
+		"g1 x20",
+		"s2000",
+		"g1 x30",
+	)
+	if want := (cycletime.Time_t{Feed: 3, Dwell: 2, Tool_change: 10, Spindle: 9}); report.Total != want {
+		t.Errorf("Total = %+v, want %+v", report.Total, want)
+	}
+	tools := map[int]cycletime.Time_t{
+		1: {Feed: 1, Dwell: 2, Tool_change: 5, Spindle: 3},
+		2: {Feed: 2, Tool_change: 5, Spindle: 6},
+	}
+	for slot, want := range tools {
+		if got := report.Tools[slot]; (got == nil) || (*got != want) {
+			t.Errorf("T%d = %+v, want %+v", slot, got, want)
+		}
+	}
+	if len(report.Operations) != 3 {
+		t.Fatalf("%d operations, want 3", len(report.Operations))
+	}
+	for n, want := range []struct {
+		name  string
+		total float64
+	}{{"", 0}, {"rough", 11}, {"finish", 13}} {
+		operation := report.Operations[n]
+		if (operation.Name != want.name) || !near(operation.Time.Total(), want.total) {
+			t.Errorf("operation %d = %q taking %v, want %q taking %v", n, operation.Name,
+				operation.Time.Total(), want.name, want.total)
+		}
+	}
+}
+
+func TestEstimate_operations_held(t *testing.T) {
+	// Moves still held by the planner when a comment comes are charged
+	// to the operation they were made in.
+	report := estimate(t, cycletime.Options_t{}, 0, "g21 g64 (one)", "g1 x10 f600", "(two)", "g1 x30")
+	if len(report.Operations) != 3 {
+		t.Fatalf("%d operations, want 3", len(report.Operations))
+	}
+	for n, want := range []float64{0, 1, 2} {
+		if got := report.Operations[n].Time.Feed; !near(got, want) {
+			t.Errorf("operation %d %q: feed %v, want %v", n, report.Operations[n].Name, got, want)
+		}
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	report := estimate(t, cycletime.Options_t{Tool_change: 5}, 0, "g21 (face)", "t1 m6", "g1 x10 f600")
+	var out bytes.Buffer
+	if err := report.Write(&out); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	want := []string{
+		"     total       feed   traverse      dwell      tools    spindle",
+		"      6.00       1.00       0.00       0.00       5.00       0.00  all",
+		"      6.00       1.00       0.00       0.00       5.00       0.00  T1",
+		"      6.00       1.00       0.00       0.00       5.00       0.00  line 1: face",
+		"",
+	}
+	if got := strings.Split(out.String(), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("report:\n%s\nwant:\n%s", out.String(), strings.Join(want, "\n"))
+	}
+}
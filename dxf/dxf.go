@@ -0,0 +1,271 @@
+package dxf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* dxf.go
+
+   Writer_t is a Canon_i which writes the tool path of a program as a DXF
+   drawing (AutoCAD Release 12, the version nearly every CAD program
+   reads), for laying the tool path over the drawing of a part or a
+   fixture. It keeps the moves (see toolpath.Path_t) and writes them when
+   Write is called, since the layer table comes before the entities.
+
+   Each move is an entity on the layer of its tool and kind, named like
+   "T1_FEED" (see Layer): straight moves are LINEs, and arcs in the
+   XY-plane which do not move along Z are ARCs, or CIRCLEs if they turn a
+   whole circle or more. Other arcs, those in the XZ- and YZ-plane and
+   helixes, are 3D POLYLINEs of chords within Tolerance of the arc.
+   Traverses are drawn dashed. The layers of each tool have its color.
+   Rotary axes are not drawn.
+
+   All moves are written in the coordinates and length units of the
+   first move (see toolpath.Fold), which are those of the program
+   before it changes its offsets or units.
+
+*/
+
+type Writer_t struct {
+	toolpath.Path_t
+
+	Tolerance float64 // of chords of arcs, 0 means a thousandth of the radius
+}
+
+var _ inc.Canon_i = &Writer_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Writer_t with no moves, and the world model of a
+   machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New() *Writer_t {
+	return &Writer_t{}
+}
+
+/* Write
+
+   Returned Value: error (the first error writing to out, or nil)
+
+   Side effects: the drawing of the moves made so far is written to out.
+
+   Called by: external programs
+
+*/
+
+func (w *Writer_t) Write(out io.Writer) error {
+	return Write(out, w.Moves, w.Tolerance)
+}
+
+/* Layer
+
+   Returned Value: the name of the layer of a move of kind made with the
+   tool in slot, as "T<slot>_<kind>"
+
+   Side effects: none
+
+   Called by: Write, external programs
+
+*/
+
+func Layer(slot int, kind toolpath.Kind) string {
+	return fmt.Sprintf("T%d_%s", slot, strings.ToUpper(kind.String()))
+}
+
+/* Write
+
+   Returned Value: error (the first error writing to out, or nil)
+
+   Side effects: the drawing of moves is written to out.
+
+   Called by: Writer_t.Write, external programs
+
+   The drawing has a header giving the version, a table of the line
+   types CONTINUOUS and DASHED, a table of the layers used, and the
+   entities, in the order of the moves. The dashes are a tenth of an
+   inch or 2.5 millimeters long.
+
+*/
+
+func Write(out io.Writer, moves []toolpath.Move_t, tolerance float64) error {
+	moves = toolpath.Fold(moves)
+	d := &drawing_t{out: out, tolerance: tolerance}
+
+	var layers []string
+	colors := map[string]int{}
+	for _, move := range moves {
+		layer := Layer(move.Tool, move.Kind)
+		if _, ok := colors[layer]; !ok {
+			layers = append(layers, layer)
+			colors[layer] = color(move.Tool)
+		}
+	}
+	dash := 2.5
+	if (len(moves) != 0) && (moves[0].Units == inc.CANON_UNITS_INCHES) {
+		dash = 0.1
+	}
+
+	d.pair(999, "tool path written by rs274ngc")
+	d.section("HEADER")
+	d.pair(9, "$ACADVER")
+	d.pair(1, "AC1009")
+	d.pair(0, "ENDSEC")
+
+	d.section("TABLES")
+	d.table("LTYPE", 2)
+	d.pair(0, "LTYPE", 2, "CONTINUOUS", 70, 0, 3, "Solid line", 72, 65, 73, 0, 40, 0.0)
+	d.pair(0, "LTYPE", 2, "DASHED", 70, 0, 3, "Dashed", 72, 65, 73, 2, 40, dash*1.5,
+		49, dash, 49, -dash/2.0)
+	d.pair(0, "ENDTAB")
+	d.table("LAYER", len(layers))
+	for _, layer := range layers {
+		linetype := inc.If(strings.HasSuffix(layer, "_TRAVERSE"), "DASHED", "CONTINUOUS").(string)
+		d.pair(0, "LAYER", 2, layer, 70, 0, 62, colors[layer], 6, linetype)
+	}
+	d.pair(0, "ENDTAB")
+	d.pair(0, "ENDSEC")
+
+	d.section("ENTITIES")
+	for n := range moves {
+		d.move(&moves[n])
+	}
+	d.pair(0, "ENDSEC")
+	d.pair(0, "EOF")
+	return d.err
+}
+
+// color returns the AutoCAD color index of the tool in slot: tool 1 is
+// red (1), through tool 6 magenta (6), then tool 7 black or white (7),
+// over again, so tool 0 is black or white.
+func color(slot int) int {
+	return (((slot-1)%7)+7)%7 + 1
+}
+
+/***********************************************************************/
+
+// drawing_t writes group codes and values to out until the first error,
+// which it keeps.
+type drawing_t struct {
+	out       io.Writer
+	err       error
+	tolerance float64
+}
+
+/* pair
+
+   Returned Value: none
+
+   Side effects: each group code and value of pairs, given in turn, is
+   written on a line of its own, the code right justified in three
+   columns.
+
+   Called by: Write, drawing_t.*
+
+*/
+
+func (d *drawing_t) pair(pairs ...interface{}) {
+	for n := 0; (n+1 < len(pairs)) && (d.err == nil); n += 2 {
+		var value string
+		switch v := pairs[n+1].(type) {
+		case float64:
+			value = num(v)
+		default:
+			value = fmt.Sprint(v)
+		}
+		_, d.err = fmt.Fprintf(d.out, "%3d\n%s\n", pairs[n], value)
+	}
+}
+
+func (d *drawing_t) section(name string) {
+	d.pair(0, "SECTION", 2, name)
+}
+
+func (d *drawing_t) table(name string, count int) {
+	d.pair(0, "TABLE", 2, name, 70, count)
+}
+
+// point writes the coordinates of point with the group codes of the
+// first point of an entity plus offset.
+func (d *drawing_t) point(offset int, point inc.CANON_POSITION) {
+	d.pair(10+offset, point.X, 20+offset, point.Y, 30+offset, point.Z)
+}
+
+/* move
+
+   Returned Value: none
+
+   Side effects: the entity of the move is written.
+
+   Called by: Write
+
+   An ARC goes counterclockwise from its start angle to its end angle,
+   in degrees, so those of a clockwise arc are those of its end and its
+   start.
+
+*/
+
+func (d *drawing_t) move(move *toolpath.Move_t) {
+	layer := Layer(move.Tool, move.Kind)
+	planar := (move.Kind == toolpath.MOVE_ARC) && (move.Plane == inc.CANON_PLANE_XY) &&
+		(move.Start.Z == move.End.Z)
+	turn := move.Turn()
+	switch {
+	case move.Kind != toolpath.MOVE_ARC:
+		d.pair(0, "LINE", 8, layer)
+		d.point(0, move.Start)
+		d.point(1, move.End)
+	case planar && (math.Abs(turn) >= (2*math.Pi)-1e-9):
+		d.pair(0, "CIRCLE", 8, layer)
+		d.point(0, inc.CANON_POSITION{X: move.Center1, Y: move.Center2, Z: move.Start.Z})
+		d.pair(40, move.Radius())
+	case planar:
+		start := math.Atan2(move.Start.Y-move.Center2, move.Start.X-move.Center1)
+		end := start + turn
+		if turn < 0.0 {
+			start, end = end, start
+		}
+		d.pair(0, "ARC", 8, layer)
+		d.point(0, inc.CANON_POSITION{X: move.Center1, Y: move.Center2, Z: move.Start.Z})
+		d.pair(40, move.Radius(), 50, degrees(start), 51, degrees(end))
+	default:
+		tolerance := d.tolerance
+		if tolerance <= 0.0 {
+			tolerance = move.Radius() * 0.001
+		}
+		d.pair(0, "POLYLINE", 8, layer, 66, 1, 70, 8)
+		d.point(0, inc.CANON_POSITION{})
+		for _, point := range move.Points(tolerance) {
+			d.pair(0, "VERTEX", 8, layer, 70, 32)
+			d.point(0, point)
+		}
+		d.pair(0, "SEQEND", 8, layer)
+	}
+}
+
+// degrees returns angle, in radians, in degrees from 0 up to 360.
+func degrees(angle float64) float64 {
+	angle = math.Mod(angle*180.0/math.Pi, 360.0)
+	return inc.If(angle < 0.0, angle+360.0, angle).(float64)
+}
+
+// num formats a number with at most six decimals.
+func num(value float64) string {
+	text := strconv.FormatFloat(value, 'f', 6, 64)
+	text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	return inc.If(text == "-0", "0", text).(string)
+}
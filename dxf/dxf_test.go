@@ -0,0 +1,154 @@
+package dxf_test
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/dxf"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+var program = []string{
+	"g21 g0 x0 y0 z5",
+	"t1 m6",
+	"g1 z-1 f100",
+	"g2 x20 y0 i10 j0",
+	"g3 x20 y0 i-10 j0",
+	"g18 g2 x30 z-1 i5 k0",
+	"g17 t2 m6",
+	"g0 z5",
+}
+
+// entity_t is an entity read back: its type and its groups by code.
+type entity_t struct {
+	kind   string
+	groups map[int][]string
+}
+
+func (e entity_t) float(code int) float64 {
+	value, _ := strconv.ParseFloat(e.groups[code][0], 64)
+	return value
+}
+
+// draw runs program on a Writer_t and returns the drawing.
+func draw(t *testing.T) string {
+	writer := dxf.New()
+	writer.Tool_max = 4
+	cnctest.Run(t, cnctest.Start(t, writer, &writer.Canon_world_t), program...)
+	var out bytes.Buffer
+	if err := writer.Write(&out); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	return out.String()
+}
+
+// entities reads the drawing back as its entities, in order, starting
+// a new one at each group 0.
+func entities(t *testing.T, drawing string) []entity_t {
+	lines := strings.Split(strings.TrimSuffix(drawing, "\n"), "\n")
+	if len(lines)%2 != 0 {
+		t.Fatalf("drawing has %d lines, not code and value pairs", len(lines))
+	}
+	var found []entity_t
+	for n := 0; n < len(lines); n += 2 {
+		code, err := strconv.Atoi(strings.TrimSpace(lines[n]))
+		if err != nil {
+			t.Fatalf("line %d: group code %q: %v", n+1, lines[n], err)
+		}
+		if code == 0 {
+			found = append(found, entity_t{kind: lines[n+1], groups: map[int][]string{}})
+		} else if len(found) != 0 {
+			last := found[len(found)-1]
+			last.groups[code] = append(last.groups[code], lines[n+1])
+		}
+	}
+	return found
+}
+
+func TestWrite(t *testing.T) {
+	found := entities(t, draw(t))
+	if (len(found) == 0) || (found[len(found)-1].kind != "EOF") {
+		t.Fatalf("drawing does not end with EOF")
+	}
+
+	var layers, kinds []string
+	var drawn []entity_t
+	for _, entity := range found {
+		switch entity.kind {
+		case "LAYER":
+			layers = append(layers, entity.groups[2][0]+"/"+entity.groups[62][0]+"/"+entity.groups[6][0])
+		case "LINE", "ARC", "CIRCLE", "POLYLINE":
+			kinds = append(kinds, entity.kind+"/"+entity.groups[8][0])
+			drawn = append(drawn, entity)
+		}
+	}
+	want := []string{"T0_TRAVERSE/7/DASHED", "T1_FEED/1/CONTINUOUS", "T1_ARC/1/CONTINUOUS", "T2_TRAVERSE/2/DASHED"}
+	if strings.Join(layers, " ") != strings.Join(want, " ") {
+		t.Errorf("layers = %v, want %v", layers, want)
+	}
+	want = []string{"LINE/T0_TRAVERSE", "LINE/T1_FEED", "ARC/T1_ARC", "CIRCLE/T1_ARC", "POLYLINE/T1_ARC", "LINE/T2_TRAVERSE"}
+	if strings.Join(kinds, " ") != strings.Join(want, " ") {
+		t.Fatalf("entities = %v, want %v", kinds, want)
+	}
+
+	// The clockwise half circle from X0 to X20 over the top is the
+	// counterclockwise ARC from 0 to 180 degrees.
+	arc := drawn[2]
+	if (arc.float(10) != 10) || (arc.float(20) != 0) || (arc.float(30) != -1) || (arc.float(40) != 10) ||
+		(arc.float(50) != 0) || (arc.float(51) != 180) {
+		t.Errorf("ARC groups = %v, want about X10 Y0 Z-1, radius 10, from 0 to 180", arc.groups)
+	}
+	circle := drawn[3]
+	if (circle.float(10) != 10) || (circle.float(20) != 0) || (circle.float(40) != 10) {
+		t.Errorf("CIRCLE groups = %v, want about X10 Y0, radius 10", circle.groups)
+	}
+	line := drawn[1]
+	if (line.float(11) != 0) || (line.float(31) != -1) || (line.float(30) != 5) {
+		t.Errorf("LINE groups = %v, want Z5 to Z-1", line.groups)
+	}
+}
+
+func TestWrite_polyline(t *testing.T) {
+	found := entities(t, draw(t))
+	start := 0
+	for (start < len(found)) && (found[start].kind != "POLYLINE") {
+		start++
+	}
+	if (start == len(found)) || (found[start].groups[70][0] != "8") {
+		t.Fatalf("no 3D POLYLINE")
+	}
+	// The half circle in the XZ-plane about X25 Z-1, clockwise looking
+	// down Y, dips to Z-6 between X20 and X30.
+	var vertices []entity_t
+	n := start + 1
+	for ; (n < len(found)) && (found[n].kind == "VERTEX"); n++ {
+		vertices = append(vertices, found[n])
+	}
+	if (n == len(found)) || (found[n].kind != "SEQEND") || (len(vertices) < 3) {
+		t.Fatalf("POLYLINE has %d vertices and does not end with SEQEND", len(vertices))
+	}
+	first, last := vertices[0], vertices[len(vertices)-1]
+	if (first.float(10) != 20) || (first.float(30) != -1) || (last.float(10) != 30) || (last.float(30) != -1) {
+		t.Errorf("POLYLINE goes from %v to %v, want X20 Z-1 to X30 Z-1", first.groups, last.groups)
+	}
+	lowest := 0.0
+	for _, vertex := range vertices {
+		if radius := math.Hypot(vertex.float(10)-25, vertex.float(30)+1); math.Abs(radius-5) > 1e-5 {
+			t.Errorf("vertex %v is off the arc", vertex.groups)
+		}
+		lowest = math.Min(lowest, vertex.float(30))
+	}
+	if math.Abs(lowest+6) > 0.01 {
+		t.Errorf("POLYLINE reaches Z%v, want Z-6", lowest)
+	}
+}
+
+func TestLayer(t *testing.T) {
+	if layer := dxf.Layer(12, toolpath.MOVE_PROBE); layer != "T12_PROBE" {
+		t.Errorf("Layer(12, probe) = %q, want T12_PROBE", layer)
+	}
+}
@@ -44,6 +44,7 @@ var (
 	_program_position_z float64 = 0.0
 	_spindle_speed      float64
 	_spindle_turning    inc.CANON_DIRECTION
+	_tool_length_offset float64
 	_tool_max           = 68                                     /*Not static. Driver reads  */
 	_tools              [inc.CANON_TOOL_MAX]inc.CANON_TOOL_TABLE /*Not static. Driver writes */
 	_traverse_rate      float64
@@ -53,6 +54,9 @@ type Canon_t struct {
 }
 
 var _ inc.Canon_i = &Canon_t{}
+var _ inc.Canon_ext_i = &Canon_t{}
+var _ inc.Canon_machine_i = &Canon_t{}
+var _ inc.Comp_i = &Canon_t{}
 
 // Fprintf formats according to a format specifier and writes to w.
 // It returns the number of bytes written and any write error encountered.
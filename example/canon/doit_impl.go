@@ -259,6 +259,10 @@ func (canon Canon_t) STRAIGHT_PROBE(
 	   double end_parameter_value) {}
 */
 
+func (c Canon_t) STOP() {
+	myFprintf("STOP()\n")
+}
+
 func (c Canon_t) DWELL(seconds float64) {
 	myFprintf("DWELL(%.4f)\n", seconds)
 }
@@ -302,10 +306,19 @@ func (c Canon_t) USE_NO_SPINDLE_FORCE() {
 	myFprintf("USE_NO_SPINDLE_FORCE()\n")
 }
 
+func (c Canon_t) USE_SPINDLE_FORCE(force float64) {
+	myFprintf("USE_SPINDLE_FORCE(%.4f)\n", force)
+}
+
+func (c Canon_t) SET_SPINDLE_TORQUE(torque float64) {
+	myFprintf("SET_SPINDLE_TORQUE(%.4f)\n", torque)
+}
+
 /* Tool Functions */
 
 func (c Canon_t) USE_TOOL_LENGTH_OFFSET(length float64) {
 	myFprintf("USE_TOOL_LENGTH_OFFSET(%.4f)\n", length)
+	_tool_length_offset = length
 }
 
 func (c Canon_t) CHANGE_TOOL(slot int) {
@@ -325,7 +338,8 @@ func (c Canon_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
 			inc.If(axis == inc.CANON_AXIS_Y, "CANON_AXIS_Y",
 				inc.If(axis == inc.CANON_AXIS_Z, "CANON_AXIS_Z",
 					inc.If(axis == inc.CANON_AXIS_A, "CANON_AXIS_A",
-						inc.If(axis == inc.CANON_AXIS_C, "CANON_AXIS_C", "UNKNOWN").(string)).(string)).(string)).(string)).(string))
+						inc.If(axis == inc.CANON_AXIS_B, "CANON_AXIS_B",
+							inc.If(axis == inc.CANON_AXIS_C, "CANON_AXIS_C", "UNKNOWN").(string)).(string)).(string)).(string)).(string)).(string))
 }
 
 func (c Canon_t) COMMENT(s string) {
@@ -201,6 +201,11 @@ func (c Canon_t) GET_EXTERNAL_TOOL_SLOT() int {
 	return _active_slot
 }
 
+/* Returns the current tool length offset */
+func (c Canon_t) GET_EXTERNAL_TOOL_LENGTH_OFFSET() float64 {
+	return _tool_length_offset
+}
+
 /* Returns maximum number of tools */
 func (c Canon_t) GET_EXTERNAL_TOOL_MAX() int {
 	return _tool_max
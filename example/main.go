@@ -494,6 +494,9 @@ func (c *CNC) report_error( /* ARGUMENTS                            */
 	}
 
 	fmt.Fprintf(os.Stderr, "%s\n", c.LineText())
+	if text := c.CompErrorText(); len(text) != 0 {
+		fmt.Fprintf(os.Stderr, "%s\n", text)
+	}
 
 	//	if print_stack == rs274ngc.ON {
 	//		for k := 0; ; k++ {
@@ -0,0 +1,266 @@
+package extents
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/flyingyizi/rs274ngc/arc"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* extents.go
+
+   Measure finds how far the tool goes in X, Y and Z, overall, with each
+   tool, and in each coordinate system, for setup sheets. Analyzer_t is
+   a Canon_i which keeps the moves of a program (see toolpath.Path_t)
+   and measures them when Report is called.
+
+   Each extent is a box in work coordinates, those the program gives,
+   and in machine coordinates, those of the machine axes with the origin
+   offsets and tool length offset in force (see toolpath.Machine_position).
+   The extents of an arc are those of the arc itself, not just of its
+   ends (see arc.Find_arc_extents). Every move counts, traverses too,
+   and is measured in the length units of the first move; rotary axes
+   are not measured.
+
+   Work coordinates are the same in every coordinate system, so the
+   overall box and that of a tool used in more than one coordinate
+   system are boxes of the positions the program gave. The boxes of a
+   coordinate system are those of the work piece held in it, unless the
+   program moves its origin with G92.
+
+*/
+
+// Extent_t is how far a set of moves goes.
+type Extent_t struct {
+	Moves   int            // how many moves
+	Work    toolpath.Box_t // in work coordinates
+	Machine toolpath.Box_t // in machine coordinates
+}
+
+// Report_t is the extents of the moves of a program.
+type Report_t struct {
+	Units   inc.CANON_UNITS   // of all the extents
+	All     Extent_t          // of every move
+	Tools   map[int]*Extent_t // by pocket of the tool in the spindle
+	Systems map[int]*Extent_t // by coordinate system, 1 (G54) to 9 (G59.3)
+}
+
+type Analyzer_t struct {
+	toolpath.Path_t
+}
+
+var _ inc.Canon_i = &Analyzer_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: an Analyzer_t with no moves, no Source, and the world
+   model of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New() *Analyzer_t {
+	return &Analyzer_t{}
+}
+
+/* Report
+
+   Returned Value: the extents of the moves made so far
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (a *Analyzer_t) Report() *Report_t {
+	return Measure(a.Moves)
+}
+
+/* Measure
+
+   Returned Value: the extents of moves
+
+   Side effects: none
+
+   Called by: Analyzer_t.Report, external programs
+
+*/
+
+func Measure(moves []toolpath.Move_t) *Report_t {
+	report := &Report_t{Tools: map[int]*Extent_t{}, Systems: map[int]*Extent_t{}}
+	if len(moves) != 0 {
+		report.Units = moves[0].Units
+	}
+	for n := range moves {
+		work := box(&moves[n])
+		factor := 1.0
+		if (moves[n].Units == inc.CANON_UNITS_INCHES) && (report.Units == inc.CANON_UNITS_MM) {
+			factor = 25.4
+		} else if (moves[n].Units == inc.CANON_UNITS_MM) && (report.Units == inc.CANON_UNITS_INCHES) {
+			factor = 1.0 / 25.4
+		}
+		machine := toolpath.Box_t{Min: moves[n].Machine(work.Min), Max: moves[n].Machine(work.Max)}
+		work = toolpath.Box_t{Min: scale(work.Min, factor), Max: scale(work.Max, factor)}
+		machine = toolpath.Box_t{Min: scale(machine.Min, factor), Max: scale(machine.Max, factor)}
+
+		for _, extent := range []*Extent_t{&report.All, find(report.Tools, moves[n].Tool),
+			find(report.Systems, moves[n].System)} {
+			extent.add(work, machine)
+		}
+	}
+	return report
+}
+
+/* box
+
+   Returned Value: the box holding the path of the move, in its work
+   coordinates and units
+
+   Side effects: none
+
+   Called by: Measure
+
+*/
+
+func box(move *toolpath.Move_t) toolpath.Box_t {
+	box := toolpath.Box_t{Min: move.Start, Max: move.Start}
+	extend := func(point inc.CANON_POSITION) {
+		box.Min.X, box.Max.X = math.Min(box.Min.X, point.X), math.Max(box.Max.X, point.X)
+		box.Min.Y, box.Max.Y = math.Min(box.Min.Y, point.Y), math.Max(box.Max.Y, point.Y)
+		box.Min.Z, box.Max.Z = math.Min(box.Min.Z, point.Z), math.Max(box.Max.Z, point.Z)
+	}
+	extend(move.End)
+	if move.Kind == toolpath.MOVE_ARC {
+		first1, second1, axis1 := toolpath.Plane_point(move.Plane, move.Start)
+		first2, second2, axis2 := toolpath.Plane_point(move.Plane, move.End)
+		min1, min2, max1, max2 := arc.Find_arc_extents(first1, second1, move.Center1, move.Center2,
+			move.Rotation, first2, second2)
+		extend(toolpath.Plane_position(move.Plane, min1, min2, math.Min(axis1, axis2)))
+		extend(toolpath.Plane_position(move.Plane, max1, max2, math.Max(axis1, axis2)))
+	}
+	box.Min.A, box.Min.B, box.Min.C = 0.0, 0.0, 0.0
+	box.Max.A, box.Max.B, box.Max.C = 0.0, 0.0, 0.0
+	return box
+}
+
+// find returns the extent in extents under key, adding it if need be.
+func find(extents map[int]*Extent_t, key int) *Extent_t {
+	if _, ok := extents[key]; !ok {
+		extents[key] = &Extent_t{}
+	}
+	return extents[key]
+}
+
+// add widens the extent to hold a move with the boxes given.
+func (e *Extent_t) add(work, machine toolpath.Box_t) {
+	if e.Moves == 0 {
+		e.Work, e.Machine = work, machine
+	} else {
+		e.Work, e.Machine = union(e.Work, work), union(e.Machine, machine)
+	}
+	e.Moves++
+}
+
+// union returns the box holding two boxes.
+func union(one, two toolpath.Box_t) toolpath.Box_t {
+	return toolpath.Box_t{
+		Min: inc.CANON_POSITION{X: math.Min(one.Min.X, two.Min.X), Y: math.Min(one.Min.Y, two.Min.Y),
+			Z: math.Min(one.Min.Z, two.Min.Z)},
+		Max: inc.CANON_POSITION{X: math.Max(one.Max.X, two.Max.X), Y: math.Max(one.Max.Y, two.Max.Y),
+			Z: math.Max(one.Max.Z, two.Max.Z)},
+	}
+}
+
+// scale returns point with X, Y and Z multiplied by factor.
+func scale(point inc.CANON_POSITION, factor float64) inc.CANON_POSITION {
+	return inc.CANON_POSITION{X: point.X * factor, Y: point.Y * factor, Z: point.Z * factor}
+}
+
+/***********************************************************************/
+
+/* System_name
+
+   Returned Value: the G code selecting the coordinate system with index
+   system (1 to 9), or "?" for another index
+
+   Side effects: none
+
+   Called by: Report_t.Write, external programs
+
+*/
+
+func System_name(system int) string {
+	names := []string{"?", "G54", "G55", "G56", "G57", "G58", "G59", "G59.1", "G59.2", "G59.3"}
+	if (system < 0) || (system >= len(names)) {
+		return "?"
+	}
+	return names[system]
+}
+
+/* Write
+
+   Returned Value: error (the first error writing to out, or nil)
+
+   Side effects: the report is written to out as a table, with a line
+   in work and a line in machine coordinates for all the moves, each
+   tool in order, and each coordinate system in order.
+
+   Called by: external programs
+
+*/
+
+func (r *Report_t) Write(out io.Writer) error {
+	units := map[inc.CANON_UNITS]string{inc.CANON_UNITS_INCHES: "inches", inc.CANON_UNITS_MM: "mm",
+		inc.CANON_UNITS_CM: "cm"}[r.Units]
+	if _, err := fmt.Fprintf(out, "units: %s\n%-6s %-7s %6s %10s %10s %10s %10s %10s %10s\n", units,
+		"", "", "moves", "min X", "min Y", "min Z", "max X", "max Y", "max Z"); err != nil {
+		return err
+	}
+	line := func(name string, extent *Extent_t) error {
+		for n, coordinates := range []string{"work", "machine"} {
+			box := inc.If(n == 0, extent.Work, extent.Machine).(toolpath.Box_t)
+			_, err := fmt.Fprintf(out, "%-6s %-7s %6d %10.4f %10.4f %10.4f %10.4f %10.4f %10.4f\n",
+				inc.If(n == 0, name, "").(string), coordinates, extent.Moves,
+				box.Min.X, box.Min.Y, box.Min.Z, box.Max.X, box.Max.Y, box.Max.Z)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := line("all", &r.All); err != nil {
+		return err
+	}
+	for _, tool := range keys(r.Tools) {
+		if err := line(fmt.Sprintf("T%d", tool), r.Tools[tool]); err != nil {
+			return err
+		}
+	}
+	for _, system := range keys(r.Systems) {
+		if err := line(System_name(system), r.Systems[system]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keys returns the keys of extents in order.
+func keys(extents map[int]*Extent_t) []int {
+	var found []int
+	for key := range extents {
+		found = append(found, key)
+	}
+	sort.Ints(found)
+	return found
+}
@@ -0,0 +1,157 @@
+package extents_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/extents"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+var program = []string{
+	"g21 g0 x0 y0 z5",
+	"t1 m6",
+	"g1 z-1 f100",
+	"g2 x20 y0 i10 j0",
+	"#5241=100 #5242=50",
+	"g55 g0 x0 y0",
+	"t2 m6",
+	"g1 x5",
+	"g20 g1 x1",
+}
+
+// measure runs program on an Analyzer_t and returns its report.
+func measure(t *testing.T) *extents.Report_t {
+	analyzer := extents.New()
+	analyzer.Tool_max = 4
+	cnc := cnctest.Start(t, analyzer, &analyzer.Canon_world_t)
+	analyzer.Source = cnc
+	cnctest.Run(t, cnc, program...)
+	return analyzer.Report()
+}
+
+// box makes a box from its least and greatest X, Y and Z.
+func box(x1, y1, z1, x2, y2, z2 float64) toolpath.Box_t {
+	return toolpath.Box_t{Min: inc.CANON_POSITION{X: x1, Y: y1, Z: z1}, Max: inc.CANON_POSITION{X: x2, Y: y2, Z: z2}}
+}
+
+func TestMeasure(t *testing.T) {
+	report := measure(t)
+	if report.Units != inc.CANON_UNITS_MM {
+		t.Errorf("Units = %v, want millimeters", report.Units)
+	}
+	// The arc reaches Y10 between its ends. After G55, the tool is at
+	// X-80 Y-50 in work coordinates, and the last move, in inches, ends
+	// at X25.4 millimeters.
+	cases := []struct {
+		name          string
+		extent        *extents.Extent_t
+		moves         int
+		work, machine toolpath.Box_t
+	}{
+		{"all", &report.All, 6, box(-80, -50, -1, 25.4, 10, 5), box(0, 0, -1, 125.4, 50, 5)},
+		{"T0", report.Tools[0], 1, box(0, 0, 0, 0, 0, 5), box(0, 0, 0, 0, 0, 5)},
+		{"T1", report.Tools[1], 3, box(-80, -50, -1, 20, 10, 5), box(0, 0, -1, 100, 50, 5)},
+		{"T2", report.Tools[2], 2, box(0, 0, -1, 25.4, 0, -1), box(100, 50, -1, 125.4, 50, -1)},
+		{"G54", report.Systems[1], 3, box(0, 0, -1, 20, 10, 5), box(0, 0, -1, 20, 10, 5)},
+		{"G55", report.Systems[2], 3, box(-80, -50, -1, 25.4, 0, -1), box(20, 0, -1, 125.4, 50, -1)},
+	}
+	for _, c := range cases {
+		if c.extent == nil {
+			t.Errorf("%s: no extent", c.name)
+			continue
+		}
+		if (c.extent.Moves != c.moves) || !near(c.extent.Work, c.work) || !near(c.extent.Machine, c.machine) {
+			t.Errorf("%s = %+v, want %d moves in %+v, machine %+v", c.name, *c.extent, c.moves, c.work, c.machine)
+		}
+	}
+	if (len(report.Tools) != 3) || (len(report.Systems) != 2) {
+		t.Errorf("%d tools and %d coordinate systems, want 3 and 2", len(report.Tools), len(report.Systems))
+	}
+}
+
+// near reports whether two boxes are the same to within 1e-9.
+func near(one, two toolpath.Box_t) bool {
+	for _, d := range []float64{one.Min.X - two.Min.X, one.Min.Y - two.Min.Y, one.Min.Z - two.Min.Z,
+		one.Max.X - two.Max.X, one.Max.Y - two.Max.Y, one.Max.Z - two.Max.Z} {
+		if (d > 1e-9) || (d < -1e-9) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMeasure_arcs(t *testing.T) {
+	// Three quarters of a circle counterclockwise from X10 Y0 about the
+	// origin pass Y10 and X-10 but not Y-10; a turn and a half in the
+	// XZ-plane passes all four.
+	moves := []toolpath.Move_t{
+		{Kind: toolpath.MOVE_ARC, Units: inc.CANON_UNITS_MM, End: inc.CANON_POSITION{Y: -10, Z: 3},
+			Start: inc.CANON_POSITION{X: 10}, Plane: inc.CANON_PLANE_XY, Rotation: 1},
+		{Kind: toolpath.MOVE_ARC, Units: inc.CANON_UNITS_MM, Start: inc.CANON_POSITION{Z: 5},
+			End: inc.CANON_POSITION{X: 2, Z: 5}, Plane: inc.CANON_PLANE_XZ, Center1: 5, Center2: 1, Rotation: -2},
+	}
+	report := extents.Measure(moves[:1])
+	if want := box(-10, -10, 0, 10, 10, 3); !near(report.All.Work, want) {
+		t.Errorf("three quarters: Work = %+v, want %+v", report.All.Work, want)
+	}
+	report = extents.Measure(moves[1:])
+	if want := box(0, 0, 4, 2, 0, 6); !near(report.All.Work, want) {
+		t.Errorf("two turns: Work = %+v, want %+v", report.All.Work, want)
+	}
+	if (report.Systems[0] == nil) || (extents.System_name(0) != "?") || (extents.System_name(7) != "G59.1") {
+		t.Errorf("moves from no Source are not in coordinate system 0 named ?")
+	}
+}
+
+func TestMeasure_length_offset(t *testing.T) {
+	// With a tool 10 long in use, the machine axes are 10 above the tip
+	// of the tool, so machine Z is work Z plus 10, as limits sees it.
+	analyzer := extents.New()
+	analyzer.Tool_max = 4
+	analyzer.Tools = make([]inc.CANON_TOOL_TABLE, 4)
+	analyzer.Tools[1].Length = 10
+	cnc := cnctest.Start(t, analyzer, &analyzer.Canon_world_t)
+	analyzer.Source = cnc
+	cnctest.Run(t, cnc, "g21 t1 m6", "g43 h1", "g0 x0 y0 z5", "g1 z-1 f100")
+	report := analyzer.Report()
+	tool := report.Tools[1]
+	if tool == nil {
+		t.Fatalf("no extent for T1")
+	}
+	if want := box(0, 0, -1, 0, 0, 5); !near(tool.Work, want) {
+		t.Errorf("Work = %+v, want %+v", tool.Work, want)
+	}
+	if want := box(0, 0, 9, 0, 0, 15); !near(tool.Machine, want) {
+		t.Errorf("Machine = %+v, want %+v", tool.Machine, want)
+	}
+}
+
+func TestReport_Write(t *testing.T) {
+	var out bytes.Buffer
+	if err := measure(t).Write(&out); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != 15 {
+		t.Fatalf("report:\n%s\nis not 14 lines", out.String())
+	}
+	want := map[int]string{
+		0: "units: mm",
+		2: "all    work         6   -80.0000   -50.0000    -1.0000    25.4000    10.0000     5.0000",
+		3: "       machine      6     0.0000     0.0000    -1.0000   125.4000    50.0000     5.0000",
+	}
+	for n, line := range want {
+		if lines[n] != line {
+			t.Errorf("line %d = %q, want %q", n+1, lines[n], line)
+		}
+	}
+	for n, name := range []string{"T0", "T1", "T2", "G54", "G55"} {
+		if line := lines[4+(2*n)]; !strings.HasPrefix(line, name+" ") {
+			t.Errorf("line %d = %q, want it for %s", 5+(2*n), line, name)
+		}
+	}
+}
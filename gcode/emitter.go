@@ -0,0 +1,852 @@
+package gcode
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc/arc"
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* emitter.go
+
+   Emitter_t is a Canon_i which writes the canonical commands it is
+   given back out as RS274 G-code, so that a program from any CAM
+   system can be normalized by running it through the interpreter. The
+   program written does what the program read did, but uses only
+   absolute coordinates (G90) and units-per-minute feed (G94), has
+   parameters, expressions, canned cycles, and cutter radius
+   compensation already worked out by the interpreter, and has no
+   changes of work coordinate system: the origin offsets in force at
+   the first move are kept, and later changes (G92, G54 - G59.3, G10)
+   are folded into the coordinates.
+
+   Options_t chooses:
+   1. the dialect: LinuxCNC, Fanuc, or Grbl. This decides the codes for
+   dwell, probing, tool length offset, and so on, and how numbers are
+   written (Fanuc needs a decimal point in every length). A command the
+   dialect has no code for is written as a comment.
+   2. the number of digits after the decimal point. Trailing zeros are
+   dropped.
+   3. modal suppression: a motion G code or axis word which is the same
+   as the last one written is left out, and a move which then has no
+   axis words is not written at all.
+   4. line numbering: N words with the given step.
+   5. whether arcs are written as G2/G3 or as G1 moves with the given
+   chord error.
+
+   Comments the interpreter makes about itself ("interpreter: ...") are
+   not written, since the interpreter will make them again.
+
+   The first error from the io.Writer is kept in Err, and nothing more
+   is written after it.
+
+*/
+
+// Dialect is the controller the G-code is written for.
+type Dialect int
+
+const (
+	_ Dialect = iota
+	DIALECT_LINUXCNC
+	DIALECT_FANUC
+	DIALECT_GRBL
+)
+
+func (dialect Dialect) String() string {
+	switch dialect {
+	case DIALECT_LINUXCNC:
+		return "LinuxCNC"
+	case DIALECT_FANUC:
+		return "Fanuc"
+	case DIALECT_GRBL:
+		return "Grbl"
+	}
+	return "UNKNOWN"
+}
+
+type Options_t struct {
+	Dialect      Dialect // 0 means DIALECT_LINUXCNC
+	Precision    int     // digits after the decimal point; 0 means 4
+	Modal        bool    // leave out words repeating the modal state
+	Line_numbers int     // step between N numbers; 0 means no N words
+	Linearize    bool    // write arcs as G1 moves
+	Tolerance    float64 // chord error for Linearize; 0 means 0.001
+}
+
+type Emitter_t struct {
+	inc.Canon_world_t
+
+	Options Options_t
+	Err     error
+
+	out         io.Writer
+	started     bool               // anything written yet
+	line_number int                // of the last N word written
+	based       bool               // base has been fixed
+	base        inc.CANON_POSITION // origin offsets of the program written
+	rotary      [3]bool            // A, B, C have been away from zero
+	motion      string             // last motion G code written
+	words       map[byte]string    // last axis words written
+	feed        string             // last F word written
+	speed       string             // last S word written
+	plane       string             // last plane G code written
+	units       string             // last units G code written
+	comp_radius float64            // from SET_CUTTER_RADIUS_COMPENSATION
+}
+
+var _ inc.Canon_i = &Emitter_t{}
+var _ inc.Canon_ext_i = &Emitter_t{}
+var _ inc.Canon_machine_i = &Emitter_t{}
+var _ inc.Comp_i = &Emitter_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: an Emitter_t writing to out with the given options,
+   with the world model of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New(out io.Writer, options Options_t) *Emitter_t {
+	return &Emitter_t{Options: options, out: out, words: map[byte]string{}}
+}
+
+/***********************************************************************/
+
+/* write
+
+   Returned Value: none
+
+   Side effects:
+   One line is written from the words given, with an N word first if
+   line numbers are on. Before the first line, the preamble is written.
+   If the write fails, the error is saved in w.Err.
+
+   Called by: the canonical commands of Emitter_t
+
+   The preamble is "%" for Fanuc, then G90 G94.
+
+*/
+
+func (w *Emitter_t) write(words ...string) {
+	if w.Err != nil {
+		return
+	}
+	if !w.started {
+		w.started = true
+		if w.Options.Dialect == DIALECT_FANUC {
+			w.raw("%")
+		}
+		w.write("G90", "G94")
+	}
+	if w.Options.Line_numbers > 0 {
+		w.line_number = w.line_number + w.Options.Line_numbers
+		words = append([]string{"N" + strconv.Itoa(w.line_number)}, words...)
+	}
+	w.raw(strings.Join(words, " "))
+}
+
+func (w *Emitter_t) raw(line string) {
+	if w.Err == nil {
+		_, w.Err = io.WriteString(w.out, line+"\n")
+	}
+}
+
+/* comment
+
+   Returned Value: none
+
+   Side effects: a comment line is written
+
+   Called by: the canonical commands of Emitter_t
+
+   Parentheses in the text are changed to brackets, since a comment
+   cannot hold them.
+
+*/
+
+func (w *Emitter_t) comment(format string, args ...interface{}) {
+	text := fmt.Sprintf(format, args...)
+	text = strings.NewReplacer("(", "[", ")", "]").Replace(text)
+	w.write("(" + text + ")")
+}
+
+/* number
+
+   Returned Value: string
+   The value with Options.Precision digits after the decimal point,
+   trailing zeros dropped. For Fanuc, the decimal point is kept, so
+   that 1 is written 1. and not taken as 1 least increment.
+
+   Side effects: none
+
+   Called by: the canonical commands of Emitter_t
+
+*/
+
+func (w *Emitter_t) number(value float64) string {
+	precision := inc.If(w.Options.Precision > 0, w.Options.Precision, 4).(int)
+	text := strconv.FormatFloat(value, 'f', precision, 64)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(text, "0")
+		if w.Options.Dialect != DIALECT_FANUC {
+			text = strings.TrimSuffix(text, ".")
+		}
+	}
+	if (text == "-0") || (text == "-0.") {
+		text = text[1:]
+	}
+	return text
+}
+
+/***********************************************************************/
+
+/* program
+
+   Returned Value: the position in the coordinate system of the program
+   written
+
+   Side effects:
+   The first time this is called, the origin offsets in force are fixed
+   as those of the program written.
+
+   Called by: the motion commands of Emitter_t
+
+*/
+
+func (w *Emitter_t) program(position inc.CANON_POSITION) inc.CANON_POSITION {
+	if !w.based {
+		w.based = true
+		w.base = w.Origin
+	}
+	position.X = position.X + w.Origin.X - w.base.X
+	position.Y = position.Y + w.Origin.Y - w.base.Y
+	position.Z = position.Z + w.Origin.Z - w.base.Z
+	position.A = position.A + w.Origin.A - w.base.A
+	position.B = position.B + w.Origin.B - w.base.B
+	position.C = position.C + w.Origin.C - w.base.C
+	return position
+}
+
+/* axis_words
+
+   Returned Value: []string
+   The axis words of the end point of a move. With Options.Modal, a
+   word the same as the last one written is left out. A, B, and C are
+   written only once the axis has been away from zero, and never for
+   Grbl.
+
+   Side effects: the words are saved as the last ones written
+
+   Called by: the motion commands of Emitter_t
+
+*/
+
+func (w *Emitter_t) axis_words(end inc.CANON_POSITION) []string {
+	values := []float64{end.X, end.Y, end.Z, end.A, end.B, end.C}
+	var words []string
+	for i, letter := range []byte("XYZABC") {
+		if i >= 3 {
+			if w.Options.Dialect == DIALECT_GRBL {
+				break
+			}
+			w.rotary[i-3] = w.rotary[i-3] || (values[i] != 0)
+			if !w.rotary[i-3] {
+				continue
+			}
+		}
+		word := string(letter) + w.number(values[i])
+		if w.Options.Modal && (w.words[letter] == word) {
+			continue
+		}
+		w.words[letter] = word
+		words = append(words, word)
+	}
+	return words
+}
+
+/* motion_word
+
+   Returned Value: the motion G code, or "" if it is left out
+
+   Side effects: the code is saved as the last one written
+
+   Called by: the motion commands of Emitter_t
+
+*/
+
+func (w *Emitter_t) motion_word(code string) string {
+	if w.Options.Modal && (w.motion == code) {
+		return ""
+	}
+	w.motion = code
+	return code
+}
+
+/* feed_word
+
+   Returned Value: the F word of the feed rate, or "" if it is left out
+
+   Side effects: the word is saved as the last one written
+
+   Called by: the feed motion commands of Emitter_t
+
+*/
+
+func (w *Emitter_t) feed_word() string {
+	word := "F" + w.number(w.Feed_rate)
+	if w.Options.Modal && (w.feed == word) {
+		return ""
+	}
+	w.feed = word
+	return word
+}
+
+/* plane_word
+
+   Returned Value: the plane G code, or "" if it is left out
+
+   Side effects: the code is saved as the last one written
+
+   Called by: Emitter_t.ARC_FEED, START_CUTTER_RADIUS_COMPENSATION
+
+*/
+
+func (w *Emitter_t) plane_word() string {
+	code := map[inc.CANON_PLANE]string{inc.CANON_PLANE_XY: "G17",
+		inc.CANON_PLANE_XZ: "G18", inc.CANON_PLANE_YZ: "G19"}[w.GET_EXTERNAL_PLANE()]
+	if w.Options.Modal && (w.plane == code) {
+		return ""
+	}
+	w.plane = code
+	return code
+}
+
+/* move
+
+   Returned Value: none
+
+   Side effects:
+   A straight move to end is written, unless Options.Modal leaves it
+   with no axis words.
+
+   Called by: Emitter_t.STRAIGHT_TRAVERSE, STRAIGHT_FEED, ARC_FEED
+
+*/
+
+func (w *Emitter_t) move(code string, end inc.CANON_POSITION) {
+	axes := w.axis_words(w.program(end))
+	if len(axes) == 0 {
+		return
+	}
+	words := []string{w.motion_word(code)}
+	words = append(words, axes...)
+	if code != "G0" {
+		words = append(words, w.feed_word())
+	}
+	w.write(nonempty(words)...)
+}
+
+func nonempty(words []string) []string {
+	var kept []string
+	for _, word := range words {
+		if word != "" {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
+
+/* Representation */
+
+func (w *Emitter_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	w.Canon_world_t.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+}
+
+func (w *Emitter_t) USE_LENGTH_UNITS(in_unit inc.CANON_UNITS) {
+	before := w.GET_EXTERNAL_LENGTH_UNIT_FACTOR()
+	w.Canon_world_t.USE_LENGTH_UNITS(in_unit)
+	factor := before / w.GET_EXTERNAL_LENGTH_UNIT_FACTOR()
+	w.base.X, w.base.Y, w.base.Z = (w.base.X * factor), (w.base.Y * factor), (w.base.Z * factor)
+	if factor != 1.0 {
+		w.words = map[byte]string{}
+		w.feed = ""
+	}
+	code := inc.If(in_unit == inc.CANON_UNITS_INCHES, "G20", "G21").(string)
+	if w.Options.Modal && (w.units == code) {
+		return
+	}
+	w.units = code
+	w.write(code)
+}
+
+/* Free Space Motion */
+
+func (w *Emitter_t) SET_TRAVERSE_RATE(rate float64) {
+	w.Canon_world_t.SET_TRAVERSE_RATE(rate)
+}
+
+func (w *Emitter_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	w.move("G0", inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c})
+	w.Canon_world_t.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+}
+
+/* Machining Attributes */
+
+func (w *Emitter_t) SET_FEED_RATE(rate float64) {
+	w.Canon_world_t.SET_FEED_RATE(rate)
+}
+
+func (w *Emitter_t) SET_FEED_REFERENCE(reference inc.CANON_FEED_REFERENCE) {
+}
+
+func (w *Emitter_t) SET_MOTION_CONTROL_MODE(mode inc.CANON_MOTION_MODE) {
+	w.Canon_world_t.SET_MOTION_CONTROL_MODE(mode)
+	switch {
+	case mode == inc.CANON_CONTINUOUS && (w.Options.Dialect == DIALECT_GRBL):
+		w.comment("SET_MOTION_CONTROL_MODE(%s)", mode)
+	case mode == inc.CANON_CONTINUOUS:
+		w.write("G64")
+	case (mode == inc.CANON_EXACT_PATH) && (w.Options.Dialect == DIALECT_LINUXCNC):
+		w.write("G61.1")
+	default:
+		w.write("G61")
+	}
+}
+
+func (w *Emitter_t) SELECT_PLANE(plane inc.CANON_PLANE) {
+	w.Canon_world_t.SELECT_PLANE(plane)
+}
+
+func (w *Emitter_t) SET_CUTTER_RADIUS_COMPENSATION(radius float64) {
+	w.comp_radius = radius
+}
+
+func (w *Emitter_t) START_CUTTER_RADIUS_COMPENSATION(side inc.CANON_SIDE) {
+	code := inc.If(side == inc.CANON_SIDE_LEFT, "G41", "G42").(string)
+	switch w.Options.Dialect {
+	case DIALECT_LINUXCNC:
+		w.write(nonempty([]string{w.plane_word(), code + ".1", "D" + w.number(2*w.comp_radius)})...)
+	case DIALECT_FANUC:
+		w.write(nonempty([]string{w.plane_word(), code, "D" + strconv.Itoa(w.Tool_slot)})...)
+	default:
+		w.comment("START_CUTTER_RADIUS_COMPENSATION(%s)", side)
+	}
+}
+
+func (w *Emitter_t) STOP_CUTTER_RADIUS_COMPENSATION() {
+	w.write("G40")
+}
+
+func (w *Emitter_t) START_SPEED_FEED_SYNCH() {
+}
+
+func (w *Emitter_t) STOP_SPEED_FEED_SYNCH() {
+}
+
+/* Machining Functions */
+
+/* ARC_FEED
+
+   With Options.Linearize, the arc is written as G1 moves (see
+   linearize). Otherwise it is written as G2 (clockwise) or G3, with
+   center offsets for the plane. An arc of more than one turn has a P
+   word for LinuxCNC; for the others it is written as full circles
+   followed by the last partial one.
+
+*/
+
+func (w *Emitter_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+
+	start := w.Position
+	end := w.Position
+	var first_start, second_start float64
+	var offsets string // letters of the center offsets, first then second
+	switch w.GET_EXTERNAL_PLANE() {
+	case inc.CANON_PLANE_YZ:
+		first_start, second_start, offsets = start.Y, start.Z, "JK"
+		end.X, end.Y, end.Z = axis_end_point, first_end, second_end
+	case inc.CANON_PLANE_XZ:
+		first_start, second_start, offsets = start.Z, start.X, "KI"
+		end.X, end.Y, end.Z = second_end, axis_end_point, first_end
+	default:
+		first_start, second_start, offsets = start.X, start.Y, "IJ"
+		end.X, end.Y, end.Z = first_end, second_end, axis_end_point
+	}
+	end.A, end.B, end.C = a, b, c
+
+	if w.Options.Linearize {
+		w.linearize(start, end, first_start, second_start, first_axis, second_axis, rotation)
+		w.Canon_world_t.ARC_FEED(first_end, second_end, first_axis,
+			second_axis, rotation, axis_end_point, a, b, c)
+		return
+	}
+
+	code := inc.If(rotation < 0, "G2", "G3").(string)
+	center := []string{
+		offsets[0:1] + w.number(first_axis-first_start),
+		offsets[1:2] + w.number(second_axis-second_start)}
+	turns := inc.If(rotation < 0, -rotation, rotation).(int)
+	if (turns > 1) && (w.Options.Dialect != DIALECT_LINUXCNC) {
+		theta := arc.Find_turn(first_start, second_start, first_axis, second_axis,
+			rotation, first_end, second_end)
+		for turn := 1; turn < turns; turn++ {
+			circle := along(start, end, (float64(turn) * inc.TWO_PI / math.Abs(theta)))
+			circle = w.in_plane(circle, first_start, second_start)
+			w.arc(code, circle, center, "")
+		}
+		turns = 1
+	}
+	w.arc(code, end, center, inc.If(turns > 1, "P"+strconv.Itoa(turns), "").(string))
+	w.Canon_world_t.ARC_FEED(first_end, second_end, first_axis,
+		second_axis, rotation, axis_end_point, a, b, c)
+}
+
+func (w *Emitter_t) arc(code string, end inc.CANON_POSITION, center []string, turns string) {
+	words := []string{w.plane_word(), w.motion_word(code)}
+	words = append(words, w.axis_words(w.program(end))...)
+	words = append(words, center...)
+	words = append(words, turns, w.feed_word())
+	w.write(nonempty(words)...)
+}
+
+/* along
+
+   Returned Value: the point fraction of the way from start to end
+
+   Side effects: none
+
+   Called by: Emitter_t.ARC_FEED, linearize
+
+*/
+
+func along(start, end inc.CANON_POSITION, fraction float64) inc.CANON_POSITION {
+	return inc.CANON_POSITION{
+		X: start.X + fraction*(end.X-start.X),
+		Y: start.Y + fraction*(end.Y-start.Y),
+		Z: start.Z + fraction*(end.Z-start.Z),
+		A: start.A + fraction*(end.A-start.A),
+		B: start.B + fraction*(end.B-start.B),
+		C: start.C + fraction*(end.C-start.C)}
+}
+
+/* in_plane
+
+   Returned Value: the position with its coordinates in the selected
+   plane set to first and second
+
+   Side effects: none
+
+   Called by: Emitter_t.ARC_FEED, linearize
+
+*/
+
+func (w *Emitter_t) in_plane(position inc.CANON_POSITION, first, second float64) inc.CANON_POSITION {
+	switch w.GET_EXTERNAL_PLANE() {
+	case inc.CANON_PLANE_YZ:
+		position.Y, position.Z = first, second
+	case inc.CANON_PLANE_XZ:
+		position.Z, position.X = first, second
+	default:
+		position.X, position.Y = first, second
+	}
+	return position
+}
+
+/* linearize
+
+   Returned Value: none
+
+   Side effects: the arc is written as G1 moves
+
+   Called by: Emitter_t.ARC_FEED
+
+   The arc is cut into equal chords no farther than Options.Tolerance
+   from it (see arc.Find_chord_count). The axis coordinate and A, B, and
+   C change in proportion to the turn, so a helix stays a helix.
+
+*/
+
+func (w *Emitter_t) linearize(start, end inc.CANON_POSITION,
+	first_start, second_start, first_axis, second_axis float64, rotation int) {
+
+	first_end, second_end := first_start, second_start
+	switch w.GET_EXTERNAL_PLANE() {
+	case inc.CANON_PLANE_YZ:
+		first_end, second_end = end.Y, end.Z
+	case inc.CANON_PLANE_XZ:
+		first_end, second_end = end.Z, end.X
+	default:
+		first_end, second_end = end.X, end.Y
+	}
+	radius := math.Hypot((first_start - first_axis), (second_start - second_axis))
+	theta := arc.Find_turn(first_start, second_start, first_axis, second_axis,
+		rotation, first_end, second_end)
+	alpha := math.Atan2((second_start - second_axis), (first_start - first_axis))
+	tolerance := inc.If(w.Options.Tolerance > 0, w.Options.Tolerance, 0.001).(float64)
+	count := arc.Find_chord_count(radius, theta, tolerance)
+
+	for i := 1; i < count; i++ {
+		fraction := float64(i) / float64(count)
+		angle := alpha + (fraction * theta)
+		point := w.in_plane(along(start, end, fraction),
+			first_axis+(radius*math.Cos(angle)), second_axis+(radius*math.Sin(angle)))
+		w.move("G1", point)
+	}
+	w.move("G1", end)
+}
+
+func (w *Emitter_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	w.move("G1", inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c})
+	w.Canon_world_t.STRAIGHT_FEED(x, y, z, a, b, c)
+}
+
+func (w *Emitter_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	code := inc.If(w.Options.Dialect == DIALECT_FANUC, "G31", "G38.2").(string)
+	end := inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+	w.words = map[byte]string{}
+	words := append([]string{code}, w.axis_words(w.program(end))...)
+	w.write(append(words, w.feed_word())...)
+	w.motion = ""
+	w.words = map[byte]string{}
+	w.Canon_world_t.STRAIGHT_PROBE(x, y, z, a, b, c)
+}
+
+func (w *Emitter_t) STOP() {
+	w.comment("STOP()")
+}
+
+func (w *Emitter_t) DWELL(seconds float64) {
+	w.write("G4", inc.If(w.Options.Dialect == DIALECT_FANUC, "X", "P").(string)+w.number(seconds))
+}
+
+/* Spindle Functions */
+
+func (w *Emitter_t) SPINDLE_RETRACT_TRAVERSE() {
+	w.comment("SPINDLE_RETRACT_TRAVERSE()")
+}
+
+func (w *Emitter_t) START_SPINDLE_CLOCKWISE() {
+	w.Canon_world_t.START_SPINDLE_CLOCKWISE()
+	w.write("M3")
+}
+
+func (w *Emitter_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	w.Canon_world_t.START_SPINDLE_COUNTERCLOCKWISE()
+	w.write("M4")
+}
+
+/* SET_SPINDLE_SPEED
+
+   For Fanuc the speed is rounded to a whole number, since many Fanuc
+   controls take an S word with a decimal point as an error.
+
+*/
+
+func (w *Emitter_t) SET_SPINDLE_SPEED(rpm float64) {
+	w.Canon_world_t.SET_SPINDLE_SPEED(rpm)
+	word := "S" + w.number(rpm)
+	if w.Options.Dialect == DIALECT_FANUC {
+		word = "S" + strconv.Itoa(int(math.Round(rpm)))
+	}
+	if w.Options.Modal && (w.speed == word) {
+		return
+	}
+	w.speed = word
+	w.write(word)
+}
+
+func (w *Emitter_t) STOP_SPINDLE_TURNING() {
+	w.Canon_world_t.STOP_SPINDLE_TURNING()
+	w.write("M5")
+}
+
+func (w *Emitter_t) SPINDLE_RETRACT() {
+	w.comment("SPINDLE_RETRACT()")
+}
+
+func (w *Emitter_t) ORIENT_SPINDLE(orientation float64, direction inc.CANON_DIRECTION) {
+	switch w.Options.Dialect {
+	case DIALECT_LINUXCNC:
+		w.write("M19", "R"+w.number(orientation),
+			inc.If(direction == inc.CANON_CLOCKWISE, "P1", "P2").(string))
+	case DIALECT_FANUC:
+		w.write("M19")
+	default:
+		w.comment("ORIENT_SPINDLE(%s, %s)", w.number(orientation), direction)
+	}
+}
+
+func (w *Emitter_t) USE_NO_SPINDLE_FORCE() {
+	w.comment("USE_NO_SPINDLE_FORCE()")
+}
+
+func (w *Emitter_t) USE_SPINDLE_FORCE(force float64) {
+	w.comment("USE_SPINDLE_FORCE(%s)", w.number(force))
+}
+
+func (w *Emitter_t) SET_SPINDLE_TORQUE(torque float64) {
+	w.comment("SET_SPINDLE_TORQUE(%s)", w.number(torque))
+}
+
+/* Tool Functions */
+
+/* USE_TOOL_LENGTH_OFFSET
+
+   LinuxCNC and Grbl are given the offset itself (G43.1). Fanuc is given
+   the H number of the tool in the spindle, so its offset table must
+   match the tool table the program was run with.
+
+*/
+
+func (w *Emitter_t) USE_TOOL_LENGTH_OFFSET(length float64) {
+	w.Canon_world_t.USE_TOOL_LENGTH_OFFSET(length)
+	switch {
+	case length == 0:
+		w.write("G49")
+	case w.Options.Dialect == DIALECT_FANUC:
+		w.write("G43", "H"+strconv.Itoa(w.Tool_slot))
+	default:
+		w.write("G43.1", "Z"+w.number(length))
+	}
+}
+
+func (w *Emitter_t) CHANGE_TOOL(slot int) {
+	w.Canon_world_t.CHANGE_TOOL(slot)
+	if w.Options.Dialect == DIALECT_GRBL {
+		w.comment("CHANGE_TOOL(%d)", slot)
+	} else {
+		w.write("M6")
+	}
+}
+
+func (w *Emitter_t) SELECT_TOOL(slot int) {
+	w.write("T" + strconv.Itoa(slot))
+}
+
+/* Misc Functions */
+
+func (w *Emitter_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
+	w.comment("CLAMP_AXIS(%s)", axis)
+}
+
+func (w *Emitter_t) COMMENT(s string) {
+	if !strings.HasPrefix(s, "interpreter: ") {
+		w.comment("%s", s)
+	}
+}
+
+func (w *Emitter_t) DISABLE_FEED_OVERRIDE() {
+	w.override("M50", "P0", "DISABLE_FEED_OVERRIDE()")
+}
+
+func (w *Emitter_t) DISABLE_SPEED_OVERRIDE() {
+	w.override("M51", "P0", "DISABLE_SPEED_OVERRIDE()")
+}
+
+func (w *Emitter_t) ENABLE_FEED_OVERRIDE() {
+	w.override("M50", "P1", "ENABLE_FEED_OVERRIDE()")
+}
+
+func (w *Emitter_t) ENABLE_SPEED_OVERRIDE() {
+	w.override("M51", "P1", "ENABLE_SPEED_OVERRIDE()")
+}
+
+func (w *Emitter_t) override(code, p, name string) {
+	if w.Options.Dialect == DIALECT_LINUXCNC {
+		w.write(code, p)
+	} else {
+		w.comment("%s", name)
+	}
+}
+
+/* FLOOD_OFF, MIST_OFF
+
+   M9 turns off both flood and mist, so the other is turned back on if
+   it was on.
+
+*/
+
+func (w *Emitter_t) FLOOD_OFF() {
+	w.Canon_world_t.FLOOD_OFF()
+	w.write("M9")
+	if w.Mist {
+		w.write("M7")
+	}
+}
+
+func (w *Emitter_t) FLOOD_ON() {
+	w.Canon_world_t.FLOOD_ON()
+	w.write("M8")
+}
+
+func (w *Emitter_t) INIT_CANON() {
+}
+
+func (w *Emitter_t) MESSAGE(s []byte) {
+	if w.Options.Dialect == DIALECT_FANUC {
+		w.comment("%s", s)
+	} else {
+		w.comment("MSG,%s", s)
+	}
+}
+
+func (w *Emitter_t) MIST_OFF() {
+	w.Canon_world_t.MIST_OFF()
+	w.write("M9")
+	if w.Flood {
+		w.write("M8")
+	}
+}
+
+func (w *Emitter_t) MIST_ON() {
+	w.Canon_world_t.MIST_ON()
+	w.write("M7")
+}
+
+func (w *Emitter_t) PALLET_SHUTTLE() {
+	if w.Options.Dialect == DIALECT_LINUXCNC {
+		w.write("M60")
+	} else {
+		w.comment("PALLET_SHUTTLE()")
+	}
+}
+
+func (w *Emitter_t) TURN_PROBE_OFF() {
+}
+
+func (w *Emitter_t) TURN_PROBE_ON() {
+}
+
+func (w *Emitter_t) UNCLAMP_AXIS(axis inc.CANON_AXIS) {
+	w.comment("UNCLAMP_AXIS(%s)", axis)
+}
+
+/* Program Functions */
+
+func (w *Emitter_t) PROGRAM_STOP() {
+	w.write("M0")
+}
+
+func (w *Emitter_t) OPTIONAL_PROGRAM_STOP() {
+	w.write("M1")
+}
+
+func (w *Emitter_t) PROGRAM_END() {
+	if w.Options.Dialect == DIALECT_FANUC {
+		w.write("M30")
+		w.raw("%")
+	} else {
+		w.write("M2")
+	}
+}
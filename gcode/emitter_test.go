@@ -0,0 +1,125 @@
+package gcode_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/gcode"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/record"
+)
+
+// emit runs lines through an Emitter_t with options and returns its output.
+func emit(t *testing.T, options gcode.Options_t, lines []string) string {
+	var out bytes.Buffer
+	w := gcode.New(&out, options)
+	cnctest.Run(t, cnctest.Start(t, w, &w.Canon_world_t), lines...)
+	if w.Err != nil {
+		t.Fatalf("Err = %v", w.Err)
+	}
+	return out.String()
+}
+
+var program = []string{
+	"g21 g0 x0 y0 z5",
+	"g1 z-1 f100",
+	"x10 (side one)",
+	"g2 x20 y0 i5 j0",
+	"g1 y10",
+	"g3 x10 y10 r5 z-2",
+	"g0 z5",
+	"m8",
+	"m2",
+}
+
+func TestEmitter_LinuxCNC(t *testing.T) {
+	got := emit(t, gcode.Options_t{Modal: true}, program)
+	want := "" +
+		"G90 G94\n" +
+		"G21\n" +
+		"G0 X0 Y0 Z5\n" +
+		"G1 Z-1 F100\n" +
+		"(side one)\n" +
+		"X10\n" +
+		"G17 G2 X20 I5 J0\n" +
+		"G1 Y10\n" +
+		"G3 X10 Z-2 I-5 J0\n" +
+		"G0 Z5\n" +
+		"M8\n" +
+		"M5\n" +
+		"M9\n" +
+		"M2\n"
+	if got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEmitter_Fanuc(t *testing.T) {
+	got := emit(t, gcode.Options_t{Dialect: gcode.DIALECT_FANUC, Precision: 3, Line_numbers: 10},
+		[]string{"g0 x1.25 y0", "g1 x2 f50.5", "m2"})
+	want := "" +
+		"%\n" +
+		"N10 G90 G94\n" +
+		"N20 G21\n" +
+		"N30 G0 X1.25 Y0. Z0.\n" +
+		"N40 G1 X2. Y0. Z0. F50.5\n" +
+		"N50 M5\n" +
+		"N60 M30\n" +
+		"%\n"
+	if got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEmitter_origin(t *testing.T) {
+	got := emit(t, gcode.Options_t{Modal: true}, []string{"g0 x1 y1", "g92 x0 y0", "g0 x1"})
+	if !strings.HasSuffix(got, "G0 X1 Y1 Z0\nX2\n") {
+		t.Errorf("output:\n%s\nwant the G92 folded in", got)
+	}
+}
+
+func TestEmitter_Linearize(t *testing.T) {
+	got := emit(t, gcode.Options_t{Modal: true, Linearize: true, Tolerance: 0.01},
+		[]string{"g0 x10 y0", "g3 x-10 y0 i-10 j0 f100"})
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	// A half circle of radius 10 needs 36 chords for a chord error of 0.01.
+	feeds := lines[3:]
+	if (len(feeds) != 36) || (feeds[0][:2] != "G1") || (feeds[35] != "X-10 Y0") {
+		t.Errorf("output:\n%s\nwant 36 G1 moves ending at X-10 Y0", got)
+	}
+	if strings.Contains(got, "G3") {
+		t.Errorf("output:\n%s\nhas an arc", got)
+	}
+}
+
+// TestEmitter_round_trip runs the program written for program and checks
+// that it makes the same moves.
+func TestEmitter_round_trip(t *testing.T) {
+	moves := []string{"STRAIGHT_TRAVERSE", "STRAIGHT_FEED", "ARC_FEED"}
+	direct := record.New()
+	cnctest.Run(t, cnctest.Start(t, direct, &direct.Canon_world_t), program...)
+
+	for _, options := range []gcode.Options_t{
+		{Modal: true},
+		{Line_numbers: 5},
+		{Dialect: gcode.DIALECT_FANUC, Modal: true},
+		{Dialect: gcode.DIALECT_GRBL, Modal: true},
+	} {
+		var lines []string
+		for _, line := range strings.Split(emit(t, options, program), "\n") {
+			if (line != "") && (line != "%") {
+				lines = append(lines, line)
+			}
+		}
+		again := record.New()
+		cnctest.Run(t, cnctest.Start(t, again, &again.Canon_world_t), lines...)
+
+		got, want := record.Strings(again.Filter(moves...)), record.Strings(direct.Filter(moves...))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: moves:\n%s\nwant:\n%s", options.Dialect,
+				strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package heightmap_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/heightmap"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+// The stock is 20 by 10 by 5, top at Z0, in cells of 0.5: 40 by 20.
+var options = heightmap.Options_t{
+	Stock:  toolpath.Box_t{Min: inc.CANON_POSITION{Z: -5}, Max: inc.CANON_POSITION{X: 20, Y: 10}},
+	Cell:   0.5,
+	Shapes: map[int]heightmap.Shape{2: heightmap.SHAPE_BALL},
+}
+
+// simulate runs lines on a Simulator_t with tools 1 and 2 four wide, and
+// returns the result.
+func simulate(t *testing.T, lines ...string) *heightmap.Result_t {
+	simulator := heightmap.New(options)
+	simulator.Tool_max = 4
+	simulator.Tools = make([]inc.CANON_TOOL_TABLE, 5)
+	simulator.Tools[1].Diameter, simulator.Tools[2].Diameter = 4, 4
+	cnc := cnctest.Start(t, simulator, &simulator.Canon_world_t)
+	simulator.Source = cnc
+	cnctest.Run(t, cnc, lines...)
+	return simulator.Simulate()
+}
+
+// slot cuts a slot 4 wide and 1 deep along Y5, the whole length of the
+// stock.
+var slot = []string{"g21 g0 x-5 y5 z5", "t1 m6", "g1 z-1 f100", "g1 x25", "g0 z5"}
+
+func TestSimulate_slot(t *testing.T) {
+	result := simulate(t, slot...)
+	// The centers of 8 rows of cells, Y 3.25 to 6.75, are under the
+	// tool.
+	if math.Abs(result.Removed-(40*8*0.25)) > 1e-9 {
+		t.Errorf("Removed = %v, want 80", result.Removed)
+	}
+	if len(result.Crashes) != 0 {
+		t.Errorf("Crashes = %+v, want none", result.Crashes)
+	}
+	m := result.Map
+	if (m.Columns != 40) || (m.Rows != 20) {
+		t.Fatalf("map is %d by %d, want 40 by 20", m.Columns, m.Rows)
+	}
+	for row, want := range map[int]float64{5: 0, 6: -1, 13: -1, 14: 0} {
+		for _, column := range []int{0, 20, 39} {
+			if got := m.Height(column, row); got != want {
+				t.Errorf("Height(%d, %d) = %v, want %v", column, row, got, want)
+			}
+		}
+	}
+}
+
+func TestSimulate_crash(t *testing.T) {
+	result := simulate(t, append(slot, "g0 x10 y5", "g0 z-2", "g0 z5")...)
+	if len(result.Crashes) != 1 {
+		t.Fatalf("Crashes = %+v, want one", result.Crashes)
+	}
+	crash := result.Crashes[0]
+	want := inc.CANON_POSITION{X: 10, Y: 5, Z: -2}
+	if (crash.Line != 7) || (crash.Tool != 1) || (crash.At != want) || (crash.Depth != 1) {
+		t.Errorf("crash = %+v, want on line 7 with tool 1 at %+v, 1 deep", crash, want)
+	}
+	if result.Map.Height(20, 10) != -2 {
+		t.Errorf("Height(20, 10) = %v, want the crash to have cut to -2", result.Map.Height(20, 10))
+	}
+}
+
+func TestSimulate_ball(t *testing.T) {
+	result := simulate(t, "g21 g0 x10 y5 z5", "t2 m6", "g1 z-2 f100", "g0 z5")
+	// A ball of radius 2 with its bottom at Z-2.
+	for _, cell := range [][2]int{{20, 10}, {23, 10}, {19, 7}} {
+		x, y := result.Map.Center(cell[0], cell[1])
+		d := math.Hypot(x-10, y-5)
+		want := -2 + 2 - math.Sqrt(4-(d*d))
+		if got := result.Map.Height(cell[0], cell[1]); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Height(%d, %d) = %v, want %v", cell[0], cell[1], got, want)
+		}
+	}
+	if got := result.Map.Height(24, 10); got != 0 {
+		t.Errorf("Height(24, 10) = %v, want uncut", got)
+	}
+}
+
+func TestMap_Write(t *testing.T) {
+	result := simulate(t, slot...)
+
+	var stl bytes.Buffer
+	if err := result.Map.Write_stl(&stl); err != nil {
+		t.Fatalf("Write_stl() = %v", err)
+	}
+	// Two triangles for each of 39 by 19 squares on top, and three for
+	// each of the 116 cells around its edge, two on a side and one on the
+	// bottom.
+	count := binary.LittleEndian.Uint32(stl.Bytes()[80:84])
+	if (count != (2*39*19)+(3*116)) || (stl.Len() != 84+(50*int(count))) {
+		t.Errorf("STL has %d triangles in %d bytes, want 1830 in %d", count, stl.Len(), 84+(50*1830))
+	}
+
+	var picture bytes.Buffer
+	if err := result.Map.Write_png(&picture); err != nil {
+		t.Fatalf("Write_png() = %v", err)
+	}
+	decoded, err := png.Decode(&picture)
+	if err != nil {
+		t.Fatalf("png.Decode() = %v", err)
+	}
+	if bounds := decoded.Bounds(); (bounds.Dx() != 40) || (bounds.Dy() != 20) {
+		t.Fatalf("picture bounds = %v, want 40 by 20", bounds)
+	}
+	top, _, _, _ := decoded.At(0, 0).RGBA()
+	cut, _, _, _ := decoded.At(0, 10).RGBA()
+	if (top != 0xffff) || (cut != 0xcccc) {
+		t.Errorf("picture gray = %#x uncut and %#x in the slot, want 0xffff and 0xcccc", top, cut)
+	}
+}
@@ -0,0 +1,302 @@
+package heightmap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* map.go
+
+   Map_t is the top of the stock as a grid of square cells, seen from
+   above, with one height for each cell (a Z-buffer, or heightmap). The
+   stock starts as a box, with every cell at the top of it, and cutting
+   lowers cells. A heightmap cannot show undercuts, which a three axis
+   mill cannot make anyway.
+
+   The map is written as a gray picture, black at the bottom of the stock
+   and white at the top, or as a closed solid in a binary STL file.
+
+*/
+
+type Map_t struct {
+	Min_x   float64   // X of the left edge of the grid
+	Min_y   float64   // Y of the front edge of the grid
+	Cell    float64   // width of a cell
+	Columns int       // cells along X
+	Rows    int       // cells along Y
+	Bottom  float64   // Z of the bottom of the stock
+	Top     float64   // Z of the top of the stock
+	Heights []float64 // row by row, from the front, and from the left in a row
+}
+
+/***********************************************************************/
+
+/* New_map
+
+   Returned Value: a Map_t of the uncut stock, with cells of width cell
+
+   Side effects: none
+
+   Called by: Simulate, external programs
+
+   The grid covers the stock, and may reach up to a cell past its back
+   and right sides if they are not a whole number of cells from the
+   front and left.
+
+*/
+
+func New_map(stock toolpath.Box_t, cell float64) *Map_t {
+	count := func(span float64) int {
+		return int(math.Max(1.0, math.Ceil((span/cell)-1e-9)))
+	}
+	m := &Map_t{
+		Min_x:   stock.Min.X,
+		Min_y:   stock.Min.Y,
+		Cell:    cell,
+		Columns: count(stock.Max.X - stock.Min.X),
+		Rows:    count(stock.Max.Y - stock.Min.Y),
+		Bottom:  stock.Min.Z,
+		Top:     stock.Max.Z,
+	}
+	m.Heights = make([]float64, m.Columns*m.Rows)
+	for n := range m.Heights {
+		m.Heights[n] = m.Top
+	}
+	return m
+}
+
+/* Height
+
+   Returned Value: the height of the cell in column and row
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (m *Map_t) Height(column, row int) float64 {
+	return m.Heights[(row*m.Columns)+column]
+}
+
+/* Center
+
+   Returned Value: the X and Y of the center of the cell in column and
+   row
+
+   Side effects: none
+
+   Called by: Map_t.cut, Map_t.Write_stl, external programs
+
+*/
+
+func (m *Map_t) Center(column, row int) (x, y float64) {
+	return m.Min_x + ((float64(column) + 0.5) * m.Cell), m.Min_y + ((float64(row) + 0.5) * m.Cell)
+}
+
+/* Volume
+
+   Returned Value: the volume of the stock left
+
+   Side effects: none
+
+   Called by: Simulate, external programs
+
+*/
+
+func (m *Map_t) Volume() float64 {
+	total := 0.0
+	for _, height := range m.Heights {
+		total = total + (height - m.Bottom)
+	}
+	return total * m.Cell * m.Cell
+}
+
+/* cut
+
+   Returned Value: how far the stock was above the tool, at most, where
+   the tool cut it, 0 if it did not cut
+
+   Side effects: unless dry, every cell whose center is under the tool is
+   lowered to the tool, but not below the bottom of the stock.
+
+   Called by: sweep
+
+   The tip of the tool is at x, y and z. A flat end mill is flat at z
+   out to radius; a ball end mill is a half ball of radius above z. A
+   tool narrower than a cell cuts the cell its tip is over.
+
+*/
+
+func (m *Map_t) cut(x, y, z, radius float64, ball, dry bool) float64 {
+	if radius < (m.Cell / 2.0) {
+		radius, ball = m.Cell/2.0, false
+	}
+	column1, column2 := m.under(x, radius, m.Min_x, m.Columns)
+	row1, row2 := m.under(y, radius, m.Min_y, m.Rows)
+
+	depth := 0.0
+	for row := row1; row <= row2; row++ {
+		for column := column1; column <= column2; column++ {
+			cx, cy := m.Center(column, row)
+			distance := math.Hypot(cx-x, cy-y)
+			if distance > radius {
+				continue
+			}
+			height := z
+			if ball {
+				height = z + radius - math.Sqrt((radius*radius)-(distance*distance))
+			}
+			height = math.Max(height, m.Bottom)
+			n := (row * m.Columns) + column
+			if m.Heights[n] > height {
+				depth = math.Max(depth, m.Heights[n]-height)
+				m.Heights[n] = inc.If(dry, m.Heights[n], height).(float64)
+			}
+		}
+	}
+	return depth
+}
+
+// under returns the first and last of count cells along an axis, the
+// first starting at low, whose centers are within radius of center.
+func (m *Map_t) under(center, radius, low float64, count int) (first, last int) {
+	first = int(math.Max(0.0, math.Ceil(((center-radius-low)/m.Cell)-0.5)))
+	last = int(math.Min(float64(count-1), math.Floor(((center+radius-low)/m.Cell)-0.5)))
+	return first, last
+}
+
+/***********************************************************************/
+
+/* Image
+
+   Returned Value: the map as a picture with a pixel for each cell,
+   black at the bottom of the stock and white at the top, with +Y up
+
+   Side effects: none
+
+   Called by: Map_t.Write_png, external programs
+
+*/
+
+func (m *Map_t) Image() *image.Gray16 {
+	picture := image.NewGray16(image.Rect(0, 0, m.Columns, m.Rows))
+	span := m.Top - m.Bottom
+	for row := 0; row < m.Rows; row++ {
+		for column := 0; column < m.Columns; column++ {
+			level := 1.0
+			if span > 0.0 {
+				level = (m.Height(column, row) - m.Bottom) / span
+			}
+			picture.SetGray16(column, m.Rows-1-row, color.Gray16{Y: uint16(math.Round(level * 0xffff))})
+		}
+	}
+	return picture
+}
+
+/* Write_png
+
+   Returned Value: error (the first error writing to out, or nil)
+
+   Side effects: the picture of the map (see Image) is written to out as
+   a PNG file.
+
+   Called by: external programs
+
+*/
+
+func (m *Map_t) Write_png(out io.Writer) error {
+	return png.Encode(out, m.Image())
+}
+
+/* Write_stl
+
+   Returned Value: error (the first error writing to out, or nil)
+
+   Side effects: the stock left is written to out as a closed solid in a
+   binary STL file.
+
+   Called by: external programs
+
+   The top is a mesh through the centers of the cells, at their
+   heights, so the solid is half a cell smaller than the grid on each
+   side. The four sides go straight down from the edges of the top to
+   the bottom of the stock. The bottom is a fan of triangles from its
+   center to every point along its edges, so that each edge of the solid
+   is shared by just two triangles.
+
+*/
+
+func (m *Map_t) Write_stl(out io.Writer) error {
+	type vertex [3]float64
+	top := func(column, row int) vertex {
+		x, y := m.Center(column, row)
+		return vertex{x, y, m.Height(column, row)}
+	}
+	bottom := func(v vertex) vertex { return vertex{v[0], v[1], m.Bottom} }
+
+	// The edge of the top, counterclockwise seen from above.
+	var edge []vertex
+	for column := 0; column < m.Columns; column++ {
+		edge = append(edge, top(column, 0))
+	}
+	for row := 1; row < m.Rows; row++ {
+		edge = append(edge, top(m.Columns-1, row))
+	}
+	for column := m.Columns - 2; column >= 0; column-- {
+		edge = append(edge, top(column, m.Rows-1))
+	}
+	for row := m.Rows - 2; row > 0; row-- {
+		edge = append(edge, top(0, row))
+	}
+
+	var triangles [][3]vertex
+	for row := 0; row+1 < m.Rows; row++ {
+		for column := 0; column+1 < m.Columns; column++ {
+			a, b := top(column, row), top(column+1, row)
+			c, d := top(column+1, row+1), top(column, row+1)
+			triangles = append(triangles, [3]vertex{a, b, c}, [3]vertex{a, c, d})
+		}
+	}
+	if len(edge) > 2 {
+		x1, y1 := m.Center(0, 0)
+		x2, y2 := m.Center(m.Columns-1, m.Rows-1)
+		center := vertex{(x1 + x2) / 2.0, (y1 + y2) / 2.0, m.Bottom}
+		for n := range edge {
+			a, b := edge[n], edge[(n+1)%len(edge)]
+			triangles = append(triangles, [3]vertex{a, bottom(a), bottom(b)}, [3]vertex{a, bottom(b), b},
+				[3]vertex{center, bottom(b), bottom(a)})
+		}
+	}
+
+	writer := bufio.NewWriter(out)
+	header := make([]byte, 80)
+	copy(header, "stock simulated by rs274ngc")
+	writer.Write(header)
+	binary.Write(writer, binary.LittleEndian, uint32(len(triangles)))
+	for _, t := range triangles {
+		u := vertex{t[1][0] - t[0][0], t[1][1] - t[0][1], t[1][2] - t[0][2]}
+		v := vertex{t[2][0] - t[0][0], t[2][1] - t[0][1], t[2][2] - t[0][2]}
+		normal := vertex{(u[1] * v[2]) - (u[2] * v[1]), (u[2] * v[0]) - (u[0] * v[2]), (u[0] * v[1]) - (u[1] * v[0])}
+		if length := math.Sqrt((normal[0] * normal[0]) + (normal[1] * normal[1]) + (normal[2] * normal[2])); length > 0.0 {
+			normal = vertex{normal[0] / length, normal[1] / length, normal[2] / length}
+		}
+		var record [12]float32
+		for n, value := range [][3]float64{normal, t[0], t[1], t[2]} {
+			for k := range value {
+				record[(3*n)+k] = float32(value[k])
+			}
+		}
+		binary.Write(writer, binary.LittleEndian, record)
+		binary.Write(writer, binary.LittleEndian, uint16(0))
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,186 @@
+package heightmap
+
+import (
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* simulate.go
+
+   Simulator_t is a Canon_i which simulates cutting the stock with the
+   tool path of a program, on a Map_t, for a look at the part before it
+   is cut. It keeps the moves (see toolpath.Path_t) and cuts them when
+   Simulate is called.
+
+   The tool is swept along each move, a tool tip at a time no more than
+   half a cell apart. The diameter of the tool is that in the tool table
+   (see GET_EXTERNAL_TOOL_TABLE) of the tool in the spindle, and its
+   shape is that given in the options, a flat end mill if none is. The
+   position of the move is that of the tip of the tool, as it is if the
+   program uses tool length offsets.
+
+   A traverse which cuts the stock is reported as a crash, but the stock
+   is cut as if it were a feed.
+
+   All moves are simulated in the coordinates and length units of the
+   first move (see toolpath.Fold), and the stock, the cell width and the
+   tool table are taken to be in those too.
+
+*/
+
+// Shape is the shape of the end of a tool.
+type Shape int
+
+const (
+	SHAPE_FLAT Shape = iota + 1 // flat end mill
+	SHAPE_BALL                  // ball end mill
+)
+
+type Options_t struct {
+	Stock  toolpath.Box_t // the uncut stock
+	Cell   float64        // width of a cell, 0 means a 256th of the wider side of the stock
+	Shapes map[int]Shape  // by tool pocket, SHAPE_FLAT if not given
+}
+
+// Crash_t is a traverse which cut the stock.
+type Crash_t struct {
+	Line  int                // sequence number of the line, 0 if no Source
+	Tool  int                // pocket of the tool in the spindle
+	At    inc.CANON_POSITION // tip of the tool where the cut was deepest
+	Depth float64            // how far the stock was above the tool there
+}
+
+// Result_t is what a simulation found.
+type Result_t struct {
+	Map     *Map_t    // the stock left
+	Removed float64   // volume of stock cut away
+	Crashes []Crash_t // in the order made
+}
+
+type Simulator_t struct {
+	toolpath.Path_t
+
+	Options Options_t
+}
+
+var _ inc.Canon_i = &Simulator_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Simulator_t with options, no moves, and the world
+   model of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New(options Options_t) *Simulator_t {
+	return &Simulator_t{Options: options}
+}
+
+/* Simulate
+
+   Returned Value: the result of cutting the stock with the moves made
+   so far, with the tools in the tool table of the world model
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (s *Simulator_t) Simulate() *Result_t {
+	return Simulate(s.Moves, s, s.Options)
+}
+
+/* Simulate
+
+   Returned Value: the result of cutting the stock in options with
+   moves, with the tools in tools
+
+   Side effects: none
+
+   Called by: Simulator_t.Simulate, external programs
+
+*/
+
+func Simulate(moves []toolpath.Move_t, tools inc.Tool_table_i, options Options_t) *Result_t {
+	moves = toolpath.Fold(moves)
+	cell := options.Cell
+	if cell <= 0.0 {
+		stock := options.Stock
+		cell = math.Max(stock.Max.X-stock.Min.X, stock.Max.Y-stock.Min.Y) / 256.0
+	}
+	result := &Result_t{Map: New_map(options.Stock, cell)}
+	before := result.Map.Volume()
+
+	for n := range moves {
+		move := &moves[n]
+		shape := options.Shapes[move.Tool]
+		radius := tools.GET_EXTERNAL_TOOL_TABLE(move.Tool).Diameter / 2.0
+		crash := sweep(result.Map, move, radius, shape == SHAPE_BALL)
+		if (move.Kind == toolpath.MOVE_TRAVERSE) && (crash.Depth > 0.0) {
+			crash.Line, crash.Tool = move.Line, move.Tool
+			result.Crashes = append(result.Crashes, crash)
+		}
+	}
+	result.Removed = before - result.Map.Volume()
+	return result
+}
+
+/* sweep
+
+   Returned Value: for a traverse, where and how far the tool went into
+   the stock, as a Crash_t with At and Depth set, Depth 0 if it did not;
+   for other moves, a Crash_t of Depth 0
+
+   Side effects: the stock in m under the tool along the move is cut.
+
+   Called by: Simulate
+
+   An arc is first cut into chords within a quarter of a cell of it, and
+   the tool is put down at both ends of each line and often enough in
+   between that no two places in a row are more than half a cell apart.
+   A traverse is first swept dry, to find how deep it goes before it
+   cuts anything away.
+
+*/
+
+func sweep(m *Map_t, move *toolpath.Move_t, radius float64, ball bool) Crash_t {
+	var deepest Crash_t
+	if move.Kind == toolpath.MOVE_TRAVERSE {
+		along(move, m.Cell, func(point inc.CANON_POSITION) {
+			if depth := m.cut(point.X, point.Y, point.Z, radius, ball, true); depth > deepest.Depth {
+				deepest.At, deepest.Depth = point, depth
+			}
+		})
+	}
+	along(move, m.Cell, func(point inc.CANON_POSITION) {
+		m.cut(point.X, point.Y, point.Z, radius, ball, false)
+	})
+	return deepest
+}
+
+// along calls visit with the places along the move the tool is put
+// down, for cells of width cell.
+func along(move *toolpath.Move_t, cell float64, visit func(point inc.CANON_POSITION)) {
+	points := move.Points(cell / 4.0)
+	visit(points[0])
+	for n := 1; n < len(points); n++ {
+		from, to := points[n-1], points[n]
+		length := math.Sqrt(((to.X - from.X) * (to.X - from.X)) + ((to.Y - from.Y) * (to.Y - from.Y)) +
+			((to.Z - from.Z) * (to.Z - from.Z)))
+		steps := int(math.Ceil(length / (cell / 2.0)))
+		for step := 1; step <= steps; step++ {
+			t := float64(step) / float64(steps)
+			visit(inc.CANON_POSITION{X: from.X + ((to.X - from.X) * t), Y: from.Y + ((to.Y - from.Y) * t),
+				Z: from.Z + ((to.Z - from.Z) * t)})
+		}
+	}
+}
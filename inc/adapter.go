@@ -0,0 +1,607 @@
+package inc
+
+/* Canon_default_t and Canon_adapter_t
+
+   Canon_default_t is a Canon_i that does nothing. Its world-give-information
+   functions describe a machine at rest at the origin, in millimeters, in
+   the XY-plane, with no tools and an empty queue, and with no parameter
+   file name, so the default file is used. It may be embedded in a type
+   that has only the functions it cares about.
+
+   Canon_adapter_t makes a Canon_i out of parts, each of which has one or
+   more of the interfaces Canon_i is made of (Canon_motion_i and so on).
+   The parts missing are filled in from Canon_default_t.
+
+*/
+
+type Canon_default_t struct {
+}
+
+var _ Canon_i = Canon_default_t{}
+
+func (Canon_default_t) COMMENT(s string) {
+}
+
+func (Canon_default_t) DISABLE_FEED_OVERRIDE() {
+}
+
+func (Canon_default_t) DISABLE_SPEED_OVERRIDE() {
+}
+
+func (Canon_default_t) ENABLE_FEED_OVERRIDE() {
+}
+
+func (Canon_default_t) ENABLE_SPEED_OVERRIDE() {
+}
+
+func (Canon_default_t) INIT_CANON() {
+}
+
+func (Canon_default_t) MESSAGE(s []byte) {
+}
+
+func (Canon_default_t) PALLET_SHUTTLE() {
+}
+
+func (Canon_default_t) OPTIONAL_PROGRAM_STOP() {
+}
+
+func (Canon_default_t) PROGRAM_END() {
+}
+
+func (Canon_default_t) PROGRAM_STOP() {
+}
+
+func (Canon_default_t) SELECT_PLANE(plane CANON_PLANE) {
+}
+
+func (Canon_default_t) SET_FEED_RATE(rate float64) {
+}
+
+func (Canon_default_t) SET_FEED_REFERENCE(reference CANON_FEED_REFERENCE) {
+}
+
+func (Canon_default_t) SET_MOTION_CONTROL_MODE(mode CANON_MOTION_MODE) {
+}
+
+func (Canon_default_t) START_SPEED_FEED_SYNCH() {
+}
+
+func (Canon_default_t) STOP_SPEED_FEED_SYNCH() {
+}
+
+func (Canon_default_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+}
+
+func (Canon_default_t) DWELL(seconds float64) {
+}
+
+func (Canon_default_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+}
+
+func (Canon_default_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+}
+
+func (Canon_default_t) USE_LENGTH_UNITS(in_unit CANON_UNITS) {
+}
+
+func (Canon_default_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+}
+
+func (Canon_default_t) ORIENT_SPINDLE(orientation float64, direction CANON_DIRECTION) {
+}
+
+func (Canon_default_t) SET_SPINDLE_SPEED(r float64) {
+}
+
+func (Canon_default_t) START_SPINDLE_CLOCKWISE() {
+}
+
+func (Canon_default_t) START_SPINDLE_COUNTERCLOCKWISE() {
+}
+
+func (Canon_default_t) STOP_SPINDLE_TURNING() {
+}
+
+func (Canon_default_t) FLOOD_OFF() {
+}
+
+func (Canon_default_t) FLOOD_ON() {
+}
+
+func (Canon_default_t) MIST_OFF() {
+}
+
+func (Canon_default_t) MIST_ON() {
+}
+
+func (Canon_default_t) CHANGE_TOOL(slot int) {
+}
+
+func (Canon_default_t) SELECT_TOOL(i int) {
+}
+
+func (Canon_default_t) USE_TOOL_LENGTH_OFFSET(offset float64) {
+}
+
+func (Canon_default_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+}
+
+func (Canon_default_t) TURN_PROBE_OFF() {
+}
+
+func (Canon_default_t) TURN_PROBE_ON() {
+}
+
+func (Canon_default_t) GET_EXTERNAL_ANGLE_UNIT_FACTOR() float64 {
+	return 1.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_FEED_RATE() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_FLOOD() int {
+	return 0
+}
+
+func (Canon_default_t) GET_EXTERNAL_LENGTH_UNIT_FACTOR() float64 {
+	return 1.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_LENGTH_UNIT_TYPE() CANON_UNITS {
+	return CANON_UNITS_MM
+}
+
+func (Canon_default_t) GET_EXTERNAL_MIST() int {
+	return 0
+}
+
+func (Canon_default_t) GET_EXTERNAL_MOTION_CONTROL_MODE() CANON_MOTION_MODE {
+	return CANON_CONTINUOUS
+}
+
+func (Canon_default_t) GET_EXTERNAL_PARAMETER_FILE_NAME() string {
+	return ""
+}
+
+func (Canon_default_t) GET_EXTERNAL_PLANE() CANON_PLANE {
+	return CANON_PLANE_XY
+}
+
+func (Canon_default_t) GET_EXTERNAL_POSITION_A() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_POSITION_B() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_POSITION_C() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_POSITION_X() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_POSITION_Y() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_POSITION_Z() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_PROBE_VALUE() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_PROBE_POSITION_A() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_PROBE_POSITION_B() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_PROBE_POSITION_C() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_PROBE_POSITION_X() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_PROBE_POSITION_Y() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_PROBE_POSITION_Z() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_SPEED() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_SPINDLE() CANON_DIRECTION {
+	return CANON_STOPPED
+}
+
+func (Canon_default_t) GET_EXTERNAL_TOOL_LENGTH_OFFSET() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_TOOL_MAX() int {
+	return 0
+}
+
+func (Canon_default_t) GET_EXTERNAL_TOOL_SLOT() int {
+	return 0
+}
+
+func (Canon_default_t) GET_EXTERNAL_TOOL_TABLE(pocket int) CANON_TOOL_TABLE {
+	return CANON_TOOL_TABLE{}
+}
+
+func (Canon_default_t) GET_EXTERNAL_TRAVERSE_RATE() float64 {
+	return 0.0
+}
+
+func (Canon_default_t) GET_EXTERNAL_QUEUE_EMPTY() int {
+	return 1
+}
+
+type Canon_adapter_t struct {
+	Program Canon_program_i
+	Motion  Canon_motion_i
+	Spindle Canon_spindle_i
+	Coolant Canon_coolant_i
+	Tooling Canon_tooling_i
+	Probe   Canon_probe_i
+	World   Canon_world_i
+}
+
+var _ Canon_i = &Canon_adapter_t{}
+
+/***********************************************************************/
+
+/* New_canon_adapter
+
+   Returned Value: *Canon_adapter_t
+
+   Side effects: none
+
+   Called by: external programs, rs274ngc_t.SetCanonParts
+
+   Each interface of the adapter is taken from the first of the parts
+   that has it, found with a type assertion. Parts of no use are ignored.
+   Interfaces none of the parts have are filled in from Canon_default_t.
+
+*/
+
+func New_canon_adapter(parts ...interface{}) *Canon_adapter_t {
+	a := &Canon_adapter_t{}
+	for n := len(parts) - 1; n >= 0; n-- { /* the first part wins */
+		if p, ok := parts[n].(Canon_program_i); ok {
+			a.Program = p
+		}
+		if p, ok := parts[n].(Canon_motion_i); ok {
+			a.Motion = p
+		}
+		if p, ok := parts[n].(Canon_spindle_i); ok {
+			a.Spindle = p
+		}
+		if p, ok := parts[n].(Canon_coolant_i); ok {
+			a.Coolant = p
+		}
+		if p, ok := parts[n].(Canon_tooling_i); ok {
+			a.Tooling = p
+		}
+		if p, ok := parts[n].(Canon_probe_i); ok {
+			a.Probe = p
+		}
+		if p, ok := parts[n].(Canon_world_i); ok {
+			a.World = p
+		}
+	}
+
+	var none Canon_default_t
+	if a.Program == nil {
+		a.Program = none
+	}
+	if a.Motion == nil {
+		a.Motion = none
+	}
+	if a.Spindle == nil {
+		a.Spindle = none
+	}
+	if a.Coolant == nil {
+		a.Coolant = none
+	}
+	if a.Tooling == nil {
+		a.Tooling = none
+	}
+	if a.Probe == nil {
+		a.Probe = none
+	}
+	if a.World == nil {
+		a.World = none
+	}
+	return a
+}
+
+func (adapter *Canon_adapter_t) COMMENT(s string) {
+	adapter.Program.COMMENT(s)
+}
+
+func (adapter *Canon_adapter_t) DISABLE_FEED_OVERRIDE() {
+	adapter.Program.DISABLE_FEED_OVERRIDE()
+}
+
+func (adapter *Canon_adapter_t) DISABLE_SPEED_OVERRIDE() {
+	adapter.Program.DISABLE_SPEED_OVERRIDE()
+}
+
+func (adapter *Canon_adapter_t) ENABLE_FEED_OVERRIDE() {
+	adapter.Program.ENABLE_FEED_OVERRIDE()
+}
+
+func (adapter *Canon_adapter_t) ENABLE_SPEED_OVERRIDE() {
+	adapter.Program.ENABLE_SPEED_OVERRIDE()
+}
+
+func (adapter *Canon_adapter_t) INIT_CANON() {
+	adapter.Program.INIT_CANON()
+}
+
+func (adapter *Canon_adapter_t) MESSAGE(s []byte) {
+	adapter.Program.MESSAGE(s)
+}
+
+func (adapter *Canon_adapter_t) PALLET_SHUTTLE() {
+	adapter.Program.PALLET_SHUTTLE()
+}
+
+func (adapter *Canon_adapter_t) OPTIONAL_PROGRAM_STOP() {
+	adapter.Program.OPTIONAL_PROGRAM_STOP()
+}
+
+func (adapter *Canon_adapter_t) PROGRAM_END() {
+	adapter.Program.PROGRAM_END()
+}
+
+func (adapter *Canon_adapter_t) PROGRAM_STOP() {
+	adapter.Program.PROGRAM_STOP()
+}
+
+func (adapter *Canon_adapter_t) SELECT_PLANE(plane CANON_PLANE) {
+	adapter.Motion.SELECT_PLANE(plane)
+}
+
+func (adapter *Canon_adapter_t) SET_FEED_RATE(rate float64) {
+	adapter.Motion.SET_FEED_RATE(rate)
+}
+
+func (adapter *Canon_adapter_t) SET_FEED_REFERENCE(reference CANON_FEED_REFERENCE) {
+	adapter.Motion.SET_FEED_REFERENCE(reference)
+}
+
+func (adapter *Canon_adapter_t) SET_MOTION_CONTROL_MODE(mode CANON_MOTION_MODE) {
+	adapter.Motion.SET_MOTION_CONTROL_MODE(mode)
+}
+
+func (adapter *Canon_adapter_t) START_SPEED_FEED_SYNCH() {
+	adapter.Motion.START_SPEED_FEED_SYNCH()
+}
+
+func (adapter *Canon_adapter_t) STOP_SPEED_FEED_SYNCH() {
+	adapter.Motion.STOP_SPEED_FEED_SYNCH()
+}
+
+func (adapter *Canon_adapter_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+	adapter.Motion.ARC_FEED(first_end, second_end, first_axis, second_axis, rotation, axis_end_point, a, b, c)
+}
+
+func (adapter *Canon_adapter_t) DWELL(seconds float64) {
+	adapter.Motion.DWELL(seconds)
+}
+
+func (adapter *Canon_adapter_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	adapter.Motion.STRAIGHT_FEED(x, y, z, a, b, c)
+}
+
+func (adapter *Canon_adapter_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	adapter.Motion.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+}
+
+func (adapter *Canon_adapter_t) USE_LENGTH_UNITS(in_unit CANON_UNITS) {
+	adapter.Motion.USE_LENGTH_UNITS(in_unit)
+}
+
+func (adapter *Canon_adapter_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	adapter.Motion.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+}
+
+func (adapter *Canon_adapter_t) ORIENT_SPINDLE(orientation float64, direction CANON_DIRECTION) {
+	adapter.Spindle.ORIENT_SPINDLE(orientation, direction)
+}
+
+func (adapter *Canon_adapter_t) SET_SPINDLE_SPEED(r float64) {
+	adapter.Spindle.SET_SPINDLE_SPEED(r)
+}
+
+func (adapter *Canon_adapter_t) START_SPINDLE_CLOCKWISE() {
+	adapter.Spindle.START_SPINDLE_CLOCKWISE()
+}
+
+func (adapter *Canon_adapter_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	adapter.Spindle.START_SPINDLE_COUNTERCLOCKWISE()
+}
+
+func (adapter *Canon_adapter_t) STOP_SPINDLE_TURNING() {
+	adapter.Spindle.STOP_SPINDLE_TURNING()
+}
+
+func (adapter *Canon_adapter_t) FLOOD_OFF() {
+	adapter.Coolant.FLOOD_OFF()
+}
+
+func (adapter *Canon_adapter_t) FLOOD_ON() {
+	adapter.Coolant.FLOOD_ON()
+}
+
+func (adapter *Canon_adapter_t) MIST_OFF() {
+	adapter.Coolant.MIST_OFF()
+}
+
+func (adapter *Canon_adapter_t) MIST_ON() {
+	adapter.Coolant.MIST_ON()
+}
+
+func (adapter *Canon_adapter_t) CHANGE_TOOL(slot int) {
+	adapter.Tooling.CHANGE_TOOL(slot)
+}
+
+func (adapter *Canon_adapter_t) SELECT_TOOL(i int) {
+	adapter.Tooling.SELECT_TOOL(i)
+}
+
+func (adapter *Canon_adapter_t) USE_TOOL_LENGTH_OFFSET(offset float64) {
+	adapter.Tooling.USE_TOOL_LENGTH_OFFSET(offset)
+}
+
+func (adapter *Canon_adapter_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	adapter.Probe.STRAIGHT_PROBE(x, y, z, a, b, c)
+}
+
+func (adapter *Canon_adapter_t) TURN_PROBE_OFF() {
+	adapter.Probe.TURN_PROBE_OFF()
+}
+
+func (adapter *Canon_adapter_t) TURN_PROBE_ON() {
+	adapter.Probe.TURN_PROBE_ON()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_ANGLE_UNIT_FACTOR() float64 {
+	return adapter.World.GET_EXTERNAL_ANGLE_UNIT_FACTOR()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_FEED_RATE() float64 {
+	return adapter.World.GET_EXTERNAL_FEED_RATE()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_FLOOD() int {
+	return adapter.World.GET_EXTERNAL_FLOOD()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_LENGTH_UNIT_FACTOR() float64 {
+	return adapter.World.GET_EXTERNAL_LENGTH_UNIT_FACTOR()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_LENGTH_UNIT_TYPE() CANON_UNITS {
+	return adapter.World.GET_EXTERNAL_LENGTH_UNIT_TYPE()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_MIST() int {
+	return adapter.World.GET_EXTERNAL_MIST()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_MOTION_CONTROL_MODE() CANON_MOTION_MODE {
+	return adapter.World.GET_EXTERNAL_MOTION_CONTROL_MODE()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_PARAMETER_FILE_NAME() string {
+	return adapter.World.GET_EXTERNAL_PARAMETER_FILE_NAME()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_PLANE() CANON_PLANE {
+	return adapter.World.GET_EXTERNAL_PLANE()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_POSITION_A() float64 {
+	return adapter.World.GET_EXTERNAL_POSITION_A()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_POSITION_B() float64 {
+	return adapter.World.GET_EXTERNAL_POSITION_B()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_POSITION_C() float64 {
+	return adapter.World.GET_EXTERNAL_POSITION_C()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_POSITION_X() float64 {
+	return adapter.World.GET_EXTERNAL_POSITION_X()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_POSITION_Y() float64 {
+	return adapter.World.GET_EXTERNAL_POSITION_Y()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_POSITION_Z() float64 {
+	return adapter.World.GET_EXTERNAL_POSITION_Z()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_PROBE_VALUE() float64 {
+	return adapter.World.GET_EXTERNAL_PROBE_VALUE()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_PROBE_POSITION_A() float64 {
+	return adapter.World.GET_EXTERNAL_PROBE_POSITION_A()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_PROBE_POSITION_B() float64 {
+	return adapter.World.GET_EXTERNAL_PROBE_POSITION_B()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_PROBE_POSITION_C() float64 {
+	return adapter.World.GET_EXTERNAL_PROBE_POSITION_C()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_PROBE_POSITION_X() float64 {
+	return adapter.World.GET_EXTERNAL_PROBE_POSITION_X()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_PROBE_POSITION_Y() float64 {
+	return adapter.World.GET_EXTERNAL_PROBE_POSITION_Y()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_PROBE_POSITION_Z() float64 {
+	return adapter.World.GET_EXTERNAL_PROBE_POSITION_Z()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_SPEED() float64 {
+	return adapter.World.GET_EXTERNAL_SPEED()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_SPINDLE() CANON_DIRECTION {
+	return adapter.World.GET_EXTERNAL_SPINDLE()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_TOOL_LENGTH_OFFSET() float64 {
+	return adapter.World.GET_EXTERNAL_TOOL_LENGTH_OFFSET()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_TOOL_MAX() int {
+	return adapter.World.GET_EXTERNAL_TOOL_MAX()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_TOOL_SLOT() int {
+	return adapter.World.GET_EXTERNAL_TOOL_SLOT()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_TOOL_TABLE(pocket int) CANON_TOOL_TABLE {
+	return adapter.World.GET_EXTERNAL_TOOL_TABLE(pocket)
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_TRAVERSE_RATE() float64 {
+	return adapter.World.GET_EXTERNAL_TRAVERSE_RATE()
+}
+
+func (adapter *Canon_adapter_t) GET_EXTERNAL_QUEUE_EMPTY() int {
+	return adapter.World.GET_EXTERNAL_QUEUE_EMPTY()
+}
@@ -233,23 +233,42 @@ type CANON_TOOL_TABLE struct {
 	Diameter float64
 }
 
+// Canon_i is the full set of canonical machining functions the
+// interpreter calls. It is made of the interfaces below, so a machine
+// that only has some of them may implement just those and be completed
+// with defaults by Canon_adapter_t.
 type Canon_i interface {
-	//******Miscellaneous Functions
+	Canon_program_i
+	Canon_motion_i
+	Canon_spindle_i
+	Canon_coolant_i
+	Canon_tooling_i
+	Canon_probe_i
+	Canon_world_i
+}
 
+// Canon_program_i has the miscellaneous and program functions.
+type Canon_program_i interface {
+	//******Miscellaneous Functions
 	COMMENT(s string)
 	DISABLE_FEED_OVERRIDE()
 	DISABLE_SPEED_OVERRIDE()
 	ENABLE_FEED_OVERRIDE()
 	ENABLE_SPEED_OVERRIDE()
-	FLOOD_OFF()
-	FLOOD_ON()
 	INIT_CANON()
 	MESSAGE([]byte)
-	MIST_OFF()
-	MIST_ON()
 	PALLET_SHUTTLE()
 	//******Miscellaneous Functions  END
 
+	//******Program 	Functions
+	OPTIONAL_PROGRAM_STOP()
+	PROGRAM_END()
+	PROGRAM_STOP()
+	//******Program 	Functions END
+}
+
+// Canon_motion_i has the machining attributes and the motion functions.
+type Canon_motion_i interface {
 	//******Machining 	Attributes
 	SELECT_PLANE(plane CANON_PLANE)
 	SET_FEED_RATE(rate float64)
@@ -259,26 +278,6 @@ type Canon_i interface {
 	STOP_SPEED_FEED_SYNCH()
 	//******Machining 	Attributes  END
 
-	//******Spindle Functions
-	ORIENT_SPINDLE(orientation float64, direction CANON_DIRECTION)
-	SET_SPINDLE_SPEED(r float64)
-	START_SPINDLE_CLOCKWISE()
-	START_SPINDLE_COUNTERCLOCKWISE()
-	STOP_SPINDLE_TURNING()
-	//******Spindle Functions END
-
-	//******Program 	Functions
-	OPTIONAL_PROGRAM_STOP()
-	PROGRAM_END()
-	PROGRAM_STOP()
-	//******Program 	Functions END
-
-	//******Tool 	Functions
-	CHANGE_TOOL(slot int)
-	SELECT_TOOL(i int)
-	USE_TOOL_LENGTH_OFFSET(offset float64)
-	//******Tool 	Functions END
-
 	//******Machining 	Functions
 	ARC_FEED(first_end, second_end, first_axis,
 		second_axis float64, rotation int, axis_end_point, a, b, c float64)
@@ -286,19 +285,46 @@ type Canon_i interface {
 	STRAIGHT_FEED(x, y, z, a, b, c float64)
 	//******Machining 	Functions END
 
-	//******Probe 	Functions
-	STRAIGHT_PROBE(x, y, z, a, b, c float64)
-	//******Probe 	Functions END
-
 	//******Free Space	Motion
 	STRAIGHT_TRAVERSE(x, y, z, a, b, c float64)
 
 	USE_LENGTH_UNITS(in_unit CANON_UNITS)
 	SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64)
+}
+
+// Canon_spindle_i has the spindle functions.
+type Canon_spindle_i interface {
+	ORIENT_SPINDLE(orientation float64, direction CANON_DIRECTION)
+	SET_SPINDLE_SPEED(r float64)
+	START_SPINDLE_CLOCKWISE()
+	START_SPINDLE_COUNTERCLOCKWISE()
+	STOP_SPINDLE_TURNING()
+}
 
+// Canon_coolant_i has the coolant functions.
+type Canon_coolant_i interface {
+	FLOOD_OFF()
+	FLOOD_ON()
+	MIST_OFF()
+	MIST_ON()
+}
+
+// Canon_tooling_i has the tool functions.
+type Canon_tooling_i interface {
+	CHANGE_TOOL(slot int)
+	SELECT_TOOL(i int)
+	USE_TOOL_LENGTH_OFFSET(offset float64)
+}
+
+// Canon_probe_i has the probe functions.
+type Canon_probe_i interface {
+	STRAIGHT_PROBE(x, y, z, a, b, c float64)
 	TURN_PROBE_OFF()
 	TURN_PROBE_ON()
+}
 
+// Canon_world_i has the world-give-information functions.
+type Canon_world_i interface {
 	//D.6 World-give-information Functions
 	//This section describes the world-give-information functions. These functions get information for
 	//the Interpreter. They are arranged alphabetically. All function names start with “GET_EXTERNAL_”.
@@ -366,7 +392,7 @@ type Canon_i interface {
 	GET_EXTERNAL_SPINDLE() CANON_DIRECTION
 
 	//Return the current tool length offset.
-	///TODO GET_EXTERNAL_TOOL_LENGTH_OFFSET() float64
+	GET_EXTERNAL_TOOL_LENGTH_OFFSET() float64
 	//returns number of slots in carousel.
 	GET_EXTERNAL_TOOL_MAX() int
 	//Returns the system value for the carousel slot in which the tool currently in the spindle
@@ -383,3 +409,105 @@ type Canon_i interface {
 	// This always returns a valid value
 	GET_EXTERNAL_QUEUE_EMPTY() int
 }
+
+// Canon_ext_i has the NIST canonical functions for clamping an axis,
+// which are not in Canon_i, so a Canon_i written before they were added
+// keeps compiling. The interpreter finds out with a type assertion and
+// calls them, for automatic A-axis clamping (M26, M27), only if they
+// are there.
+type Canon_ext_i interface {
+	CLAMP_AXIS(axis CANON_AXIS)
+	UNCLAMP_AXIS(axis CANON_AXIS)
+}
+
+// Canon_machine_i has the other NIST canonical functions which are not
+// in Canon_i. No code of the language gives them, so the interpreter
+// never calls them, but a program driving a canon directly may;
+// middlewares and fanouts pass them on to the canons which have them,
+// and the recorder and writers keep them.
+type Canon_machine_i interface {
+	//******Free Space	Motion
+	SET_TRAVERSE_RATE(rate float64)
+
+	//******Machining 	Functions
+	STOP()
+
+	//******Spindle Functions
+	SET_SPINDLE_TORQUE(torque float64)
+	SPINDLE_RETRACT()
+	SPINDLE_RETRACT_TRAVERSE()
+	USE_NO_SPINDLE_FORCE()
+	USE_SPINDLE_FORCE(force float64)
+}
+
+// Comp_i has the NIST cutter radius compensation functions, implemented
+// by a Canon_i whose machine does its own compensation. The interpreter
+// finds out with a type assertion and calls them only if compensation
+// is passed through to the machine (see SetCompPassthrough), which it
+// refuses for a canon without them; otherwise it offsets the path
+// itself.
+type Comp_i interface {
+	SET_CUTTER_RADIUS_COMPENSATION(radius float64)
+	START_CUTTER_RADIUS_COMPENSATION(side CANON_SIDE)
+	STOP_CUTTER_RADIUS_COMPENSATION()
+}
+
+// Tcpc_i is implemented by a Canon_i which can carry out tool center
+// point control (G43.4), such as the kinematics layer. The interpreter
+// finds out with a type assertion, so a plain Canon_i need not have it.
+type Tcpc_i interface {
+	START_TOOL_CENTER_POINT_CONTROL()
+	STOP_TOOL_CENTER_POINT_CONTROL()
+
+	// Returns zero if tool center point control is off, non-zero if on.
+	GET_EXTERNAL_TOOL_CENTER_POINT_CONTROL() int
+}
+
+// Blend_i is implemented by a Canon_i which can blend moves in
+// CANON_CONTINUOUS mode within a tolerance, such as the planner. The
+// interpreter finds out with a type assertion and calls it on G64, so a
+// plain Canon_i need not have it.
+type Blend_i interface {
+	// path: how far the tool may leave the programmed path at a corner,
+	// naive: how far straight moves may be from a line to be run as one,
+	// both in the length units in force, 0 for no tolerance given
+	SET_MOTION_CONTROL_TOLERANCE(path, naive float64)
+}
+
+// Feed_mode_i is implemented by a Canon_i which needs to know the feed
+// mode, such as one which breaks moves up and must keep the time of an
+// inverse time move. The interpreter finds out with a type assertion and
+// calls it on G93 and G94, so a plain Canon_i need not have it.
+type Feed_mode_i interface {
+	SET_FEED_MODE(mode FeedMode)
+}
+
+// Fault_i is implemented by a Canon_i which may refuse a command, such
+// as one checking travel limits. The interpreter finds out with a type
+// assertion and asks it after each block, so a plain Canon_i need not
+// have it.
+type Fault_i interface {
+	// Returns RS274NGC_OK if no command has been refused since
+	// INIT_CANON, and otherwise the error code and a description of the
+	// first command refused.
+	GET_EXTERNAL_FAULT() (status STATUS, text string)
+}
+
+// Source_i is what the canons which stamp their output with the line
+// being run need to know about it. The interpreter has it.
+type Source_i interface {
+	SequenceNumber() int
+	LineText() string
+}
+
+// System_i is a Source_i which also tells the coordinate system in use,
+// as the interpreter does.
+type System_i interface {
+	OriginIndex() int
+}
+
+// Tool_table_i is where the diameters and lengths of tools are found,
+// such as a Canon_i.
+type Tool_table_i interface {
+	GET_EXTERNAL_TOOL_TABLE(pocket int) CANON_TOOL_TABLE
+}
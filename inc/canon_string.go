@@ -0,0 +1,107 @@
+package inc
+
+/* Names of the canonical enumerations
+
+   These are the names used in the canonical command log written by the
+   NIST interpreter, such as CANON_PLANE_XY. A value out of range is
+   written as UNKNOWN.
+
+*/
+
+func (side CANON_SIDE) String() string {
+	switch side {
+	case CANON_SIDE_RIGHT:
+		return "CANON_SIDE_RIGHT"
+	case CANON_SIDE_LEFT:
+		return "CANON_SIDE_LEFT"
+	case CANON_SIDE_OFF:
+		return "CANON_SIDE_OFF"
+	}
+	return "UNKNOWN"
+}
+
+func (axis CANON_AXIS) String() string {
+	switch axis {
+	case CANON_AXIS_X:
+		return "CANON_AXIS_X"
+	case CANON_AXIS_Y:
+		return "CANON_AXIS_Y"
+	case CANON_AXIS_Z:
+		return "CANON_AXIS_Z"
+	case CANON_AXIS_A:
+		return "CANON_AXIS_A"
+	case CANON_AXIS_B:
+		return "CANON_AXIS_B"
+	case CANON_AXIS_C:
+		return "CANON_AXIS_C"
+	}
+	return "UNKNOWN"
+}
+
+func (units CANON_UNITS) String() string {
+	switch units {
+	case CANON_UNITS_INCHES:
+		return "CANON_UNITS_INCHES"
+	case CANON_UNITS_MM:
+		return "CANON_UNITS_MM"
+	case CANON_UNITS_CM:
+		return "CANON_UNITS_CM"
+	}
+	return "UNKNOWN"
+}
+
+func (plane CANON_PLANE) String() string {
+	switch plane {
+	case CANON_PLANE_XY:
+		return "CANON_PLANE_XY"
+	case CANON_PLANE_YZ:
+		return "CANON_PLANE_YZ"
+	case CANON_PLANE_XZ:
+		return "CANON_PLANE_XZ"
+	}
+	return "UNKNOWN"
+}
+
+func (mode CANON_MOTION_MODE) String() string {
+	switch mode {
+	case CANON_EXACT_STOP:
+		return "CANON_EXACT_STOP"
+	case CANON_EXACT_PATH:
+		return "CANON_EXACT_PATH"
+	case CANON_CONTINUOUS:
+		return "CANON_CONTINUOUS"
+	}
+	return "UNKNOWN"
+}
+
+func (direction CANON_DIRECTION) String() string {
+	switch direction {
+	case CANON_STOPPED:
+		return "CANON_STOPPED"
+	case CANON_CLOCKWISE:
+		return "CANON_CLOCKWISE"
+	case CANON_COUNTERCLOCKWISE:
+		return "CANON_COUNTERCLOCKWISE"
+	}
+	return "UNKNOWN"
+}
+
+func (reference CANON_FEED_REFERENCE) String() string {
+	switch reference {
+	case CANON_WORKPIECE:
+		return "CANON_WORKPIECE"
+	case CANON_XYZ:
+		return "CANON_XYZ"
+	}
+	return "UNKNOWN"
+}
+
+func (mode CANON_SPEED_FEED_MODE) String() string {
+	switch mode {
+	case CANON_SYNCHED:
+		return "CANON_SYNCHED"
+	case CANON_INDEPENDENT:
+		return "CANON_INDEPENDENT"
+	}
+	return "UNKNOWN"
+}
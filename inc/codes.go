@@ -69,8 +69,13 @@ const (
 	G_38_2        = 382 /*G38.2 straight probe*/
 	G_40          = 400 /*G40------刀具补偿/刀具偏置注销     G40 cancel cutter radius compensation      */
 	G_41          = 410 /*G41------刀具补偿——左             G41 start cutter radius compensation left  */
+	G_41_1        = 411 /*G41.1 start cutter radius compensation left, diameter given by D*/
 	G_42          = 420 /*G42------刀具补偿——右             G42 start cutter radius compensation right */
+	G_42_1        = 421 /*G42.1 start cutter radius compensation right, diameter given by D*/
 	G_43          = 430 /*G43------刀具偏置——正             G43 tool length offset (plus)              */
+	G_43_1        = 431 /*G43.1 dynamic tool length offset, given on the block*/
+	G_43_2        = 432 /*G43.2 apply additional tool length offset*/
+	G_43_4        = 434 /*G43.4 tool length offset with tool center point control*/
 	G_49          = 490 /*G49------刀具偏置0/+                G49 cancel tool length offset*/
 	G_53          = 530 /*G53------直线偏移，注销            G53 motion in machine coordinate system  */
 	G_54          = 540 /*G54------直线偏移x                G54 use preset work coordinate system 1   */
@@ -227,6 +227,14 @@ var _rs274ngc_errors = map[int]string{
 	//NCE_J_WORD_MISSING_IN_ABSOLUTE_CENTER_ARC                                            :
 	//NCE_K_WORD_MISSING_IN_ABSOLUTE_CENTER_ARC                                            :
 	//NCE_S_WORD_MISSING_WITH_G96                                                          :
+	NCE_CANNOT_USE_G43_4_WITHOUT_TCPC_KINEMATICS:/* 202 */ "Cannot use g43.4 without tcpc kinematics",           // convert_tool_length_offset
+	NCE_Z_WORD_MISSING_WITH_G43_1:/* 203 */ "Z word missing with g43.1",                                         // convert_tool_length_offset
+	NCE_ONLY_Z_WORD_ALLOWED_WITH_G43_1_OR_G43_2:/* 204 */ "Only z word allowed with g43.1 or g43.2",             // enhance_block
+	NCE_D_WORD_MISSING_WITH_G41_1_OR_G42_1:/* 205 */ "D word missing with g41.1 or g42.1",                       // convert_cutter_compensation_on
+	NCE_TOOL_ORIENTATION_OUT_OF_RANGE:/* 206 */ "Tool orientation out of range",                                 // convert_cutter_compensation_on
+	NCE_CANNOT_USE_XY_PLANE_WITH_CUTTER_RADIUS_COMP:/* 207 */ "Cannot use xy plane with cutter radius comp",     // convert_set_plane
+	NCE_CUTTER_GOUGING_BETWEEN_LINES_WITH_COMP:/* 208 */ "Cutter gouging between lines with cutter radius comp", // comp_corner, comp_gouge
+	NCE_MOVE_OUTSIDE_MACHINE_LIMITS:/* 209 */ "Move outside machine limits",                                     // rs274ngc_execute (from the canon)
 }
 
 /***********************************************************************/
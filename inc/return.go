@@ -229,11 +229,19 @@ const (
 	NCE_J_WORD_MISSING_IN_ABSOLUTE_CENTER_ARC
 	NCE_K_WORD_MISSING_IN_ABSOLUTE_CENTER_ARC
 	NCE_S_WORD_MISSING_WITH_G96
+	NCE_CANNOT_USE_G43_4_WITHOUT_TCPC_KINEMATICS
+	NCE_Z_WORD_MISSING_WITH_G43_1
+	NCE_ONLY_Z_WORD_ALLOWED_WITH_G43_1_OR_G43_2
+	NCE_D_WORD_MISSING_WITH_G41_1_OR_G42_1
+	NCE_TOOL_ORIENTATION_OUT_OF_RANGE
+	NCE_CANNOT_USE_XY_PLANE_WITH_CUTTER_RADIUS_COMP
+	NCE_CUTTER_GOUGING_BETWEEN_LINES_WITH_COMP
+	NCE_MOVE_OUTSIDE_MACHINE_LIMITS
 )
 
 const (
 	RS274NGC_MIN_ERROR = 3
-	RS274NGC_MAX_ERROR = 201
+	RS274NGC_MAX_ERROR = 209
 )
 
 //If simulate  ?: operator
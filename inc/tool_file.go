@@ -0,0 +1,73 @@
+package inc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+/* Read_tool_file
+
+   Returned Value: error
+   If any of the following errors occur, this returns an error naming
+   the file. Otherwise, it returns nil.
+   1. The file cannot be opened or read.
+   2. No blank line is found.
+   3. A line of data cannot be read.
+   4. A tool slot number is less than 0 or greater than CANON_TOOL_MAX.
+
+   Side Effects:
+   w.Tools is replaced by the tool table in the file, and w.Tool_max is
+   set to the highest slot number in it.
+
+   Called By: external programs
+
+   The file is in the format of the NIST tool file (see
+   example/rs274ngc.tool_default). Everything above the first blank
+   line is read and ignored, so any sort of header material may be used.
+   Everything after the first blank line should be data. Each line of
+   data should have four or more items separated by white space: slot,
+   tool id, tool length offset, and tool diameter. Any other items, such
+   as the holder id and tool description, are not read. Blank lines
+   among the data are skipped.
+
+*/
+
+func (w *Canon_world_t) Read_tool_file(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	tools := make([]CANON_TOOL_TABLE, CANON_TOOL_MAX+1)
+	tool_max := 0
+	scanner := bufio.NewScanner(file)
+	header := true
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if header || (text == "") {
+			header = header && (text != "")
+			continue
+		}
+		var slot, id int
+		var length, diameter float64
+		if n, _ := fmt.Sscanf(text, "%d %d %f %f", &slot, &id, &length, &diameter); n < 4 {
+			return fmt.Errorf("%s:%d: bad tool line %q", filename, line, text)
+		}
+		if (slot < 0) || (slot > CANON_TOOL_MAX) {
+			return fmt.Errorf("%s:%d: tool slot %d out of range", filename, line, slot)
+		}
+		tools[slot] = CANON_TOOL_TABLE{id: id, Length: length, Diameter: diameter}
+		tool_max = If(slot > tool_max, slot, tool_max).(int)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	if header {
+		return fmt.Errorf("%s: no blank line before the tool data", filename)
+	}
+	w.Tools, w.Tool_max = tools[:tool_max+1], tool_max
+	return nil
+}
@@ -28,7 +28,7 @@ const (
 	RS274NGC_TEXT_SIZE = 256
 	// array sizes
 	RS274NGC_ACTIVE_G_CODES  = 12
-	RS274NGC_ACTIVE_M_CODES  = 7
+	RS274NGC_ACTIVE_M_CODES  = 8
 	RS274NGC_ACTIVE_SETTINGS = 3
 	// number of parameters in parameter table
 	RS274NGC_MAX_PARAMETERS = 5400
@@ -0,0 +1,284 @@
+package inc
+
+/* Canon_world_t
+
+   Canon_world_t is a Canon_i which does nothing but keep the world model
+   the canonical commands imply, so its world-give-information functions
+   answer as a machine that carried the commands out would. It is meant
+   to be embedded by a Canon_i that records or translates commands; such
+   a type calls the Canon_world_t function after doing its own work.
+
+   As in the example canon, positions are in program coordinates: they
+   move with SET_ORIGIN_OFFSETS and are converted by USE_LENGTH_UNITS.
+   The probe position is taken to be the end point of STRAIGHT_PROBE.
+
+   The zero value is a machine at rest at the origin, in millimeters, in
+   the XY-plane, with no tools. Tools and a parameter file name may be
+   set before the interpreter is initialized.
+
+*/
+
+type Canon_world_t struct {
+	Canon_default_t
+
+	Parameter_file_name string             // "" means the default file
+	Tool_max            int                // number of pockets in the carousel
+	Tools               []CANON_TOOL_TABLE // indexed by pocket
+
+	Position           CANON_POSITION // program position
+	Origin             CANON_POSITION // from SET_ORIGIN_OFFSETS
+	Probe_position     CANON_POSITION // at the last probe trip
+	Length_units       CANON_UNITS    // 0 means CANON_UNITS_MM
+	Plane              CANON_PLANE    // 0 means CANON_PLANE_XY
+	Motion_mode        CANON_MOTION_MODE
+	Feed_rate          float64
+	Traverse_rate      float64
+	Speed              float64
+	Spindle            CANON_DIRECTION
+	Flood              bool
+	Mist               bool
+	Tool_slot          int // pocket of the tool in the spindle
+	Tool_length_offset float64
+}
+
+var _ Canon_i = &Canon_world_t{}
+
+/* Commands which change the world model */
+
+func (w *Canon_world_t) SELECT_PLANE(plane CANON_PLANE) {
+	w.Plane = plane
+}
+
+func (w *Canon_world_t) SET_FEED_RATE(rate float64) {
+	w.Feed_rate = rate
+}
+
+func (w *Canon_world_t) SET_TRAVERSE_RATE(rate float64) {
+	w.Traverse_rate = rate
+}
+
+func (w *Canon_world_t) SET_MOTION_CONTROL_MODE(mode CANON_MOTION_MODE) {
+	w.Motion_mode = mode
+}
+
+func (w *Canon_world_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	w.Position.X = w.Position.X + w.Origin.X - x
+	w.Position.Y = w.Position.Y + w.Origin.Y - y
+	w.Position.Z = w.Position.Z + w.Origin.Z - z
+	w.Position.A = w.Position.A + w.Origin.A - a
+	w.Position.B = w.Position.B + w.Origin.B - b
+	w.Position.C = w.Position.C + w.Origin.C - c
+	w.Origin = CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+}
+
+func (w *Canon_world_t) USE_LENGTH_UNITS(in_unit CANON_UNITS) {
+	if (in_unit != CANON_UNITS_INCHES) && (in_unit != CANON_UNITS_MM) && (in_unit != CANON_UNITS_CM) {
+		return
+	}
+	factor := millimeters(w.GET_EXTERNAL_LENGTH_UNIT_TYPE()) / millimeters(in_unit)
+	w.Length_units = in_unit
+	w.Position.X, w.Position.Y, w.Position.Z =
+		(w.Position.X * factor), (w.Position.Y * factor), (w.Position.Z * factor)
+	w.Origin.X, w.Origin.Y, w.Origin.Z =
+		(w.Origin.X * factor), (w.Origin.Y * factor), (w.Origin.Z * factor)
+}
+
+/* millimeters
+
+   Returned Value: the number of millimeters in a length unit of units
+
+   Side effects: none
+
+   Called by: USE_LENGTH_UNITS, GET_EXTERNAL_LENGTH_UNIT_FACTOR
+
+*/
+
+func millimeters(units CANON_UNITS) float64 {
+	switch units {
+	case CANON_UNITS_INCHES:
+		return 25.4
+	case CANON_UNITS_CM:
+		return 10.0
+	}
+	return 1.0
+}
+
+func (w *Canon_world_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	w.Position = CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+}
+
+func (w *Canon_world_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	w.Position = CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+}
+
+func (w *Canon_world_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	w.Position = CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+	w.Probe_position = w.Position
+}
+
+func (w *Canon_world_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+
+	switch w.GET_EXTERNAL_PLANE() {
+	case CANON_PLANE_YZ:
+		w.Position.X, w.Position.Y, w.Position.Z = axis_end_point, first_end, second_end
+	case CANON_PLANE_XZ:
+		w.Position.X, w.Position.Y, w.Position.Z = second_end, axis_end_point, first_end
+	default:
+		w.Position.X, w.Position.Y, w.Position.Z = first_end, second_end, axis_end_point
+	}
+	w.Position.A, w.Position.B, w.Position.C = a, b, c
+}
+
+func (w *Canon_world_t) SET_SPINDLE_SPEED(r float64) {
+	w.Speed = r
+}
+
+func (w *Canon_world_t) START_SPINDLE_CLOCKWISE() {
+	w.Spindle = CANON_CLOCKWISE
+}
+
+func (w *Canon_world_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	w.Spindle = CANON_COUNTERCLOCKWISE
+}
+
+func (w *Canon_world_t) STOP_SPINDLE_TURNING() {
+	w.Spindle = CANON_STOPPED
+}
+
+func (w *Canon_world_t) FLOOD_OFF() {
+	w.Flood = false
+}
+
+func (w *Canon_world_t) FLOOD_ON() {
+	w.Flood = true
+}
+
+func (w *Canon_world_t) MIST_OFF() {
+	w.Mist = false
+}
+
+func (w *Canon_world_t) MIST_ON() {
+	w.Mist = true
+}
+
+func (w *Canon_world_t) CHANGE_TOOL(slot int) {
+	w.Tool_slot = slot
+}
+
+func (w *Canon_world_t) USE_TOOL_LENGTH_OFFSET(offset float64) {
+	w.Tool_length_offset = offset
+}
+
+/* World-give-information functions */
+
+func (w *Canon_world_t) GET_EXTERNAL_FEED_RATE() float64 {
+	return w.Feed_rate
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_FLOOD() int {
+	return If(w.Flood, 1, 0).(int)
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_LENGTH_UNIT_FACTOR() float64 {
+	return 1.0 / millimeters(w.GET_EXTERNAL_LENGTH_UNIT_TYPE())
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_LENGTH_UNIT_TYPE() CANON_UNITS {
+	return If(w.Length_units == 0, CANON_UNITS_MM, w.Length_units).(CANON_UNITS)
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_MIST() int {
+	return If(w.Mist, 1, 0).(int)
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_MOTION_CONTROL_MODE() CANON_MOTION_MODE {
+	return If(w.Motion_mode == 0, CANON_CONTINUOUS, w.Motion_mode).(CANON_MOTION_MODE)
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_PARAMETER_FILE_NAME() string {
+	return w.Parameter_file_name
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_PLANE() CANON_PLANE {
+	return If(w.Plane == 0, CANON_PLANE_XY, w.Plane).(CANON_PLANE)
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_POSITION_A() float64 {
+	return w.Position.A
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_POSITION_B() float64 {
+	return w.Position.B
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_POSITION_C() float64 {
+	return w.Position.C
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_POSITION_X() float64 {
+	return w.Position.X
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_POSITION_Y() float64 {
+	return w.Position.Y
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_POSITION_Z() float64 {
+	return w.Position.Z
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_PROBE_POSITION_A() float64 {
+	return w.Probe_position.A
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_PROBE_POSITION_B() float64 {
+	return w.Probe_position.B
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_PROBE_POSITION_C() float64 {
+	return w.Probe_position.C
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_PROBE_POSITION_X() float64 {
+	return w.Probe_position.X
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_PROBE_POSITION_Y() float64 {
+	return w.Probe_position.Y
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_PROBE_POSITION_Z() float64 {
+	return w.Probe_position.Z
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_SPEED() float64 {
+	return w.Speed
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_SPINDLE() CANON_DIRECTION {
+	return If(w.Spindle == 0, CANON_STOPPED, w.Spindle).(CANON_DIRECTION)
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_TOOL_LENGTH_OFFSET() float64 {
+	return w.Tool_length_offset
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_TOOL_MAX() int {
+	return w.Tool_max
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_TOOL_SLOT() int {
+	return w.Tool_slot
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_TOOL_TABLE(pocket int) CANON_TOOL_TABLE {
+	if (pocket < 0) || (pocket >= len(w.Tools)) {
+		return CANON_TOOL_TABLE{}
+	}
+	return w.Tools[pocket]
+}
+
+func (w *Canon_world_t) GET_EXTERNAL_TRAVERSE_RATE() float64 {
+	return w.Traverse_rate
+}
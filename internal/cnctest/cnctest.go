@@ -0,0 +1,88 @@
+package cnctest
+
+import (
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc"
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* cnctest.go
+
+   This has what the tests of the packages beside the interpreter share
+   for running NC programs: an interpreter initialized with the example
+   parameter file, and lines read and executed on it.
+
+*/
+
+// Parameter_file_name is the example parameter file, as seen from the
+// directory of a package beside example.
+const Parameter_file_name = "../example/rs274ngc.var"
+
+/* Start
+
+   Returned Value: *rs274ngc.Rs274ngc_t, the interpreter
+
+   Side effects:
+   world is given the example parameter file, canon is given to a new
+   interpreter and the interpreter is initialized. If initializing
+   fails, the test fails at once.
+
+   Called by: the tests
+
+   world is the world model the GET_EXTERNAL_ functions of canon answer
+   from, usually embedded in canon or in the canon canon writes to.
+   Anything else world needs, such as Tool_max or Tools, is set by the
+   caller before this is called.
+
+*/
+
+func Start(t testing.TB, canon inc.Canon_i, world *inc.Canon_world_t) *rs274ngc.Rs274ngc_t {
+	world.Parameter_file_name = Parameter_file_name
+	cnc := &rs274ngc.Rs274ngc_t{}
+	cnc.SetCanon(canon)
+	if status := cnc.Init(); status != inc.RS274NGC_OK {
+		t.Fatalf("Init() = %v", status)
+	}
+	return cnc
+}
+
+/* Execute
+
+   Returned Value: inc.STATUS
+   If reading line fails, this returns what rs274ngc_read returned.
+   Otherwise, it returns what rs274ngc_execute returned.
+
+   Side effects: line is read and, if that works, executed.
+
+   Called by: Run, the tests
+
+*/
+
+func Execute(cnc *rs274ngc.Rs274ngc_t, line string) inc.STATUS {
+	status := cnc.Read([]byte(line))
+	if status == inc.RS274NGC_OK {
+		status = cnc.Execute()
+	}
+	return status
+}
+
+/* Run
+
+   Returned Value: none
+
+   Side effects:
+   lines are executed in order. If one returns anything but
+   RS274NGC_OK or RS274NGC_EXIT, the test fails at once.
+
+   Called by: the tests
+
+*/
+
+func Run(t testing.TB, cnc *rs274ngc.Rs274ngc_t, lines ...string) {
+	for _, line := range lines {
+		if status := Execute(cnc, line); (status != inc.RS274NGC_OK) && (status != inc.RS274NGC_EXIT) {
+			t.Fatalf("%s: status = %v", line, status)
+		}
+	}
+}
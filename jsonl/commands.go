@@ -0,0 +1,234 @@
+package jsonl
+
+import "github.com/flyingyizi/rs274ngc/inc"
+
+/* The canonical commands
+
+   Each updates the world model, then writes itself, so the modal
+   context of its record is the one it leaves in force.
+
+*/
+
+func (w *Writer_t) COMMENT(s string) {
+	w.write("COMMENT", args{"text": s})
+}
+
+func (w *Writer_t) DISABLE_FEED_OVERRIDE() {
+	w.write("DISABLE_FEED_OVERRIDE", nil)
+}
+
+func (w *Writer_t) DISABLE_SPEED_OVERRIDE() {
+	w.write("DISABLE_SPEED_OVERRIDE", nil)
+}
+
+func (w *Writer_t) ENABLE_FEED_OVERRIDE() {
+	w.write("ENABLE_FEED_OVERRIDE", nil)
+}
+
+func (w *Writer_t) ENABLE_SPEED_OVERRIDE() {
+	w.write("ENABLE_SPEED_OVERRIDE", nil)
+}
+
+func (w *Writer_t) INIT_CANON() {
+	w.write("INIT_CANON", nil)
+}
+
+func (w *Writer_t) MESSAGE(s []byte) {
+	w.write("MESSAGE", args{"text": string(s)})
+}
+
+func (w *Writer_t) PALLET_SHUTTLE() {
+	w.write("PALLET_SHUTTLE", nil)
+}
+
+func (w *Writer_t) OPTIONAL_PROGRAM_STOP() {
+	w.write("OPTIONAL_PROGRAM_STOP", nil)
+}
+
+func (w *Writer_t) PROGRAM_END() {
+	w.write("PROGRAM_END", nil)
+}
+
+func (w *Writer_t) PROGRAM_STOP() {
+	w.write("PROGRAM_STOP", nil)
+}
+
+func (w *Writer_t) SELECT_PLANE(plane inc.CANON_PLANE) {
+	w.Canon_world_t.SELECT_PLANE(plane)
+	w.write("SELECT_PLANE", args{"plane": plane.String()})
+}
+
+func (w *Writer_t) SET_FEED_RATE(rate float64) {
+	w.Canon_world_t.SET_FEED_RATE(rate)
+	w.write("SET_FEED_RATE", args{"rate": rate})
+}
+
+func (w *Writer_t) SET_FEED_REFERENCE(reference inc.CANON_FEED_REFERENCE) {
+	w.write("SET_FEED_REFERENCE", args{"reference": reference.String()})
+}
+
+func (w *Writer_t) SET_MOTION_CONTROL_MODE(mode inc.CANON_MOTION_MODE) {
+	w.Canon_world_t.SET_MOTION_CONTROL_MODE(mode)
+	w.write("SET_MOTION_CONTROL_MODE", args{"mode": mode.String()})
+}
+
+func (w *Writer_t) START_SPEED_FEED_SYNCH() {
+	w.write("START_SPEED_FEED_SYNCH", nil)
+}
+
+func (w *Writer_t) STOP_SPEED_FEED_SYNCH() {
+	w.write("STOP_SPEED_FEED_SYNCH", nil)
+}
+
+func (w *Writer_t) SET_CUTTER_RADIUS_COMPENSATION(radius float64) {
+	w.write("SET_CUTTER_RADIUS_COMPENSATION", args{"radius": radius})
+}
+
+func (w *Writer_t) START_CUTTER_RADIUS_COMPENSATION(side inc.CANON_SIDE) {
+	w.write("START_CUTTER_RADIUS_COMPENSATION", args{"side": side.String()})
+}
+
+func (w *Writer_t) STOP_CUTTER_RADIUS_COMPENSATION() {
+	w.write("STOP_CUTTER_RADIUS_COMPENSATION", nil)
+}
+
+func (w *Writer_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+	w.Canon_world_t.ARC_FEED(first_end, second_end, first_axis,
+		second_axis, rotation, axis_end_point, a, b, c)
+	w.write("ARC_FEED", args{"first_end": first_end, "second_end": second_end, "first_axis": first_axis, "second_axis": second_axis, "rotation": rotation, "axis_end_point": axis_end_point, "a": a, "b": b, "c": c})
+}
+
+func (w *Writer_t) DWELL(seconds float64) {
+	w.write("DWELL", args{"seconds": seconds})
+}
+
+func (w *Writer_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	w.Canon_world_t.STRAIGHT_FEED(x, y, z, a, b, c)
+	w.write("STRAIGHT_FEED", args{"x": x, "y": y, "z": z, "a": a, "b": b, "c": c})
+}
+
+func (w *Writer_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	w.Canon_world_t.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+	w.write("STRAIGHT_TRAVERSE", args{"x": x, "y": y, "z": z, "a": a, "b": b, "c": c})
+}
+
+func (w *Writer_t) USE_LENGTH_UNITS(in_unit inc.CANON_UNITS) {
+	w.Canon_world_t.USE_LENGTH_UNITS(in_unit)
+	w.write("USE_LENGTH_UNITS", args{"units": in_unit.String()})
+}
+
+func (w *Writer_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	w.Canon_world_t.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+	w.write("SET_ORIGIN_OFFSETS", args{"x": x, "y": y, "z": z, "a": a, "b": b, "c": c})
+}
+
+func (w *Writer_t) ORIENT_SPINDLE(orientation float64, direction inc.CANON_DIRECTION) {
+	w.write("ORIENT_SPINDLE", args{"orientation": orientation, "direction": direction.String()})
+}
+
+func (w *Writer_t) SET_SPINDLE_SPEED(rpm float64) {
+	w.Canon_world_t.SET_SPINDLE_SPEED(rpm)
+	w.write("SET_SPINDLE_SPEED", args{"rpm": rpm})
+}
+
+func (w *Writer_t) START_SPINDLE_CLOCKWISE() {
+	w.Canon_world_t.START_SPINDLE_CLOCKWISE()
+	w.write("START_SPINDLE_CLOCKWISE", nil)
+}
+
+func (w *Writer_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	w.Canon_world_t.START_SPINDLE_COUNTERCLOCKWISE()
+	w.write("START_SPINDLE_COUNTERCLOCKWISE", nil)
+}
+
+func (w *Writer_t) STOP_SPINDLE_TURNING() {
+	w.Canon_world_t.STOP_SPINDLE_TURNING()
+	w.write("STOP_SPINDLE_TURNING", nil)
+}
+
+func (w *Writer_t) FLOOD_OFF() {
+	w.Canon_world_t.FLOOD_OFF()
+	w.write("FLOOD_OFF", nil)
+}
+
+func (w *Writer_t) FLOOD_ON() {
+	w.Canon_world_t.FLOOD_ON()
+	w.write("FLOOD_ON", nil)
+}
+
+func (w *Writer_t) MIST_OFF() {
+	w.Canon_world_t.MIST_OFF()
+	w.write("MIST_OFF", nil)
+}
+
+func (w *Writer_t) MIST_ON() {
+	w.Canon_world_t.MIST_ON()
+	w.write("MIST_ON", nil)
+}
+
+func (w *Writer_t) CHANGE_TOOL(slot int) {
+	w.Canon_world_t.CHANGE_TOOL(slot)
+	w.write("CHANGE_TOOL", args{"slot": slot})
+}
+
+func (w *Writer_t) SELECT_TOOL(slot int) {
+	w.write("SELECT_TOOL", args{"slot": slot})
+}
+
+func (w *Writer_t) USE_TOOL_LENGTH_OFFSET(length float64) {
+	w.Canon_world_t.USE_TOOL_LENGTH_OFFSET(length)
+	w.write("USE_TOOL_LENGTH_OFFSET", args{"length": length})
+}
+
+func (w *Writer_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	w.Canon_world_t.STRAIGHT_PROBE(x, y, z, a, b, c)
+	w.write("STRAIGHT_PROBE", args{"x": x, "y": y, "z": z, "a": a, "b": b, "c": c})
+}
+
+func (w *Writer_t) TURN_PROBE_OFF() {
+	w.write("TURN_PROBE_OFF", nil)
+}
+
+func (w *Writer_t) TURN_PROBE_ON() {
+	w.write("TURN_PROBE_ON", nil)
+}
+
+/* The canonical extensions */
+
+func (w *Writer_t) SET_TRAVERSE_RATE(rate float64) {
+	w.Canon_world_t.SET_TRAVERSE_RATE(rate)
+	w.write("SET_TRAVERSE_RATE", args{"rate": rate})
+}
+
+func (w *Writer_t) STOP() {
+	w.write("STOP", nil)
+}
+
+func (w *Writer_t) SET_SPINDLE_TORQUE(torque float64) {
+	w.write("SET_SPINDLE_TORQUE", args{"torque": torque})
+}
+
+func (w *Writer_t) SPINDLE_RETRACT() {
+	w.write("SPINDLE_RETRACT", nil)
+}
+
+func (w *Writer_t) SPINDLE_RETRACT_TRAVERSE() {
+	w.write("SPINDLE_RETRACT_TRAVERSE", nil)
+}
+
+func (w *Writer_t) USE_NO_SPINDLE_FORCE() {
+	w.write("USE_NO_SPINDLE_FORCE", nil)
+}
+
+func (w *Writer_t) USE_SPINDLE_FORCE(force float64) {
+	w.write("USE_SPINDLE_FORCE", args{"force": force})
+}
+
+func (w *Writer_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
+	w.write("CLAMP_AXIS", args{"axis": axis.String()})
+}
+
+func (w *Writer_t) UNCLAMP_AXIS(axis inc.CANON_AXIS) {
+	w.write("UNCLAMP_AXIS", args{"axis": axis.String()})
+}
@@ -0,0 +1,120 @@
+package jsonl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/jsonl"
+	"github.com/flyingyizi/rs274ngc/record"
+)
+
+var program = []string{
+	"g20 g0 x1 y2 z0.5",
+	"g1 f10 x3",
+	"g19 g2 y3 z1.5 j0.5 k0.5",
+	"m3 m7",
+	"m26",
+}
+
+func TestReplay(t *testing.T) {
+	direct := record.New()
+	cnctest.Run(t, cnctest.Start(t, direct, &direct.Canon_world_t), program...)
+
+	var out bytes.Buffer
+	w := jsonl.New(&out)
+	cnc := cnctest.Start(t, w, &w.Canon_world_t)
+	w.Source = cnc
+	cnctest.Run(t, cnc, program...)
+	if w.Err != nil {
+		t.Fatalf("Err = %v", w.Err)
+	}
+
+	replayed := record.New()
+	if err := jsonl.Replay(&out, replayed); err != nil {
+		t.Fatalf("Replay() = %v", err)
+	}
+	if !reflect.DeepEqual(replayed.Strings(), direct.Strings()) {
+		t.Errorf("replayed:\n%s\nwant:\n%s",
+			strings.Join(replayed.Strings(), "\n"), strings.Join(direct.Strings(), "\n"))
+	}
+}
+
+func TestWriter_Record(t *testing.T) {
+	var out bytes.Buffer
+	w := jsonl.New(&out)
+	cnc := cnctest.Start(t, w, &w.Canon_world_t)
+	out.Reset()
+	w.Source = cnc
+	cnctest.Run(t, cnc, program...)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	var feed jsonl.Record_t
+	if err := json.Unmarshal([]byte(lines[3]), &feed); err != nil {
+		t.Fatalf("%s: %v", lines[3], err)
+	}
+	want := jsonl.Record_t{
+		Seq:     8,
+		Line:    2,
+		Text:    "g1 f10 x3",
+		Command: "STRAIGHT_FEED",
+		Args:    map[string]interface{}{"x": 3.0, "y": 2.0, "z": 0.5, "a": 0.0, "b": 0.0, "c": 0.0},
+		Context: jsonl.Context_t{
+			Units:       "CANON_UNITS_INCHES",
+			Plane:       "CANON_PLANE_XY",
+			Motion_mode: "CANON_CONTINUOUS",
+			Feed_rate:   10,
+			Spindle:     "CANON_STOPPED",
+		},
+	}
+	if !reflect.DeepEqual(feed, want) {
+		t.Errorf("record 8 = %+v, want %+v", feed, want)
+	}
+}
+
+func TestReplay_errors(t *testing.T) {
+	for _, test := range []struct {
+		stream string
+		err    string
+	}{
+		{`{"command":"FLOOD_ON"}` + "\n" + `{"command":"FLY"}`, "line 2: unknown command \"FLY\""},
+		{`{"command":"DWELL","args":{}}`, "line 1: DWELL: missing argument seconds"},
+		{`{"command":"CHANGE_TOOL","args":{"slot":1.5}}`, "line 1: CHANGE_TOOL: argument slot is 1.5 of type float64"},
+		{`{"command":"SELECT_PLANE","args":{"plane":"XY"}}`, "line 1: SELECT_PLANE: argument plane is XY of type string"},
+		{`[1, 2]`, "line 1: json: cannot unmarshal array into Go value of type jsonl.Record_t"},
+	} {
+		rec := record.New()
+		err := jsonl.Replay(strings.NewReader(test.stream), rec)
+		if (err == nil) || (err.Error() != test.err) {
+			t.Errorf("Replay(%s) = %v, want %s", test.stream, err, test.err)
+		}
+		if (len(rec.Calls) != 0) && (rec.Calls[0].Name != "FLOOD_ON") {
+			t.Errorf("Replay(%s) gave %v", test.stream, rec.Strings())
+		}
+	}
+}
+
+func TestReplay_machine_functions(t *testing.T) {
+	// The NIST functions outside Canon_i are written and replayed too.
+	var out bytes.Buffer
+	w := jsonl.New(&out)
+	w.SET_TRAVERSE_RATE(500)
+	w.STOP()
+	w.SET_SPINDLE_TORQUE(2)
+	w.SPINDLE_RETRACT()
+	w.SPINDLE_RETRACT_TRAVERSE()
+	w.USE_NO_SPINDLE_FORCE()
+	w.USE_SPINDLE_FORCE(3)
+	replayed := record.New()
+	if err := jsonl.Replay(&out, replayed); err != nil {
+		t.Fatalf("Replay() = %v", err)
+	}
+	want := []string{"SET_TRAVERSE_RATE(500.0000)", "STOP()", "SET_SPINDLE_TORQUE(2.0000)", "SPINDLE_RETRACT()",
+		"SPINDLE_RETRACT_TRAVERSE()", "USE_NO_SPINDLE_FORCE()", "USE_SPINDLE_FORCE(3.0000)"}
+	if got := replayed.Strings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed %v, want %v", got, want)
+	}
+}
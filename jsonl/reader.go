@@ -0,0 +1,369 @@
+package jsonl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* reader.go
+
+   Replay reads a stream written by Writer_t and gives each command to
+   another Canon_i, as the interpreter would have. The line, text, and
+   context of the records are not used.
+
+   The canonical extensions and the cutter radius compensation
+   functions are given to the Canon_i only if it has them (see
+   inc.Canon_ext_i, inc.Canon_machine_i and inc.Comp_i); otherwise they
+   are skipped.
+
+*/
+
+/***********************************************************************/
+
+/* Replay
+
+   Returned Value: error
+   If any of the following errors occur, this returns an error naming
+   the line of the stream it occurred on. Otherwise, it returns nil.
+   1. A line is not a JSON record.
+   2. Apply returns an error for a record.
+   3. in cannot be read.
+
+   Side effects: the commands of the records up to any error are given
+   to canon.
+
+   Called by: external programs
+
+   Blank lines are skipped.
+
+*/
+
+func Replay(in io.Reader, canon inc.Canon_i) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record_t
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if err := Apply(&record, canon); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+/***********************************************************************/
+
+/* Apply
+
+   Returned Value: error
+   If the command of the record is not known, or an argument is missing
+   or of the wrong type, this returns an error saying so. Otherwise, it
+   returns nil.
+
+   Side effects:
+   If there is no error, the command of the record is given to canon.
+
+   Called by:
+   Replay
+   external programs
+
+*/
+
+func Apply(record *Record_t, canon inc.Canon_i) error {
+	ext, _ := canon.(inc.Canon_ext_i)
+	machine, _ := canon.(inc.Canon_machine_i)
+	comp, _ := canon.(inc.Comp_i)
+	r := &arguments_t{command: record.Command, args: record.Args}
+
+	switch record.Command {
+	case "COMMENT":
+		if s := r.string("text"); r.ok() {
+			canon.COMMENT(s)
+		}
+	case "DISABLE_FEED_OVERRIDE":
+		canon.DISABLE_FEED_OVERRIDE()
+	case "DISABLE_SPEED_OVERRIDE":
+		canon.DISABLE_SPEED_OVERRIDE()
+	case "ENABLE_FEED_OVERRIDE":
+		canon.ENABLE_FEED_OVERRIDE()
+	case "ENABLE_SPEED_OVERRIDE":
+		canon.ENABLE_SPEED_OVERRIDE()
+	case "INIT_CANON":
+		canon.INIT_CANON()
+	case "MESSAGE":
+		if s := []byte(r.string("text")); r.ok() {
+			canon.MESSAGE(s)
+		}
+	case "PALLET_SHUTTLE":
+		canon.PALLET_SHUTTLE()
+	case "OPTIONAL_PROGRAM_STOP":
+		canon.OPTIONAL_PROGRAM_STOP()
+	case "PROGRAM_END":
+		canon.PROGRAM_END()
+	case "PROGRAM_STOP":
+		canon.PROGRAM_STOP()
+	case "SELECT_PLANE":
+		if plane := inc.CANON_PLANE(r.enum("plane", canon_plane_names)); r.ok() {
+			canon.SELECT_PLANE(plane)
+		}
+	case "SET_FEED_RATE":
+		if rate := r.float("rate"); r.ok() {
+			canon.SET_FEED_RATE(rate)
+		}
+	case "SET_FEED_REFERENCE":
+		if reference := inc.CANON_FEED_REFERENCE(r.enum("reference", canon_feed_reference_names)); r.ok() {
+			canon.SET_FEED_REFERENCE(reference)
+		}
+	case "SET_MOTION_CONTROL_MODE":
+		if mode := inc.CANON_MOTION_MODE(r.enum("mode", canon_motion_mode_names)); r.ok() {
+			canon.SET_MOTION_CONTROL_MODE(mode)
+		}
+	case "START_SPEED_FEED_SYNCH":
+		canon.START_SPEED_FEED_SYNCH()
+	case "STOP_SPEED_FEED_SYNCH":
+		canon.STOP_SPEED_FEED_SYNCH()
+	case "SET_CUTTER_RADIUS_COMPENSATION":
+		if radius := r.float("radius"); r.ok() {
+			if comp != nil {
+				comp.SET_CUTTER_RADIUS_COMPENSATION(radius)
+			}
+		}
+	case "START_CUTTER_RADIUS_COMPENSATION":
+		if side := inc.CANON_SIDE(r.enum("side", canon_side_names)); r.ok() {
+			if comp != nil {
+				comp.START_CUTTER_RADIUS_COMPENSATION(side)
+			}
+		}
+	case "STOP_CUTTER_RADIUS_COMPENSATION":
+		if comp != nil {
+			comp.STOP_CUTTER_RADIUS_COMPENSATION()
+		}
+	case "ARC_FEED":
+		first_end, second_end := r.float("first_end"), r.float("second_end")
+		first_axis, second_axis := r.float("first_axis"), r.float("second_axis")
+		rotation, axis_end_point := r.int("rotation"), r.float("axis_end_point")
+		if a, b, c := r.float("a"), r.float("b"), r.float("c"); r.ok() {
+			canon.ARC_FEED(first_end, second_end, first_axis,
+				second_axis, rotation, axis_end_point, a, b, c)
+		}
+	case "DWELL":
+		if seconds := r.float("seconds"); r.ok() {
+			canon.DWELL(seconds)
+		}
+	case "STRAIGHT_FEED":
+		if x, y, z, a, b, c := r.xyzabc(); r.ok() {
+			canon.STRAIGHT_FEED(x, y, z, a, b, c)
+		}
+	case "STRAIGHT_TRAVERSE":
+		if x, y, z, a, b, c := r.xyzabc(); r.ok() {
+			canon.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+		}
+	case "USE_LENGTH_UNITS":
+		if in_unit := inc.CANON_UNITS(r.enum("units", canon_units_names)); r.ok() {
+			canon.USE_LENGTH_UNITS(in_unit)
+		}
+	case "SET_ORIGIN_OFFSETS":
+		if x, y, z, a, b, c := r.xyzabc(); r.ok() {
+			canon.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+		}
+	case "ORIENT_SPINDLE":
+		if orientation, direction := r.float("orientation"), inc.CANON_DIRECTION(r.enum("direction", canon_direction_names)); r.ok() {
+			canon.ORIENT_SPINDLE(orientation, direction)
+		}
+	case "SET_SPINDLE_SPEED":
+		if rpm := r.float("rpm"); r.ok() {
+			canon.SET_SPINDLE_SPEED(rpm)
+		}
+	case "START_SPINDLE_CLOCKWISE":
+		canon.START_SPINDLE_CLOCKWISE()
+	case "START_SPINDLE_COUNTERCLOCKWISE":
+		canon.START_SPINDLE_COUNTERCLOCKWISE()
+	case "STOP_SPINDLE_TURNING":
+		canon.STOP_SPINDLE_TURNING()
+	case "FLOOD_OFF":
+		canon.FLOOD_OFF()
+	case "FLOOD_ON":
+		canon.FLOOD_ON()
+	case "MIST_OFF":
+		canon.MIST_OFF()
+	case "MIST_ON":
+		canon.MIST_ON()
+	case "CHANGE_TOOL":
+		if slot := r.int("slot"); r.ok() {
+			canon.CHANGE_TOOL(slot)
+		}
+	case "SELECT_TOOL":
+		if slot := r.int("slot"); r.ok() {
+			canon.SELECT_TOOL(slot)
+		}
+	case "USE_TOOL_LENGTH_OFFSET":
+		if length := r.float("length"); r.ok() {
+			canon.USE_TOOL_LENGTH_OFFSET(length)
+		}
+	case "STRAIGHT_PROBE":
+		if x, y, z, a, b, c := r.xyzabc(); r.ok() {
+			canon.STRAIGHT_PROBE(x, y, z, a, b, c)
+		}
+	case "TURN_PROBE_OFF":
+		canon.TURN_PROBE_OFF()
+	case "TURN_PROBE_ON":
+		canon.TURN_PROBE_ON()
+	case "SET_TRAVERSE_RATE":
+		if rate := r.float("rate"); r.ok() {
+			if machine != nil {
+				machine.SET_TRAVERSE_RATE(rate)
+			}
+		}
+	case "STOP":
+		if machine != nil {
+			machine.STOP()
+		}
+	case "SET_SPINDLE_TORQUE":
+		if torque := r.float("torque"); r.ok() {
+			if machine != nil {
+				machine.SET_SPINDLE_TORQUE(torque)
+			}
+		}
+	case "SPINDLE_RETRACT":
+		if machine != nil {
+			machine.SPINDLE_RETRACT()
+		}
+	case "SPINDLE_RETRACT_TRAVERSE":
+		if machine != nil {
+			machine.SPINDLE_RETRACT_TRAVERSE()
+		}
+	case "USE_NO_SPINDLE_FORCE":
+		if machine != nil {
+			machine.USE_NO_SPINDLE_FORCE()
+		}
+	case "USE_SPINDLE_FORCE":
+		if force := r.float("force"); r.ok() {
+			if machine != nil {
+				machine.USE_SPINDLE_FORCE(force)
+			}
+		}
+	case "CLAMP_AXIS":
+		if axis := inc.CANON_AXIS(r.enum("axis", canon_axis_names)); r.ok() {
+			if ext != nil {
+				ext.CLAMP_AXIS(axis)
+			}
+		}
+	case "UNCLAMP_AXIS":
+		if axis := inc.CANON_AXIS(r.enum("axis", canon_axis_names)); r.ok() {
+			if ext != nil {
+				ext.UNCLAMP_AXIS(axis)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown command %q", record.Command)
+	}
+	return r.err
+}
+
+/* arguments_t
+
+   arguments_t takes the arguments of a record apart. The first error
+   met is kept in err; after it, every value read is zero. ok tells
+   whether all the values read so far were good.
+
+*/
+
+type arguments_t struct {
+	command string
+	args    map[string]interface{}
+	err     error
+}
+
+func (r *arguments_t) ok() bool {
+	return r.err == nil
+}
+
+func (r *arguments_t) value(name string) interface{} {
+	if r.err != nil {
+		return nil
+	}
+	value, ok := r.args[name]
+	if !ok {
+		r.err = fmt.Errorf("%s: missing argument %s", r.command, name)
+	}
+	return value
+}
+
+func (r *arguments_t) wrong(name string, value interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%s: argument %s is %v of type %T", r.command, name, value, value)
+	}
+}
+
+func (r *arguments_t) float(name string) float64 {
+	value := r.value(name)
+	number, ok := value.(float64)
+	if !ok {
+		r.wrong(name, value)
+	}
+	return number
+}
+
+func (r *arguments_t) int(name string) int {
+	number := r.float(name)
+	if number != float64(int(number)) {
+		r.wrong(name, number)
+	}
+	return int(number)
+}
+
+func (r *arguments_t) string(name string) string {
+	value := r.value(name)
+	text, ok := value.(string)
+	if !ok {
+		r.wrong(name, value)
+	}
+	return text
+}
+
+func (r *arguments_t) enum(name string, names map[string]int) int {
+	text := r.string(name)
+	number, ok := names[text]
+	if !ok {
+		r.wrong(name, text)
+	}
+	return number
+}
+
+func (r *arguments_t) xyzabc() (x, y, z, a, b, c float64) {
+	return r.float("x"), r.float("y"), r.float("z"), r.float("a"), r.float("b"), r.float("c")
+}
+
+/* Names of the enumerations, for enum
+
+   All the enumerations start at 1 and have fewer than ten values.
+
+*/
+
+func names(name func(i int) string) map[string]int {
+	table := map[string]int{}
+	for i := 1; i < 10; i++ {
+		table[name(i)] = i
+	}
+	delete(table, "UNKNOWN")
+	return table
+}
+
+var (
+	canon_axis_names           = names(func(i int) string { return inc.CANON_AXIS(i).String() })
+	canon_direction_names      = names(func(i int) string { return inc.CANON_DIRECTION(i).String() })
+	canon_feed_reference_names = names(func(i int) string { return inc.CANON_FEED_REFERENCE(i).String() })
+	canon_motion_mode_names    = names(func(i int) string { return inc.CANON_MOTION_MODE(i).String() })
+	canon_plane_names          = names(func(i int) string { return inc.CANON_PLANE(i).String() })
+	canon_side_names           = names(func(i int) string { return inc.CANON_SIDE(i).String() })
+	canon_units_names          = names(func(i int) string { return inc.CANON_UNITS(i).String() })
+)
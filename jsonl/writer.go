@@ -0,0 +1,148 @@
+package jsonl
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* writer.go
+
+   Writer_t is a Canon_i which writes each canonical command it is given
+   as one JSON object on a line of its own (JSON Lines), for programs
+   that want the commands as data rather than text. For example:
+
+      {"seq":5,"line":3,"text":"g1 x1 f10","command":"STRAIGHT_FEED",
+       "args":{"a":0,"b":0,"c":0,"x":1,"y":0,"z":0},
+       "context":{"units":"CANON_UNITS_MM","plane":"CANON_PLANE_XY",...}}
+
+   (all on one line). The fields of a record are those of Record_t.
+   Arguments are named as in the canonical function. Enumerations are
+   written by name (see inc/canon_string.go). Lengths are in the units
+   given in the context.
+
+   The world-give-information functions are not written.
+   The world model is kept by the embedded inc.Canon_world_t.
+
+   If Source is set, each record carries the sequence number and text of
+   the line the interpreter was on; an interpreter (rs274ngc.Rs274ngc_t)
+   serves as a Source.
+
+   The first error from the io.Writer is kept in Err, and nothing more
+   is written after it.
+
+   Replay reads such a stream back into another Canon_i.
+
+*/
+
+// Context_t is the modal context in force after a command.
+type Context_t struct {
+	Units         string  `json:"units"`
+	Plane         string  `json:"plane"`
+	Motion_mode   string  `json:"motion_mode"`
+	Feed_rate     float64 `json:"feed_rate"`
+	Traverse_rate float64 `json:"traverse_rate"`
+	Speed         float64 `json:"speed"`
+	Spindle       string  `json:"spindle"`
+	Tool_slot     int     `json:"tool_slot"`
+	Flood         bool    `json:"flood"`
+	Mist          bool    `json:"mist"`
+}
+
+// Record_t is one line of the stream.
+type Record_t struct {
+	Seq     int                    `json:"seq"`            // number of the record, from 1
+	Line    int                    `json:"line,omitempty"` // sequence number of the source line
+	Text    string                 `json:"text,omitempty"` // text of the source line
+	Command string                 `json:"command"`        // name of the canonical function
+	Args    map[string]interface{} `json:"args,omitempty"`
+	Context Context_t              `json:"context"`
+}
+
+// args are the named arguments of a command.
+type args = map[string]interface{}
+
+type Writer_t struct {
+	inc.Canon_world_t
+
+	Source inc.Source_i
+	Err    error
+
+	encoder *json.Encoder
+	seq     int // of the last record written
+}
+
+var _ inc.Canon_i = &Writer_t{}
+var _ inc.Canon_ext_i = &Writer_t{}
+var _ inc.Canon_machine_i = &Writer_t{}
+var _ inc.Comp_i = &Writer_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Writer_t writing to out, with the world model of
+   a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New(out io.Writer) *Writer_t {
+	return &Writer_t{encoder: json.NewEncoder(out)}
+}
+
+/***********************************************************************/
+
+/* write
+
+   Returned Value: none
+
+   Side effects:
+   One record is written, and the record number is incremented.
+   If the write fails, the error is saved in w.Err.
+
+   Called by: every canonical command of Writer_t
+
+*/
+
+func (w *Writer_t) write(command string, arguments args) {
+	if w.Err != nil {
+		return
+	}
+	w.seq++
+	record := Record_t{Seq: w.seq, Command: command, Args: arguments, Context: w.context()}
+	if w.Source != nil {
+		record.Line = w.Source.SequenceNumber()
+		record.Text = w.Source.LineText()
+	}
+	w.Err = w.encoder.Encode(&record)
+}
+
+/* context
+
+   Returned Value: the modal context of the world model
+
+   Side effects: none
+
+   Called by: Writer_t.write
+
+*/
+
+func (w *Writer_t) context() Context_t {
+	return Context_t{
+		Units:         w.GET_EXTERNAL_LENGTH_UNIT_TYPE().String(),
+		Plane:         w.GET_EXTERNAL_PLANE().String(),
+		Motion_mode:   w.GET_EXTERNAL_MOTION_CONTROL_MODE().String(),
+		Feed_rate:     w.GET_EXTERNAL_FEED_RATE(),
+		Traverse_rate: w.GET_EXTERNAL_TRAVERSE_RATE(),
+		Speed:         w.GET_EXTERNAL_SPEED(),
+		Spindle:       w.GET_EXTERNAL_SPINDLE().String(),
+		Tool_slot:     w.GET_EXTERNAL_TOOL_SLOT(),
+		Flood:         w.Flood,
+		Mist:          w.Mist,
+	}
+}
@@ -0,0 +1,630 @@
+package kinematics
+
+import (
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/arc"
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* kinematics.go
+
+   This is a layer which sits between the interpreter and a Canon_i
+   and carries out tool center point control (TCPC, G43.4) for five-axis
+   machines.
+
+   With TCPC off, every canonical command is passed through unchanged.
+
+   With TCPC on, the X, Y, and Z values the interpreter gives are
+   tool-tip positions in the program (workpiece) coordinate system.
+   This layer converts them into machine joint positions before handing
+   them on, so that the controller does not have to compensate for the
+   pivot length or the rotation of the table. The inverse conversion is
+   applied to the GET_EXTERNAL_POSITION_ and GET_EXTERNAL_PROBE_POSITION_
+   functions, so rs274ngc_synch reads tool-tip positions back.
+
+   Two families of machines are handled:
+
+   1. table-table: the workpiece sits on a C rotary table carried by an
+   A (about X) or B (about Y) tilting trunnion. The tool always points
+   along the machine Z axis, so the tool length offset is passed through.
+
+   2. head-head: the spindle is carried by an A or B tilting head which
+   is in turn carried by a C rotary head. The tool tip is the pivot
+   length plus the tool length away from the pivot point, along the
+   tool axis. While TCPC is on, this layer takes over the tool length
+   offset and sends USE_TOOL_LENGTH_OFFSET(0) to the next Canon_i.
+
+   Rotary axis values are in degrees and turn the table or head by the
+   right hand rule about the axis they name. Rotary values themselves
+   are passed through unchanged.
+
+   While TCPC is on, a STRAIGHT_FEED which moves a rotary axis is broken
+   into straight feeds small enough that the tool tip stays close to the
+   programmed line, and an ARC_FEED made with the rotary axes anywhere
+   but zero is broken into straight feeds within the chord tolerance.
+   STRAIGHT_TRAVERSE is only converted at its end point, since the path
+   of a traverse is not controlled anyway.
+
+*/
+
+type KINEMATICS_TYPE int
+
+const (
+	_ KINEMATICS_TYPE = iota
+	KINEMATICS_TABLE_AC
+	KINEMATICS_TABLE_BC
+	KINEMATICS_HEAD_AC
+	KINEMATICS_HEAD_BC
+)
+
+// Config_t describes the machine. Lengths are in millimeters and are
+// converted to the current length units as needed.
+type Config_t struct {
+	Type KINEMATICS_TYPE
+
+	// table-table: machine position of the point where the tilting axis
+	// crosses the rotary axis (at zero tilt). Only X, Y, and Z are used.
+	Center inc.CANON_POSITION
+	// table-table: offset of the rotary table axis from Center, for
+	// machines on which the two axes do not cross.
+	Offset inc.CANON_POSITION
+
+	// head-head: distance from the pivot point down to the spindle gauge
+	// line, measured with the rotary axes at zero.
+	Pivot float64
+
+	// largest rotary axis change, in degrees, of one straight feed
+	// segment sent while TCPC is on. Zero means 1 degree.
+	Max_angle_step float64
+	// chord tolerance, in millimeters, for arcs broken into straight
+	// feeds while TCPC is on. Zero means 0.001 mm.
+	Tolerance float64
+}
+
+type Kinematics_t struct {
+	inc.Canon_i /* the next Canon_i, commands not handled here pass through */
+
+	config Config_t
+
+	tcpc               bool
+	current            inc.CANON_POSITION // tool tip, program coordinates
+	origin             inc.CANON_POSITION // from SET_ORIGIN_OFFSETS
+	length_units       inc.CANON_UNITS
+	plane              inc.CANON_PLANE
+	tool_length_offset float64
+}
+
+var _ inc.Canon_i = &Kinematics_t{}
+var _ inc.Tcpc_i = &Kinematics_t{}
+var _ inc.Canon_ext_i = &Kinematics_t{}
+var _ inc.Canon_machine_i = &Kinematics_t{}
+var _ inc.Comp_i = &Kinematics_t{}
+
+type point struct {
+	x, y, z float64
+}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: *Kinematics_t
+
+   Side effects: none
+
+   Called by: external programs
+
+   This returns a kinematics layer passing its output to next. The
+   current position and length units are read from next, so next should
+   be in the state the interpreter will be started with.
+
+*/
+
+func New(next inc.Canon_i, config Config_t) *Kinematics_t {
+
+	k := &Kinematics_t{Canon_i: next, config: config}
+	if k.config.Max_angle_step <= 0.0 {
+		k.config.Max_angle_step = 1.0
+	}
+	if k.config.Tolerance <= 0.0 {
+		k.config.Tolerance = 0.001
+	}
+	k.length_units = next.GET_EXTERNAL_LENGTH_UNIT_TYPE()
+	k.plane = next.GET_EXTERNAL_PLANE()
+	k.tool_length_offset = next.GET_EXTERNAL_TOOL_LENGTH_OFFSET()
+	k.current = inc.CANON_POSITION{
+		X: next.GET_EXTERNAL_POSITION_X(),
+		Y: next.GET_EXTERNAL_POSITION_Y(),
+		Z: next.GET_EXTERNAL_POSITION_Z(),
+		A: next.GET_EXTERNAL_POSITION_A(),
+		B: next.GET_EXTERNAL_POSITION_B(),
+		C: next.GET_EXTERNAL_POSITION_C()}
+	return k
+}
+
+// Unwrap returns the next Canon_i, as mux.Filter_t does.
+func (k *Kinematics_t) Unwrap() inc.Canon_i {
+	return k.Canon_i
+}
+
+/* Tool center point control */
+
+func (k *Kinematics_t) START_TOOL_CENTER_POINT_CONTROL() {
+	if k.tcpc {
+		return
+	}
+	k.tcpc = true
+	if k.is_head() {
+		k.Canon_i.USE_TOOL_LENGTH_OFFSET(0.0)
+	}
+}
+
+func (k *Kinematics_t) STOP_TOOL_CENTER_POINT_CONTROL() {
+	if !k.tcpc {
+		return
+	}
+	k.tcpc = false
+	if k.is_head() {
+		k.Canon_i.USE_TOOL_LENGTH_OFFSET(k.tool_length_offset)
+	}
+}
+
+func (k *Kinematics_t) GET_EXTERNAL_TOOL_CENTER_POINT_CONTROL() int {
+	return inc.If(k.tcpc, 1, 0).(int)
+}
+
+/* NIST canonical extensions, passed on if the next Canon_i has them */
+
+func (k *Kinematics_t) ext() inc.Canon_ext_i {
+	ext, _ := k.Canon_i.(inc.Canon_ext_i)
+	return ext
+}
+
+func (k *Kinematics_t) machine() inc.Canon_machine_i {
+	machine, _ := k.Canon_i.(inc.Canon_machine_i)
+	return machine
+}
+
+func (k *Kinematics_t) comp() inc.Comp_i {
+	comp, _ := k.Canon_i.(inc.Comp_i)
+	return comp
+}
+
+func (k *Kinematics_t) SET_TRAVERSE_RATE(rate float64) {
+	if machine := k.machine(); machine != nil {
+		machine.SET_TRAVERSE_RATE(rate)
+	}
+}
+
+func (k *Kinematics_t) STOP() {
+	if machine := k.machine(); machine != nil {
+		machine.STOP()
+	}
+}
+
+func (k *Kinematics_t) SET_SPINDLE_TORQUE(torque float64) {
+	if machine := k.machine(); machine != nil {
+		machine.SET_SPINDLE_TORQUE(torque)
+	}
+}
+
+func (k *Kinematics_t) SPINDLE_RETRACT() {
+	if machine := k.machine(); machine != nil {
+		machine.SPINDLE_RETRACT()
+	}
+}
+
+func (k *Kinematics_t) SPINDLE_RETRACT_TRAVERSE() {
+	if machine := k.machine(); machine != nil {
+		machine.SPINDLE_RETRACT_TRAVERSE()
+	}
+}
+
+func (k *Kinematics_t) USE_NO_SPINDLE_FORCE() {
+	if machine := k.machine(); machine != nil {
+		machine.USE_NO_SPINDLE_FORCE()
+	}
+}
+
+func (k *Kinematics_t) USE_SPINDLE_FORCE(force float64) {
+	if machine := k.machine(); machine != nil {
+		machine.USE_SPINDLE_FORCE(force)
+	}
+}
+
+func (k *Kinematics_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
+	if ext := k.ext(); ext != nil {
+		ext.CLAMP_AXIS(axis)
+	}
+}
+
+func (k *Kinematics_t) UNCLAMP_AXIS(axis inc.CANON_AXIS) {
+	if ext := k.ext(); ext != nil {
+		ext.UNCLAMP_AXIS(axis)
+	}
+}
+
+func (k *Kinematics_t) SET_CUTTER_RADIUS_COMPENSATION(radius float64) {
+	if comp := k.comp(); comp != nil {
+		comp.SET_CUTTER_RADIUS_COMPENSATION(radius)
+	}
+}
+
+func (k *Kinematics_t) START_CUTTER_RADIUS_COMPENSATION(side inc.CANON_SIDE) {
+	if comp := k.comp(); comp != nil {
+		comp.START_CUTTER_RADIUS_COMPENSATION(side)
+	}
+}
+
+func (k *Kinematics_t) STOP_CUTTER_RADIUS_COMPENSATION() {
+	if comp := k.comp(); comp != nil {
+		comp.STOP_CUTTER_RADIUS_COMPENSATION()
+	}
+}
+
+/* Commands whose state this layer has to follow */
+
+func (k *Kinematics_t) USE_TOOL_LENGTH_OFFSET(offset float64) {
+	k.tool_length_offset = offset
+	if k.tcpc && k.is_head() {
+		return
+	}
+	k.Canon_i.USE_TOOL_LENGTH_OFFSET(offset)
+}
+
+func (k *Kinematics_t) SELECT_PLANE(plane inc.CANON_PLANE) {
+	k.plane = plane
+	k.Canon_i.SELECT_PLANE(plane)
+}
+
+func (k *Kinematics_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	k.current.X = k.current.X + k.origin.X - x
+	k.current.Y = k.current.Y + k.origin.Y - y
+	k.current.Z = k.current.Z + k.origin.Z - z
+	k.current.A = k.current.A + k.origin.A - a
+	k.current.B = k.current.B + k.origin.B - b
+	k.current.C = k.current.C + k.origin.C - c
+	k.origin = inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+	k.Canon_i.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+}
+
+func (k *Kinematics_t) USE_LENGTH_UNITS(in_unit inc.CANON_UNITS) {
+	factor := k.unit_factor(in_unit) / k.unit_factor(k.length_units)
+	k.current.X = k.current.X * factor
+	k.current.Y = k.current.Y * factor
+	k.current.Z = k.current.Z * factor
+	k.origin.X = k.origin.X * factor
+	k.origin.Y = k.origin.Y * factor
+	k.origin.Z = k.origin.Z * factor
+	k.length_units = in_unit
+	k.Canon_i.USE_LENGTH_UNITS(in_unit)
+}
+
+/* Motion */
+
+func (k *Kinematics_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	j := k.forward(point{x, y, z}, a, b, c)
+	k.Canon_i.STRAIGHT_TRAVERSE(j.x, j.y, j.z, a, b, c)
+	k.current = inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+}
+
+func (k *Kinematics_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	j := k.forward(point{x, y, z}, a, b, c)
+	k.Canon_i.STRAIGHT_PROBE(j.x, j.y, j.z, a, b, c)
+	k.current = inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+}
+
+/***********************************************************************/
+
+/* STRAIGHT_FEED
+
+   Side effects:
+   One or more STRAIGHT_FEED calls are made to the next Canon_i.
+
+   If TCPC is off or no rotary axis moves, the end point is converted
+   and one call is made. Otherwise, the move is broken into enough
+   pieces that no rotary axis turns more than Max_angle_step in any
+   piece, and the tool tip is interpolated along the programmed line.
+
+*/
+
+func (k *Kinematics_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+
+	var n int = 1
+
+	if k.tcpc {
+		step := math.Max(math.Abs(a-k.current.A),
+			math.Max(math.Abs(b-k.current.B), math.Abs(c-k.current.C)))
+		n = int(math.Ceil(step / k.config.Max_angle_step))
+		if n < 1 {
+			n = 1
+		}
+	}
+
+	start := k.current
+	for i := 1; i <= n; i++ {
+		f := float64(i) / float64(n)
+		p := point{
+			start.X + f*(x-start.X),
+			start.Y + f*(y-start.Y),
+			start.Z + f*(z-start.Z)}
+		pa := start.A + f*(a-start.A)
+		pb := start.B + f*(b-start.B)
+		pc := start.C + f*(c-start.C)
+		if i == n { /* hit the end exactly */
+			p, pa, pb, pc = point{x, y, z}, a, b, c
+		}
+		j := k.forward(p, pa, pb, pc)
+		k.Canon_i.STRAIGHT_FEED(j.x, j.y, j.z, pa, pb, pc)
+	}
+	k.current = inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}
+}
+
+/***********************************************************************/
+
+/* ARC_FEED
+
+   Side effects:
+   An ARC_FEED call or a series of STRAIGHT_FEED calls is made to the
+   next Canon_i.
+
+   If TCPC is off, or all rotary axes stay at zero so that the
+   conversion only moves the tool tip, the arc is passed through: as it
+   is for a table, and moved up by the tool length for a head, whose
+   next Canon_i was given no tool length offset. Otherwise the
+   arc is broken into straight feeds no farther than Tolerance from the
+   arc (see arc.Find_chord_count), with rotary axes and the axis
+   coordinate moving evenly.
+
+   The arguments are as for ARC_FEED: the end point and center in the
+   selected plane, the number of turns (positive counterclockwise), the
+   end point on the axis perpendicular to the plane, and the rotary end
+   points.
+
+*/
+
+func (k *Kinematics_t) ARC_FEED(
+	first_end, second_end, first_axis, second_axis float64, rotation int,
+	axis_end_point, a, b, c float64) {
+
+	if !k.tcpc || ((a == 0) && (b == 0) && (c == 0) &&
+		(k.current.A == 0) && (k.current.B == 0) && (k.current.C == 0)) {
+		var first, second, axis float64 /* the shift of a head at zero angles */
+		if k.tcpc && k.is_head() {
+			j := k.forward(point{0, 0, 0}, 0, 0, 0)
+			first, second, axis = k.inplane(inc.CANON_POSITION{X: j.x, Y: j.y, Z: j.z})
+		}
+		k.Canon_i.ARC_FEED(first_end+first, second_end+second, first_axis+first, second_axis+second,
+			rotation, axis_end_point+axis, a, b, c)
+		k.current = k.unplane(first_end, second_end, axis_end_point)
+		k.current.A, k.current.B, k.current.C = a, b, c
+		return
+	}
+
+	start := k.current
+	first_start, second_start, axis_start := k.inplane(start)
+
+	radius := math.Hypot(first_start-first_axis, second_start-second_axis)
+	radius_end := math.Hypot(first_end-first_axis, second_end-second_axis)
+	theta1 := math.Atan2(second_start-second_axis, first_start-first_axis)
+	sweep := arc.Find_turn(first_start, second_start, first_axis, second_axis, rotation, first_end, second_end)
+
+	tolerance := k.config.Tolerance * k.unit_factor(k.length_units)
+	n := arc.Find_chord_count(radius, sweep, tolerance)
+	step := math.Max(math.Abs(a-start.A),
+		math.Max(math.Abs(b-start.B), math.Abs(c-start.C)))
+	if m := int(math.Ceil(step / k.config.Max_angle_step)); m > n {
+		n = m
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	for i := 1; i <= n; i++ {
+		f := float64(i) / float64(n)
+		theta := theta1 + f*sweep
+		r := radius + f*(radius_end-radius)
+		p := k.unplane(first_axis+r*math.Cos(theta), second_axis+r*math.Sin(theta),
+			axis_start+f*(axis_end_point-axis_start))
+		pa := start.A + f*(a-start.A)
+		pb := start.B + f*(b-start.B)
+		pc := start.C + f*(c-start.C)
+		if i == n { /* hit the end exactly */
+			p = k.unplane(first_end, second_end, axis_end_point)
+			pa, pb, pc = a, b, c
+		}
+		j := k.forward(point{p.X, p.Y, p.Z}, pa, pb, pc)
+		k.Canon_i.STRAIGHT_FEED(j.x, j.y, j.z, pa, pb, pc)
+	}
+	k.current = k.unplane(first_end, second_end, axis_end_point)
+	k.current.A, k.current.B, k.current.C = a, b, c
+}
+
+/* World-give-information */
+
+func (k *Kinematics_t) GET_EXTERNAL_POSITION_X() float64 { return k.position().x }
+func (k *Kinematics_t) GET_EXTERNAL_POSITION_Y() float64 { return k.position().y }
+func (k *Kinematics_t) GET_EXTERNAL_POSITION_Z() float64 { return k.position().z }
+
+// While a head-head layer holds the tool length offset, the next Canon_i
+// has zero, so the offset is reported from here.
+func (k *Kinematics_t) GET_EXTERNAL_TOOL_LENGTH_OFFSET() float64 {
+	if k.tcpc && k.is_head() {
+		return k.tool_length_offset
+	}
+	return k.Canon_i.GET_EXTERNAL_TOOL_LENGTH_OFFSET()
+}
+
+func (k *Kinematics_t) GET_EXTERNAL_PROBE_POSITION_X() float64 { return k.probe_position().x }
+func (k *Kinematics_t) GET_EXTERNAL_PROBE_POSITION_Y() float64 { return k.probe_position().y }
+func (k *Kinematics_t) GET_EXTERNAL_PROBE_POSITION_Z() float64 { return k.probe_position().z }
+
+func (k *Kinematics_t) position() point {
+	n := k.Canon_i
+	return k.inverse(point{n.GET_EXTERNAL_POSITION_X(), n.GET_EXTERNAL_POSITION_Y(),
+		n.GET_EXTERNAL_POSITION_Z()},
+		n.GET_EXTERNAL_POSITION_A(), n.GET_EXTERNAL_POSITION_B(), n.GET_EXTERNAL_POSITION_C())
+}
+
+func (k *Kinematics_t) probe_position() point {
+	n := k.Canon_i
+	return k.inverse(point{n.GET_EXTERNAL_PROBE_POSITION_X(), n.GET_EXTERNAL_PROBE_POSITION_Y(),
+		n.GET_EXTERNAL_PROBE_POSITION_Z()},
+		n.GET_EXTERNAL_PROBE_POSITION_A(), n.GET_EXTERNAL_PROBE_POSITION_B(),
+		n.GET_EXTERNAL_PROBE_POSITION_C())
+}
+
+/***********************************************************************/
+
+/* forward
+
+   Returned Value: point, the joint position in program coordinates
+
+   Called by: the motion commands above
+
+   This converts a tool-tip position in program coordinates to the
+   joint position the next Canon_i should be sent, with the rotary axes
+   at the given (program) values. If TCPC is off, the tip is returned.
+
+   table-table:
+   q = Center + Rtilt(Offset + Rc(tip - Center - Offset))
+
+   head-head, with L the pivot length plus tool length and d the tool
+   direction Rc(Rtilt(0, 0, -1)):
+   joint = tip - L * d - (0, 0, pivot length)
+
+*/
+
+func (k *Kinematics_t) forward(tip point, a, b, c float64) point {
+
+	if !k.tcpc {
+		return tip
+	}
+
+	tilt, rot := k.angles(a, b, c)
+	scale := k.unit_factor(k.length_units)
+
+	if k.is_head() {
+		pivot := k.config.Pivot * scale
+		length := pivot + k.tool_length_offset
+		d := k.rotate_z(k.rotate_tilt(point{0, 0, -1}, tilt), rot)
+		return point{tip.x - length*d.x, tip.y - length*d.y, tip.z - length*d.z - pivot}
+	}
+
+	center := k.config_point(k.config.Center, scale)
+	offset := k.config_point(k.config.Offset, scale)
+	q := point{ /* machine coordinates, relative to the rotary axis */
+		tip.x + k.origin.X - center.x - offset.x,
+		tip.y + k.origin.Y - center.y - offset.y,
+		tip.z + k.origin.Z - center.z - offset.z}
+	q = k.rotate_z(q, rot)
+	q = k.rotate_tilt(point{q.x + offset.x, q.y + offset.y, q.z + offset.z}, tilt)
+	return point{
+		q.x + center.x - k.origin.X,
+		q.y + center.y - k.origin.Y,
+		q.z + center.z - k.origin.Z}
+}
+
+/* inverse
+
+   Returned Value: point, the tool-tip position in program coordinates
+
+   Called by: position, probe_position
+
+   This undoes forward.
+
+*/
+
+func (k *Kinematics_t) inverse(joint point, a, b, c float64) point {
+
+	if !k.tcpc {
+		return joint
+	}
+
+	tilt, rot := k.angles(a, b, c)
+	scale := k.unit_factor(k.length_units)
+
+	if k.is_head() {
+		pivot := k.config.Pivot * scale
+		length := pivot + k.tool_length_offset
+		d := k.rotate_z(k.rotate_tilt(point{0, 0, -1}, tilt), rot)
+		return point{joint.x + length*d.x, joint.y + length*d.y, joint.z + pivot + length*d.z}
+	}
+
+	center := k.config_point(k.config.Center, scale)
+	offset := k.config_point(k.config.Offset, scale)
+	q := point{
+		joint.x + k.origin.X - center.x,
+		joint.y + k.origin.Y - center.y,
+		joint.z + k.origin.Z - center.z}
+	q = k.rotate_tilt(q, -tilt)
+	q = k.rotate_z(point{q.x - offset.x, q.y - offset.y, q.z - offset.z}, -rot)
+	return point{
+		q.x + center.x + offset.x - k.origin.X,
+		q.y + center.y + offset.y - k.origin.Y,
+		q.z + center.z + offset.z - k.origin.Z}
+}
+
+/* angles returns the machine tilt and rotary angles in radians. */
+func (k *Kinematics_t) angles(a, b, c float64) (tilt, rot float64) {
+	if (k.config.Type == KINEMATICS_TABLE_AC) || (k.config.Type == KINEMATICS_HEAD_AC) {
+		tilt = a + k.origin.A
+	} else {
+		tilt = b + k.origin.B
+	}
+	rot = c + k.origin.C
+	return tilt * inc.PI / 180.0, rot * inc.PI / 180.0
+}
+
+func (k *Kinematics_t) is_head() bool {
+	return (k.config.Type == KINEMATICS_HEAD_AC) || (k.config.Type == KINEMATICS_HEAD_BC)
+}
+
+/* rotate_tilt turns p about X (A machines) or Y (B machines). */
+func (k *Kinematics_t) rotate_tilt(p point, angle float64) point {
+	s, c := math.Sincos(angle)
+	if (k.config.Type == KINEMATICS_TABLE_AC) || (k.config.Type == KINEMATICS_HEAD_AC) {
+		return point{p.x, c*p.y - s*p.z, s*p.y + c*p.z}
+	}
+	return point{c*p.x + s*p.z, p.y, -s*p.x + c*p.z}
+}
+
+func (k *Kinematics_t) rotate_z(p point, angle float64) point {
+	s, c := math.Sincos(angle)
+	return point{c*p.x - s*p.y, s*p.x + c*p.y, p.z}
+}
+
+func (k *Kinematics_t) config_point(p inc.CANON_POSITION, scale float64) point {
+	return point{p.X * scale, p.Y * scale, p.Z * scale}
+}
+
+/* unit_factor returns the number of the given units per millimeter. */
+func (k *Kinematics_t) unit_factor(units inc.CANON_UNITS) float64 {
+	if units == inc.CANON_UNITS_INCHES {
+		return inc.INCH_PER_MM
+	} else if units == inc.CANON_UNITS_CM {
+		return 0.1
+	}
+	return 1.0
+}
+
+/* inplane splits p into ARC_FEED's first, second, and axis values. */
+func (k *Kinematics_t) inplane(p inc.CANON_POSITION) (first, second, axis float64) {
+	if k.plane == inc.CANON_PLANE_YZ {
+		return p.Y, p.Z, p.X
+	} else if k.plane == inc.CANON_PLANE_XZ {
+		return p.Z, p.X, p.Y
+	}
+	return p.X, p.Y, p.Z
+}
+
+/* unplane undoes inplane, leaving the rotary values zero. */
+func (k *Kinematics_t) unplane(first, second, axis float64) inc.CANON_POSITION {
+	if k.plane == inc.CANON_PLANE_YZ {
+		return inc.CANON_POSITION{X: axis, Y: first, Z: second}
+	} else if k.plane == inc.CANON_PLANE_XZ {
+		return inc.CANON_POSITION{X: second, Y: axis, Z: first}
+	}
+	return inc.CANON_POSITION{X: first, Y: second, Z: axis}
+}
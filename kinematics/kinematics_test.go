@@ -0,0 +1,192 @@
+package kinematics_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/kinematics"
+	"github.com/flyingyizi/rs274ngc/record"
+)
+
+// setup returns a kinematics layer of config with TCPC on, a tool 20
+// long and the program origin away from the machine origin, in front of
+// a Recorder_t.
+func setup(config kinematics.Config_t) (*kinematics.Kinematics_t, *record.Recorder_t) {
+	rec := record.New()
+	k := kinematics.New(rec, config)
+	k.SET_ORIGIN_OFFSETS(5, -3, 2, 0, 0, 0)
+	k.USE_TOOL_LENGTH_OFFSET(20)
+	k.START_TOOL_CENTER_POINT_CONTROL()
+	rec.Reset()
+	return k, rec
+}
+
+// joint returns the X, Y and Z of the last motion command rec was given.
+func joint(rec *record.Recorder_t) (x, y, z float64) {
+	args := rec.Calls[len(rec.Calls)-1].Args
+	return args[0].(float64), args[1].(float64), args[2].(float64)
+}
+
+func TestKinematics_round_trip(t *testing.T) {
+	// Each tip, sent at the rotary position, comes back from the
+	// GET_EXTERNAL_POSITION_ functions as it was sent.
+	configs := []kinematics.Config_t{
+		{Type: kinematics.KINEMATICS_TABLE_AC, Center: inc.CANON_POSITION{X: 100, Y: 50, Z: -200}},
+		{Type: kinematics.KINEMATICS_TABLE_BC, Center: inc.CANON_POSITION{X: 100, Y: 50, Z: -200},
+			Offset: inc.CANON_POSITION{X: 0, Y: 0, Z: 30}},
+		{Type: kinematics.KINEMATICS_HEAD_AC, Pivot: 150},
+		{Type: kinematics.KINEMATICS_HEAD_BC, Pivot: 150},
+	}
+	tips := []struct{ x, y, z, a, b, c float64 }{
+		{0, 0, 0, 0, 0, 0},
+		{10, 20, -5, 30, 0, 0},
+		{10, 20, -5, 0, -45, 90},
+		{-40, 15, 60, 90, 90, 200},
+		{3, -7, 11, -20, 35, -135},
+	}
+	for _, config := range configs {
+		k, rec := setup(config)
+		for _, p := range tips {
+			k.STRAIGHT_TRAVERSE(p.x, p.y, p.z, p.a, p.b, p.c)
+			x, y, z := k.GET_EXTERNAL_POSITION_X(), k.GET_EXTERNAL_POSITION_Y(), k.GET_EXTERNAL_POSITION_Z()
+			if (math.Abs(x-p.x) > 1e-9) || (math.Abs(y-p.y) > 1e-9) || (math.Abs(z-p.z) > 1e-9) {
+				jx, jy, jz := joint(rec)
+				t.Errorf("type %d: tip %v went to joint (%g, %g, %g) and came back as (%g, %g, %g)",
+					config.Type, p, jx, jy, jz, x, y, z)
+			}
+		}
+	}
+}
+
+func TestKinematics_forward(t *testing.T) {
+	// A head tilted 90 degrees about Y puts the pivot the pivot length
+	// plus the tool length along X from the tip, and lifts the spindle
+	// by the pivot length; TCPC off passes the tip through.
+	k, rec := setup(kinematics.Config_t{Type: kinematics.KINEMATICS_HEAD_BC, Pivot: 150})
+	k.STRAIGHT_TRAVERSE(10, 20, 30, 0, 90, 0)
+	if x, y, z := joint(rec); (math.Abs(x-180) > 1e-9) || (math.Abs(y-20) > 1e-9) || (math.Abs(z+120) > 1e-9) {
+		t.Errorf("joint (%g, %g, %g), want (180, 20, -120)", x, y, z)
+	}
+
+	// A table turned 90 degrees about Z at the program origin, which is
+	// the center, turns the tip with it.
+	k, rec = setup(kinematics.Config_t{Type: kinematics.KINEMATICS_TABLE_AC, Center: inc.CANON_POSITION{X: 5, Y: -3, Z: 2}})
+	k.STRAIGHT_TRAVERSE(10, 0, 0, 0, 0, 90)
+	if x, y, z := joint(rec); (math.Abs(x) > 1e-9) || (math.Abs(y-10) > 1e-9) || (math.Abs(z) > 1e-9) {
+		t.Errorf("joint (%g, %g, %g), want (0, 10, 0)", x, y, z)
+	}
+
+	k.STOP_TOOL_CENTER_POINT_CONTROL()
+	k.STRAIGHT_TRAVERSE(10, 0, 0, 0, 0, 90)
+	if x, y, z := joint(rec); (x != 10) || (y != 0) || (z != 0) {
+		t.Errorf("joint (%g, %g, %g) with TCPC off, want (10, 0, 0)", x, y, z)
+	}
+}
+
+// untwist returns where a joint position sent to a table turning about
+// Z at the program origin, at c degrees, puts the tip.
+func untwist(x, y, c float64) (float64, float64) {
+	s, co := math.Sincos(-c * math.Pi / 180.0)
+	return (co * x) - (s * y), (s * x) + (co * y)
+}
+
+func TestKinematics_angle_step(t *testing.T) {
+	// A feed turning C by 9 degrees, in steps of 2 at most, is sent in
+	// 5 pieces, each with the tip on the programmed line.
+	k, rec := setup(kinematics.Config_t{Type: kinematics.KINEMATICS_TABLE_AC,
+		Center: inc.CANON_POSITION{X: 5, Y: -3, Z: 2}, Max_angle_step: 2})
+	k.STRAIGHT_FEED(10, 0, 0, 0, 0, 0)
+	rec.Reset()
+	k.STRAIGHT_FEED(20, 0, -4, 0, 0, 9)
+	feeds := rec.Filter("STRAIGHT_FEED")
+	if len(feeds) != 5 {
+		t.Fatalf("%d feeds, want 5: %v", len(feeds), rec.Strings())
+	}
+	last := 0.0
+	for n, feed := range feeds {
+		c := feed.Args[5].(float64)
+		if step := c - last; (step <= 0.0) || (step > 2.0+1e-9) {
+			t.Errorf("feed %d turns C by %g", n, step)
+		}
+		last = c
+		x, y := untwist(feed.Args[0].(float64), feed.Args[1].(float64), c)
+		if (math.Abs(y) > 1e-9) || (math.Abs(feed.Args[2].(float64)-((x-10.0)*-0.4)) > 1e-9) {
+			t.Errorf("feed %d puts the tip at (%g, %g, %g), off the line", n, x, y, feed.Args[2])
+		}
+	}
+	if last != 9 {
+		t.Errorf("feeds end at C%g, want C9", last)
+	}
+}
+
+func TestKinematics_arc(t *testing.T) {
+	// With C at 30, a quarter circle of radius 10 is sent as chords of
+	// the circle no farther than the tolerance from it; at C0 it is
+	// passed through.
+	k, rec := setup(kinematics.Config_t{Type: kinematics.KINEMATICS_TABLE_AC,
+		Center: inc.CANON_POSITION{X: 5, Y: -3, Z: 2}, Tolerance: 0.01})
+	k.STRAIGHT_FEED(10, 0, 0, 0, 0, 30)
+	rec.Reset()
+	k.ARC_FEED(0, 10, 0, 0, 1, 0, 0, 0, 30)
+	if len(rec.Filter("ARC_FEED")) != 0 {
+		t.Fatalf("the arc was passed through: %v", rec.Strings())
+	}
+	feeds := rec.Filter("STRAIGHT_FEED")
+	if len(feeds) < 2 {
+		t.Fatalf("%d feeds, want the arc in chords", len(feeds))
+	}
+	x0, y0 := 10.0, 0.0
+	for n, feed := range feeds {
+		x, y := untwist(feed.Args[0].(float64), feed.Args[1].(float64), 30)
+		if math.Abs(math.Hypot(x, y)-10) > 1e-9 {
+			t.Errorf("feed %d ends at (%g, %g), off the circle", n, x, y)
+		}
+		if sag := 10 - math.Hypot((x+x0)/2, (y+y0)/2); sag > 0.01+1e-9 {
+			t.Errorf("feed %d is %g from the circle", n, sag)
+		}
+		x0, y0 = x, y
+	}
+	if (math.Abs(x0) > 1e-9) || (math.Abs(y0-10) > 1e-9) {
+		t.Errorf("feeds end at (%g, %g), want (0, 10)", x0, y0)
+	}
+
+	k.STRAIGHT_FEED(10, 0, 0, 0, 0, 0)
+	rec.Reset()
+	k.ARC_FEED(0, 10, 0, 0, 1, 0, 0, 0, 0)
+	if names := rec.Names(); (len(names) != 1) || (names[0] != "ARC_FEED") {
+		t.Errorf("calls %v at C0, want the arc passed through", names)
+	}
+}
+
+func TestKinematics_head_arc(t *testing.T) {
+	// A head at zero angles with TCPC on lifts the spindle by the tool
+	// length, which the next Canon_i is not given, so an arc is passed
+	// through moved up by it, in any plane.
+	cases := []struct {
+		plane inc.CANON_PLANE
+		arc   [6]float64 // first end, second end, first axis, second axis, axis end, turn
+		want  [6]float64
+	}{
+		{inc.CANON_PLANE_XY, [6]float64{0, 10, 0, 0, 0, 1}, [6]float64{0, 10, 0, 0, 20, 1}},
+		{inc.CANON_PLANE_XZ, [6]float64{10, 0, 0, 0, 0, 1}, [6]float64{30, 0, 20, 0, 0, 1}},
+		{inc.CANON_PLANE_YZ, [6]float64{0, 10, 0, 0, 10, 1}, [6]float64{0, 30, 0, 20, 10, 1}},
+	}
+	for _, c := range cases {
+		k, rec := setup(kinematics.Config_t{Type: kinematics.KINEMATICS_HEAD_AC, Pivot: 100})
+		k.SELECT_PLANE(c.plane)
+		k.STRAIGHT_FEED(10, 0, 0, 0, 0, 0)
+		rec.Reset()
+		k.ARC_FEED(c.arc[0], c.arc[1], c.arc[2], c.arc[3], int(c.arc[5]), c.arc[4], 0, 0, 0)
+		arcs := rec.Filter("ARC_FEED")
+		if len(arcs) != 1 {
+			t.Fatalf("plane %v: calls %v, want an ARC_FEED", c.plane, rec.Strings())
+		}
+		args := arcs[0].Args
+		got := [6]float64{args[0].(float64), args[1].(float64), args[2].(float64), args[3].(float64),
+			args[5].(float64), float64(args[4].(int))}
+		if got != c.want {
+			t.Errorf("plane %v: arc %v, want %v", c.plane, got, c.want)
+		}
+	}
+}
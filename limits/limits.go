@@ -0,0 +1,229 @@
+package limits
+
+import (
+	"fmt"
+
+	"github.com/flyingyizi/rs274ngc/arc"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/mux"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* limits.go
+
+   Limits_t is a middleware (see mux) which keeps the machine inside its
+   travel, for putting in front of a real machine. Every traverse
+   (including the moves of G28 and G30 to a home position), feed, arc
+   and probe move is checked before it is passed on; a move which would
+   take an axis past its least or greatest travel is refused, and the
+   interpreter stops with NCE_MOVE_OUTSIDE_MACHINE_LIMITS (see
+   inc.Fault_i). Its description (see FaultErrorText) names the line,
+   the axis, and how far past the limit the move would go.
+
+   Limits are in machine coordinates: the positions the program gives,
+   plus the origin offsets in force and, for Z, the tool length offset,
+   so a bad work offset or tool length is caught. They are in
+   millimeters for X, Y and Z, whatever the length units of the
+   program, and in degrees for A, B and C. An axis whose least travel is
+   not less than its greatest is not checked.
+
+   A straight move is checked at its end, since it starts inside the
+   limits. An arc is checked at its true extents in its plane (see
+   arc.Find_arc_extents), not just at its end.
+
+   Once a move is refused, every later move is dropped too, and the
+   interpreter stops at each block, until INIT_CANON (a new Init)
+   clears the fault. Everything else is passed through.
+
+   The start of an arc, the plane, the length units and the tool length
+   offset are read from the next Canon_i (see mux.Filter_t).
+
+*/
+
+type Limits_t struct {
+	mux.Filter_t
+
+	Min    [6]float64   // least travel of X, Y, Z in millimeters and A, B, C in degrees
+	Max    [6]float64   // greatest travel, the same
+	Source inc.Source_i // the line being run, nil if not known
+
+	origin inc.CANON_POSITION // origin offsets, X, Y and Z in millimeters
+	fault  inc.STATUS
+	text   string
+}
+
+var _ inc.Canon_i = &Limits_t{}
+var _ inc.Fault_i = &Limits_t{}
+
+// axis_names are the names of the axes, in the order of Min and Max.
+var axis_names = [6]string{"X", "Y", "Z", "A", "B", "C"}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Limits_t passing its output to next, keeping the
+   axes between min and max
+
+   Side effects: none
+
+   Called by: external programs, Middleware
+
+*/
+
+func New(next inc.Canon_i, min, max [6]float64) *Limits_t {
+	return &Limits_t{Filter_t: mux.Filter_t{Canon_i: next}, Min: min, Max: max}
+}
+
+/* Middleware
+
+   Returned Value: a mux.Middleware_f making a Limits_t with min and max
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func Middleware(min, max [6]float64) mux.Middleware_f {
+	return func(next inc.Canon_i) inc.Canon_i {
+		return New(next, min, max)
+	}
+}
+
+/***********************************************************************/
+
+/* factor
+
+   Returned Value: the number of millimeters in a length unit of the
+   next Canon_i
+
+   Side effects: none
+
+   Called by: check, SET_ORIGIN_OFFSETS
+
+*/
+
+func (l *Limits_t) factor() float64 {
+	switch l.Canon_i.GET_EXTERNAL_LENGTH_UNIT_TYPE() {
+	case inc.CANON_UNITS_INCHES:
+		return 25.4
+	case inc.CANON_UNITS_CM:
+		return 10.0
+	}
+	return 1.0
+}
+
+/* check
+
+   Returned Value: whether the moves may go through all of points (in
+   the coordinates of the program)
+
+   Side effects: if not, or if a move has been refused already, the
+   fault is set.
+
+   Called by: the motion commands of Limits_t
+
+   The fault is set for the first axis of the first point found outside
+   its limits.
+
+*/
+
+func (l *Limits_t) check(points ...inc.CANON_POSITION) bool {
+	if l.fault != inc.RS274NGC_OK {
+		return false
+	}
+	factor := l.factor()
+	length := l.Canon_i.GET_EXTERNAL_TOOL_LENGTH_OFFSET()
+	for _, p := range points {
+		p.X, p.Y, p.Z = (p.X * factor), (p.Y * factor), (p.Z * factor)
+		m := toolpath.Machine_position(p, l.origin, (length * factor))
+		machine := [6]float64{m.X, m.Y, m.Z, m.A, m.B, m.C}
+		for n, value := range machine {
+			if !(l.Min[n] < l.Max[n]) || ((value >= l.Min[n]) && (value <= l.Max[n])) {
+				continue
+			}
+			over, side := value-l.Max[n], "greatest"
+			if value < l.Min[n] {
+				over, side = l.Min[n]-value, "least"
+			}
+			line := ""
+			if l.Source != nil {
+				line = fmt.Sprintf("line %d %q: ", l.Source.SequenceNumber(), l.Source.LineText())
+			}
+			l.fault = inc.NCE_MOVE_OUTSIDE_MACHINE_LIMITS
+			l.text = fmt.Sprintf("%s: %s%s would go %.4f %s past its %s travel",
+				inc.Rs274ngc_error_text(l.fault), line, axis_names[n], over,
+				inc.If(n < 3, "mm", "degrees").(string), side)
+			return false
+		}
+	}
+	return true
+}
+
+/***********************************************************************/
+
+func (l *Limits_t) GET_EXTERNAL_FAULT() (status inc.STATUS, text string) {
+	return l.fault, l.text
+}
+
+func (l *Limits_t) INIT_CANON() {
+	l.fault, l.text = inc.RS274NGC_OK, ""
+	l.origin = inc.CANON_POSITION{}
+	l.Canon_i.INIT_CANON()
+}
+
+func (l *Limits_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	factor := l.factor()
+	l.origin = inc.CANON_POSITION{X: x * factor, Y: y * factor, Z: z * factor, A: a, B: b, C: c}
+	l.Canon_i.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+}
+
+func (l *Limits_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	if l.check(inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}) {
+		l.Canon_i.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+	}
+}
+
+func (l *Limits_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	if l.check(inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}) {
+		l.Canon_i.STRAIGHT_FEED(x, y, z, a, b, c)
+	}
+}
+
+func (l *Limits_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	if l.check(inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c}) {
+		l.Canon_i.STRAIGHT_PROBE(x, y, z, a, b, c)
+	}
+}
+
+/* ARC_FEED
+
+   Side effects: the arc is passed on if its extents are inside the
+   limits.
+
+   Called by: the interpreter
+
+   The corners of the box around the arc in its plane, at the end along
+   the axis of the plane, are checked; the axis and the rotary axes
+   change evenly from the start, which is inside the limits.
+
+*/
+
+func (l *Limits_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+
+	next := l.Canon_i
+	plane := next.GET_EXTERNAL_PLANE()
+	first, second, _ := toolpath.Plane_point(plane, inc.CANON_POSITION{X: next.GET_EXTERNAL_POSITION_X(),
+		Y: next.GET_EXTERNAL_POSITION_Y(), Z: next.GET_EXTERNAL_POSITION_Z()})
+	min1, min2, max1, max2 := arc.Find_arc_extents(first, second, first_axis, second_axis, rotation,
+		first_end, second_end)
+	low := toolpath.Plane_position(plane, min1, min2, axis_end_point)
+	high := toolpath.Plane_position(plane, max1, max2, axis_end_point)
+	low.A, low.B, low.C = a, b, c
+	high.A, high.B, high.C = a, b, c
+	if l.check(low, high) {
+		next.ARC_FEED(first_end, second_end, first_axis, second_axis, rotation, axis_end_point, a, b, c)
+	}
+}
@@ -0,0 +1,121 @@
+package limits_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/limits"
+	"github.com/flyingyizi/rs274ngc/mux"
+	"github.com/flyingyizi/rs274ngc/record"
+)
+
+// setup returns an interpreter running through a Limits_t, with X from
+// 0 to 280, Y from 0 to 200 and Z from -100 to 0 millimeters, in front
+// of a Recorder_t.
+func setup(t *testing.T) (*rs274ngc.Rs274ngc_t, *record.Recorder_t) {
+	rec := record.New()
+	rec.Tool_max = 4
+	l := limits.New(rec, [6]float64{0, 0, -100}, [6]float64{280, 200, 0})
+	cnc := cnctest.Start(t, l, &rec.Canon_world_t)
+	l.Source = cnc
+	return cnc, rec
+}
+
+func TestLimits_moves(t *testing.T) {
+	cases := []struct {
+		name    string
+		program []string
+		text    string // of the fault, "" for none
+	}{
+		{"inside", []string{"g21 g0 x10 y10 z-5", "g1 x270 y190 z-99 f100", "g28"}, ""},
+		{"work offset", []string{"g21 g10 l2 p1 z-150", "g54 g0 x10 y10", "z-10"},
+			`line 3 "z-10": Z would go 60.0000 mm past its least travel`},
+		{"inches", []string{"g20 g0 x11.1"}, `X would go 1.9400 mm past its greatest travel`},
+		{"tool length", []string{"g21 g0 x10 y10 z-60", "g43.1 z45", "g0 z-60"}, ""},
+		{"long tool", []string{"g21 g0 x10 y10 z-60", "g43.1 z-45", "g0 z-60"},
+			`Z would go 5.0000 mm past its least travel`},
+		{"arc inside", []string{"g21 g0 x250 y0", "g2 x250 y100 i0 j50 f100"}, ""},
+		{"arc outside", []string{"g21 g0 x250 y0", "g3 x250 y100 i0 j50 f100"},
+			`X would go 20.0000 mm past its greatest travel`},
+		{"probe", []string{"g21 g0 x10 y10", "g38.2 y-5 f100"}, `Y would go 5.0000 mm past its least travel`},
+	}
+	for _, c := range cases {
+		cnc, rec := setup(t)
+		var status inc.STATUS
+		for _, line := range c.program {
+			if status = cnctest.Execute(cnc, line); (status != inc.RS274NGC_OK) && (status != inc.RS274NGC_EXECUTE_FINISH) {
+				break
+			}
+		}
+		if c.text == "" {
+			if (status != inc.RS274NGC_OK) || (cnc.FaultErrorText() != "") {
+				t.Errorf("%s: status %v %q, want no fault", c.name, status, cnc.FaultErrorText())
+			}
+			continue
+		}
+		if (status != inc.NCE_MOVE_OUTSIDE_MACHINE_LIMITS) || !strings.HasSuffix(cnc.FaultErrorText(), c.text) ||
+			!strings.HasPrefix(cnc.FaultErrorText(), "Move outside machine limits: ") {
+			t.Errorf("%s: status %v %q, want a fault ending %q", c.name, status, cnc.FaultErrorText(), c.text)
+		}
+		if refused := rec.Filter("ARC_FEED", "STRAIGHT_PROBE"); len(refused) != 0 {
+			t.Errorf("%s: %v was passed on", c.name, refused[0])
+		}
+	}
+}
+
+func TestLimits_fault(t *testing.T) {
+	// After a fault, the machine does not move until Init.
+	cnc, rec := setup(t)
+	if status := cnctest.Execute(cnc, "g21 g0 z10"); status != inc.NCE_MOVE_OUTSIDE_MACHINE_LIMITS {
+		t.Fatalf("status %v, want NCE_MOVE_OUTSIDE_MACHINE_LIMITS", status)
+	}
+	rec.Reset()
+	if status := cnctest.Execute(cnc, "g0 z-10"); status != inc.NCE_MOVE_OUTSIDE_MACHINE_LIMITS {
+		t.Errorf("status %v after a fault, want NCE_MOVE_OUTSIDE_MACHINE_LIMITS", status)
+	}
+	if len(rec.Filter("STRAIGHT_TRAVERSE")) != 0 {
+		t.Errorf("moved after a fault: %v", rec.Strings())
+	}
+	if status := cnc.Init(); status != inc.RS274NGC_OK {
+		t.Fatalf("Init() = %v", status)
+	}
+	if status := cnctest.Execute(cnc, "g0 z-10"); (status != inc.RS274NGC_OK) || (len(rec.Filter("STRAIGHT_TRAVERSE")) != 1) {
+		t.Errorf("status %v and calls %v after Init, want a traverse", status, rec.Strings())
+	}
+}
+
+func TestLimits_chained(t *testing.T) {
+	// The interpreter finds the limiter behind a middleware, and inside
+	// a fanout, and stops at its fault.
+	cases := []struct {
+		name  string
+		chain func(l *limits.Limits_t, rec *record.Recorder_t) (inc.Canon_i, *inc.Canon_world_t)
+		line  string
+	}{
+		{"behind an offset", func(l *limits.Limits_t, rec *record.Recorder_t) (inc.Canon_i, *inc.Canon_world_t) {
+			return mux.Chain(l, mux.Offset(10, 0, 0)), &rec.Canon_world_t
+		}, "g21 g0 x275"},
+		{"in a fanout", func(l *limits.Limits_t, rec *record.Recorder_t) (inc.Canon_i, *inc.Canon_world_t) {
+			primary := record.New()
+			return mux.New_fanout(primary, l), &primary.Canon_world_t
+		}, "g21 g0 x285"},
+	}
+	for _, c := range cases {
+		rec := record.New()
+		l := limits.New(rec, [6]float64{0, 0, -100}, [6]float64{280, 200, 0})
+		canon, world := c.chain(l, rec)
+		world.Tool_max = 4
+		cnc := cnctest.Start(t, canon, world)
+		l.Source = cnc
+		if status := cnctest.Execute(cnc, c.line); (status != inc.NCE_MOVE_OUTSIDE_MACHINE_LIMITS) ||
+			!strings.HasSuffix(cnc.FaultErrorText(), "X would go 5.0000 mm past its greatest travel") {
+			t.Errorf("%s: status %v %q, want a fault 5 mm past X", c.name, status, cnc.FaultErrorText())
+		}
+		if len(rec.Filter("STRAIGHT_TRAVERSE")) != 0 {
+			t.Errorf("%s: the traverse was passed on: %v", c.name, rec.Strings())
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package linearize
+
+import (
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/arc"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/mux"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* linearize.go
+
+   Linearizer_t is a middleware (see mux) which breaks every ARC_FEED
+   into STRAIGHT_FEEDs, for controllers which take only lines. The lines
+   are chords of the arc, none farther from it than the tolerance (see
+   arc.Find_chord_count). Arcs in any plane, helical arcs and arcs of
+   more than one turn are broken up alike: the angle, the coordinate
+   along the axis of the plane, the radius (if the arc does not end as
+   far from its center as it starts) and the rotary axes all change
+   evenly from one chord to the next.
+   Everything else is passed through.
+
+   In units per minute feed mode, the chords are fed at the feed rate of
+   the arc, as the tool is. In inverse time feed mode, the interpreter
+   sets the feed rate so the arc takes the time programmed, so each chord
+   is given its own feed rate, to take the time of its part of the arc,
+   and the feed rate of the arc is set again after the last chord. The
+   feed mode is given by the interpreter (see inc.Feed_mode_i).
+
+   The start of an arc, the plane, the length units and the feed rate
+   are read from the next Canon_i (see mux.Filter_t).
+
+*/
+
+// TOLERANCE_DEFAULT is the chord tolerance in millimeters if none is
+// given.
+const TOLERANCE_DEFAULT = 0.001
+
+type Linearizer_t struct {
+	mux.Filter_t
+
+	Tolerance float64 // chord tolerance in millimeters
+
+	mode inc.FeedMode
+}
+
+var _ inc.Canon_i = &Linearizer_t{}
+var _ inc.Feed_mode_i = &Linearizer_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Linearizer_t passing its output to next, with
+   chords within tolerance millimeters of the arcs, TOLERANCE_DEFAULT if
+   tolerance is not positive
+
+   Side effects: none
+
+   Called by: external programs, Middleware
+
+*/
+
+func New(next inc.Canon_i, tolerance float64) *Linearizer_t {
+	if tolerance <= 0.0 {
+		tolerance = TOLERANCE_DEFAULT
+	}
+	return &Linearizer_t{Filter_t: mux.Filter_t{Canon_i: next}, Tolerance: tolerance, mode: inc.UNITS_PER_MINUTE}
+}
+
+/* Middleware
+
+   Returned Value: a mux.Middleware_f making a Linearizer_t with tolerance
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func Middleware(tolerance float64) mux.Middleware_f {
+	return func(next inc.Canon_i) inc.Canon_i {
+		return New(next, tolerance)
+	}
+}
+
+/***********************************************************************/
+
+/* ARC_FEED
+
+   Side effects: STRAIGHT_FEED calls, and in inverse time feed mode
+   SET_FEED_RATE calls, are made to the next Canon_i.
+
+   Called by: the interpreter
+
+   The chords go through evenly spaced points of the arc (see
+   toolpath.Move_t.Point_at), the last at the end of the arc exactly. The
+   number of chords is found from the greater of the radii at the ends.
+
+*/
+
+func (l *Linearizer_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+
+	next := l.Canon_i
+	plane := next.GET_EXTERNAL_PLANE()
+	move := toolpath.Move_t{Kind: toolpath.MOVE_ARC, Plane: plane, Center1: first_axis, Center2: second_axis,
+		Rotation: rotation,
+		Start: inc.CANON_POSITION{X: next.GET_EXTERNAL_POSITION_X(), Y: next.GET_EXTERNAL_POSITION_Y(),
+			Z: next.GET_EXTERNAL_POSITION_Z(), A: next.GET_EXTERNAL_POSITION_A(),
+			B: next.GET_EXTERNAL_POSITION_B(), C: next.GET_EXTERNAL_POSITION_C()},
+	}
+	move.End = toolpath.Plane_position(plane, first_end, second_end, axis_end_point)
+	move.End.A, move.End.B, move.End.C = a, b, c
+
+	tolerance := l.Tolerance
+	switch next.GET_EXTERNAL_LENGTH_UNIT_TYPE() {
+	case inc.CANON_UNITS_INCHES:
+		tolerance = tolerance / 25.4
+	case inc.CANON_UNITS_CM:
+		tolerance = tolerance / 10.0
+	}
+	first2, second2, _ := toolpath.Plane_point(plane, move.End)
+	radius := math.Max(move.Radius(), math.Hypot(first2-first_axis, second2-second_axis))
+	chords := arc.Find_chord_count(radius, move.Turn(), tolerance)
+
+	rate := next.GET_EXTERNAL_FEED_RATE()
+	piece := move.Length() / float64(chords) /* of the arc, for each chord */
+	sent := rate
+	from := move.Start
+	for n := 1; n <= chords; n++ {
+		to := move.End
+		if n < chords {
+			to = move.Point_at(float64(n) / float64(chords))
+		}
+		if (l.mode == inc.INVERSE_TIME) && (piece > 0.0) {
+			chord := arc.Find_straight_length(to.X, to.Y, to.Z, to.A, to.B, to.C,
+				from.X, from.Y, from.Z, from.A, from.B, from.C)
+			if segment_rate := rate * chord / piece; math.Abs(segment_rate-sent) > 1e-9*rate {
+				next.SET_FEED_RATE(segment_rate)
+				sent = segment_rate
+			}
+		}
+		next.STRAIGHT_FEED(to.X, to.Y, to.Z, to.A, to.B, to.C)
+		from = to
+	}
+	if sent != rate {
+		next.SET_FEED_RATE(rate)
+	}
+}
+
+func (l *Linearizer_t) SET_FEED_MODE(mode inc.FeedMode) {
+	l.mode = mode
+	if feed, ok := l.Canon_i.(inc.Feed_mode_i); ok {
+		feed.SET_FEED_MODE(mode)
+	}
+}
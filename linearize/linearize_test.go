@@ -0,0 +1,110 @@
+package linearize_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/arc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/linearize"
+	"github.com/flyingyizi/rs274ngc/mux"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+// run runs program through a Linearizer_t with tolerance in front of a
+// Path_t, and returns both.
+func run(t *testing.T, tolerance float64, program ...string) (*linearize.Linearizer_t, *toolpath.Path_t) {
+	path := toolpath.New()
+	linearizer := linearize.New(path, tolerance)
+	cnctest.Run(t, cnctest.Start(t, linearizer, &path.Canon_world_t), program...)
+	return linearizer, path
+}
+
+// length returns how far a straight move goes in X, Y and Z.
+func length(move *toolpath.Move_t) float64 {
+	return math.Sqrt(math.Pow(move.End.X-move.Start.X, 2) + math.Pow(move.End.Y-move.Start.Y, 2) +
+		math.Pow(move.End.Z-move.Start.Z, 2))
+}
+
+func TestLinearizer_chords(t *testing.T) {
+	// Half a circle of radius 10 about X10 Y0, within 0.01 mm.
+	_, path := run(t, 0.01, "g21 g17 g1 x0 y0 f100", "g2 x20 y0 i10 j0")
+	moves := path.Moves[1:]
+	if want := arc.Find_chord_count(10, math.Pi, 0.01); len(moves) != want {
+		t.Fatalf("%d chords, want %d", len(moves), want)
+	}
+	for n := range moves {
+		move := &moves[n]
+		middle := math.Hypot(((move.Start.X+move.End.X)/2)-10, (move.Start.Y+move.End.Y)/2)
+		if (move.Kind != toolpath.MOVE_FEED) || (move.Feed_rate != 100) ||
+			!(math.Abs(math.Hypot(move.End.X-10, move.End.Y)-10) < 1e-9) || (middle < 10-0.01) || (move.End.Y < -1e-9) {
+			t.Errorf("chord %d = %+v is not on the clockwise arc", n, *move)
+		}
+	}
+	if end := moves[len(moves)-1].End; (end.X != 20) || (end.Y != 0) {
+		t.Errorf("ends at X%v Y%v, want X20 Y0", end.X, end.Y)
+	}
+}
+
+func TestLinearizer_helix(t *testing.T) {
+	// Two turns and a quarter counterclockwise in the XZ-plane about X5
+	// Z0 from X10 Z0 to X5 Z-5, rising 9 in Y, in inches with a tolerance
+	// of 0.0254 mm.
+	linearizer, path := run(t, 0.0254, "g20 g18 g1 x10 y0 z0 f10")
+	linearizer.ARC_FEED(-5, 5, 0, 5, 3, 9, 0, 0, 90)
+	moves := path.Moves[1:]
+	turn := (4 * math.Pi) + (math.Pi / 2)
+	if want := arc.Find_chord_count(5, turn, 0.001); len(moves) != want {
+		t.Fatalf("%d chords, want %d", len(moves), want)
+	}
+	for n := range moves {
+		end, f := moves[n].End, float64(n+1)/float64(len(moves))
+		angle := turn * f
+		if !(math.Abs(end.Y-(9*f)) < 1e-9) || !(math.Abs(end.C-(90*f)) < 1e-9) ||
+			!(math.Abs(end.Z+(5*math.Sin(angle))) < 1e-9) || !(math.Abs(end.X-(5+(5*math.Cos(angle)))) < 1e-9) {
+			t.Errorf("chord %d ends at %+v, want %v of the way around", n, end, f)
+		}
+	}
+}
+
+func TestLinearizer_inverse_time(t *testing.T) {
+	// The arc is to take half a minute; the line before it a minute.
+	_, path := run(t, 0.01, "g21 g17 g93 g1 x0 y0 f1", "g2 x20 y0 i10 j0 f2")
+	minutes := 0.0
+	for n := 1; n < len(path.Moves); n++ {
+		minutes = minutes + (length(&path.Moves[n]) / path.Moves[n].Feed_rate)
+	}
+	if math.Abs(minutes-0.5) > 1e-9 {
+		t.Errorf("chords take %v minutes, want 0.5", minutes)
+	}
+	if want := 10 * math.Pi * 2; math.Abs(path.Feed_rate-want) > 1e-9 {
+		t.Errorf("feed rate after the arc = %v, want %v", path.Feed_rate, want)
+	}
+
+	// In units per minute mode the chords go at the feed rate, and take
+	// less time than the arc.
+	_, path = run(t, 0.01, "g21 g17 g94 g1 x0 y0 f100", "g2 x20 y0 i10 j0")
+	for n := 1; n < len(path.Moves); n++ {
+		if path.Moves[n].Feed_rate != 100 {
+			t.Fatalf("chord %d feed rate = %v, want 100", n, path.Moves[n].Feed_rate)
+		}
+	}
+}
+
+func TestLinearizer_inverse_time_chained(t *testing.T) {
+	// Behind another middleware, the linearizer is still told of G93, so
+	// the chords take the half minute the arc is to take.
+	path := toolpath.New()
+	canon := mux.Chain(path, mux.Offset(5, 0, 0), linearize.Middleware(0.01))
+	cnctest.Run(t, cnctest.Start(t, canon, &path.Canon_world_t), "g21 g17 g93 g1 x0 y0 f1", "g2 x20 y0 i10 j0 f2")
+	if len(path.Moves) < 3 {
+		t.Fatalf("%d moves, want the arc in chords", len(path.Moves))
+	}
+	minutes := 0.0
+	for n := 1; n < len(path.Moves); n++ {
+		minutes = minutes + (length(&path.Moves[n]) / path.Moves[n].Feed_rate)
+	}
+	if math.Abs(minutes-0.5) > 1e-9 {
+		t.Errorf("chords take %v minutes, want 0.5", minutes)
+	}
+}
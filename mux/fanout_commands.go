@@ -0,0 +1,342 @@
+package mux
+
+import "github.com/flyingyizi/rs274ngc/inc"
+
+/* The canonical commands of Fanout_t
+
+   Each is given to every target in turn, the primary first.
+
+*/
+
+func (f *Fanout_t) COMMENT(s string) {
+	for _, target := range f.targets {
+		target.COMMENT(s)
+	}
+}
+
+func (f *Fanout_t) DISABLE_FEED_OVERRIDE() {
+	for _, target := range f.targets {
+		target.DISABLE_FEED_OVERRIDE()
+	}
+}
+
+func (f *Fanout_t) DISABLE_SPEED_OVERRIDE() {
+	for _, target := range f.targets {
+		target.DISABLE_SPEED_OVERRIDE()
+	}
+}
+
+func (f *Fanout_t) ENABLE_FEED_OVERRIDE() {
+	for _, target := range f.targets {
+		target.ENABLE_FEED_OVERRIDE()
+	}
+}
+
+func (f *Fanout_t) ENABLE_SPEED_OVERRIDE() {
+	for _, target := range f.targets {
+		target.ENABLE_SPEED_OVERRIDE()
+	}
+}
+
+func (f *Fanout_t) INIT_CANON() {
+	for _, target := range f.targets {
+		target.INIT_CANON()
+	}
+}
+
+func (f *Fanout_t) MESSAGE(s []byte) {
+	for _, target := range f.targets {
+		target.MESSAGE(s)
+	}
+}
+
+func (f *Fanout_t) PALLET_SHUTTLE() {
+	for _, target := range f.targets {
+		target.PALLET_SHUTTLE()
+	}
+}
+
+func (f *Fanout_t) OPTIONAL_PROGRAM_STOP() {
+	for _, target := range f.targets {
+		target.OPTIONAL_PROGRAM_STOP()
+	}
+}
+
+func (f *Fanout_t) PROGRAM_END() {
+	for _, target := range f.targets {
+		target.PROGRAM_END()
+	}
+}
+
+func (f *Fanout_t) PROGRAM_STOP() {
+	for _, target := range f.targets {
+		target.PROGRAM_STOP()
+	}
+}
+
+func (f *Fanout_t) SELECT_PLANE(plane inc.CANON_PLANE) {
+	for _, target := range f.targets {
+		target.SELECT_PLANE(plane)
+	}
+}
+
+func (f *Fanout_t) SET_FEED_RATE(rate float64) {
+	for _, target := range f.targets {
+		target.SET_FEED_RATE(rate)
+	}
+}
+
+func (f *Fanout_t) SET_FEED_REFERENCE(reference inc.CANON_FEED_REFERENCE) {
+	for _, target := range f.targets {
+		target.SET_FEED_REFERENCE(reference)
+	}
+}
+
+func (f *Fanout_t) SET_MOTION_CONTROL_MODE(mode inc.CANON_MOTION_MODE) {
+	for _, target := range f.targets {
+		target.SET_MOTION_CONTROL_MODE(mode)
+	}
+}
+
+func (f *Fanout_t) START_SPEED_FEED_SYNCH() {
+	for _, target := range f.targets {
+		target.START_SPEED_FEED_SYNCH()
+	}
+}
+
+func (f *Fanout_t) STOP_SPEED_FEED_SYNCH() {
+	for _, target := range f.targets {
+		target.STOP_SPEED_FEED_SYNCH()
+	}
+}
+
+func (f *Fanout_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+	for _, target := range f.targets {
+		target.ARC_FEED(first_end, second_end, first_axis,
+			second_axis, rotation, axis_end_point, a, b, c)
+	}
+}
+
+func (f *Fanout_t) DWELL(seconds float64) {
+	for _, target := range f.targets {
+		target.DWELL(seconds)
+	}
+}
+
+func (f *Fanout_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	for _, target := range f.targets {
+		target.STRAIGHT_FEED(x, y, z, a, b, c)
+	}
+}
+
+func (f *Fanout_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	for _, target := range f.targets {
+		target.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+	}
+}
+
+func (f *Fanout_t) USE_LENGTH_UNITS(in_unit inc.CANON_UNITS) {
+	for _, target := range f.targets {
+		target.USE_LENGTH_UNITS(in_unit)
+	}
+}
+
+func (f *Fanout_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	for _, target := range f.targets {
+		target.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+	}
+}
+
+func (f *Fanout_t) ORIENT_SPINDLE(orientation float64, direction inc.CANON_DIRECTION) {
+	for _, target := range f.targets {
+		target.ORIENT_SPINDLE(orientation, direction)
+	}
+}
+
+func (f *Fanout_t) SET_SPINDLE_SPEED(speed float64) {
+	for _, target := range f.targets {
+		target.SET_SPINDLE_SPEED(speed)
+	}
+}
+
+func (f *Fanout_t) START_SPINDLE_CLOCKWISE() {
+	for _, target := range f.targets {
+		target.START_SPINDLE_CLOCKWISE()
+	}
+}
+
+func (f *Fanout_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	for _, target := range f.targets {
+		target.START_SPINDLE_COUNTERCLOCKWISE()
+	}
+}
+
+func (f *Fanout_t) STOP_SPINDLE_TURNING() {
+	for _, target := range f.targets {
+		target.STOP_SPINDLE_TURNING()
+	}
+}
+
+func (f *Fanout_t) FLOOD_OFF() {
+	for _, target := range f.targets {
+		target.FLOOD_OFF()
+	}
+}
+
+func (f *Fanout_t) FLOOD_ON() {
+	for _, target := range f.targets {
+		target.FLOOD_ON()
+	}
+}
+
+func (f *Fanout_t) MIST_OFF() {
+	for _, target := range f.targets {
+		target.MIST_OFF()
+	}
+}
+
+func (f *Fanout_t) MIST_ON() {
+	for _, target := range f.targets {
+		target.MIST_ON()
+	}
+}
+
+func (f *Fanout_t) CHANGE_TOOL(slot int) {
+	for _, target := range f.targets {
+		target.CHANGE_TOOL(slot)
+	}
+}
+
+func (f *Fanout_t) SELECT_TOOL(i int) {
+	for _, target := range f.targets {
+		target.SELECT_TOOL(i)
+	}
+}
+
+func (f *Fanout_t) USE_TOOL_LENGTH_OFFSET(offset float64) {
+	for _, target := range f.targets {
+		target.USE_TOOL_LENGTH_OFFSET(offset)
+	}
+}
+
+func (f *Fanout_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	for _, target := range f.targets {
+		target.STRAIGHT_PROBE(x, y, z, a, b, c)
+	}
+}
+
+func (f *Fanout_t) TURN_PROBE_OFF() {
+	for _, target := range f.targets {
+		target.TURN_PROBE_OFF()
+	}
+}
+
+func (f *Fanout_t) TURN_PROBE_ON() {
+	for _, target := range f.targets {
+		target.TURN_PROBE_ON()
+	}
+}
+
+/* The canonical extensions of Fanout_t
+
+   Each, and each cutter radius compensation function, is given to the
+   targets which have the functions.
+
+*/
+
+func (f *Fanout_t) SET_TRAVERSE_RATE(rate float64) {
+	for _, target := range f.targets {
+		if machine, ok := target.(inc.Canon_machine_i); ok {
+			machine.SET_TRAVERSE_RATE(rate)
+		}
+	}
+}
+
+func (f *Fanout_t) STOP() {
+	for _, target := range f.targets {
+		if machine, ok := target.(inc.Canon_machine_i); ok {
+			machine.STOP()
+		}
+	}
+}
+
+func (f *Fanout_t) SET_SPINDLE_TORQUE(torque float64) {
+	for _, target := range f.targets {
+		if machine, ok := target.(inc.Canon_machine_i); ok {
+			machine.SET_SPINDLE_TORQUE(torque)
+		}
+	}
+}
+
+func (f *Fanout_t) SPINDLE_RETRACT() {
+	for _, target := range f.targets {
+		if machine, ok := target.(inc.Canon_machine_i); ok {
+			machine.SPINDLE_RETRACT()
+		}
+	}
+}
+
+func (f *Fanout_t) SPINDLE_RETRACT_TRAVERSE() {
+	for _, target := range f.targets {
+		if machine, ok := target.(inc.Canon_machine_i); ok {
+			machine.SPINDLE_RETRACT_TRAVERSE()
+		}
+	}
+}
+
+func (f *Fanout_t) USE_NO_SPINDLE_FORCE() {
+	for _, target := range f.targets {
+		if machine, ok := target.(inc.Canon_machine_i); ok {
+			machine.USE_NO_SPINDLE_FORCE()
+		}
+	}
+}
+
+func (f *Fanout_t) USE_SPINDLE_FORCE(force float64) {
+	for _, target := range f.targets {
+		if machine, ok := target.(inc.Canon_machine_i); ok {
+			machine.USE_SPINDLE_FORCE(force)
+		}
+	}
+}
+
+func (f *Fanout_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
+	for _, target := range f.targets {
+		if ext, ok := target.(inc.Canon_ext_i); ok {
+			ext.CLAMP_AXIS(axis)
+		}
+	}
+}
+
+func (f *Fanout_t) UNCLAMP_AXIS(axis inc.CANON_AXIS) {
+	for _, target := range f.targets {
+		if ext, ok := target.(inc.Canon_ext_i); ok {
+			ext.UNCLAMP_AXIS(axis)
+		}
+	}
+}
+
+func (f *Fanout_t) SET_CUTTER_RADIUS_COMPENSATION(radius float64) {
+	for _, target := range f.targets {
+		if comp, ok := target.(inc.Comp_i); ok {
+			comp.SET_CUTTER_RADIUS_COMPENSATION(radius)
+		}
+	}
+}
+
+func (f *Fanout_t) START_CUTTER_RADIUS_COMPENSATION(side inc.CANON_SIDE) {
+	for _, target := range f.targets {
+		if comp, ok := target.(inc.Comp_i); ok {
+			comp.START_CUTTER_RADIUS_COMPENSATION(side)
+		}
+	}
+}
+
+func (f *Fanout_t) STOP_CUTTER_RADIUS_COMPENSATION() {
+	for _, target := range f.targets {
+		if comp, ok := target.(inc.Comp_i); ok {
+			comp.STOP_CUTTER_RADIUS_COMPENSATION()
+		}
+	}
+}
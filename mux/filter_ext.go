@@ -0,0 +1,82 @@
+package mux
+
+import "github.com/flyingyizi/rs274ngc/inc"
+
+/* The canonical extensions of Filter_t
+
+   Each, and each cutter radius compensation function, is passed on to
+   the next Canon_i if it has the functions, and dropped otherwise.
+
+*/
+
+func (f Filter_t) SET_TRAVERSE_RATE(rate float64) {
+	if machine, ok := f.Canon_i.(inc.Canon_machine_i); ok {
+		machine.SET_TRAVERSE_RATE(rate)
+	}
+}
+
+func (f Filter_t) STOP() {
+	if machine, ok := f.Canon_i.(inc.Canon_machine_i); ok {
+		machine.STOP()
+	}
+}
+
+func (f Filter_t) SET_SPINDLE_TORQUE(torque float64) {
+	if machine, ok := f.Canon_i.(inc.Canon_machine_i); ok {
+		machine.SET_SPINDLE_TORQUE(torque)
+	}
+}
+
+func (f Filter_t) SPINDLE_RETRACT() {
+	if machine, ok := f.Canon_i.(inc.Canon_machine_i); ok {
+		machine.SPINDLE_RETRACT()
+	}
+}
+
+func (f Filter_t) SPINDLE_RETRACT_TRAVERSE() {
+	if machine, ok := f.Canon_i.(inc.Canon_machine_i); ok {
+		machine.SPINDLE_RETRACT_TRAVERSE()
+	}
+}
+
+func (f Filter_t) USE_NO_SPINDLE_FORCE() {
+	if machine, ok := f.Canon_i.(inc.Canon_machine_i); ok {
+		machine.USE_NO_SPINDLE_FORCE()
+	}
+}
+
+func (f Filter_t) USE_SPINDLE_FORCE(force float64) {
+	if machine, ok := f.Canon_i.(inc.Canon_machine_i); ok {
+		machine.USE_SPINDLE_FORCE(force)
+	}
+}
+
+func (f Filter_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
+	if ext, ok := f.Canon_i.(inc.Canon_ext_i); ok {
+		ext.CLAMP_AXIS(axis)
+	}
+}
+
+func (f Filter_t) UNCLAMP_AXIS(axis inc.CANON_AXIS) {
+	if ext, ok := f.Canon_i.(inc.Canon_ext_i); ok {
+		ext.UNCLAMP_AXIS(axis)
+	}
+}
+
+func (f Filter_t) SET_CUTTER_RADIUS_COMPENSATION(radius float64) {
+	if comp, ok := f.Canon_i.(inc.Comp_i); ok {
+		comp.SET_CUTTER_RADIUS_COMPENSATION(radius)
+	}
+}
+
+func (f Filter_t) START_CUTTER_RADIUS_COMPENSATION(side inc.CANON_SIDE) {
+	if comp, ok := f.Canon_i.(inc.Comp_i); ok {
+		comp.START_CUTTER_RADIUS_COMPENSATION(side)
+	}
+}
+
+func (f Filter_t) STOP_CUTTER_RADIUS_COMPENSATION() {
+	if comp, ok := f.Canon_i.(inc.Comp_i); ok {
+		comp.STOP_CUTTER_RADIUS_COMPENSATION()
+	}
+}
@@ -0,0 +1,233 @@
+package mux
+
+import "github.com/flyingyizi/rs274ngc/inc"
+
+/* mux.go
+
+   This has two ways of putting Canon_i's together.
+
+   Fanout_t gives every canonical command to several Canon_i's, for
+   example a machine driver, a logger, and a viewer. One of them, the
+   primary, is the machine: the world-give-information functions are
+   answered by it alone, and it is given each command first.
+
+   A middleware is a Canon_i which changes the commands it is given
+   (converting units, shifting positions, checking limits) before
+   passing them to the next Canon_i. Filter_t passes everything through
+   unchanged; a middleware embeds it and has only the functions it
+   changes. Chain puts middlewares in front of a Canon_i. Offset_t is a
+   middleware that shifts positions.
+
+   For example, to shift a program and both run and log it:
+
+      canon := mux.Chain(mux.New_fanout(machine, logger), mux.Offset(10, 0, 0))
+      cnc.SetCanon(canon)
+
+   Both have an Unwrap method giving what they pass commands to, as the
+   errors package does for wrapped errors, so the interpreter can find
+   the optional functions (inc.Tcpc_i and the like) of the Canon_i's
+   behind them.
+
+*/
+
+/***********************************************************************/
+
+type Fanout_t struct {
+	inc.Canon_world_i // the primary
+
+	targets []inc.Canon_i // the primary first
+}
+
+var _ inc.Canon_i = &Fanout_t{}
+var _ inc.Canon_ext_i = &Fanout_t{}
+var _ inc.Canon_machine_i = &Fanout_t{}
+var _ inc.Comp_i = &Fanout_t{}
+
+/* New_fanout
+
+   Returned Value: *Fanout_t
+
+   Side effects: none
+
+   Called by: external programs
+
+   The commands are given to primary and then to others in order.
+   Only primary answers the world-give-information functions.
+
+*/
+
+func New_fanout(primary inc.Canon_i, others ...inc.Canon_i) *Fanout_t {
+	return &Fanout_t{Canon_world_i: primary, targets: append([]inc.Canon_i{primary}, others...)}
+}
+
+// Unwrap returns the targets, the primary first.
+func (f *Fanout_t) Unwrap() []inc.Canon_i {
+	return f.targets
+}
+
+/***********************************************************************/
+
+/* Filter_t
+
+   Filter_t passes every function to the Canon_i it embeds, the next one
+   in the chain. The canonical extensions and the cutter radius
+   compensation functions are passed on only if the next Canon_i has
+   them.
+
+   A middleware may read the state of the machine (the position, the
+   plane, the length units and so on) from the next Canon_i rather than
+   keep its own, since the next Canon_i must keep it and answer the
+   world-give-information functions, as a machine must for the
+   interpreter. A middleware which holds commands back, or changes those
+   which set that state, answers for itself instead.
+
+*/
+
+type Filter_t struct {
+	inc.Canon_i
+}
+
+var _ inc.Canon_ext_i = Filter_t{}
+var _ inc.Canon_machine_i = Filter_t{}
+var _ inc.Comp_i = Filter_t{}
+
+// Unwrap returns the next Canon_i.
+func (f Filter_t) Unwrap() inc.Canon_i {
+	return f.Canon_i
+}
+
+// Middleware_f makes a middleware in front of next.
+type Middleware_f func(next inc.Canon_i) inc.Canon_i
+
+/* Chain
+
+   Returned Value: inc.Canon_i
+   The first middleware, in front of the second, and so on, the last in
+   front of target. With no middlewares, this is target.
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func Chain(target inc.Canon_i, middlewares ...Middleware_f) inc.Canon_i {
+	canon := target
+	for n := len(middlewares) - 1; n >= 0; n-- {
+		canon = middlewares[n](canon)
+	}
+	return canon
+}
+
+/***********************************************************************/
+
+/* Offset_t
+
+   Offset_t is a middleware which moves every position by a fixed offset,
+   given in the units in use, as though the work were fixtured that much
+   farther along the axes. Positions read back from the next Canon_i are
+   moved back, so the interpreter does not notice.
+
+*/
+
+type Offset_t struct {
+	Filter_t
+
+	Offset inc.CANON_POSITION
+}
+
+/* Offset
+
+   Returned Value: a Middleware_f making an Offset_t
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func Offset(x, y, z float64) Middleware_f {
+	return func(next inc.Canon_i) inc.Canon_i {
+		return &Offset_t{Filter_t: Filter_t{next}, Offset: inc.CANON_POSITION{X: x, Y: y, Z: z}}
+	}
+}
+
+func (o *Offset_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	o.Canon_i.STRAIGHT_TRAVERSE(x+o.Offset.X, y+o.Offset.Y, z+o.Offset.Z,
+		a+o.Offset.A, b+o.Offset.B, c+o.Offset.C)
+}
+
+func (o *Offset_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	o.Canon_i.STRAIGHT_FEED(x+o.Offset.X, y+o.Offset.Y, z+o.Offset.Z,
+		a+o.Offset.A, b+o.Offset.B, c+o.Offset.C)
+}
+
+func (o *Offset_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	o.Canon_i.STRAIGHT_PROBE(x+o.Offset.X, y+o.Offset.Y, z+o.Offset.Z,
+		a+o.Offset.A, b+o.Offset.B, c+o.Offset.C)
+}
+
+func (o *Offset_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+
+	var first, second, axis float64
+	switch o.Canon_i.GET_EXTERNAL_PLANE() {
+	case inc.CANON_PLANE_YZ:
+		first, second, axis = o.Offset.Y, o.Offset.Z, o.Offset.X
+	case inc.CANON_PLANE_XZ:
+		first, second, axis = o.Offset.Z, o.Offset.X, o.Offset.Y
+	default:
+		first, second, axis = o.Offset.X, o.Offset.Y, o.Offset.Z
+	}
+	o.Canon_i.ARC_FEED(first_end+first, second_end+second, first_axis+first,
+		second_axis+second, rotation, axis_end_point+axis,
+		a+o.Offset.A, b+o.Offset.B, c+o.Offset.C)
+}
+
+func (o *Offset_t) GET_EXTERNAL_POSITION_A() float64 {
+	return o.Canon_i.GET_EXTERNAL_POSITION_A() - o.Offset.A
+}
+
+func (o *Offset_t) GET_EXTERNAL_POSITION_B() float64 {
+	return o.Canon_i.GET_EXTERNAL_POSITION_B() - o.Offset.B
+}
+
+func (o *Offset_t) GET_EXTERNAL_POSITION_C() float64 {
+	return o.Canon_i.GET_EXTERNAL_POSITION_C() - o.Offset.C
+}
+
+func (o *Offset_t) GET_EXTERNAL_POSITION_X() float64 {
+	return o.Canon_i.GET_EXTERNAL_POSITION_X() - o.Offset.X
+}
+
+func (o *Offset_t) GET_EXTERNAL_POSITION_Y() float64 {
+	return o.Canon_i.GET_EXTERNAL_POSITION_Y() - o.Offset.Y
+}
+
+func (o *Offset_t) GET_EXTERNAL_POSITION_Z() float64 {
+	return o.Canon_i.GET_EXTERNAL_POSITION_Z() - o.Offset.Z
+}
+
+func (o *Offset_t) GET_EXTERNAL_PROBE_POSITION_A() float64 {
+	return o.Canon_i.GET_EXTERNAL_PROBE_POSITION_A() - o.Offset.A
+}
+
+func (o *Offset_t) GET_EXTERNAL_PROBE_POSITION_B() float64 {
+	return o.Canon_i.GET_EXTERNAL_PROBE_POSITION_B() - o.Offset.B
+}
+
+func (o *Offset_t) GET_EXTERNAL_PROBE_POSITION_C() float64 {
+	return o.Canon_i.GET_EXTERNAL_PROBE_POSITION_C() - o.Offset.C
+}
+
+func (o *Offset_t) GET_EXTERNAL_PROBE_POSITION_X() float64 {
+	return o.Canon_i.GET_EXTERNAL_PROBE_POSITION_X() - o.Offset.X
+}
+
+func (o *Offset_t) GET_EXTERNAL_PROBE_POSITION_Y() float64 {
+	return o.Canon_i.GET_EXTERNAL_PROBE_POSITION_Y() - o.Offset.Y
+}
+
+func (o *Offset_t) GET_EXTERNAL_PROBE_POSITION_Z() float64 {
+	return o.Canon_i.GET_EXTERNAL_PROBE_POSITION_Z() - o.Offset.Z
+}
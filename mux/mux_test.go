@@ -0,0 +1,143 @@
+package mux_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/kinematics"
+	"github.com/flyingyizi/rs274ngc/mux"
+	"github.com/flyingyizi/rs274ngc/record"
+)
+
+func TestFanout(t *testing.T) {
+	primary, logger := record.New(), record.New()
+	primary.Tool_max = 4
+	logger.Tool_max = 99
+	fanout := mux.New_fanout(primary, logger, inc.Canon_default_t{})
+	cnc := cnctest.Start(t, fanout, &primary.Canon_world_t)
+	cnctest.Run(t, cnc, "g0 x1 y2", "m26", "g1 f10 x3")
+
+	if len(primary.Calls) == 0 {
+		t.Fatalf("the primary was given nothing")
+	}
+	if !reflect.DeepEqual(logger.Strings(), primary.Strings()) {
+		t.Errorf("logger:\n%v\nprimary:\n%v", logger.Strings(), primary.Strings())
+	}
+	if len(primary.Filter("CLAMP_AXIS")) != 1 {
+		t.Errorf("calls = %v, want a CLAMP_AXIS", primary.Strings())
+	}
+	if fanout.GET_EXTERNAL_TOOL_MAX() != 4 {
+		t.Errorf("GET_EXTERNAL_TOOL_MAX() = %d, want the primary's 4", fanout.GET_EXTERNAL_TOOL_MAX())
+	}
+}
+
+// tag_t is a middleware which comments on each feed with its name.
+type tag_t struct {
+	mux.Filter_t
+	name string
+}
+
+func (tag *tag_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	tag.Canon_i.COMMENT(tag.name)
+	tag.Canon_i.STRAIGHT_FEED(x, y, z, a, b, c)
+}
+
+func tag(name string) mux.Middleware_f {
+	return func(next inc.Canon_i) inc.Canon_i {
+		return &tag_t{Filter_t: mux.Filter_t{Canon_i: next}, name: name}
+	}
+}
+
+func TestChain(t *testing.T) {
+	rec := record.New()
+	canon := mux.Chain(rec, tag("first"), mux.Offset(10, 0, 1), tag("last"))
+	cnc := cnctest.Start(t, canon, &rec.Canon_world_t)
+	cnctest.Run(t, cnc, "g0 x1 y2", "g1 f10 x3", "g18 g2 x5 i1 k0", "m26")
+	rec.Calls = rec.Filter("COMMENT", "STRAIGHT_TRAVERSE", "STRAIGHT_FEED", "ARC_FEED", "CLAMP_AXIS")
+
+	rec.Expect(t,
+		"STRAIGHT_TRAVERSE(11.0000, 2.0000, 0.0000, 0.0000, 0.0000, 0.0000)",
+		`COMMENT("first")`,
+		`COMMENT("last")`,
+		"STRAIGHT_FEED(13.0000, 2.0000, 0.0000, 0.0000, 0.0000, 0.0000)",
+		"ARC_FEED(0.0000, 15.0000, 0.0000, 14.0000, -1, 2.0000, 0.0000, 0.0000, 0.0000)",
+		"CLAMP_AXIS(CANON_AXIS_A)")
+
+	// The machine started at Z0, which the interpreter sees as Z-1.
+	if (canon.GET_EXTERNAL_POSITION_X() != 5) || (canon.GET_EXTERNAL_POSITION_Z() != -1) {
+		t.Errorf("position = %v, %v; want it unshifted, 5, -1",
+			canon.GET_EXTERNAL_POSITION_X(), canon.GET_EXTERNAL_POSITION_Z())
+	}
+}
+
+// modes_t is a middleware which keeps the feed modes and tolerances it
+// is given, without passing them on.
+type modes_t struct {
+	mux.Filter_t
+	modes      []inc.FeedMode
+	tolerances []float64
+}
+
+func (m *modes_t) SET_FEED_MODE(mode inc.FeedMode) {
+	m.modes = append(m.modes, mode)
+}
+
+func (m *modes_t) SET_MOTION_CONTROL_TOLERANCE(path, naive float64) {
+	m.tolerances = append(m.tolerances, path)
+}
+
+func TestChain_optional(t *testing.T) {
+	// The optional functions of Canon_i's behind a middleware and in a
+	// fanout are found, and each is called once.
+	rec, logger := record.New(), record.New()
+	rec.Tool_max = 4
+	rec.Tools = make([]inc.CANON_TOOL_TABLE, 5)
+	rec.Tools[1].Length = 20
+	head := kinematics.New(rec, kinematics.Config_t{Type: kinematics.KINEMATICS_HEAD_BC, Pivot: 150})
+	first, second := &modes_t{Filter_t: mux.Filter_t{Canon_i: head}}, &modes_t{Filter_t: mux.Filter_t{Canon_i: logger}}
+	canon := mux.Chain(mux.New_fanout(first, second), mux.Offset(10, 0, 0))
+	cnc := cnctest.Start(t, canon, &rec.Canon_world_t)
+	cnctest.Run(t, cnc, "g21 g43.4 h1", "g93 g64 p0.5", "g94")
+
+	if head.GET_EXTERNAL_TOOL_CENTER_POINT_CONTROL() == 0 {
+		t.Errorf("TCPC is off after G43.4")
+	}
+	for n, m := range []*modes_t{first, second} {
+		if want := []inc.FeedMode{inc.INVERSE_TIME, inc.UNITS_PER_MINUTE}; !reflect.DeepEqual(m.modes, want) {
+			t.Errorf("target %d: feed modes %v, want %v", n+1, m.modes, want)
+		}
+		if want := []float64{0.5}; !reflect.DeepEqual(m.tolerances, want) {
+			t.Errorf("target %d: tolerances %v, want %v", n+1, m.tolerances, want)
+		}
+	}
+}
+
+func TestChain_machine_functions(t *testing.T) {
+	// The NIST functions the interpreter never calls are passed on by
+	// middlewares and fanouts to the canons which have them.
+	primary, logger := record.New(), record.New()
+	canon := mux.Chain(mux.New_fanout(primary, logger, inc.Canon_default_t{}), mux.Offset(1, 2, 3))
+	machine, ok := canon.(inc.Canon_machine_i)
+	if !ok {
+		t.Fatalf("%T is not an inc.Canon_machine_i", canon)
+	}
+	machine.SET_TRAVERSE_RATE(500)
+	machine.STOP()
+	machine.SET_SPINDLE_TORQUE(2)
+	machine.SPINDLE_RETRACT()
+	machine.SPINDLE_RETRACT_TRAVERSE()
+	machine.USE_NO_SPINDLE_FORCE()
+	machine.USE_SPINDLE_FORCE(3)
+	want := []string{"SET_TRAVERSE_RATE(500.0000)", "STOP()", "SET_SPINDLE_TORQUE(2.0000)", "SPINDLE_RETRACT()",
+		"SPINDLE_RETRACT_TRAVERSE()", "USE_NO_SPINDLE_FORCE()", "USE_SPINDLE_FORCE(3.0000)"}
+	for _, rec := range []*record.Recorder_t{primary, logger} {
+		if got := rec.Strings(); !reflect.DeepEqual(got, want) {
+			t.Errorf("calls %v, want %v", got, want)
+		}
+	}
+	if primary.GET_EXTERNAL_TRAVERSE_RATE() != 500 {
+		t.Errorf("traverse rate %g, want 500", primary.GET_EXTERNAL_TRAVERSE_RATE())
+	}
+}
@@ -0,0 +1,377 @@
+package nist
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* writer.go
+
+   Writer_t is a Canon_i which writes each canonical command it is given
+   to an io.Writer in the format of the NIST stand-alone interpreter, so
+   that its output can be compared with that of the C reference
+   interpreter line for line. On each output line is printed:
+   1. an output line number (sequential, starting with 1), in five
+   columns, followed by a space.
+   2. the line number (N word) of the input line, written as N followed
+   by the digits padded with spaces to six columns, or "N....." if the
+   input line has none.
+   3. a printed representation of the function call which was made.
+
+   For example:
+
+       5 N0010  STRAIGHT_FEED(1.0000, 2.0000, 0.0000, 0.0000, 0.0000, 0.0000)
+
+   INIT_CANON and the world-give-information functions print nothing,
+   as in the reference. The world model is kept by the embedded
+   inc.Canon_world_t.
+
+   If Source is set, the line number is taken from the text of the line
+   the interpreter is on; an interpreter (rs274ngc.Rs274ngc_t) serves as
+   a Source. Otherwise every line is written with "N.....".
+
+   The first error from the io.Writer is kept in Err, and nothing more
+   is written after it.
+
+*/
+
+type Writer_t struct {
+	inc.Canon_world_t
+
+	Source inc.Source_i
+	Err    error
+
+	out         io.Writer
+	line_number int // of the next output line
+}
+
+var _ inc.Canon_i = &Writer_t{}
+var _ inc.Canon_ext_i = &Writer_t{}
+var _ inc.Canon_machine_i = &Writer_t{}
+var _ inc.Comp_i = &Writer_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Writer_t writing to out, with the world model of
+   a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New(out io.Writer) *Writer_t {
+	return &Writer_t{out: out, line_number: 1}
+}
+
+/***********************************************************************/
+
+/* print
+
+   Returned Value: none
+
+   Side effects:
+   One output line is written, and the output line number is incremented.
+   If the write fails, the error is saved in w.Err.
+
+   Called by: every canonical command of Writer_t except INIT_CANON
+
+   This is PRINT0 ... PRINT10 of the NIST canon.cc.
+
+*/
+
+func (w *Writer_t) print(format string, args ...interface{}) {
+	if w.Err != nil {
+		return
+	}
+	text := fmt.Sprintf("%5d ", w.line_number) + w.nc_line_number() +
+		fmt.Sprintf(format, args...) + "\n"
+	w.line_number++
+	_, w.Err = io.WriteString(w.out, text)
+}
+
+/***********************************************************************/
+
+/* nc_line_number
+
+   Returned Value: string
+   If the input line, skipping leading spaces, tabs, and block delete
+   slashes, starts with N or n, this is N followed by the digits of the
+   line number, padded with spaces to seven characters in all. Otherwise
+   it is "N..... ".
+
+   Side effects: none
+
+   Called by: Writer_t.print
+
+   This is print_nc_line_number of the NIST canon.cc. Like the original,
+   it does not pad a line number of more than six digits.
+
+*/
+
+func (w *Writer_t) nc_line_number() string {
+	var text string
+	if w.Source != nil {
+		text = w.Source.LineText()
+	}
+	k := 0
+	for (k < len(text)) && ((text[k] == '\t') || (text[k] == ' ') || (text[k] == '/')) {
+		k++
+	}
+	if (k == len(text)) || ((text[k] != 'n') && (text[k] != 'N')) {
+		return "N..... "
+	}
+	number := "N"
+	for k++; (k < len(text)) && (text[k] >= '0') && (text[k] <= '9'); k++ {
+		number = number + text[k:k+1]
+	}
+	return fmt.Sprintf("%-7s", number)
+}
+
+/* Representation */
+
+func (w *Writer_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	w.print("SET_ORIGIN_OFFSETS(%.4f, %.4f, %.4f, %.4f, %.4f, %.4f)", x, y, z, a, b, c)
+	w.Canon_world_t.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+}
+
+func (w *Writer_t) USE_LENGTH_UNITS(in_unit inc.CANON_UNITS) {
+	w.print("USE_LENGTH_UNITS(%s)", in_unit)
+	w.Canon_world_t.USE_LENGTH_UNITS(in_unit)
+}
+
+/* Free Space Motion */
+
+func (w *Writer_t) SET_TRAVERSE_RATE(rate float64) {
+	w.print("SET_TRAVERSE_RATE(%.4f)", rate)
+	w.Canon_world_t.SET_TRAVERSE_RATE(rate)
+}
+
+func (w *Writer_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	w.print("STRAIGHT_TRAVERSE(%.4f, %.4f, %.4f, %.4f, %.4f, %.4f)", x, y, z, a, b, c)
+	w.Canon_world_t.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+}
+
+/* Machining Attributes */
+
+func (w *Writer_t) SET_FEED_RATE(rate float64) {
+	w.print("SET_FEED_RATE(%.4f)", rate)
+	w.Canon_world_t.SET_FEED_RATE(rate)
+}
+
+func (w *Writer_t) SET_FEED_REFERENCE(reference inc.CANON_FEED_REFERENCE) {
+	w.print("SET_FEED_REFERENCE(%s)",
+		inc.If(reference == inc.CANON_WORKPIECE, "CANON_WORKPIECE", "CANON_XYZ").(string))
+}
+
+func (w *Writer_t) SET_MOTION_CONTROL_MODE(mode inc.CANON_MOTION_MODE) {
+	w.print("SET_MOTION_CONTROL_MODE(%s)", mode)
+	w.Canon_world_t.SET_MOTION_CONTROL_MODE(mode)
+}
+
+func (w *Writer_t) SELECT_PLANE(plane inc.CANON_PLANE) {
+	w.print("SELECT_PLANE(%s)", plane)
+	w.Canon_world_t.SELECT_PLANE(plane)
+}
+
+func (w *Writer_t) SET_CUTTER_RADIUS_COMPENSATION(radius float64) {
+	w.print("SET_CUTTER_RADIUS_COMPENSATION(%.4f)", radius)
+}
+
+func (w *Writer_t) START_CUTTER_RADIUS_COMPENSATION(side inc.CANON_SIDE) {
+	w.print("START_CUTTER_RADIUS_COMPENSATION(%s)",
+		inc.If(side == inc.CANON_SIDE_LEFT, "LEFT",
+			inc.If(side == inc.CANON_SIDE_RIGHT, "RIGHT", "UNKNOWN")).(string))
+}
+
+func (w *Writer_t) STOP_CUTTER_RADIUS_COMPENSATION() {
+	w.print("STOP_CUTTER_RADIUS_COMPENSATION()")
+}
+
+func (w *Writer_t) START_SPEED_FEED_SYNCH() {
+	w.print("START_SPEED_FEED_SYNCH()")
+}
+
+func (w *Writer_t) STOP_SPEED_FEED_SYNCH() {
+	w.print("STOP_SPEED_FEED_SYNCH()")
+}
+
+/* Machining Functions */
+
+func (w *Writer_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+
+	w.print("ARC_FEED(%.4f, %.4f, %.4f, %.4f, %d, %.4f, %.4f, %.4f, %.4f)",
+		first_end, second_end, first_axis, second_axis, rotation, axis_end_point, a, b, c)
+	w.Canon_world_t.ARC_FEED(first_end, second_end, first_axis,
+		second_axis, rotation, axis_end_point, a, b, c)
+}
+
+func (w *Writer_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	w.print("STRAIGHT_FEED(%.4f, %.4f, %.4f, %.4f, %.4f, %.4f)", x, y, z, a, b, c)
+	w.Canon_world_t.STRAIGHT_FEED(x, y, z, a, b, c)
+}
+
+func (w *Writer_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	w.print("STRAIGHT_PROBE(%.4f, %.4f, %.4f, %.4f, %.4f, %.4f)", x, y, z, a, b, c)
+	w.Canon_world_t.STRAIGHT_PROBE(x, y, z, a, b, c)
+}
+
+func (w *Writer_t) STOP() {
+	w.print("STOP()")
+}
+
+func (w *Writer_t) DWELL(seconds float64) {
+	w.print("DWELL(%.4f)", seconds)
+}
+
+/* Spindle Functions */
+
+func (w *Writer_t) SPINDLE_RETRACT_TRAVERSE() {
+	w.print("SPINDLE_RETRACT_TRAVERSE()")
+}
+
+func (w *Writer_t) START_SPINDLE_CLOCKWISE() {
+	w.print("START_SPINDLE_CLOCKWISE()")
+	w.Canon_world_t.START_SPINDLE_CLOCKWISE()
+}
+
+func (w *Writer_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	w.print("START_SPINDLE_COUNTERCLOCKWISE()")
+	w.Canon_world_t.START_SPINDLE_COUNTERCLOCKWISE()
+}
+
+func (w *Writer_t) SET_SPINDLE_SPEED(rpm float64) {
+	w.print("SET_SPINDLE_SPEED(%.4f)", rpm)
+	w.Canon_world_t.SET_SPINDLE_SPEED(rpm)
+}
+
+func (w *Writer_t) STOP_SPINDLE_TURNING() {
+	w.print("STOP_SPINDLE_TURNING()")
+	w.Canon_world_t.STOP_SPINDLE_TURNING()
+}
+
+func (w *Writer_t) SPINDLE_RETRACT() {
+	w.print("SPINDLE_RETRACT()")
+}
+
+func (w *Writer_t) ORIENT_SPINDLE(orientation float64, direction inc.CANON_DIRECTION) {
+	w.print("ORIENT_SPINDLE(%.4f, %s)", orientation,
+		inc.If(direction == inc.CANON_CLOCKWISE, "CANON_CLOCKWISE", "CANON_COUNTERCLOCKWISE").(string))
+}
+
+func (w *Writer_t) USE_NO_SPINDLE_FORCE() {
+	w.print("USE_NO_SPINDLE_FORCE()")
+}
+
+func (w *Writer_t) USE_SPINDLE_FORCE(force float64) {
+	w.print("USE_SPINDLE_FORCE(%.4f)", force)
+}
+
+func (w *Writer_t) SET_SPINDLE_TORQUE(torque float64) {
+	w.print("SET_SPINDLE_TORQUE(%.4f)", torque)
+}
+
+/* Tool Functions */
+
+func (w *Writer_t) USE_TOOL_LENGTH_OFFSET(length float64) {
+	w.print("USE_TOOL_LENGTH_OFFSET(%.4f)", length)
+	w.Canon_world_t.USE_TOOL_LENGTH_OFFSET(length)
+}
+
+func (w *Writer_t) CHANGE_TOOL(slot int) {
+	w.print("CHANGE_TOOL(%d)", slot)
+	w.Canon_world_t.CHANGE_TOOL(slot)
+}
+
+func (w *Writer_t) SELECT_TOOL(slot int) {
+	w.print("SELECT_TOOL(%d)", slot)
+}
+
+/* Misc Functions */
+
+func (w *Writer_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
+	w.print("CLAMP_AXIS(%s)", axis)
+}
+
+func (w *Writer_t) COMMENT(s string) {
+	w.print("COMMENT(\"%s\")", s)
+}
+
+func (w *Writer_t) DISABLE_FEED_OVERRIDE() {
+	w.print("DISABLE_FEED_OVERRIDE()")
+}
+
+func (w *Writer_t) DISABLE_SPEED_OVERRIDE() {
+	w.print("DISABLE_SPEED_OVERRIDE()")
+}
+
+func (w *Writer_t) ENABLE_FEED_OVERRIDE() {
+	w.print("ENABLE_FEED_OVERRIDE()")
+}
+
+func (w *Writer_t) ENABLE_SPEED_OVERRIDE() {
+	w.print("ENABLE_SPEED_OVERRIDE()")
+}
+
+func (w *Writer_t) FLOOD_OFF() {
+	w.print("FLOOD_OFF()")
+	w.Canon_world_t.FLOOD_OFF()
+}
+
+func (w *Writer_t) FLOOD_ON() {
+	w.print("FLOOD_ON()")
+	w.Canon_world_t.FLOOD_ON()
+}
+
+func (w *Writer_t) INIT_CANON() {
+}
+
+func (w *Writer_t) MESSAGE(s []byte) {
+	w.print("MESSAGE(\"%s\")", s)
+}
+
+func (w *Writer_t) MIST_OFF() {
+	w.print("MIST_OFF()")
+	w.Canon_world_t.MIST_OFF()
+}
+
+func (w *Writer_t) MIST_ON() {
+	w.print("MIST_ON()")
+	w.Canon_world_t.MIST_ON()
+}
+
+func (w *Writer_t) PALLET_SHUTTLE() {
+	w.print("PALLET_SHUTTLE()")
+}
+
+func (w *Writer_t) TURN_PROBE_OFF() {
+	w.print("TURN_PROBE_OFF()")
+}
+
+func (w *Writer_t) TURN_PROBE_ON() {
+	w.print("TURN_PROBE_ON()")
+}
+
+func (w *Writer_t) UNCLAMP_AXIS(axis inc.CANON_AXIS) {
+	w.print("UNCLAMP_AXIS(%s)", axis)
+}
+
+/* Program Functions */
+
+func (w *Writer_t) PROGRAM_STOP() {
+	w.print("PROGRAM_STOP()")
+}
+
+func (w *Writer_t) OPTIONAL_PROGRAM_STOP() {
+	w.print("OPTIONAL_PROGRAM_STOP()")
+}
+
+func (w *Writer_t) PROGRAM_END() {
+	w.print("PROGRAM_END()")
+}
@@ -0,0 +1,54 @@
+package nist_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/nist"
+)
+
+func TestWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := nist.New(&out)
+	cnc := cnctest.Start(t, w, &w.Canon_world_t)
+	out.Reset()
+	w.Source = cnc
+	cnctest.Run(t, cnc, "n0010 g20 g0 x1 y2", "g1 f10 x3", " /N7 m4 m8")
+
+	want := "" +
+		"    4 N0010  USE_LENGTH_UNITS(CANON_UNITS_INCHES)\n" +
+		"    5 N0010  STRAIGHT_TRAVERSE(1.0000, 2.0000, 0.0000, 0.0000, 0.0000, 0.0000)\n" +
+		"    6 N..... SET_FEED_RATE(10.0000)\n" +
+		"    7 N..... STRAIGHT_FEED(3.0000, 2.0000, 0.0000, 0.0000, 0.0000, 0.0000)\n" +
+		"    8 N7     START_SPINDLE_COUNTERCLOCKWISE()\n" +
+		"    9 N7     FLOOD_ON()\n"
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if w.GET_EXTERNAL_SPINDLE() != inc.CANON_COUNTERCLOCKWISE {
+		t.Errorf("spindle = %v, want CANON_COUNTERCLOCKWISE", w.GET_EXTERNAL_SPINDLE())
+	}
+}
+
+// failing_t is an io.Writer which always fails.
+type failing_t struct {
+	writes int
+}
+
+func (f *failing_t) Write(p []byte) (int, error) {
+	f.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestWriter_Err(t *testing.T) {
+	out := &failing_t{}
+	w := nist.New(out)
+	w.FLOOD_ON()
+	w.FLOOD_OFF()
+	if (w.Err == nil) || (out.writes != 1) {
+		t.Errorf("Err = %v after %d writes, want disk full after 1", w.Err, out.writes)
+	}
+}
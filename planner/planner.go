@@ -0,0 +1,605 @@
+package planner
+
+import (
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* planner.go
+
+   Planner_t is a Canon_i which turns the moves of a program into timed
+   segments, for driving motion hardware. Each segment is a move (see
+   toolpath.Move_t) with the speeds at its ends, its profile of speed
+   against time (see profile.go), and when it starts, so that where the
+   tool is may be found at any time (see Segment_t.At).
+
+   Moves are held in a queue, so that the machine slows for corners and
+   is always able to stop at the end of the queue. When the queue holds
+   more than Options.Lookahead moves, the first is planned and sent; all
+   are sent, and the machine stops, when the queue is flushed. The queue
+   is flushed after a probe, so GET_EXTERNAL_QUEUE_EMPTY is true when the
+   interpreter reads the probe position, and after every move in
+   CANON_EXACT_STOP mode (G61.1). It is also flushed before each command
+   which must happen between moves: dwells, spindle, coolant and tool
+   changes, stops and program ends. A driver which carries those out put
+   after the planner in a mux.Fanout_t gets them in order.
+
+   No axis goes faster than its greatest velocity, or changes speed or
+   acceleration faster than its greatest acceleration or jerk (see
+   Options_t). An arc also goes no faster than the least acceleration of
+   the axes of its plane allows around its radius. A traverse goes at
+   the traverse rate (GET_EXTERNAL_TRAVERSE_RATE) if there is one. A
+   move with no limit on its speed at all takes no time.
+
+   The speed at a corner is found by junction deviation: it is that at
+   which the tool could go around an arc, touching both moves and coming
+   within the deviation of the corner, at the lesser acceleration of the
+   two moves. The path is not changed. The deviation is
+   Options.Junction_deviation in CANON_EXACT_PATH mode (G61), and the P
+   tolerance of G64 in CANON_CONTINUOUS mode if one was given (see
+   SET_MOTION_CONTROL_TOLERANCE). With the Q tolerance of G64, straight
+   feeds which all come within it of one line are run as one segment.
+
+   Speeds are in millimeters a second (degrees for moves of only rotary
+   axes), and the limits and tolerances are converted to millimeters
+   from the length units in force; positions are left in the length
+   units of the move.
+
+*/
+
+// LOOKAHEAD_DEFAULT is the number of moves held if Options.Lookahead is 0.
+const LOOKAHEAD_DEFAULT = 100
+
+type Options_t struct {
+	Max_velocity       [6]float64 // X, Y, Z in millimeters and A, B, C in degrees a minute, 0 for no limit
+	Max_acceleration   [6]float64 // the same a second squared, 0 for no limit
+	Max_jerk           [6]float64 // the same a second cubed, 0 for no limit (trapezoidal profiles)
+	Junction_deviation float64    // millimeters, for corners without a G64 P tolerance
+	Lookahead          int        // moves held, 0 for LOOKAHEAD_DEFAULT
+}
+
+// Segment_t is a move planned in time.
+type Segment_t struct {
+	toolpath.Move_t
+
+	Length   float64 // of the path, in millimeters (degrees for moves of only rotary axes)
+	Entry    float64 // speed at the start
+	Peak     float64 // greatest speed
+	Exit     float64 // speed at the end
+	Time     float64 // seconds from the start of the first segment to the start of this one
+	Duration float64 // seconds
+	Move     int     // number of the first move run in it (see Planner_t.Moves)
+
+	profile profile_t
+}
+
+type Planner_t struct {
+	inc.Canon_world_t
+
+	Options  Options_t
+	Source   inc.Source_i
+	Output   func(segment *Segment_t) // called with each segment in order, nil to keep them in Segments
+	Segments []Segment_t
+	Moves    int // moves given so far, counting those which go nowhere
+
+	path_tolerance  float64 // millimeters, from G64 P
+	naive_tolerance float64 // millimeters, from G64 Q
+	queue           []queued_t
+	entry           float64 // speed at the start of the first move in the queue
+	clock           float64 // seconds to the start of the first move in the queue
+}
+
+// queued_t is a move held in the queue.
+type queued_t struct {
+	segment   Segment_t
+	speed     float64    // greatest
+	limits    limits_t   // on the change of speed
+	start     [6]float64 // direction at the start
+	end       [6]float64 // direction at the end
+	mode      inc.CANON_MOTION_MODE
+	deviation float64              // junction deviation at the end
+	points    []inc.CANON_POSITION // ends of moves run as one, but the last
+}
+
+var _ inc.Canon_i = &Planner_t{}
+var _ inc.Blend_i = &Planner_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Planner_t with options, an empty queue, no Source,
+   and the world model of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New(options Options_t) *Planner_t {
+	return &Planner_t{Options: options}
+}
+
+/* At
+
+   Returned Value: where the tool is, and how fast it goes, t seconds
+   after the start of the segment (0 to its Duration)
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (s *Segment_t) At(t float64) (point inc.CANON_POSITION, speed float64) {
+	if (s.Length <= 0.0) || (t >= s.Duration) {
+		return s.Point_at(1.0), s.Exit
+	}
+	distance, speed := s.profile.at(math.Max(0.0, t))
+	return s.Point_at(math.Min(1.0, distance/s.Length)), speed
+}
+
+/* Flush
+
+   Returned Value: none
+
+   Side effects: every move in the queue is planned to end at rest, and
+   sent.
+
+   Called by: external programs, and the functions of Planner_t before
+   or after which the machine stops
+
+*/
+
+func (p *Planner_t) Flush() {
+	p.release(len(p.queue))
+}
+
+/***********************************************************************/
+
+/* factor
+
+   Returned Value: what a length in units is multiplied by to make it
+   millimeters
+
+   Side effects: none
+
+   Called by: measure, SET_MOTION_CONTROL_TOLERANCE
+
+*/
+
+func factor(units inc.CANON_UNITS) float64 {
+	switch units {
+	case inc.CANON_UNITS_INCHES:
+		return 25.4
+	case inc.CANON_UNITS_CM:
+		return 10.0
+	}
+	return 1.0
+}
+
+/* measure
+
+   Returned Value: bool (false if the move goes nowhere)
+
+   Side effects: the length, greatest speed, limits and end directions of
+   q are set from its move, at the feed rate of the move or, for a
+   traverse, at rate.
+
+   Called by: place, merge
+
+   The share of the move made by each axis (millimeters or degrees per
+   millimeter along the path) scales the limits of that axis, and the
+   directions at the ends are taken from the first and last millionth of
+   the path.
+
+*/
+
+func (p *Planner_t) measure(q *queued_t, rate float64) bool {
+	move := &q.segment.Move_t
+	f := factor(move.Units)
+	if (move.Kind != toolpath.MOVE_ARC) &&
+		(move.Start.X == move.End.X) && (move.Start.Y == move.End.Y) && (move.Start.Z == move.End.Z) {
+		f = 1.0 /* only rotary axes, whose feed is in degrees */
+	}
+	q.segment.Length = move.Length() * f
+	if q.segment.Length <= 0.0 {
+		return false
+	}
+	q.speed = math.Inf(1)
+	if rate > 0.0 {
+		q.speed = rate * f / 60.0
+	}
+	q.limits = limits_t{accel: math.Inf(1)}
+
+	delta := difference(move.Start, move.End, f)
+	share := [6]float64{}
+	for n := range delta {
+		share[n] = math.Abs(delta[n]) / q.segment.Length
+	}
+	q.start = unit(difference(move.Start, move.Point_at(1e-6), f))
+	q.end = unit(difference(move.Point_at(1.0-1e-6), move.End, f))
+	if move.Kind == toolpath.MOVE_ARC {
+		// Both axes of the plane go as fast as the tool somewhere on the
+		// arc, and must turn it around the center.
+		first, second := plane_axes(move.Plane)
+		share[first], share[second] = 1.0, 1.0
+		turning := math.Inf(1)
+		for _, n := range []int{first, second} {
+			if p.Options.Max_acceleration[n] > 0.0 {
+				turning = math.Min(turning, p.Options.Max_acceleration[n])
+			}
+		}
+		q.speed = math.Min(q.speed, math.Sqrt(turning*move.Radius()*f))
+	}
+
+	jerk := math.Inf(1)
+	for n := range share {
+		if share[n] == 0.0 {
+			continue
+		}
+		if limit := p.Options.Max_velocity[n]; limit > 0.0 {
+			q.speed = math.Min(q.speed, limit/60.0/share[n])
+		}
+		if limit := p.Options.Max_acceleration[n]; limit > 0.0 {
+			q.limits.accel = math.Min(q.limits.accel, limit/share[n])
+		}
+		if limit := p.Options.Max_jerk[n]; limit > 0.0 {
+			jerk = math.Min(jerk, limit/share[n])
+		}
+	}
+	if !math.IsInf(jerk, 1) {
+		q.limits.jerk = jerk
+	}
+	return true
+}
+
+/* add
+
+   Returned Value: the segment of the move, for the caller to finish
+
+   Side effects: a move of the given kind from the current position to
+   end is counted in p.Moves and put at the end of the queue, to be
+   placed (see place).
+
+   Called by: the motion functions of Planner_t, before the world model
+   is moved.
+
+*/
+
+func (p *Planner_t) add(kind toolpath.Kind, end inc.CANON_POSITION) *Segment_t {
+	q := queued_t{mode: p.GET_EXTERNAL_MOTION_CONTROL_MODE()}
+	q.segment.Move_t = toolpath.Move_t{
+		Kind:      kind,
+		Tool:      p.Tool_slot,
+		Units:     p.GET_EXTERNAL_LENGTH_UNIT_TYPE(),
+		Feed_rate: inc.If(kind == toolpath.MOVE_TRAVERSE, 0.0, p.Feed_rate).(float64),
+		Origin:    p.Origin,
+		Start:     p.Position,
+		End:       end,
+	}
+	q.segment.Move, p.Moves = p.Moves, (p.Moves + 1)
+	if p.Source != nil {
+		q.segment.Line = p.Source.SequenceNumber()
+	}
+	if system, ok := p.Source.(inc.System_i); ok {
+		q.segment.System = system.OriginIndex()
+	}
+	q.deviation = p.Options.Junction_deviation
+	if (q.mode != inc.CANON_EXACT_PATH) && (p.path_tolerance > 0.0) {
+		q.deviation = p.path_tolerance
+	}
+	p.queue = append(p.queue, q)
+	return &p.queue[len(p.queue)-1].segment
+}
+
+/* place
+
+   Returned Value: none
+
+   Side effects: the move last added is measured at rate, and taken out
+   of the queue if it goes nowhere, or run as one with the move before it
+   if it may be (see merge). The queue is flushed if the machine must
+   stop after it, and moves are sent if the queue is too long.
+
+   Called by: the motion functions of Planner_t, after add and after the
+   arc of an ARC_FEED is set
+
+*/
+
+func (p *Planner_t) place(rate float64) {
+	last := len(p.queue) - 1
+	q := &p.queue[last]
+	if !p.measure(q, rate) {
+		p.queue = p.queue[:last]
+		return
+	}
+	if (last > 0) && p.merge(&p.queue[last-1], q) {
+		p.queue = p.queue[:last]
+		return
+	}
+	if (q.mode == inc.CANON_EXACT_STOP) || (q.segment.Kind == toolpath.MOVE_PROBE) {
+		p.Flush()
+		return
+	}
+	lookahead := inc.If(p.Options.Lookahead > 0, p.Options.Lookahead, LOOKAHEAD_DEFAULT).(int)
+	if len(p.queue) > lookahead {
+		p.release(len(p.queue) - lookahead)
+	}
+}
+
+/* merge
+
+   Returned Value: bool (true if next was run as one with q)
+
+   Side effects: if q and next are straight feeds alike but for their
+   ends, in CANON_CONTINUOUS mode with a G64 Q tolerance, and the ends of
+   all the moves run as one in q come within the tolerance of the line
+   from the start of q to the end of next, q is made to end there.
+
+   Called by: place
+
+*/
+
+func (p *Planner_t) merge(q, next *queued_t) bool {
+	one, two := &q.segment.Move_t, &next.segment.Move_t
+	if (p.naive_tolerance <= 0.0) || (next.mode != inc.CANON_CONTINUOUS) || (q.mode != next.mode) ||
+		(one.Kind != toolpath.MOVE_FEED) || (two.Kind != toolpath.MOVE_FEED) ||
+		(one.Feed_rate != two.Feed_rate) || (one.Tool != two.Tool) || (one.Units != two.Units) ||
+		(one.Origin != two.Origin) || (one.System != two.System) ||
+		(one.Start.A != two.End.A) || (one.Start.B != two.End.B) || (one.Start.C != two.End.C) ||
+		(one.End.A != two.End.A) || (one.End.B != two.End.B) || (one.End.C != two.End.C) {
+		return false
+	}
+	tolerance := p.naive_tolerance / factor(one.Units)
+	for _, point := range append(q.points, one.End) {
+		if from_line(point, one.Start, two.End) > tolerance {
+			return false
+		}
+	}
+	q.points = append(q.points, one.End)
+	one.End = two.End
+	p.measure(q, one.Feed_rate)
+	return true
+}
+
+/* release
+
+   Returned Value: none
+
+   Side effects: the first count moves in the queue are planned, given
+   their times, and sent to p.Output (or kept in p.Segments), and taken
+   out of the queue.
+
+   Called by: Flush, place
+
+   The speed at each joint between moves is found first going back from
+   the end of the queue, where the machine stops, as the most it may be
+   at the start of a move to come down to the speed at its end, then
+   going forward from the speed at the start of the queue, as the most it
+   may be at the end of a move reached from the speed at its start. Each
+   is also no more than the speed at the corner (see corner).
+
+*/
+
+func (p *Planner_t) release(count int) {
+	if count <= 0 {
+		return
+	}
+	joints := make([]float64, len(p.queue)+1)
+	joints[0] = p.entry
+	for n := len(p.queue) - 1; n > 0; n-- {
+		q := &p.queue[n]
+		joints[n] = math.Min(p.corner(&p.queue[n-1], q), q.limits.reach(joints[n+1], q.segment.Length, q.speed))
+	}
+	for n := 0; n < count; n++ {
+		q := &p.queue[n]
+		joints[n+1] = math.Min(joints[n+1], q.limits.reach(joints[n], q.segment.Length, q.speed))
+
+		segment := q.segment
+		segment.Entry, segment.Exit, segment.Time = joints[n], joints[n+1], p.clock
+		if math.IsInf(q.speed, 1) {
+			segment.Peak = 0.0 /* no limit on speed, so no time */
+		} else {
+			segment.profile = q.limits.profile(segment.Length, segment.Entry, segment.Exit, q.speed)
+			segment.Peak, segment.Duration = segment.profile.peak, segment.profile.time()
+		}
+		p.clock = p.clock + segment.Duration
+		if p.Output != nil {
+			p.Output(&segment)
+		} else {
+			p.Segments = append(p.Segments, segment)
+		}
+	}
+	p.entry = joints[count]
+	p.queue = append(p.queue[:0], p.queue[count:]...)
+}
+
+/* corner
+
+   Returned Value: the greatest speed going from q to next
+
+   Side effects: none
+
+   Called by: release
+
+   This is the speed at which the tool could go around an arc, tangent
+   to both moves, whose middle comes within the deviation of the corner,
+   at the lesser acceleration of the two moves. Moves which go on in the
+   same direction need not slow, and the tool stops to go back the way
+   it came, or if the deviation is 0.
+
+*/
+
+func (p *Planner_t) corner(q, next *queued_t) float64 {
+	most := math.Min(q.speed, next.speed)
+	if q.mode == inc.CANON_EXACT_STOP {
+		return 0.0
+	}
+	cosine := 0.0
+	for n := range q.end {
+		cosine = cosine + (q.end[n] * next.start[n])
+	}
+	if cosine >= 1.0-1e-9 {
+		return most
+	}
+	accel := math.Min(q.limits.accel, next.limits.accel)
+	if math.IsInf(accel, 1) {
+		return most
+	}
+	sine := math.Sqrt(math.Max(0.0, (1.0+cosine)/2.0)) /* of half the angle between the moves */
+	if sine >= 1.0 {
+		return most
+	}
+	return math.Min(most, math.Sqrt(accel*q.deviation*sine/(1.0-sine)))
+}
+
+// difference returns the move from one position to another, X, Y and Z
+// multiplied by f.
+func difference(from, to inc.CANON_POSITION, f float64) [6]float64 {
+	return [6]float64{(to.X - from.X) * f, (to.Y - from.Y) * f, (to.Z - from.Z) * f,
+		to.A - from.A, to.B - from.B, to.C - from.C}
+}
+
+// unit returns v scaled to a length of 1, or v if it is all 0.
+func unit(v [6]float64) [6]float64 {
+	length := 0.0
+	for n := range v {
+		length = length + (v[n] * v[n])
+	}
+	if length == 0.0 {
+		return v
+	}
+	for n := range v {
+		v[n] = v[n] / math.Sqrt(length)
+	}
+	return v
+}
+
+// plane_axes returns the indexes of the first and second coordinates
+// of plane, X 0, Y 1, Z 2.
+func plane_axes(plane inc.CANON_PLANE) (first, second int) {
+	switch plane {
+	case inc.CANON_PLANE_YZ:
+		return 1, 2
+	case inc.CANON_PLANE_XZ:
+		return 2, 0
+	}
+	return 0, 1
+}
+
+// from_line returns how far point is, in X, Y and Z, from the line
+// between from and to.
+func from_line(point, from, to inc.CANON_POSITION) float64 {
+	along := [3]float64{to.X - from.X, to.Y - from.Y, to.Z - from.Z}
+	off := [3]float64{point.X - from.X, point.Y - from.Y, point.Z - from.Z}
+	length := (along[0] * along[0]) + (along[1] * along[1]) + (along[2] * along[2])
+	t := 0.0
+	if length > 0.0 {
+		t = math.Max(0.0, math.Min(1.0, ((off[0]*along[0])+(off[1]*along[1])+(off[2]*along[2]))/length))
+	}
+	return math.Sqrt(math.Pow(off[0]-(t*along[0]), 2) + math.Pow(off[1]-(t*along[1]), 2) +
+		math.Pow(off[2]-(t*along[2]), 2))
+}
+
+/***********************************************************************/
+
+func (p *Planner_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	p.add(toolpath.MOVE_TRAVERSE, inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c})
+	p.place(p.GET_EXTERNAL_TRAVERSE_RATE())
+	p.Canon_world_t.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+}
+
+func (p *Planner_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	p.add(toolpath.MOVE_FEED, inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c})
+	p.place(p.Feed_rate)
+	p.Canon_world_t.STRAIGHT_FEED(x, y, z, a, b, c)
+}
+
+func (p *Planner_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	p.add(toolpath.MOVE_PROBE, inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c})
+	p.place(p.Feed_rate)
+	p.Canon_world_t.STRAIGHT_PROBE(x, y, z, a, b, c)
+}
+
+func (p *Planner_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+
+	plane := p.GET_EXTERNAL_PLANE()
+	end := toolpath.Plane_position(plane, first_end, second_end, axis_end_point)
+	end.A, end.B, end.C = a, b, c
+	segment := p.add(toolpath.MOVE_ARC, end)
+	segment.Plane, segment.Center1, segment.Center2, segment.Rotation = plane, first_axis, second_axis, rotation
+	p.place(p.Feed_rate)
+	p.Canon_world_t.ARC_FEED(first_end, second_end, first_axis,
+		second_axis, rotation, axis_end_point, a, b, c)
+}
+
+func (p *Planner_t) SET_MOTION_CONTROL_TOLERANCE(path, naive float64) {
+	f := factor(p.GET_EXTERNAL_LENGTH_UNIT_TYPE())
+	p.path_tolerance, p.naive_tolerance = path*f, naive*f
+}
+
+func (p *Planner_t) GET_EXTERNAL_QUEUE_EMPTY() int {
+	return inc.If(len(p.queue) == 0, 1, 0).(int)
+}
+
+func (p *Planner_t) DWELL(seconds float64) {
+	p.Flush()
+	p.clock = p.clock + seconds
+}
+
+func (p *Planner_t) CHANGE_TOOL(slot int) {
+	p.Flush()
+	p.Canon_world_t.CHANGE_TOOL(slot)
+}
+
+func (p *Planner_t) START_SPINDLE_CLOCKWISE() {
+	p.Flush()
+	p.Canon_world_t.START_SPINDLE_CLOCKWISE()
+}
+
+func (p *Planner_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	p.Flush()
+	p.Canon_world_t.START_SPINDLE_COUNTERCLOCKWISE()
+}
+
+func (p *Planner_t) STOP_SPINDLE_TURNING() {
+	p.Flush()
+	p.Canon_world_t.STOP_SPINDLE_TURNING()
+}
+
+func (p *Planner_t) FLOOD_ON() {
+	p.Flush()
+	p.Canon_world_t.FLOOD_ON()
+}
+
+func (p *Planner_t) FLOOD_OFF() {
+	p.Flush()
+	p.Canon_world_t.FLOOD_OFF()
+}
+
+func (p *Planner_t) MIST_ON() {
+	p.Flush()
+	p.Canon_world_t.MIST_ON()
+}
+
+func (p *Planner_t) MIST_OFF() {
+	p.Flush()
+	p.Canon_world_t.MIST_OFF()
+}
+
+func (p *Planner_t) OPTIONAL_PROGRAM_STOP() {
+	p.Flush()
+}
+
+func (p *Planner_t) PROGRAM_STOP() {
+	p.Flush()
+}
+
+func (p *Planner_t) PROGRAM_END() {
+	p.Flush()
+}
@@ -0,0 +1,139 @@
+package planner_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc"
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/planner"
+)
+
+// plan runs program on a Planner_t with options and returns it, and the
+// interpreter, without flushing the queue.
+func plan(t *testing.T, options planner.Options_t, program ...string) (*planner.Planner_t, *rs274ngc.Rs274ngc_t) {
+	p := planner.New(options)
+	p.Tool_max = 4
+	cnc := cnctest.Start(t, p, &p.Canon_world_t)
+	p.Source = cnc
+	cnctest.Run(t, cnc, program...)
+	return p, cnc
+}
+
+// near reports whether two numbers are the same to within 1e-6.
+func near(one, two float64) bool {
+	return math.Abs(one-two) < 1e-6
+}
+
+func TestPlanner_profiles(t *testing.T) {
+	// 10 mm/s at 100 mm/s/s: speeding up takes 0.1 s and 0.5 mm; with a
+	// jerk of 1000 mm/s/s/s it takes 0.2 s and 1 mm.
+	cases := []struct {
+		name     string
+		options  planner.Options_t
+		duration float64
+		at       float64
+		x, speed float64
+	}{
+		{"trapezoid", planner.Options_t{Max_acceleration: [6]float64{100}}, 10.1, 0.05, 0.125, 5},
+		{"S curve", planner.Options_t{Max_acceleration: [6]float64{100}, Max_jerk: [6]float64{1000}},
+			10.2, 0.1, 1000 * 0.001 / 6, 5},
+	}
+	for _, c := range cases {
+		p, _ := plan(t, c.options, "g21 g1 x100 f600")
+		p.Flush()
+		if len(p.Segments) != 1 {
+			t.Fatalf("%s: %d segments, want 1", c.name, len(p.Segments))
+		}
+		segment := &p.Segments[0]
+		if !near(segment.Duration, c.duration) || !near(segment.Peak, 10) || (segment.Entry != 0) ||
+			(segment.Exit != 0) {
+			t.Errorf("%s: %+v, want %v s at 10 mm/s from rest to rest", c.name, *segment, c.duration)
+		}
+		point, speed := segment.At(c.at)
+		if !near(point.X, c.x) || !near(speed, c.speed) {
+			t.Errorf("%s: At(%v) = X%v at %v, want X%v at %v", c.name, c.at, point.X, speed, c.x, c.speed)
+		}
+		if point, speed = segment.At(segment.Duration / 2); !near(point.X, 50) || !near(speed, 10) {
+			t.Errorf("%s: half way X%v at %v, want X50 at 10", c.name, point.X, speed)
+		}
+	}
+}
+
+func TestPlanner_corners(t *testing.T) {
+	options := planner.Options_t{Max_acceleration: [6]float64{100, 100, 100}, Junction_deviation: 0.05}
+	corner := func(deviation float64) float64 {
+		sine := math.Sqrt(0.5)
+		return math.Sqrt(100 * deviation * sine / (1 - sine))
+	}
+	cases := []struct {
+		name    string
+		program []string
+		exit    float64
+	}{
+		{"straight on", []string{"g21 g64 g1 x50 f600", "x100"}, 10},
+		{"exact stop", []string{"g21 g61.1 g1 x50 f600", "x100"}, 0},
+		{"exact path", []string{"g21 g61 g1 x50 f600", "y50"}, corner(0.05)},
+		{"continuous", []string{"g21 g64 g1 x50 f600", "y50"}, corner(0.05)},
+		{"tolerance", []string{"g21 g64 p0.2 g1 x50 f600", "y50"}, corner(0.2)},
+		{"reversal", []string{"g21 g64 p0.2 g1 x50 f600", "x0"}, 0},
+	}
+	for _, c := range cases {
+		p, _ := plan(t, options, c.program...)
+		p.Flush()
+		if (len(p.Segments) != 2) || !near(p.Segments[0].Exit, c.exit) || !near(p.Segments[1].Entry, c.exit) {
+			t.Errorf("%s: %+v, want 2 segments meeting at %v", c.name, p.Segments, c.exit)
+		}
+	}
+}
+
+func TestPlanner_queue(t *testing.T) {
+	p, _ := plan(t, planner.Options_t{Max_acceleration: [6]float64{100, 100}, Lookahead: 2},
+		"g21 g1 x10 f600", "y10", "x0")
+	if (len(p.Segments) != 1) || (p.GET_EXTERNAL_QUEUE_EMPTY() != 0) {
+		t.Fatalf("%d segments sent and queue empty %d, want 1 and 0", len(p.Segments), p.GET_EXTERNAL_QUEUE_EMPTY())
+	}
+	p.Flush()
+	if (len(p.Segments) != 3) || (p.GET_EXTERNAL_QUEUE_EMPTY() == 0) {
+		t.Fatalf("%d segments sent and queue empty %d, want 3 and 1", len(p.Segments), p.GET_EXTERNAL_QUEUE_EMPTY())
+	}
+	for n := 1; n < len(p.Segments); n++ {
+		if one, two := &p.Segments[n-1], &p.Segments[n]; !near(one.Time+one.Duration, two.Time) ||
+			(one.Exit != two.Entry) || (two.Line != n+1) {
+			t.Errorf("segment %d = %+v does not follow %+v", n, *two, *one)
+		}
+	}
+
+	// A dwell comes between segments; a probe empties the queue before
+	// the interpreter reads the next line.
+	var sent []*planner.Segment_t
+	p, cnc := plan(t, planner.Options_t{}, "g21 g1 x10 f600", "g4 p2")
+	p.Output = func(segment *planner.Segment_t) { sent = append(sent, segment) }
+	for _, line := range []string{"g1 x20", "g38.2 x30", "g1 x40"} {
+		if status := cnc.Read([]byte(line)); status != inc.RS274NGC_OK {
+			t.Fatalf("%s: Read() = %v", line, status)
+		}
+		if status := cnc.Execute(); (status != inc.RS274NGC_OK) && (status != inc.RS274NGC_EXECUTE_FINISH) {
+			t.Fatalf("%s: Execute() = %v", line, status)
+		}
+	}
+	if (len(p.Segments) != 1) || (len(sent) != 2) || !near(sent[0].Time, 3) || !near(sent[1].Time, 4) {
+		t.Errorf("kept %d and sent %d segments, want 1 and 2, at 3 and 4 s", len(p.Segments), len(sent))
+	}
+}
+
+func TestPlanner_naive(t *testing.T) {
+	for _, c := range []struct {
+		tolerance string
+		segments  int
+	}{{"q0.1", 1}, {"q0.01", 3}, {"", 3}} {
+		p, _ := plan(t, planner.Options_t{}, "g21 g64 "+c.tolerance+" g1 x10 y0.05 f600", "x20 y0", "x30 y0.05")
+		p.Flush()
+		if len(p.Segments) != c.segments {
+			t.Errorf("G64 %s: %d segments, want %d", c.tolerance, len(p.Segments), c.segments)
+		} else if end := p.Segments[c.segments-1].End; end.X != 30 {
+			t.Errorf("G64 %s: ends at X%v, want X30", c.tolerance, end.X)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package planner
+
+import (
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* profile.go
+
+   The speed along a segment is a profile of three parts: a ramp from the
+   entry speed up to the peak, a cruise at the peak, and a ramp down to
+   the exit speed. A ramp changes the speed at no more than the greatest
+   acceleration and, if there is a greatest jerk, changes the
+   acceleration at no more than that, so the speed follows an S curve;
+   with no greatest jerk it is a straight line, so the profile is a
+   trapezoid.
+
+   An S-curve ramp is the same backward as forward, so the distance it
+   covers is the mean of its end speeds times its time, as for a
+   straight ramp.
+
+*/
+
+// limits_t is how fast the speed may change, accel +Inf and jerk 0 for
+// no limit.
+type limits_t struct {
+	accel float64
+	jerk  float64
+}
+
+// ramp_t is a change of speed from one speed to another.
+type ramp_t struct {
+	from, to float64
+	sign     float64 // 1 speeding up, -1 slowing
+	jerk     float64 // 0 for a straight ramp
+	peak     float64 // greatest acceleration reached
+	edge     float64 // time to reach the peak acceleration, 0 for a straight ramp
+	middle   float64 // time at the peak acceleration
+}
+
+/* ramp
+
+   Returned Value: the ramp from speed from to speed to, as fast as the
+   limits allow
+
+   Side effects: none
+
+   Called by: limits_t.reach, limits_t.profile
+
+*/
+
+func (l limits_t) ramp(from, to float64) ramp_t {
+	r := ramp_t{from: from, to: to, sign: inc.If(to >= from, 1.0, -1.0).(float64), jerk: l.jerk, peak: l.accel}
+	change := math.Abs(to - from)
+	if (change == 0.0) || (math.IsInf(l.accel, 1) && (l.jerk == 0.0)) {
+		return r
+	}
+	if l.jerk > 0.0 {
+		r.edge = math.Min(l.accel/l.jerk, math.Sqrt(change/l.jerk))
+		r.peak = l.jerk * r.edge
+	}
+	r.middle = math.Max(0.0, (change/r.peak)-r.edge)
+	return r
+}
+
+// time returns how long the ramp takes.
+func (r ramp_t) time() float64 {
+	return (2.0 * r.edge) + r.middle
+}
+
+// distance returns how far the ramp goes.
+func (r ramp_t) distance() float64 {
+	return ((r.from + r.to) / 2.0) * r.time()
+}
+
+/* at
+
+   Returned Value: the distance gone and the speed, t seconds into the
+   ramp (0 to the time of the ramp)
+
+   Side effects: none
+
+   Called by: profile_t.at
+
+   The last part of the ramp is found from its end, going backward.
+
+*/
+
+func (r ramp_t) at(t float64) (distance, speed float64) {
+	switch {
+	case t <= r.edge:
+		return (r.from * t) + (r.sign * r.jerk * t * t * t / 6.0), r.from + (r.sign * r.jerk * t * t / 2.0)
+	case t <= r.edge+r.middle:
+		gone, speed := r.at(r.edge)
+		t = t - r.edge
+		return gone + (speed * t) + (r.sign * r.peak * t * t / 2.0), speed + (r.sign * r.peak * t)
+	}
+	left := math.Max(0.0, r.time()-t)
+	return r.distance() - ((r.to * left) - (r.sign * r.jerk * left * left * left / 6.0)),
+		r.to - (r.sign * r.jerk * left * left / 2.0)
+}
+
+/* reach
+
+   Returned Value: the greatest speed, no more than most, at one end of
+   length from which speed may be reached at the other end
+
+   Side effects: none
+
+   Called by: Planner_t.plan, profile
+
+   Without a greatest jerk this is worked out; with one it is found by
+   bisection, since the distance of a ramp grows with its change of
+   speed.
+
+*/
+
+func (l limits_t) reach(speed, length, most float64) float64 {
+	if math.IsInf(l.accel, 1) && (l.jerk == 0.0) {
+		return most
+	}
+	if l.jerk == 0.0 {
+		return math.Min(most, math.Sqrt((speed*speed)+(2.0*l.accel*length)))
+	}
+	if l.ramp(speed, most).distance() <= length {
+		return most
+	}
+	low, high := speed, most
+	for n := 0; n < 60; n++ {
+		middle := (low + high) / 2.0
+		if l.ramp(speed, middle).distance() <= length {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return low
+}
+
+// profile_t is the speed along a segment.
+type profile_t struct {
+	up, down ramp_t
+	cruise   float64 // time at the peak
+	peak     float64
+}
+
+/* profile
+
+   Returned Value: the fastest profile along length from entry to exit
+   speed, going no faster than most
+
+   Side effects: none
+
+   Called by: Planner_t.release
+
+   The planner has made sure the exit speed may be reached from the
+   entry speed in the length. If the ramps to and from most do not fit,
+   the peak is found by bisection.
+
+*/
+
+func (l limits_t) profile(length, entry, exit, most float64) profile_t {
+	fits := func(peak float64) bool {
+		return l.ramp(entry, peak).distance()+l.ramp(peak, exit).distance() <= length
+	}
+	peak := most
+	if !fits(peak) {
+		low, high := math.Max(entry, exit), most
+		for n := 0; n < 60; n++ {
+			middle := (low + high) / 2.0
+			if fits(middle) {
+				low = middle
+			} else {
+				high = middle
+			}
+		}
+		peak = low
+	}
+	p := profile_t{up: l.ramp(entry, peak), down: l.ramp(peak, exit), peak: peak}
+	if peak > 0.0 {
+		p.cruise = math.Max(0.0, (length-p.up.distance()-p.down.distance())/peak)
+	}
+	return p
+}
+
+// time returns how long the profile takes.
+func (p profile_t) time() float64 {
+	return p.up.time() + p.cruise + p.down.time()
+}
+
+// at returns the distance gone and the speed, t seconds into the
+// profile.
+func (p profile_t) at(t float64) (distance, speed float64) {
+	if t <= p.up.time() {
+		return p.up.at(t)
+	}
+	distance = p.up.distance()
+	t = t - p.up.time()
+	if t <= p.cruise {
+		return distance + (p.peak * t), p.peak
+	}
+	distance = distance + (p.peak * p.cruise)
+	gone, speed := p.down.at(math.Min(t-p.cruise, p.down.time()))
+	return distance + gone, speed
+}
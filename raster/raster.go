@@ -0,0 +1,243 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* raster.go
+
+   Renderer_t is a Canon_i which draws the tool path of a program as a
+   picture made of pixels, for a thumbnail of the program or a look at it
+   where SVG cannot be shown. It keeps the moves (see toolpath.Path_t)
+   and draws them when Image or Write is called. Only the image packages
+   of the standard library are used.
+
+   The picture is of one view (see toolpath.View), as large as it fits in
+   the width and height asked for, and centered. Traverses are drawn
+   dashed and paler than feeds and arcs, which are drawn solid. Moves are
+   colored by the tool in the spindle (see CHANGE_TOOL), as in the svg
+   package. Arcs are drawn as straight lines no more than half a pixel
+   off them.
+
+   All moves are drawn in the coordinates and length units of the first
+   move (see toolpath.Fold), and so is the stock outline, if any.
+
+*/
+
+type Options_t struct {
+	View        toolpath.View   // 0 means toolpath.VIEW_TOP
+	Width       int             // pixels, 0 means 256
+	Height      int             // pixels, 0 means 256
+	Stock       *toolpath.Box_t // outline of the stock, nil for none
+	Hide_rapids bool            // true means traverses are not drawn
+}
+
+type Renderer_t struct {
+	toolpath.Path_t
+
+	Options Options_t
+}
+
+var _ inc.Canon_i = &Renderer_t{}
+
+const (
+	margin = 4.0 // pixels around the drawing
+	dash   = 4   // pixels drawn, then skipped, along a traverse
+)
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	stock_gray = color.RGBA{0xaa, 0xaa, 0xaa, 0xff}
+)
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Renderer_t with options, no moves, and the world
+   model of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New(options Options_t) *Renderer_t {
+	return &Renderer_t{Options: options}
+}
+
+/* Image
+
+   Returned Value: the picture of the moves made so far
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (r *Renderer_t) Image() *image.RGBA {
+	return Render(r.Moves, r.Options)
+}
+
+/* Write
+
+   Returned Value: error (the first error writing to out, or nil)
+
+   Side effects: the picture of the moves made so far is written to out
+   as a PNG file.
+
+   Called by: external programs
+
+*/
+
+func (r *Renderer_t) Write(out io.Writer) error {
+	return png.Encode(out, r.Image())
+}
+
+/* Render
+
+   Returned Value: the picture of moves
+
+   Side effects: none
+
+   Called by: Renderer_t.Image, external programs
+
+*/
+
+func Render(moves []toolpath.Move_t, options Options_t) *image.RGBA {
+	moves = toolpath.Fold(moves)
+	view := inc.If(options.View == 0, toolpath.VIEW_TOP, options.View).(toolpath.View)
+	width := inc.If(options.Width > 0, options.Width, 256).(int)
+	height := inc.If(options.Height > 0, options.Height, 256).(int)
+
+	picture := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(picture, picture.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	var shown []toolpath.Move_t
+	for _, move := range moves {
+		if !options.Hide_rapids || (move.Kind != toolpath.MOVE_TRAVERSE) {
+			shown = append(shown, move)
+		}
+	}
+	frame := toolpath.Fit(view, shown, options.Stock, float64(width), float64(height), margin)
+	frame.Left = (float64(width) - frame.Width) / 2.0
+	frame.Top = (float64(height) - frame.Height) / 2.0
+
+	if options.Stock != nil {
+		corners := options.Stock.Corners()
+		for _, edge := range options.Stock.Edges() {
+			pen := pen_t{picture: picture, color: stock_gray}
+			pen.line(&frame, corners[edge[0]], corners[edge[1]])
+		}
+	}
+	for n := range shown {
+		draw_move(picture, &frame, &shown[n])
+	}
+	return picture
+}
+
+/* draw_move
+
+   Returned Value: none
+
+   Side effects: the move is drawn on picture.
+
+   Called by: Render
+
+*/
+
+func draw_move(picture *image.RGBA, frame *toolpath.Frame_t, move *toolpath.Move_t) {
+	pen := pen_t{picture: picture, color: toolpath.Tool_color(move.Tool)}
+	if move.Kind == toolpath.MOVE_TRAVERSE {
+		pen.color = pale(pen.color)
+		pen.dashed = true
+	}
+	points := move.Points(0.5 / frame.Scale)
+	for n := 1; n < len(points); n++ {
+		pen.line(frame, points[n-1], points[n])
+	}
+}
+
+// pale returns c halfway to the background.
+func pale(c color.RGBA) color.RGBA {
+	return color.RGBA{
+		uint8((int(c.R) + int(background.R)) / 2),
+		uint8((int(c.G) + int(background.G)) / 2),
+		uint8((int(c.B) + int(background.B)) / 2),
+		0xff,
+	}
+}
+
+/***********************************************************************/
+
+// pen_t draws lines on a picture, keeping its place in the dash pattern
+// from one line to the next.
+type pen_t struct {
+	picture *image.RGBA
+	color   color.RGBA
+	dashed  bool
+	count   int // pixels along the dash pattern so far
+}
+
+/* line
+
+   Returned Value: none
+
+   Side effects: the line from one point to another, as seen in frame, is
+   drawn on the picture, skipping pixels between dashes if the pen is
+   dashed. Pixels outside the picture are not drawn.
+
+   Called by: Render, draw_move
+
+   This is Bresenham's line algorithm. The last pixel of a dashed line
+   is left for the next line, so that a polyline does not count it twice
+   in the dash pattern.
+
+*/
+
+func (pen *pen_t) line(frame *toolpath.Frame_t, from, to inc.CANON_POSITION) {
+	fx, fy := frame.Point(from)
+	tx, ty := frame.Point(to)
+	x0, y0 := int(math.Floor(fx)), int(math.Floor(fy))
+	x1, y1 := int(math.Floor(tx)), int(math.Floor(ty))
+
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := inc.If(x0 < x1, 1, -1).(int), inc.If(y0 < y1, 1, -1).(int)
+	err := dx + dy
+	for (x0 != x1) || (y0 != y1) {
+		pen.plot(x0, y0)
+		e2 := 2 * err
+		if e2 >= dy {
+			err, x0 = err+dy, x0+sx
+		}
+		if e2 <= dx {
+			err, y0 = err+dx, y0+sy
+		}
+	}
+	if !pen.dashed {
+		pen.plot(x1, y1)
+	}
+}
+
+// plot draws the pixel at x, y if the pen is down there.
+func (pen *pen_t) plot(x, y int) {
+	down := !pen.dashed || ((pen.count/dash)%2 == 0)
+	pen.count++
+	if down && image.Pt(x, y).In(pen.picture.Rect) {
+		pen.picture.SetRGBA(x, y, pen.color)
+	}
+}
+
+func abs(n int) int {
+	return inc.If(n < 0, -n, n).(int)
+}
@@ -0,0 +1,115 @@
+package raster_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/raster"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+var program = []string{
+	"g21 g0 x0 y0 z10",
+	"t1 m6",
+	"g1 z-1 f100",
+	"g1 x40",
+	"g2 x40 y-40 i0 j-20",
+	"t2 m6",
+	"g1 x0",
+	"g0 z10",
+	"g0 x0 y0",
+}
+
+// run runs program on a Renderer_t with options.
+func run(t *testing.T, options raster.Options_t) *raster.Renderer_t {
+	renderer := raster.New(options)
+	renderer.Tool_max = 4
+	cnctest.Run(t, cnctest.Start(t, renderer, &renderer.Canon_world_t), program...)
+	return renderer
+}
+
+// count returns how many pixels of picture are c.
+func count(picture *image.RGBA, c color.RGBA) int {
+	n := 0
+	bounds := picture.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if picture.RGBAAt(x, y) == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestRender_top(t *testing.T) {
+	picture := run(t, raster.Options_t{Width: 108, Height: 68}).Image()
+	if bounds := picture.Bounds(); (bounds.Dx() != 108) || (bounds.Dy() != 68) {
+		t.Fatalf("bounds = %v, want 108 x 68", bounds)
+	}
+	// The path spans X 0 to 60 and Y -40 to 0, so the scale is 1.5
+	// pixels a millimeter, and the drawing is 98 pixels wide, centered.
+	// X60 is at 99 pixels, but the arc falls a little short of it.
+	tool1, tool2 := toolpath.Tool_color(1), toolpath.Tool_color(2)
+	pixels := map[image.Point]color.RGBA{
+		{9, 4}:   tool1, // X0 Y0, the start of the feed along X
+		{39, 4}:  tool1, // X20 Y0
+		{98, 34}: tool1, // X60 Y-20, the far side of the arc
+		{39, 64}: tool2, // X20 Y-40, the feed back along X
+		{40, 30}: {0xff, 0xff, 0xff, 0xff},
+	}
+	for point, want := range pixels {
+		if got := picture.RGBAAt(point.X, point.Y); got != want {
+			t.Errorf("pixel %v = %v, want %v", point, got, want)
+		}
+	}
+}
+
+func TestRender_rapids(t *testing.T) {
+	options := raster.Options_t{View: toolpath.VIEW_FRONT, Width: 100, Height: 100}
+	renderer := run(t, options)
+	pale := color.RGBA{0x95, 0xcf, 0x95, 0xff} // tool 2 halfway to white
+	if n := count(renderer.Image(), pale); n == 0 {
+		t.Errorf("no traverses drawn in the front view")
+	}
+	renderer.Options.Hide_rapids = true
+	if n := count(renderer.Image(), pale); n != 0 {
+		t.Errorf("%d pixels of traverses drawn with Hide_rapids", n)
+	}
+}
+
+func TestRender_stock(t *testing.T) {
+	stock := &toolpath.Box_t{Min: inc.CANON_POSITION{X: -10, Y: -50, Z: -5}, Max: inc.CANON_POSITION{X: 70, Y: 10}}
+	picture := raster.Render(nil, raster.Options_t{Width: 88, Height: 68, Stock: stock})
+	gray := color.RGBA{0xaa, 0xaa, 0xaa, 0xff}
+	// The stock fills the picture, less the margins, at one pixel a
+	// millimeter, and is seen from above as a rectangle.
+	if n := count(picture, gray); n != (2*81)+(2*59) {
+		t.Errorf("%d pixels of stock, want the edges of an 81 x 61 rectangle", n)
+	}
+	for _, point := range []image.Point{{4, 4}, {84, 4}, {4, 64}, {84, 64}} {
+		if picture.RGBAAt(point.X, point.Y) != gray {
+			t.Errorf("pixel %v is not a corner of the stock", point)
+		}
+	}
+}
+
+func TestRenderer_Write(t *testing.T) {
+	renderer := run(t, raster.Options_t{})
+	var out bytes.Buffer
+	if err := renderer.Write(&out); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	picture, err := png.Decode(&out)
+	if err != nil {
+		t.Fatalf("png.Decode() = %v", err)
+	}
+	if bounds := picture.Bounds(); (bounds.Dx() != 256) || (bounds.Dy() != 256) {
+		t.Errorf("bounds = %v, want the default 256 x 256", bounds)
+	}
+}
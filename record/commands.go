@@ -0,0 +1,255 @@
+package record
+
+import "github.com/flyingyizi/rs274ngc/inc"
+
+/* The canonical commands
+
+   Each records itself, then does what Canon_world_t does.
+
+*/
+
+func (r *Recorder_t) COMMENT(s string) {
+	r.add("COMMENT", s)
+	r.Canon_world_t.COMMENT(s)
+}
+
+func (r *Recorder_t) DISABLE_FEED_OVERRIDE() {
+	r.add("DISABLE_FEED_OVERRIDE")
+	r.Canon_world_t.DISABLE_FEED_OVERRIDE()
+}
+
+func (r *Recorder_t) DISABLE_SPEED_OVERRIDE() {
+	r.add("DISABLE_SPEED_OVERRIDE")
+	r.Canon_world_t.DISABLE_SPEED_OVERRIDE()
+}
+
+func (r *Recorder_t) ENABLE_FEED_OVERRIDE() {
+	r.add("ENABLE_FEED_OVERRIDE")
+	r.Canon_world_t.ENABLE_FEED_OVERRIDE()
+}
+
+func (r *Recorder_t) ENABLE_SPEED_OVERRIDE() {
+	r.add("ENABLE_SPEED_OVERRIDE")
+	r.Canon_world_t.ENABLE_SPEED_OVERRIDE()
+}
+
+func (r *Recorder_t) INIT_CANON() {
+	r.add("INIT_CANON")
+	r.Canon_world_t.INIT_CANON()
+}
+
+func (r *Recorder_t) MESSAGE(s []byte) {
+	r.add("MESSAGE", string(s))
+	r.Canon_world_t.MESSAGE(s)
+}
+
+func (r *Recorder_t) PALLET_SHUTTLE() {
+	r.add("PALLET_SHUTTLE")
+	r.Canon_world_t.PALLET_SHUTTLE()
+}
+
+func (r *Recorder_t) OPTIONAL_PROGRAM_STOP() {
+	r.add("OPTIONAL_PROGRAM_STOP")
+	r.Canon_world_t.OPTIONAL_PROGRAM_STOP()
+}
+
+func (r *Recorder_t) PROGRAM_END() {
+	r.add("PROGRAM_END")
+	r.Canon_world_t.PROGRAM_END()
+}
+
+func (r *Recorder_t) PROGRAM_STOP() {
+	r.add("PROGRAM_STOP")
+	r.Canon_world_t.PROGRAM_STOP()
+}
+
+func (r *Recorder_t) SELECT_PLANE(plane inc.CANON_PLANE) {
+	r.add("SELECT_PLANE", plane)
+	r.Canon_world_t.SELECT_PLANE(plane)
+}
+
+func (r *Recorder_t) SET_FEED_RATE(rate float64) {
+	r.add("SET_FEED_RATE", rate)
+	r.Canon_world_t.SET_FEED_RATE(rate)
+}
+
+func (r *Recorder_t) SET_FEED_REFERENCE(reference inc.CANON_FEED_REFERENCE) {
+	r.add("SET_FEED_REFERENCE", reference)
+	r.Canon_world_t.SET_FEED_REFERENCE(reference)
+}
+
+func (r *Recorder_t) SET_MOTION_CONTROL_MODE(mode inc.CANON_MOTION_MODE) {
+	r.add("SET_MOTION_CONTROL_MODE", mode)
+	r.Canon_world_t.SET_MOTION_CONTROL_MODE(mode)
+}
+
+func (r *Recorder_t) START_SPEED_FEED_SYNCH() {
+	r.add("START_SPEED_FEED_SYNCH")
+	r.Canon_world_t.START_SPEED_FEED_SYNCH()
+}
+
+func (r *Recorder_t) STOP_SPEED_FEED_SYNCH() {
+	r.add("STOP_SPEED_FEED_SYNCH")
+	r.Canon_world_t.STOP_SPEED_FEED_SYNCH()
+}
+
+func (r *Recorder_t) SET_CUTTER_RADIUS_COMPENSATION(radius float64) {
+	r.add("SET_CUTTER_RADIUS_COMPENSATION", radius)
+}
+
+func (r *Recorder_t) START_CUTTER_RADIUS_COMPENSATION(side inc.CANON_SIDE) {
+	r.add("START_CUTTER_RADIUS_COMPENSATION", side)
+}
+
+func (r *Recorder_t) STOP_CUTTER_RADIUS_COMPENSATION() {
+	r.add("STOP_CUTTER_RADIUS_COMPENSATION")
+}
+
+func (r *Recorder_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+	r.add("ARC_FEED", first_end, second_end, first_axis, second_axis, rotation, axis_end_point, a, b, c)
+	r.Canon_world_t.ARC_FEED(first_end, second_end, first_axis, second_axis, rotation, axis_end_point, a, b, c)
+}
+
+func (r *Recorder_t) DWELL(seconds float64) {
+	r.add("DWELL", seconds)
+	r.Canon_world_t.DWELL(seconds)
+}
+
+func (r *Recorder_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	r.add("STRAIGHT_FEED", x, y, z, a, b, c)
+	r.Canon_world_t.STRAIGHT_FEED(x, y, z, a, b, c)
+}
+
+func (r *Recorder_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	r.add("STRAIGHT_TRAVERSE", x, y, z, a, b, c)
+	r.Canon_world_t.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+}
+
+func (r *Recorder_t) USE_LENGTH_UNITS(in_unit inc.CANON_UNITS) {
+	r.add("USE_LENGTH_UNITS", in_unit)
+	r.Canon_world_t.USE_LENGTH_UNITS(in_unit)
+}
+
+func (r *Recorder_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+	r.add("SET_ORIGIN_OFFSETS", x, y, z, a, b, c)
+	r.Canon_world_t.SET_ORIGIN_OFFSETS(x, y, z, a, b, c)
+}
+
+func (r *Recorder_t) ORIENT_SPINDLE(orientation float64, direction inc.CANON_DIRECTION) {
+	r.add("ORIENT_SPINDLE", orientation, direction)
+	r.Canon_world_t.ORIENT_SPINDLE(orientation, direction)
+}
+
+func (r *Recorder_t) SET_SPINDLE_SPEED(speed float64) {
+	r.add("SET_SPINDLE_SPEED", speed)
+	r.Canon_world_t.SET_SPINDLE_SPEED(speed)
+}
+
+func (r *Recorder_t) START_SPINDLE_CLOCKWISE() {
+	r.add("START_SPINDLE_CLOCKWISE")
+	r.Canon_world_t.START_SPINDLE_CLOCKWISE()
+}
+
+func (r *Recorder_t) START_SPINDLE_COUNTERCLOCKWISE() {
+	r.add("START_SPINDLE_COUNTERCLOCKWISE")
+	r.Canon_world_t.START_SPINDLE_COUNTERCLOCKWISE()
+}
+
+func (r *Recorder_t) STOP_SPINDLE_TURNING() {
+	r.add("STOP_SPINDLE_TURNING")
+	r.Canon_world_t.STOP_SPINDLE_TURNING()
+}
+
+func (r *Recorder_t) FLOOD_OFF() {
+	r.add("FLOOD_OFF")
+	r.Canon_world_t.FLOOD_OFF()
+}
+
+func (r *Recorder_t) FLOOD_ON() {
+	r.add("FLOOD_ON")
+	r.Canon_world_t.FLOOD_ON()
+}
+
+func (r *Recorder_t) MIST_OFF() {
+	r.add("MIST_OFF")
+	r.Canon_world_t.MIST_OFF()
+}
+
+func (r *Recorder_t) MIST_ON() {
+	r.add("MIST_ON")
+	r.Canon_world_t.MIST_ON()
+}
+
+func (r *Recorder_t) CHANGE_TOOL(slot int) {
+	r.add("CHANGE_TOOL", slot)
+	r.Canon_world_t.CHANGE_TOOL(slot)
+}
+
+func (r *Recorder_t) SELECT_TOOL(i int) {
+	r.add("SELECT_TOOL", i)
+	r.Canon_world_t.SELECT_TOOL(i)
+}
+
+func (r *Recorder_t) USE_TOOL_LENGTH_OFFSET(offset float64) {
+	r.add("USE_TOOL_LENGTH_OFFSET", offset)
+	r.Canon_world_t.USE_TOOL_LENGTH_OFFSET(offset)
+}
+
+func (r *Recorder_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	r.add("STRAIGHT_PROBE", x, y, z, a, b, c)
+	r.Canon_world_t.STRAIGHT_PROBE(x, y, z, a, b, c)
+}
+
+func (r *Recorder_t) TURN_PROBE_OFF() {
+	r.add("TURN_PROBE_OFF")
+	r.Canon_world_t.TURN_PROBE_OFF()
+}
+
+func (r *Recorder_t) TURN_PROBE_ON() {
+	r.add("TURN_PROBE_ON")
+	r.Canon_world_t.TURN_PROBE_ON()
+}
+
+/* The canonical extensions
+
+   The world model keeps only the traverse rate of these.
+
+*/
+
+func (r *Recorder_t) SET_TRAVERSE_RATE(rate float64) {
+	r.add("SET_TRAVERSE_RATE", rate)
+	r.Canon_world_t.SET_TRAVERSE_RATE(rate)
+}
+
+func (r *Recorder_t) STOP() {
+	r.add("STOP")
+}
+
+func (r *Recorder_t) SET_SPINDLE_TORQUE(torque float64) {
+	r.add("SET_SPINDLE_TORQUE", torque)
+}
+
+func (r *Recorder_t) SPINDLE_RETRACT() {
+	r.add("SPINDLE_RETRACT")
+}
+
+func (r *Recorder_t) SPINDLE_RETRACT_TRAVERSE() {
+	r.add("SPINDLE_RETRACT_TRAVERSE")
+}
+
+func (r *Recorder_t) USE_NO_SPINDLE_FORCE() {
+	r.add("USE_NO_SPINDLE_FORCE")
+}
+
+func (r *Recorder_t) USE_SPINDLE_FORCE(force float64) {
+	r.add("USE_SPINDLE_FORCE", force)
+}
+
+func (r *Recorder_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
+	r.add("CLAMP_AXIS", axis)
+}
+
+func (r *Recorder_t) UNCLAMP_AXIS(axis inc.CANON_AXIS) {
+	r.add("UNCLAMP_AXIS", axis)
+}
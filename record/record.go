@@ -0,0 +1,245 @@
+package record
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* record.go
+
+   Recorder_t is a Canon_i which keeps every canonical command it is
+   given as a Call_t, so a test or a tool can look at what the
+   interpreter did instead of parsing printed output. It also keeps the
+   world model (see inc.Canon_world_t), so the interpreter reads back
+   the positions, units, and plane its own commands imply.
+
+   The world-give-information functions are not recorded.
+
+   If Source is set, each call is stamped with the sequence number and
+   text of the line the interpreter was on when it made the call. An
+   interpreter (rs274ngc.Rs274ngc_t) serves as a Source.
+
+   A typical test is:
+
+      rec := record.New()
+      cnc.SetCanon(rec)
+      rec.Source = cnc
+      ... read and execute lines ...
+      rec.Expect(t,
+         "STRAIGHT_TRAVERSE(1.0000, 0.0000, 0.0000, 0.0000, 0.0000, 0.0000)",
+         "STRAIGHT_FEED(1.0000, 2.0000, 0.0000, 0.0000, 0.0000, 0.0000)")
+
+*/
+
+// Call_t is one recorded canonical command.
+type Call_t struct {
+	Sequence int           // number of the call, from 1
+	Line     int           // sequence number of the line, 0 if no Source
+	Text     string        // text of the line, "" if no Source
+	Name     string        // name of the canonical function
+	Args     []interface{} // float64, int, string, or an inc enumeration
+}
+
+// Testing_i is the part of *testing.T that Expect uses.
+type Testing_i interface {
+	Errorf(format string, args ...interface{})
+}
+
+type Recorder_t struct {
+	inc.Canon_world_t
+
+	Source inc.Source_i
+	Calls  []Call_t
+}
+
+var _ inc.Canon_i = &Recorder_t{}
+var _ inc.Canon_ext_i = &Recorder_t{}
+var _ inc.Canon_machine_i = &Recorder_t{}
+var _ inc.Comp_i = &Recorder_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Recorder_t with no calls, no Source, and the world
+   model of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New() *Recorder_t {
+	return &Recorder_t{}
+}
+
+/***********************************************************************/
+
+/* add
+
+   Returned Value: none
+
+   Side effects: a Call_t is appended to r.Calls
+
+   Called by: every canonical command of Recorder_t
+
+*/
+
+func (r *Recorder_t) add(name string, args ...interface{}) {
+	call := Call_t{Sequence: len(r.Calls) + 1, Name: name, Args: args}
+	if r.Source != nil {
+		call.Line = r.Source.SequenceNumber()
+		call.Text = r.Source.LineText()
+	}
+	r.Calls = append(r.Calls, call)
+}
+
+/***********************************************************************/
+
+/* String
+
+   Returned Value: string
+   The call written as in the NIST canonical command log, for example
+   STRAIGHT_FEED(1.0000, 2.0000, 0.0000, 0.0000, 0.0000, 0.0000).
+   Floats have four decimal places, strings are quoted, and enumerations
+   are given by name.
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (call Call_t) String() string {
+	args := make([]string, len(call.Args))
+	for i, arg := range call.Args {
+		switch v := arg.(type) {
+		case float64:
+			args[i] = fmt.Sprintf("%.4f", v)
+		case string:
+			args[i] = fmt.Sprintf("%q", v)
+		default:
+			args[i] = fmt.Sprint(v)
+		}
+	}
+	return call.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+/***********************************************************************/
+
+/* Reset
+
+   Returned Value: none
+
+   Side effects: the recorded calls are thrown away. The world model is
+   kept, since the interpreter still believes in it.
+
+   Called by: external programs
+
+*/
+
+func (r *Recorder_t) Reset() {
+	r.Calls = nil
+}
+
+// Strings returns the recorded calls as strings.
+func (r *Recorder_t) Strings() []string {
+	return Strings(r.Calls)
+}
+
+// Names returns the names of the recorded calls.
+func (r *Recorder_t) Names() []string {
+	names := make([]string, len(r.Calls))
+	for i, call := range r.Calls {
+		names[i] = call.Name
+	}
+	return names
+}
+
+// Filter returns the recorded calls with any of the given names.
+func (r *Recorder_t) Filter(names ...string) []Call_t {
+	var calls []Call_t
+	for _, call := range r.Calls {
+		for _, name := range names {
+			if call.Name == name {
+				calls = append(calls, call)
+				break
+			}
+		}
+	}
+	return calls
+}
+
+// Strings returns calls as strings.
+func Strings(calls []Call_t) []string {
+	strs := make([]string, len(calls))
+	for i, call := range calls {
+		strs[i] = call.String()
+	}
+	return strs
+}
+
+/***********************************************************************/
+
+/* Compare
+
+   Returned Value: string
+   "" if the calls, written as strings, are exactly want. Otherwise a
+   description of the first difference, giving the line the call came
+   from if it is known.
+
+   Side effects: none
+
+   Called by:
+   Recorder_t.Expect
+   external programs
+
+*/
+
+func Compare(got []Call_t, want []string) string {
+	for i := 0; (i < len(got)) || (i < len(want)); i++ {
+		switch {
+		case i >= len(got):
+			return fmt.Sprintf("call %d: got nothing, want %s", i+1, want[i])
+		case i >= len(want):
+			return fmt.Sprintf("call %d: got %s%s, want nothing", i+1, got[i], from(got[i]))
+		case got[i].String() != want[i]:
+			return fmt.Sprintf("call %d: got %s%s, want %s", i+1, got[i], from(got[i]), want[i])
+		}
+	}
+	return ""
+}
+
+func from(call Call_t) string {
+	if call.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (line %d: %s)", call.Line, call.Text)
+}
+
+/***********************************************************************/
+
+/* Expect
+
+   Returned Value: bool (true if the recorded calls are exactly want)
+
+   Side effects: if they are not, the first difference is reported
+   with t.Errorf
+
+   Called by: external programs (tests)
+
+*/
+
+func (r *Recorder_t) Expect(t Testing_i, want ...string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if diff := Compare(r.Calls, want); diff != "" {
+		t.Errorf("%s", diff)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package record_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/record"
+)
+
+// run initializes an interpreter writing to rec and runs lines on it.
+func run(t *testing.T, rec *record.Recorder_t, lines ...string) {
+	cnc := cnctest.Start(t, rec, &rec.Canon_world_t)
+	rec.Reset()
+	rec.Source = cnc
+	cnctest.Run(t, cnc, lines...)
+}
+
+func TestRecorder_Expect(t *testing.T) {
+	rec := record.New()
+	run(t, rec, "g20 g0 x1 y2", "g18 g1 x2 y1 f10")
+
+	rec.Expect(t,
+		"USE_LENGTH_UNITS(CANON_UNITS_INCHES)",
+		"STRAIGHT_TRAVERSE(1.0000, 2.0000, 0.0000, 0.0000, 0.0000, 0.0000)",
+		"SET_FEED_RATE(10.0000)",
+		"SELECT_PLANE(CANON_PLANE_XZ)",
+		"STRAIGHT_FEED(2.0000, 1.0000, 0.0000, 0.0000, 0.0000, 0.0000)")
+
+	if rec.GET_EXTERNAL_LENGTH_UNIT_TYPE() != inc.CANON_UNITS_INCHES {
+		t.Errorf("units = %v, want CANON_UNITS_INCHES", rec.GET_EXTERNAL_LENGTH_UNIT_TYPE())
+	}
+	if rec.GET_EXTERNAL_PLANE() != inc.CANON_PLANE_XZ {
+		t.Errorf("plane = %v, want CANON_PLANE_XZ", rec.GET_EXTERNAL_PLANE())
+	}
+	if (rec.GET_EXTERNAL_POSITION_X() != 2) || (rec.GET_EXTERNAL_POSITION_Y() != 1) {
+		t.Errorf("position = %v, want X2 Y1", rec.Position)
+	}
+}
+
+func TestRecorder_Units(t *testing.T) {
+	// Centimeters convert like the other units: 25 mm is 2.5 cm and
+	// 25/25.4 in.
+	rec := record.New()
+	rec.STRAIGHT_TRAVERSE(25, 0, 0, 0, 0, 0)
+	rec.USE_LENGTH_UNITS(inc.CANON_UNITS_CM)
+	if x := rec.GET_EXTERNAL_POSITION_X(); math.Abs(x-2.5) > 1e-9 {
+		t.Errorf("X = %g cm, want 2.5", x)
+	}
+	if f := rec.GET_EXTERNAL_LENGTH_UNIT_FACTOR(); math.Abs(f-0.1) > 1e-9 {
+		t.Errorf("factor = %g in cm, want 0.1", f)
+	}
+	rec.USE_LENGTH_UNITS(inc.CANON_UNITS_INCHES)
+	if x := rec.GET_EXTERNAL_POSITION_X(); math.Abs(x-(25/25.4)) > 1e-9 {
+		t.Errorf("X = %g in, want %g", x, 25/25.4)
+	}
+	rec.USE_LENGTH_UNITS(inc.CANON_UNITS_MM)
+	if x := rec.GET_EXTERNAL_POSITION_X(); math.Abs(x-25) > 1e-9 {
+		t.Errorf("X = %g mm, want 25", x)
+	}
+}
+
+func TestRecorder_Source(t *testing.T) {
+	rec := record.New()
+	run(t, rec, "g0 x1", "g1 f5 x3", "g0 x0")
+
+	moves := rec.Filter("STRAIGHT_FEED", "STRAIGHT_TRAVERSE")
+	lines := []int{1, 2, 3}
+	if len(moves) != len(lines) {
+		t.Fatalf("moves = %v, want %d", moves, len(lines))
+	}
+	for i, move := range moves {
+		if move.Line != lines[i] {
+			t.Errorf("%s: line = %d, want %d", move, move.Line, lines[i])
+		}
+	}
+	if moves[1].Text != "g1 f5 x3" {
+		t.Errorf("text = %q, want %q", moves[1].Text, "g1 f5 x3")
+	}
+}
+
+// errors_t collects what Expect reports.
+type errors_t []string
+
+func (e *errors_t) Errorf(format string, args ...interface{}) {
+	*e = append(*e, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder_Mismatch(t *testing.T) {
+	rec := record.New()
+	run(t, rec, "g0 x1", "g0 x2")
+
+	var errors errors_t
+	if rec.Expect(&errors, "STRAIGHT_TRAVERSE(1.0000, 0.0000, 0.0000, 0.0000, 0.0000, 0.0000)") {
+		t.Errorf("Expect() = true with a call missing")
+	}
+	want := "call 2: got STRAIGHT_TRAVERSE(2.0000, 0.0000, 0.0000, 0.0000, 0.0000, 0.0000)" +
+		" (line 2: g0 x2), want nothing"
+	if (len(errors) != 1) || (errors[0] != want) {
+		t.Errorf("errors = %q, want %q", errors, want)
+	}
+}
@@ -7,8 +7,8 @@ import (
 	"io"
 	"math"
 	"os"
-	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/flyingyizi/rs274ngc/inc"
 )
@@ -85,21 +85,25 @@ const (
    This version does not use any additional memory as it runs. No
    memory is allocated by the source code.
 
-   This version does not suppress superfluous commands, such as a command
-   to start the spindle when the spindle is already turning, or a command
-   to turn on flood coolant, when flood coolant is already on.  When the
-   interpreter is being used for direct control of the machining center,
-   suppressing superfluous commands might confuse the user and could be
-   dangerous, but when it is used to translate from one file to another,
-   suppression can produce more concise output. Future versions might
-   include an option for suppressing superfluous commands.
+   By default this version does not suppress superfluous commands, such
+   as a command to start the spindle when the spindle is already turning,
+   or a command to turn on flood coolant, when flood coolant is already
+   on.  When the interpreter is being used for direct control of the
+   machining center, suppressing superfluous commands might confuse the
+   user and could be dangerous, but when it is used to translate from one
+   file to another, suppression can produce more concise output. Calling
+   SetSuppress(true) turns on the option for suppressing superfluous
+   commands.
 
 */
 
 /****************************************************************************/
 
 type Rs274ngc_i interface {
-	SetCanon(c inc.Canon_i)
+	// give the canonical machining functions to call
+	SetCanon(canon inc.Canon_i)
+	// give them in parts, the functions none of the parts has doing nothing
+	SetCanonParts(parts ...interface{}) error
 	// open NC-program file
 	Open(filename string) inc.STATUS
 	// read the command
@@ -126,7 +130,7 @@ type Rs274ngc_i interface {
 
 	// copy active G codes into array [0]..[11]
 	active_g_codes(codes []inc.GCodes)
-	// copy active M codes into array [0]..[6]
+	// copy active M codes into array [0]..[7]
 	active_m_codes(codes []int)
 	// copy active F, S settings into array [0]..[2]
 	active_settings(settings []float64)
@@ -136,6 +140,19 @@ type Rs274ngc_i interface {
 	// copy the text of the most recently read line into the line_text array,
 	// but stop at max_size if the text is longer
 	LineText() string
+	// return the number of the most recently read line of the file
+	SequenceNumber() int
+	// return the coordinate system in use, 1 (G54) to 9 (G59.3)
+	OriginIndex() int
+
+	// set the number of motion blocks held for cutter comp lookahead
+	SetCompLookahead(n int)
+	// return the text, naming both lines, of the last cutter comp error
+	CompErrorText() string
+	// have the machine, not the interpreter, do cutter radius comp
+	SetCompPassthrough(on bool) error
+	// leave out canonical commands which would change nothing
+	SetSuppress(on bool)
 }
 type Rs274ngc_t = rs274ngc_t
 
@@ -144,11 +161,240 @@ var _ Rs274ngc_i = &rs274ngc_t{}
 type rs274ngc_t struct {
 	_setup Setup_t
 
-	canon inc.Canon_i
+	canon       inc.Canon_i
+	canon_ext   inc.Canon_ext_i /* nil if the canon does not have them */
+	canon_tcpc  inc.Tcpc_i      /* nil if the canon does not have them */
+	canon_comp  inc.Comp_i      /* nil if the canon does not have them */
+	canon_blend inc.Blend_i     /* nil if the canon does not have it */
+	canon_feed  inc.Feed_mode_i /* nil if the canon does not have it */
+	canon_fault inc.Fault_i     /* nil if the canon does not have it */
 }
 
-func (cnc *rs274ngc_t) SetCanon(c inc.Canon_i) {
-	cnc.canon = c
+/***********************************************************************/
+
+/* SetCanon
+
+   Returned Value: none
+
+   Side effects:
+   The canon the interpreter calls is set, and the optional functions it
+   has (see find_optional) are found.
+
+   Called By: external programs
+
+*/
+
+func (cnc *rs274ngc_t) SetCanon(canon inc.Canon_i) {
+	cnc.canon = canon
+	cnc.find_optional(canon)
+}
+
+/* SetCanonParts
+
+   Returned Value: error
+   If a part has none of the interfaces of a canon (the parts of
+   inc.Canon_i and the optional functions), this returns an error saying
+   which, and the canon is not changed. Otherwise it returns nil.
+
+   Side effects:
+   The canon the interpreter calls is set to the parts put together by
+   inc.New_canon_adapter, so a machine with, say, only motion and spindle
+   functions need not stub the rest, and the optional functions the
+   parts have are found.
+
+   Called By: external programs
+
+*/
+
+func (cnc *rs274ngc_t) SetCanonParts(parts ...interface{}) error {
+	for n, part := range parts {
+		switch part.(type) {
+		case inc.Canon_program_i, inc.Canon_motion_i, inc.Canon_spindle_i, inc.Canon_coolant_i,
+			inc.Canon_tooling_i, inc.Canon_probe_i, inc.Canon_world_i,
+			inc.Canon_ext_i, inc.Comp_i, inc.Tcpc_i, inc.Blend_i, inc.Feed_mode_i, inc.Fault_i:
+		default:
+			return fmt.Errorf("canon part %d, of type %T, has none of the canon interfaces", n+1, part)
+		}
+	}
+	cnc.canon = inc.New_canon_adapter(parts...)
+	cnc.find_optional(parts...)
+	return nil
+}
+
+/* find_optional
+
+   Returned Value: none
+
+   Side effects:
+   The optional functions of the canon (inc.Canon_ext_i, inc.Comp_i,
+   inc.Tcpc_i, inc.Blend_i, inc.Feed_mode_i and inc.Fault_i) are found
+   with type assertions, in the parts and in whatever they unwrap to.
+   If the canon has no cutter radius compensation functions, passing
+   compensation through (see SetCompPassthrough) is turned off.
+
+   Called By: SetCanon, SetCanonParts
+
+   A part with an Unwrap method (as the mux middlewares and fanout have)
+   is looked into, first the part and then what it unwraps to, so the
+   optional functions of a Canon_i behind a middleware are found. The
+   canonical extensions and tool center point control are taken from the
+   first found. The cutter radius compensation functions are taken from
+   the first found that gives them to a Canon_i which carries them out
+   (see find_comp), since a middleware has them whether or not the next
+   Canon_i does. The others are called on each found which is not behind
+   another found for the same functions, since that one passes the call
+   on itself; the first refused command of any of them is the fault.
+
+*/
+
+func (cnc *rs274ngc_t) find_optional(parts ...interface{}) {
+	var found optional_t
+	for _, part := range parts {
+		found.walk(part, false, false, false)
+	}
+	cnc.canon_ext = found.ext
+	cnc.canon_tcpc = found.tcpc
+	cnc.canon_comp = nil
+	for _, part := range parts {
+		if cnc.canon_comp = find_comp(part); cnc.canon_comp != nil {
+			break
+		}
+	}
+	if cnc.canon_comp == nil {
+		cnc._setup.comp_passthrough = OFF
+	}
+	cnc.canon_blend = nil
+	cnc.canon_feed = nil
+	cnc.canon_fault = nil
+	if len(found.blends) != 0 { /* not a nil slice in an interface */
+		cnc.canon_blend = found.blends
+	}
+	if len(found.feeds) != 0 {
+		cnc.canon_feed = found.feeds
+	}
+	if len(found.faults) != 0 {
+		cnc.canon_fault = found.faults
+	}
+}
+
+// find_comp returns part, if it has the cutter radius compensation
+// functions and either carries them out itself (it unwraps to nothing)
+// or something it unwraps to does; failing that, the first found the
+// same way in what part unwraps to; and nil if there is none.
+func find_comp(part interface{}) inc.Comp_i {
+	var next []inc.Canon_i
+	wraps := true
+	switch wrapper := part.(type) {
+	case interface{ Unwrap() inc.Canon_i }:
+		next = []inc.Canon_i{wrapper.Unwrap()}
+	case interface{ Unwrap() []inc.Canon_i }:
+		next = wrapper.Unwrap()
+	default:
+		wraps = false
+	}
+	comp, ok := part.(inc.Comp_i)
+	if ok && !wraps {
+		return comp
+	}
+	for _, one := range next {
+		if found := find_comp(one); (found != nil) && ok {
+			return comp
+		} else if found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// optional_t is what find_optional has found so far.
+type optional_t struct {
+	ext    inc.Canon_ext_i
+	tcpc   inc.Tcpc_i
+	blends blends_t
+	feeds  feed_modes_t
+	faults faults_t
+}
+
+// walk looks into part; blend, feed and fault tell whether something in
+// front of part has the functions already.
+func (found *optional_t) walk(part interface{}, blend, feed, fault bool) {
+	if ext, ok := part.(inc.Canon_ext_i); ok && (found.ext == nil) {
+		found.ext = ext
+	}
+	if tcpc, ok := part.(inc.Tcpc_i); ok && (found.tcpc == nil) {
+		found.tcpc = tcpc
+	}
+	if one, ok := part.(inc.Blend_i); ok && !blend {
+		found.blends, blend = append(found.blends, one), true
+	}
+	if one, ok := part.(inc.Feed_mode_i); ok && !feed {
+		found.feeds, feed = append(found.feeds, one), true
+	}
+	if one, ok := part.(inc.Fault_i); ok && !fault {
+		found.faults, fault = append(found.faults, one), true
+	}
+	switch wrapper := part.(type) {
+	case interface{ Unwrap() inc.Canon_i }:
+		found.walk(wrapper.Unwrap(), blend, feed, fault)
+	case interface{ Unwrap() []inc.Canon_i }:
+		for _, next := range wrapper.Unwrap() {
+			found.walk(next, blend, feed, fault)
+		}
+	}
+}
+
+// blends_t gives SET_MOTION_CONTROL_TOLERANCE to each of its Blend_i's.
+type blends_t []inc.Blend_i
+
+func (all blends_t) SET_MOTION_CONTROL_TOLERANCE(path, naive float64) {
+	for _, one := range all {
+		one.SET_MOTION_CONTROL_TOLERANCE(path, naive)
+	}
+}
+
+// feed_modes_t gives SET_FEED_MODE to each of its Feed_mode_i's.
+type feed_modes_t []inc.Feed_mode_i
+
+func (all feed_modes_t) SET_FEED_MODE(mode inc.FeedMode) {
+	for _, one := range all {
+		one.SET_FEED_MODE(mode)
+	}
+}
+
+// faults_t reports the fault of the first of its Fault_i's having one.
+type faults_t []inc.Fault_i
+
+func (all faults_t) GET_EXTERNAL_FAULT() (status inc.STATUS, text string) {
+	for _, one := range all {
+		if status, text = one.GET_EXTERNAL_FAULT(); status != inc.RS274NGC_OK {
+			return status, text
+		}
+	}
+	return inc.RS274NGC_OK, ""
+}
+
+/***********************************************************************/
+
+/* FaultErrorText
+
+   Returned Value: string
+
+   Side effects: none
+
+   Called by: external programs
+
+   This returns the canon's description of the command it refused (see
+   inc.Fault_i), or an empty string if it has refused none or cannot
+   refuse commands.
+
+*/
+
+func (cnc *rs274ngc_t) FaultErrorText() string {
+	if cnc.canon_fault == nil {
+		return ""
+	}
+	_, text := cnc.canon_fault.GET_EXTERNAL_FAULT()
+	return text
 }
 
 /***********************************************************************/
@@ -245,6 +491,7 @@ func (cnc *rs274ngc_t) Open(filename string) inc.STATUS { /* string: the name of
 */
 
 func (cnc *rs274ngc_t) Close() inc.STATUS {
+	cnc.comp_flush()
 	cnc._setup.file_pointer.Close()
 	cnc.reset()
 
@@ -302,7 +549,9 @@ func (cnc *rs274ngc_t) Read(command []byte) inc.STATUS { /* a string to read */
 		cnc.read_text(command)
 	if read_status == inc.RS274NGC_EXECUTE_FINISH || read_status == inc.RS274NGC_OK {
 		if cnc._setup.line_length != 0 {
-			cnc.parse_line( /*cnc._setup.blocktext*/ )
+			if status := cnc.parse_line(); status != inc.RS274NGC_OK {
+				return status
+			}
 		}
 
 	} else if read_status == inc.RS274NGC_ENDFILE {
@@ -318,6 +567,8 @@ func (cnc *rs274ngc_t) Read(command []byte) inc.STATUS { /* a string to read */
 /* rs274ngc_execute
 
    Returned Value: int)
+   If the canon has refused a command (see inc.Fault_i), this returns
+   the error code it gives.
    If execute_block returns RS274NGC_EXIT, this returns that.
    If execute_block returns RS274NGC_EXECUTE_FINISH, this returns that.
    If execute_block returns an error code, this returns that code.
@@ -338,6 +589,7 @@ func (cnc *rs274ngc_t) Read(command []byte) inc.STATUS { /* a string to read */
 func (cnc *rs274ngc_t) Execute() inc.STATUS { /* NO ARGUMENTS */
 
 	status := inc.RS274NGC_OK
+	cnc._setup.comp_error = ""
 
 	if cnc._setup.line_length != 0 { /* line not blank */
 		for n := int64(0); n < cnc._setup.block1.Parameter_occurrence; n++ {
@@ -354,6 +606,11 @@ func (cnc *rs274ngc_t) Execute() inc.STATUS { /* NO ARGUMENTS */
 			(status != inc.RS274NGC_EXIT) {
 			return (status)
 		}
+		if cnc.canon_fault != nil {
+			if fault, _ := cnc.canon_fault.GET_EXTERNAL_FAULT(); fault != inc.RS274NGC_OK {
+				return fault
+			}
+		}
 	} else { /* blank line is OK */
 		status = inc.RS274NGC_OK
 	}
@@ -381,7 +638,9 @@ func (cnc *rs274ngc_t) Execute() inc.STATUS { /* NO ARGUMENTS */
    Otherwise, it returns RS274NGC_OK.
 
    Side effects:
-   One block of RS274/NGC instructions is executed.
+   One block of RS274/NGC instructions is executed. Cutter comp moves
+   held for lookahead are sent first unless the block may be held too
+   (see comp_queueable).
 
    Called by:
    rs274ngc_execute
@@ -415,7 +674,10 @@ func (cnc *rs274ngc_t) execute_block( /* ARGUMENTS
 	//static char name[] = "execute_block";
 	var status inc.STATUS
 
-	if 0 == len(cnc._setup.block1.comment) {
+	if (len(cnc._setup.comp_queue) != 0) && !cnc.comp_queueable() {
+		cnc.comp_flush()
+	}
+	if 0 != len(cnc._setup.block1.comment) {
 		cnc.convert_comment(cnc._setup.block1.comment)
 	}
 	if cnc._setup.block1.g_modes[5] != -1 {
@@ -436,7 +698,10 @@ func (cnc *rs274ngc_t) execute_block( /* ARGUMENTS
 		cnc.convert_tool_select()
 	}
 	cnc.convert_m()
-	cnc.convert_g()
+	status = cnc.convert_g()
+	if status != inc.RS274NGC_OK {
+		return status
+	}
 
 	if cnc._setup.block1.m_modes[4] != -1 { /* converts m0, m1, m2, m30, or m60 */
 		status = cnc.convert_stop()
@@ -621,9 +886,16 @@ func (cnc *rs274ngc_t) parse_line() inc.STATUS {
 
 	cnc._setup.block1.Init_block()
 
-	cnc._setup.block1.Read_items(cnc._setup.tool_max, cnc._setup.blocktext, cnc._setup.parameters)
-	cnc._setup.block1.Enhance_block(&cnc._setup)
-	cnc._setup.block1.Check_items(&cnc._setup)
+	if status := cnc._setup.block1.Read_items(cnc._setup.tool_max, cnc._setup.blocktext,
+		cnc._setup.parameters); status != inc.RS274NGC_OK {
+		return inc.STATUS(status)
+	}
+	if status := cnc._setup.block1.Enhance_block(&cnc._setup); status != inc.RS274NGC_OK {
+		return inc.STATUS(status)
+	}
+	if status := cnc._setup.block1.Check_items(&cnc._setup); status != inc.RS274NGC_OK {
+		return inc.STATUS(status)
+	}
 	return inc.RS274NGC_OK
 }
 
@@ -788,6 +1060,7 @@ func (cnc *rs274ngc_t) Init() inc.STATUS { /* NO ARGUMENTS */
 	/*CC*/
 	cnc._setup.origin_offset.C = pars[k+6]
 
+	cnc._setup.a_axis_clamping = OFF
 	//_setup.current_slot set in rs274ngc_synch
 	//_setup.current.X set in rs274ngc_synch
 	//_setup.current.Y set in rs274ngc_synch
@@ -801,6 +1074,7 @@ func (cnc *rs274ngc_t) Init() inc.STATUS { /* NO ARGUMENTS */
 	//cnc._setup.file_pointer = nil
 	//_setup.flood set in rs274ngc_synch
 	cnc._setup.length_offset_index = 1
+	cnc._setup.length_offset_mode = inc.G_49
 	//_setup.length_units set in rs274ngc_synch
 	cnc._setup.line_length = 0
 	cnc._setup.linetext = ""
@@ -817,8 +1091,9 @@ func (cnc *rs274ngc_t) Init() inc.STATUS { /* NO ARGUMENTS */
 	//_setup.percent_flag does not need initialization
 	//_setup.plane set in rs274ngc_synch
 	cnc._setup.probe_flag = OFF
-	cnc._setup.program_x = inc.UNKNOWN /* for cutter comp */
-	cnc._setup.program_y = inc.UNKNOWN /* for cutter comp */
+	cnc._setup.program_1 = inc.UNKNOWN /* for cutter comp */
+	cnc._setup.program_2 = inc.UNKNOWN /* for cutter comp */
+	cnc._setup.comp_queue = nil        /* for cutter comp */
 	//_setup.retract_mode does not need initialization
 	//_setup.selected_tool_slot set in rs274ngc_synch
 	cnc._setup.sequence_number = 0 /*DOES THIS NEED TO BE AT TOP? */
@@ -828,7 +1103,7 @@ func (cnc *rs274ngc_t) Init() inc.STATUS { /* NO ARGUMENTS */
 	//_setup.spindle_turning set in rs274ngc_synch
 	//_setup.stack does not need initialization
 	//_setup.stack_index does not need initialization
-	cnc._setup.tool_length_offset = 0.0
+	//_setup.tool_length_offset set in rs274ngc_synch
 	//_setup.tool_max set in rs274ngc_synch
 	//_setup.tool_table set in rs274ngc_synch
 	cnc._setup.tool_table_index = 1
@@ -1198,6 +1473,91 @@ func (cnc *rs274ngc_t) LineText() string {
 
 /***********************************************************************/
 
+/* rs274ngc_sequence_number
+
+   Returned Value: the current interpreter sequence number (how many
+   lines read since the last time the sequence number was reset to zero,
+   which happens only when rs274ngc_init or rs274ngc_open is called).
+
+   Side Effects: none
+
+   Called By: external programs
+
+*/
+
+func (cnc *rs274ngc_t) SequenceNumber() int {
+	return cnc._setup.sequence_number
+}
+
+/***********************************************************************/
+
+/* OriginIndex
+
+   Returned Value: the index of the coordinate system in use, 1 to 9
+   (1 for G54, 6 for G59, 7 to 9 for G59.1 to G59.3).
+
+   Side Effects: none
+
+   Called By: external programs
+
+*/
+
+func (cnc *rs274ngc_t) OriginIndex() int {
+	return cnc._setup.origin_index
+}
+
+/***********************************************************************/
+
+/* SetSuppress
+
+   Returned Value: none
+
+   Side effects: _setup.suppress is set.
+
+   Called by: external programs
+
+   With on true, the interpreter does not call a canonical command which
+   would leave the machine as it is according to the machine settings:
+   SET_FEED_RATE or SET_SPINDLE_SPEED with the rate or speed in force,
+   SELECT_PLANE, USE_LENGTH_UNITS, SET_MOTION_CONTROL_MODE or
+   USE_TOOL_LENGTH_OFFSET with the plane, units, mode or offset in force,
+   a spindle or coolant command for the state the spindle or coolant is
+   in, and a G0 or G1 move (not made with cutter radius compensation)
+   ending where it starts.
+
+   This relies on the machine settings agreeing with the machine, as they
+   do after rs274ngc_init or rs274ngc_synch. It should not be used when
+   the interpreter is controlling a machine directly.
+
+*/
+
+func (cnc *rs274ngc_t) SetSuppress(on bool) {
+	cnc._setup.suppress = ON_OFF(on)
+}
+
+/***********************************************************************/
+
+/* superfluous
+
+   Returned Value: bool
+   true if suppression is on (see SetSuppress) and unchanged is true.
+
+   Side effects: none
+
+   Called by: the convert_XXX functions calling a canonical command which
+   might be superfluous.
+
+   The caller gives as unchanged whether the command would leave the
+   machine settings as they are.
+
+*/
+
+func (cnc *rs274ngc_t) superfluous(unchanged bool) bool {
+	return (cnc._setup.suppress == ON) && unchanged
+}
+
+/***********************************************************************/
+
 /* rs274ngc_load_tool_table
 
    Returned Value: int
@@ -1326,13 +1686,7 @@ func close_and_downcase( /* ARGUMENTS                   */
 		t       string
 	)
 
-	//匹配一个或多个空白符的正则表达式
-	reg := regexp.MustCompile("\\s+")
-	str := reg.ReplaceAllString(line, "")
-
-	str = strings.ToLower(str)
-
-	for _, item := range str {
+	for _, item := range line {
 		if comment {
 			t = t + string(item)
 			if item == ')' {
@@ -1343,8 +1697,8 @@ func close_and_downcase( /* ARGUMENTS                   */
 		} else if item == '(' { /* comment is starting */
 			comment = true
 			t = t + string(item)
-		} else {
-			t = t + string(item) /* copy anything else */
+		} else if !unicode.IsSpace(item) {
+			t = t + string(unicode.ToLower(item)) /* copy anything else */
 		}
 	}
 
@@ -1372,6 +1726,10 @@ func close_and_downcase( /* ARGUMENTS                   */
    This function gets the _setup world model in synch with the rest of
    the controller.
 
+   If the canon is a kinematics layer with tool center point control on,
+   the positions read are tool-tip positions (the layer applies the
+   inverse kinematics in GET_EXTERNAL_POSITION_X and the others).
+
 */
 
 func (cnc *rs274ngc_t) synch() inc.STATUS { /* NO ARGUMENTS */
@@ -1394,8 +1752,22 @@ func (cnc *rs274ngc_t) synch() inc.STATUS { /* NO ARGUMENTS */
 	cnc._setup.selected_tool_slot = cnc.canon.GET_EXTERNAL_TOOL_SLOT()
 	cnc._setup.speed = cnc.canon.GET_EXTERNAL_SPEED()
 	cnc._setup.spindle_turning = cnc.canon.GET_EXTERNAL_SPINDLE()
+	cnc._setup.tool_length_offset = cnc.canon.GET_EXTERNAL_TOOL_LENGTH_OFFSET()
+	if (cnc._setup.tool_length_offset != 0.0) && (cnc._setup.length_offset_mode == inc.G_49) {
+		cnc._setup.length_offset_mode = inc.G_43
+	}
 	cnc._setup.tool_max = uint(cnc.canon.GET_EXTERNAL_TOOL_MAX())
 	cnc._setup.traverse_rate = cnc.canon.GET_EXTERNAL_TRAVERSE_RATE()
+	if tcpc := cnc.canon_tcpc; tcpc != nil {
+		cnc._setup.tcpc = inc.If(tcpc.GET_EXTERNAL_TOOL_CENTER_POINT_CONTROL() != 0, ON, OFF).(ON_OFF)
+	} else {
+		cnc._setup.tcpc = OFF
+	}
+	if cnc._setup.tcpc == ON {
+		cnc._setup.length_offset_mode = inc.G_43_4
+	} else if cnc._setup.length_offset_mode == inc.G_43_4 {
+		cnc._setup.length_offset_mode = inc.G_43
+	}
 
 	cnc.load_tool_table() /*  must set  _setup.tool_max first */
 
@@ -1445,6 +1817,10 @@ func (cnc *rs274ngc_t) convert_comment(comment string /* string with comment */)
 	}
 
 	str = bytes.TrimSpace(str)
+	if len(str) == 0 {
+		cnc.canon.COMMENT(comment)
+		return inc.RS274NGC_OK
+	}
 	item = str[0]
 	if (item != 'S') && (item != 's') {
 		cnc.canon.COMMENT(comment)
@@ -1453,6 +1829,10 @@ func (cnc *rs274ngc_t) convert_comment(comment string /* string with comment */)
 		str = str[1:]
 	}
 	str = bytes.TrimSpace(str)
+	if len(str) == 0 {
+		cnc.canon.COMMENT(comment)
+		return inc.RS274NGC_OK
+	}
 	item = str[0]
 	if (item != 'G') && (item != 'g') {
 		cnc.canon.COMMENT(comment)
@@ -1461,6 +1841,10 @@ func (cnc *rs274ngc_t) convert_comment(comment string /* string with comment */)
 		str = str[1:]
 	}
 	str = bytes.TrimSpace(str)
+	if len(str) == 0 {
+		cnc.canon.COMMENT(comment)
+		return inc.RS274NGC_OK
+	}
 	item = str[0]
 	if item != ',' {
 		cnc.canon.COMMENT(comment)
@@ -1488,7 +1872,8 @@ func (cnc *rs274ngc_t) convert_comment(comment string /* string with comment */)
    The canonical machine to which commands are being sent does not have
    a feed mode, so no command setting the distance mode is generated in
    this function. A comment function call is made (conditionally)
-   explaining the change in mode, however.
+   explaining the change in mode, however. If the canon wants to know the
+   feed mode (see inc.Feed_mode_i), SET_FEED_MODE is called too.
 
    Called by: execute_block.
 
@@ -1511,6 +1896,9 @@ func (cnc *rs274ngc_t) convert_feed_mode( /* ARGUMENTS
 	} else {
 		return inc.NCE_BUG_CODE_NOT_G93_OR_G94
 	}
+	if cnc.canon_feed != nil {
+		cnc.canon_feed.SET_FEED_MODE(cnc._setup.feed_mode)
+	}
 	return inc.RS274NGC_OK
 }
 
@@ -1533,7 +1921,12 @@ func (cnc *rs274ngc_t) convert_feed_mode( /* ARGUMENTS
 
 func (cnc *rs274ngc_t) convert_feed_rate() inc.STATUS { /* pointer to machine settings              */
 
-	cnc.canon.SET_FEED_RATE(cnc._setup.block1.f_number)
+	if len(cnc._setup.comp_queue) != 0 { /* keep it in order with held moves */
+		cnc.comp_hold(comp_move_t{motion: -1, feed_rate: cnc._setup.block1.f_number,
+			block: cnc._setup.comp_block})
+	} else if !cnc.superfluous(cnc._setup.feed_rate == cnc._setup.block1.f_number) {
+		cnc.canon.SET_FEED_RATE(cnc._setup.block1.f_number)
+	}
 	cnc._setup.feed_rate = cnc._setup.block1.f_number
 	return inc.RS274NGC_OK
 }
@@ -1555,7 +1948,9 @@ func (cnc *rs274ngc_t) convert_feed_rate() inc.STATUS { /* pointer to machine se
 
 func (cnc *rs274ngc_t) convert_speed() inc.STATUS { /* pointer to machine settings              */
 
-	cnc.canon.SET_SPINDLE_SPEED(cnc._setup.block1.s_number)
+	if !cnc.superfluous(cnc._setup.speed == cnc._setup.block1.s_number) {
+		cnc.canon.SET_SPINDLE_SPEED(cnc._setup.block1.s_number)
+	}
 	cnc._setup.speed = cnc._setup.block1.s_number
 	return inc.RS274NGC_OK
 }
@@ -1579,10 +1974,14 @@ func (cnc *rs274ngc_t) convert_speed() inc.STATUS { /* pointer to machine settin
    1. changing the tool (m6) - which also retracts and stops the spindle.
    2. Turning the spindle on or off (m3, m4, and m5)
    3. Turning coolant on and off (m7, m8, and m9)
-   4. turning a-axis clamping on and off (m26, m27) - commented out.
+   4. turning automatic a-axis clamping on and off (m26, m27).
    5. enabling or disabling feed and speed overrides (m49, m49).
    Within each group, only the first code encountered will be executed.
 
+   CLAMP_AXIS and UNCLAMP_AXIS are called for m26 and m27 only if the
+   canon has them (see inc.Canon_ext_i). While automatic clamping is on,
+   convert_motion unclamps the A-axis around moves of the A-axis.
+
    This does nothing with m0, m1, m2, m30, or m60 (which are handled in
    convert_stop).
 
@@ -1597,45 +1996,55 @@ func (cnc *rs274ngc_t) convert_m() inc.STATUS {
 	}
 
 	if cnc._setup.block1.m_modes[7] == 3 {
-		cnc.canon.START_SPINDLE_CLOCKWISE()
+		if !cnc.superfluous(cnc._setup.spindle_turning == inc.CANON_CLOCKWISE) {
+			cnc.canon.START_SPINDLE_CLOCKWISE()
+		}
 		cnc._setup.spindle_turning = inc.CANON_CLOCKWISE
 	} else if cnc._setup.block1.m_modes[7] == 4 {
-		cnc.canon.START_SPINDLE_COUNTERCLOCKWISE()
+		if !cnc.superfluous(cnc._setup.spindle_turning == inc.CANON_COUNTERCLOCKWISE) {
+			cnc.canon.START_SPINDLE_COUNTERCLOCKWISE()
+		}
 		cnc._setup.spindle_turning = inc.CANON_COUNTERCLOCKWISE
 	} else if cnc._setup.block1.m_modes[7] == 5 {
-		cnc.canon.STOP_SPINDLE_TURNING()
+		if !cnc.superfluous(cnc._setup.spindle_turning == inc.CANON_STOPPED) {
+			cnc.canon.STOP_SPINDLE_TURNING()
+		}
 		cnc._setup.spindle_turning = inc.CANON_STOPPED
 	}
 
 	if cnc._setup.block1.m_modes[8] == 7 {
-		cnc.canon.MIST_ON()
+		if !cnc.superfluous(cnc._setup.coolant.mist == ON) {
+			cnc.canon.MIST_ON()
+		}
 		cnc._setup.coolant.mist = ON
 	} else if cnc._setup.block1.m_modes[8] == 8 {
-		cnc.canon.FLOOD_ON()
+		if !cnc.superfluous(cnc._setup.coolant.flood == ON) {
+			cnc.canon.FLOOD_ON()
+		}
 		cnc._setup.coolant.flood = ON
 	} else if cnc._setup.block1.m_modes[8] == 9 {
-		cnc.canon.MIST_OFF()
+		if !cnc.superfluous(cnc._setup.coolant.mist == OFF) {
+			cnc.canon.MIST_OFF()
+		}
 		cnc._setup.coolant.mist = OFF
-		cnc.canon.FLOOD_OFF()
+		if !cnc.superfluous(cnc._setup.coolant.flood == OFF) {
+			cnc.canon.FLOOD_OFF()
+		}
 		cnc._setup.coolant.flood = OFF
 	}
 
-	/* No axis clamps in this version
-	     if (cnc._setup.block1.m_modes[2] == 26)
-	       {
-	   #ifdef DEBUG_EMC
-	   COMMENT("interpreter: automatic A-axis clamping turned on");
-	   #endif
-	   cnc._setup.a_axis_clamping = ON;
-	   }
-	   else if (cnc._setup.block1.m_modes[2] == 27)
-	   {
-	   #ifdef DEBUG_EMC
-	   COMMENT("interpreter: automatic A-axis clamping turned off");
-	   #endif
-	   cnc._setup.a_axis_clamping = OFF;
-	   }
-	*/
+	ext := cnc.canon_ext
+	if cnc._setup.block1.m_modes[2] == 26 {
+		if ext != nil {
+			ext.CLAMP_AXIS(inc.CANON_AXIS_A)
+		}
+		cnc._setup.a_axis_clamping = ON
+	} else if cnc._setup.block1.m_modes[2] == 27 {
+		if ext != nil {
+			ext.UNCLAMP_AXIS(inc.CANON_AXIS_A)
+		}
+		cnc._setup.a_axis_clamping = OFF
+	}
 
 	if cnc._setup.block1.m_modes[9] == 48 {
 		cnc.canon.ENABLE_FEED_OVERRIDE()
@@ -1796,8 +2205,8 @@ func (cnc *rs274ngc_t) convert_tool_select() inc.STATUS {
    1.  mode 0, G4 only - dwell. Left here from earlier versions.
    2.  mode 2, one of (G17, G18, G19) - plane selection.
    3.  mode 6, one of (G20, G21) - length units.
-   4.  mode 7, one of (G40, G41, G42) - cutter radius compensation.
-   5.  mode 8, one of (G43, G49) - tool length offset
+   4.  mode 7, one of (G40, G41, G41.1, G42, G42.1) - cutter radius compensation.
+   5.  mode 8, one of (G43, G43.1, G43.2, G43.4, G49) - tool length offset
    6.  mode 12, one of (G54, G55, G56, G57, G58, G59, G59.1, G59.2, G59.3)
    - coordinate system selection.
    7.  mode 13, one of (G61, G61.1, G64) - control mode
@@ -1820,38 +2229,58 @@ func (cnc *rs274ngc_t) convert_g() inc.STATUS {
 	//int status;
 
 	if cnc._setup.block1.g_modes[0] == inc.G_4 {
-		cnc.convert_dwell(cnc._setup.block1.p_number)
+		if status := cnc.convert_dwell(cnc._setup.block1.p_number); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.g_modes[2] != -1 {
-		cnc.convert_set_plane(cnc._setup.block1.g_modes[2])
+		if status := cnc.convert_set_plane(cnc._setup.block1.g_modes[2]); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.g_modes[6] != -1 {
-		cnc.convert_length_units(cnc._setup.block1.g_modes[6])
+		if status := cnc.convert_length_units(cnc._setup.block1.g_modes[6]); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.g_modes[7] != -1 {
-		cnc.convert_cutter_compensation(cnc._setup.block1.g_modes[7])
+		if status := cnc.convert_cutter_compensation(cnc._setup.block1.g_modes[7]); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.g_modes[8] != -1 {
-		cnc.convert_tool_length_offset(cnc._setup.block1.g_modes[8])
+		if status := cnc.convert_tool_length_offset(cnc._setup.block1.g_modes[8]); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.g_modes[12] != -1 {
-		cnc.convert_coordinate_system(cnc._setup.block1.g_modes[12])
+		if status := cnc.convert_coordinate_system(cnc._setup.block1.g_modes[12]); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.g_modes[13] != -1 {
-		cnc.convert_control_mode(cnc._setup.block1.g_modes[13])
+		if status := cnc.convert_control_mode(cnc._setup.block1.g_modes[13]); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.g_modes[3] != -1 {
-		cnc.convert_distance_mode(cnc._setup.block1.g_modes[3])
+		if status := cnc.convert_distance_mode(cnc._setup.block1.g_modes[3]); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.g_modes[10] != -1 {
-		cnc.convert_retract_mode(cnc._setup.block1.g_modes[10])
+		if status := cnc.convert_retract_mode(cnc._setup.block1.g_modes[10]); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.g_modes[0] != -1 {
-		cnc.convert_modal_0(cnc._setup.block1.g_modes[0])
+		if status := cnc.convert_modal_0(cnc._setup.block1.g_modes[0]); status != inc.RS274NGC_OK {
+			return status
+		}
 	}
 	if cnc._setup.block1.motion_to_be != -1 {
 		//fmt.Fprintf(os.Stdout, "%s %s", cnc._setup.linetext, "   ") //todo
-		cnc.convert_motion(cnc._setup.block1.motion_to_be)
+		return cnc.convert_motion(cnc._setup.block1.motion_to_be)
 	}
 	return inc.RS274NGC_OK
 }
@@ -1881,7 +2310,9 @@ func (cnc *rs274ngc_t) convert_dwell(time float64) inc.STATUS { /* time in secon
    Returned Value: int
    If any of the following errors occur, this returns the error code shown.
    Otherwise, it returns RS274NGC_OK.
-   1. G_18 or G_19 is called when cutter radius compensation is on:
+   1. G_17, G_18 or G_19 is called to change the plane when cutter
+   radius compensation is on:
+   NCE_CANNOT_USE_XY_PLANE_WITH_CUTTER_RADIUS_COMP
    NCE_CANNOT_USE_XZ_PLANE_WITH_CUTTER_RADIUS_COMP
    NCE_CANNOT_USE_YZ_PLANE_WITH_CUTTER_RADIUS_COMP
    2. The g_code is not G_17, G_18, or G_19:
@@ -1892,27 +2323,42 @@ func (cnc *rs274ngc_t) convert_dwell(time float64) inc.STATUS { /* time in secon
 
    Called by: convert_g.
 
+   Cutter radius compensation works in whichever plane is selected, but
+   the programmed point it keeps is in the coordinates of that plane, so
+   the plane may not be changed while compensation is on. Selecting the
+   plane that is already selected is allowed.
+
 */
 
 func (cnc *rs274ngc_t) convert_set_plane( /* ARGUMENTS                    */
 	g_code inc.GCodes) inc.STATUS { /* pointer to machine settings  */
 
 	//static char name[] = "convert_set_plane";
+	comp := (cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF)
+
 	if g_code == inc.G_17 {
-		cnc.canon.SELECT_PLANE(inc.CANON_PLANE_XY)
+		if comp && (cnc._setup.plane != inc.CANON_PLANE_XY) {
+			return inc.NCE_CANNOT_USE_XY_PLANE_WITH_CUTTER_RADIUS_COMP
+		}
+		if !cnc.superfluous(cnc._setup.plane == inc.CANON_PLANE_XY) {
+			cnc.canon.SELECT_PLANE(inc.CANON_PLANE_XY)
+		}
 		cnc._setup.plane = inc.CANON_PLANE_XY
 	} else if g_code == inc.G_18 {
-		if cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF {
+		if comp && (cnc._setup.plane != inc.CANON_PLANE_XZ) {
 			return inc.NCE_CANNOT_USE_XZ_PLANE_WITH_CUTTER_RADIUS_COMP
 		}
-		cnc.canon.SELECT_PLANE(inc.CANON_PLANE_XZ)
+		if !cnc.superfluous(cnc._setup.plane == inc.CANON_PLANE_XZ) {
+			cnc.canon.SELECT_PLANE(inc.CANON_PLANE_XZ)
+		}
 		cnc._setup.plane = inc.CANON_PLANE_XZ
 	} else if g_code == inc.G_19 {
-		if cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF {
+		if comp && (cnc._setup.plane != inc.CANON_PLANE_YZ) {
 			return inc.NCE_CANNOT_USE_YZ_PLANE_WITH_CUTTER_RADIUS_COMP
 		}
-
-		cnc.canon.SELECT_PLANE(inc.CANON_PLANE_YZ)
+		if !cnc.superfluous(cnc._setup.plane == inc.CANON_PLANE_YZ) {
+			cnc.canon.SELECT_PLANE(inc.CANON_PLANE_YZ)
+		}
 		cnc._setup.plane = inc.CANON_PLANE_YZ
 	} else {
 		return inc.NCE_BUG_CODE_NOT_G17_G18_OR_G19
@@ -1971,7 +2417,9 @@ func (cnc *rs274ngc_t) convert_length_units( /* ARGUMENTS                    */
 	}
 
 	if g_code == inc.G_20 {
-		cnc.canon.USE_LENGTH_UNITS(inc.CANON_UNITS_INCHES)
+		if !cnc.superfluous(cnc._setup.length_units == inc.CANON_UNITS_INCHES) {
+			cnc.canon.USE_LENGTH_UNITS(inc.CANON_UNITS_INCHES)
+		}
 		if cnc._setup.length_units != inc.CANON_UNITS_INCHES {
 			cnc._setup.length_units = inc.CANON_UNITS_INCHES
 			cnc._setup.current.X = (cnc._setup.current.X * inc.INCH_PER_MM)
@@ -1991,7 +2439,9 @@ func (cnc *rs274ngc_t) convert_length_units( /* ARGUMENTS                    */
 				(cnc._setup.origin_offset.Z * inc.INCH_PER_MM)
 		}
 	} else if g_code == inc.G_21 {
-		cnc.canon.USE_LENGTH_UNITS(inc.CANON_UNITS_MM)
+		if !cnc.superfluous(cnc._setup.length_units == inc.CANON_UNITS_MM) {
+			cnc.canon.USE_LENGTH_UNITS(inc.CANON_UNITS_MM)
+		}
 		if cnc._setup.length_units != inc.CANON_UNITS_MM {
 			cnc._setup.length_units = inc.CANON_UNITS_MM
 			cnc._setup.current.X = (cnc._setup.current.X * inc.MM_PER_INCH)
@@ -2026,7 +2476,7 @@ func (cnc *rs274ngc_t) convert_length_units( /* ARGUMENTS                    */
    is called and returns an error code, this returns that code.
    If any of the following errors occur, this returns the error shown.
    Otherwise, it returns RS274NGC_OK.
-   1. g_code is not G_40, G_41, or G_42:
+   1. g_code is not G_40, G_41, G_41_1, G_42, or G_42_1:
    NCE_BUG_CODE_NOT_G40_G41_OR_G42
 
    Side effects:
@@ -2042,21 +2492,18 @@ func (cnc *rs274ngc_t) convert_length_units( /* ARGUMENTS                    */
 
 */
 func (cnc *rs274ngc_t) convert_cutter_compensation( /* ARGUMENTS                    */
-	g_code inc.GCodes) inc.STATUS { /* must be G_40, G_41, or G_42              */
+	g_code inc.GCodes) inc.STATUS { /* must be G_40, G_41, G_41_1, G_42, or G_42_1 */
 
 	//static char name[] = "convert_cutter_compensation";
 
 	if g_code == inc.G_40 {
-		cnc.convert_cutter_compensation_off()
-	} else if g_code == inc.G_41 {
-		cnc.convert_cutter_compensation_on(inc.CANON_SIDE_LEFT)
-	} else if g_code == inc.G_42 {
-		cnc.convert_cutter_compensation_on(inc.CANON_SIDE_RIGHT)
-	} else {
-		return inc.NCE_BUG_CODE_NOT_G40_G41_OR_G42
+		return cnc.convert_cutter_compensation_off()
+	} else if (g_code == inc.G_41) || (g_code == inc.G_41_1) {
+		return cnc.convert_cutter_compensation_on(inc.CANON_SIDE_LEFT)
+	} else if (g_code == inc.G_42) || (g_code == inc.G_42_1) {
+		return cnc.convert_cutter_compensation_on(inc.CANON_SIDE_RIGHT)
 	}
-
-	return inc.RS274NGC_OK
+	return inc.NCE_BUG_CODE_NOT_G40_G41_OR_G42
 }
 
 /****************************************************************************/
@@ -2068,9 +2515,11 @@ func (cnc *rs274ngc_t) convert_cutter_compensation( /* ARGUMENTS
    Side effects:
    A comment is made that cutter radius compensation is turned off.
    The machine model of the cutter radius compensation mode is set to OFF.
-   The value of program_x in the machine model is set to UNKNOWN.
+   The value of program_1 in the machine model is set to UNKNOWN.
    This serves as a flag when cutter radius compensation is
-   turned on again.
+   turned on again. If compensation is passed through (see
+   SetCompPassthrough) and was on, STOP_CUTTER_RADIUS_COMPENSATION is
+   called.
 
    Called by: convert_cutter_compensation
 
@@ -2078,8 +2527,14 @@ func (cnc *rs274ngc_t) convert_cutter_compensation( /* ARGUMENTS
 func (cnc *rs274ngc_t) convert_cutter_compensation_off() inc.STATUS {
 
 	cnc.canon.COMMENT(("interpreter: cutter radius compensation off"))
+	cnc.comp_flush()
+	if (cnc._setup.comp_passthrough == ON) &&
+		(cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF) {
+		cnc.canon_comp.STOP_CUTTER_RADIUS_COMPENSATION()
+	}
 	cnc._setup.cutter_comp_side = inc.CANON_SIDE_OFF
-	cnc._setup.program_x = inc.UNKNOWN
+	cnc._setup.cutter_comp_dynamic = OFF
+	cnc._setup.program_1 = inc.UNKNOWN
 	return inc.RS274NGC_OK
 }
 
@@ -2090,10 +2545,12 @@ func (cnc *rs274ngc_t) convert_cutter_compensation_off() inc.STATUS {
    Returned Value: int
    If any of the following errors occur, this returns the error code shown.
    Otherwise, it returns RS274NGC_OK.
-   1. The selected plane is not the XY plane:
-   NCE_CANNOT_TURN_CUTTER_RADIUS_COMP_ON_OUT_OF_XY_PLANE
-   2. Cutter radius compensation is already on:
+   1. Cutter radius compensation is already on:
    NCE_CANNOT_TURN_CUTTER_RADIUS_COMP_ON_WHEN_ON
+   2. G41.1 or G42.1 is used without a D word:
+   NCE_D_WORD_MISSING_WITH_G41_1_OR_G42_1
+   3. G41.1 or G42.1 is used with an L word outside 0 to 9:
+   NCE_TOOL_ORIENTATION_OUT_OF_RANGE
 
    Side effects:
    A COMMENT function call is made (conditionally) saying that the
@@ -2109,10 +2566,11 @@ func (cnc *rs274ngc_t) convert_cutter_compensation_off() inc.STATUS {
    check_other_codes checks that a d word occurs only in a block with g41
    or g42.
 
-   Cutter radius compensation is carried out in the interpreter, so no
-   call is made to a canonical function (although there is a canonical
-   function, START_CUTTER_RADIUS_COMPENSATION, that could be called if
-   the primitive level could execute it).
+   Cutter radius compensation is normally carried out in the interpreter,
+   so no call is made to a canonical function. If the primitive level
+   can execute it (see SetCompPassthrough), SET_CUTTER_RADIUS_COMPENSATION
+   and START_CUTTER_RADIUS_COMPENSATION are called instead, and the moves
+   that follow are given as programmed.
 
    This version uses a D word if there is one in the block, but it does
    not require a D word, since the sample programs which the interpreter
@@ -2133,6 +2591,16 @@ func (cnc *rs274ngc_t) convert_cutter_compensation_off() inc.STATUS {
    requires that the profile use arcs (not straight lines) to go around
    convex corners.
 
+   With G41.1 and G42.1, the D word is the diameter of the tool, not a
+   slot number, and it is required. The tool table is not used, so a
+   program can adjust for measured tool wear without touching the tool
+   table. An L word may give the tool orientation (0 to 9, as for lathe
+   tools); it is recorded but does not change the offset path.
+
+   Compensation may be turned on in any plane. Left and right are as
+   seen looking down the axis perpendicular to the plane from its
+   positive end (Y for G18, X for G19), as for the XY-plane.
+
 */
 
 func (cnc *rs274ngc_t) convert_cutter_compensation_on( /* ARGUMENTS               */
@@ -2140,16 +2608,31 @@ func (cnc *rs274ngc_t) convert_cutter_compensation_on( /* ARGUMENTS
 
 	//static char name[] = "convert_cutter_compensation_on";
 
-	if cnc._setup.plane != inc.CANON_PLANE_XY {
-		return inc.NCE_CANNOT_TURN_CUTTER_RADIUS_COMP_ON_OUT_OF_XY_PLANE
-	}
-
 	if cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF {
 		return inc.NCE_CANNOT_TURN_CUTTER_RADIUS_COMP_ON_WHEN_ON
 	}
 
+	var radius float64
+
 	index := inc.If(cnc._setup.block1.d_number != -1, cnc._setup.block1.d_number, cnc._setup.current_slot).(int)
-	radius := ((cnc._setup.tool_table[index].Diameter) / 2.0)
+	comp := cnc._setup.block1.g_modes[GCodeCutterRadiusCompensation]
+	dynamic := inc.If((comp == inc.G_41_1) || (comp == inc.G_42_1), ON, OFF).(ON_OFF)
+	orientation := 0
+	if dynamic == ON {
+		if cnc._setup.block1.d_number == -1 {
+			return inc.NCE_D_WORD_MISSING_WITH_G41_1_OR_G42_1
+		}
+		if cnc._setup.block1.l_number != -1 {
+			if cnc._setup.block1.l_number > 9 {
+				return inc.NCE_TOOL_ORIENTATION_OUT_OF_RANGE
+			}
+			orientation = cnc._setup.block1.l_number
+		}
+		radius = (cnc._setup.block1.d_value / 2.0)
+		index = cnc._setup.tool_table_index
+	} else {
+		radius = ((cnc._setup.tool_table[index].Diameter) / 2.0)
+	}
 
 	if radius < 0.0 { /* switch side & make radius positive if radius negative */
 		radius = -radius
@@ -2168,7 +2651,14 @@ func (cnc *rs274ngc_t) convert_cutter_compensation_on( /* ARGUMENTS
 
 	}
 
+	if cnc._setup.comp_passthrough == ON {
+		cnc.canon_comp.SET_CUTTER_RADIUS_COMPENSATION(radius)
+		cnc.canon_comp.START_CUTTER_RADIUS_COMPENSATION(side)
+	}
+
 	cnc._setup.cutter_comp_radius = radius
+	cnc._setup.cutter_comp_dynamic = dynamic
+	cnc._setup.cutter_comp_orientation = orientation
 	cnc._setup.tool_table_index = index
 	cnc._setup.cutter_comp_side = side
 	return inc.RS274NGC_OK
@@ -2181,24 +2671,48 @@ func (cnc *rs274ngc_t) convert_cutter_compensation_on( /* ARGUMENTS
    Returned Value: int
    If any of the following errors occur, this returns the error code shown.
    Otherwise, it returns RS274NGC_OK.
-   1. The block has no offset index (h number): NCE_OFFSET_INDEX_MISSING
-   2. The g_code argument is not G_43 or G_49:
+   1. G43.1 is used and the block has no z value:
+   NCE_Z_WORD_MISSING_WITH_G43_1
+   2. G43.2 is used and the block has neither an offset index (h number)
+   nor a z value: NCE_OFFSET_INDEX_MISSING
+   3. G43.4 is used but the canon does not do tool center point control:
+   NCE_CANNOT_USE_G43_4_WITHOUT_TCPC_KINEMATICS
+   4. The g_code argument is not G_43, G_43_1, G_43_2, G_43_4 or G_49:
    NCE_BUG_CODE_NOT_G43_OR_G49
 
    Side effects:
    A USE_TOOL_LENGTH_OFFSET function call is made. Current_z,
-   tool_length_offset, and length_offset_index are reset.
+   tool_length_offset, length_offset_index and length_offset_mode
+   are reset. Tool center point control may be turned on or off.
 
    Called by: convert_g
 
-   This is called to execute g43 or g49.
+   This is called to execute g43, g43.1, g43.2, g43.4 or g49.
 
    The g49 RS274/NGC command translates into a USE_TOOL_LENGTH_OFFSET(0.0)
    function call.
 
    The g43 RS274/NGC command translates into a USE_TOOL_LENGTH_OFFSET(length)
    function call, where length is the value of the entry in the tool length
-   offset table whose index is the H number in the block.
+   offset table whose index is the H number in the block. If there is no
+   H number, the slot of the tool in the spindle is used, so the H number
+   only serves to override that.
+
+   The g43.1 command uses the z value on the block as the offset, so an
+   offset measured at run time (by a tool setter, say) can be used without
+   writing it into the tool table. The z value is the offset itself and is
+   not affected by the distance mode.
+
+   The g43.2 command adds to the offset in effect: the tool table length
+   of the H number in the block, the z value on the block, or both.
+
+   The g43.4 command does what g43 does and then makes a
+   START_TOOL_CENTER_POINT_CONTROL call, so that following positions are
+   taken as tool-tip positions. This needs a canon which implements
+   inc.Tcpc_i (see the kinematics package). The other codes are in the
+   same modal group, so they make a STOP_TOOL_CENTER_POINT_CONTROL call
+   first if tool center point control is on. The words of the block are
+   checked before that, so a block in error leaves it on.
 
    The H number in the block (if present) was checked for being a non-negative
    integer when it was read, so that check does not need to be repeated.
@@ -2206,30 +2720,87 @@ func (cnc *rs274ngc_t) convert_cutter_compensation_on( /* ARGUMENTS
 */
 
 func (cnc *rs274ngc_t) convert_tool_length_offset( /* ARGUMENTS                    */
-	g_code inc.GCodes) inc.STATUS { /* g_code being executed (must be G_43 or G_49) */
+	g_code inc.GCodes) inc.STATUS { /* g_code being executed (must be in modal group 8) */
 
 	//static char name[] = "convert_tool_length_offset";
 	var offset float64
 
+	tcpc := cnc.canon_tcpc
+
+	switch g_code {
+	case inc.G_43_1:
+		if cnc._setup.block1.z_flag == OFF {
+			return inc.NCE_Z_WORD_MISSING_WITH_G43_1
+		}
+	case inc.G_43_2:
+		if (cnc._setup.block1.h_number == -1) && (cnc._setup.block1.z_flag == OFF) {
+			return inc.NCE_OFFSET_INDEX_MISSING
+		}
+	case inc.G_43_4:
+		if tcpc == nil {
+			return inc.NCE_CANNOT_USE_G43_4_WITHOUT_TCPC_KINEMATICS
+		}
+	case inc.G_43, inc.G_49:
+	default:
+		return inc.NCE_BUG_CODE_NOT_G43_OR_G49
+	}
+
+	if (g_code != inc.G_43_4) && (cnc._setup.tcpc == ON) {
+		tcpc.STOP_TOOL_CENTER_POINT_CONTROL()
+		cnc._setup.tcpc = OFF
+	}
+
 	if g_code == inc.G_49 {
-		cnc.canon.USE_TOOL_LENGTH_OFFSET(0.0)
+		if !cnc.superfluous(cnc._setup.tool_length_offset == 0.0) {
+			cnc.canon.USE_TOOL_LENGTH_OFFSET(0.0)
+		}
 		cnc._setup.current.Z = (cnc._setup.current.Z +
 			cnc._setup.tool_length_offset)
 		cnc._setup.tool_length_offset = 0.0
 		cnc._setup.length_offset_index = 0
-	} else if g_code == inc.G_43 {
+		cnc._setup.length_offset_mode = inc.G_49
+	} else if g_code == inc.G_43_1 {
+		offset = cnc._setup.block1.z_number
+		if !cnc.superfluous(cnc._setup.tool_length_offset == offset) {
+			cnc.canon.USE_TOOL_LENGTH_OFFSET(offset)
+		}
+		cnc._setup.current.Z =
+			(cnc._setup.current.Z + cnc._setup.tool_length_offset - offset)
+		cnc._setup.tool_length_offset = offset
+		cnc._setup.length_offset_index = 0
+		cnc._setup.length_offset_mode = inc.G_43_1
+	} else if g_code == inc.G_43_2 {
 		index := cnc._setup.block1.h_number
-		if index == -1 {
-			return inc.NCE_OFFSET_INDEX_MISSING
+		offset = cnc._setup.tool_length_offset
+		if index != -1 {
+			offset = offset + cnc._setup.tool_table[index].Length
+		}
+		if cnc._setup.block1.z_flag == ON {
+			offset = offset + cnc._setup.block1.z_number
 		}
+		if !cnc.superfluous(cnc._setup.tool_length_offset == offset) {
+			cnc.canon.USE_TOOL_LENGTH_OFFSET(offset)
+		}
+		cnc._setup.current.Z =
+			(cnc._setup.current.Z + cnc._setup.tool_length_offset - offset)
+		cnc._setup.tool_length_offset = offset
+		cnc._setup.length_offset_mode = inc.G_43_2
+	} else { /* G_43 or G_43_4 */
+		index := inc.If(cnc._setup.block1.h_number != -1,
+			cnc._setup.block1.h_number, cnc._setup.current_slot).(int)
 		offset = cnc._setup.tool_table[index].Length
-		cnc.canon.USE_TOOL_LENGTH_OFFSET(offset)
+		if !cnc.superfluous(cnc._setup.tool_length_offset == offset) {
+			cnc.canon.USE_TOOL_LENGTH_OFFSET(offset)
+		}
 		cnc._setup.current.Z =
 			(cnc._setup.current.Z + cnc._setup.tool_length_offset - offset)
 		cnc._setup.tool_length_offset = offset
 		cnc._setup.length_offset_index = index
-	} else {
-		return inc.NCE_BUG_CODE_NOT_G43_OR_G49
+		cnc._setup.length_offset_mode = g_code
+		if (g_code == inc.G_43_4) && (cnc._setup.tcpc == OFF) {
+			tcpc.START_TOOL_CENTER_POINT_CONTROL()
+			cnc._setup.tcpc = ON
+		}
 	}
 
 	return inc.RS274NGC_OK
@@ -2419,7 +2990,13 @@ func (cnc *rs274ngc_t) convert_coordinate_system( /* ARGUMENTS
    mode to CANON_EXACT_STOP.
 
    It is OK to call SET_MOTION_CONTROL_MODE(CANON_XXX) when CANON_XXX is
-   already in force.
+   already in force. The call is not made then if superfluous commands
+   are being suppressed.
+
+   On G_64, if the canon can blend (see inc.Blend_i), it is also given
+   the path tolerance from the P word and the tolerance for running
+   straight moves as one from the Q word, each 0 if the word is not on
+   the line. This call is always made, so that G64 alone clears them.
 
 */
 func (cnc *rs274ngc_t) convert_control_mode( /* ARGUMENTS                    */
@@ -2427,14 +3004,25 @@ func (cnc *rs274ngc_t) convert_control_mode( /* ARGUMENTS                    */
 
 	//static char name[] = "convert_control_mode";
 	if g_code == inc.G_61 {
-		cnc.canon.SET_MOTION_CONTROL_MODE(inc.CANON_EXACT_PATH)
+		if !cnc.superfluous(cnc._setup.control_mode == inc.CANON_EXACT_PATH) {
+			cnc.canon.SET_MOTION_CONTROL_MODE(inc.CANON_EXACT_PATH)
+		}
 		cnc._setup.control_mode = inc.CANON_EXACT_PATH
 	} else if g_code == inc.G_61_1 {
-		cnc.canon.SET_MOTION_CONTROL_MODE(inc.CANON_EXACT_STOP)
+		if !cnc.superfluous(cnc._setup.control_mode == inc.CANON_EXACT_STOP) {
+			cnc.canon.SET_MOTION_CONTROL_MODE(inc.CANON_EXACT_STOP)
+		}
 		cnc._setup.control_mode = inc.CANON_EXACT_STOP
 	} else if g_code == inc.G_64 {
-		cnc.canon.SET_MOTION_CONTROL_MODE(inc.CANON_CONTINUOUS)
+		if !cnc.superfluous(cnc._setup.control_mode == inc.CANON_CONTINUOUS) {
+			cnc.canon.SET_MOTION_CONTROL_MODE(inc.CANON_CONTINUOUS)
+		}
 		cnc._setup.control_mode = inc.CANON_CONTINUOUS
+		if cnc.canon_blend != nil {
+			block := &cnc._setup.block1
+			cnc.canon_blend.SET_MOTION_CONTROL_TOLERANCE(inc.If(block.p_number == -1.0, 0.0, block.p_number).(float64),
+				inc.If(block.q_number == -1.0, 0.0, block.q_number).(float64))
+		}
 	} else {
 		return inc.NCE_BUG_CODE_NOT_G61_G61_1_OR_G64
 	}
@@ -2585,6 +3173,12 @@ func (cnc *rs274ngc_t) convert_modal_0( /* ARGUMENTS
 
    Called by: convert_g.
 
+   If automatic A-axis clamping is on (m26) and a straight or arc move
+   has an a value, UNCLAMP_AXIS is called before the move and CLAMP_AXIS
+   after it, if the canon has them (see inc.Canon_ext_i), unless the move
+   is left out (see straight_superfluous). Cutter comp moves held for
+   lookahead are sent first, so the clamp follows them.
+
 */
 
 func (cnc *rs274ngc_t) convert_motion( /* ARGUMENTS                                 */
@@ -2592,6 +3186,14 @@ func (cnc *rs274ngc_t) convert_motion( /* ARGUMENTS
 
 	//static char name[] = "convert_motion";
 	s = inc.RS274NGC_OK
+	ext := cnc.canon_ext
+	clamp := (ext != nil) && (cnc._setup.a_axis_clamping == ON) &&
+		(cnc._setup.block1.a_flag == ON) &&
+		((motion == inc.G_2) || (motion == inc.G_3) ||
+			(((motion == inc.G_0) || (motion == inc.G_1)) && !cnc.straight_superfluous(cnc.block_ends())))
+	if clamp {
+		ext.UNCLAMP_AXIS(inc.CANON_AXIS_A)
+	}
 	if (motion == inc.G_0) || (motion == inc.G_1) {
 		s = cnc.convert_straight(motion)
 	} else if (motion == inc.G_3) || (motion == inc.G_2) {
@@ -2606,6 +3208,10 @@ func (cnc *rs274ngc_t) convert_motion( /* ARGUMENTS
 	} else {
 		s = inc.NCE_BUG_UNKNOWN_MOTION_CODE
 	}
+	if clamp {
+		cnc.comp_flush()
+		ext.CLAMP_AXIS(inc.CANON_AXIS_A)
+	}
 	return
 }
 
@@ -3106,7 +3712,9 @@ func (cnc *rs274ngc_t) convert_probe() inc.STATUS {
    Returned Value: int (RS274NGC_OK)
 
    Side effects:
-   The values of px, py, pz, aa_p, bb_p, and cc_p are set
+   The values of px, py, pz, aa_p, bb_p, and cc_p are set to the end
+   point of the block (see block_ends). With G53, a comment is made that
+   offsets are suspended.
 
    Called by:
    convert_arc
@@ -3114,6 +3722,35 @@ func (cnc *rs274ngc_t) convert_probe() inc.STATUS {
    convert_probe
    convert_straight
 
+*/
+
+func (cnc *rs274ngc_t) find_ends( /* ARGUMENTS                                    */
+	px, /* pointer to end_x                             */
+	py, /* pointer to end_y                             */
+	pz, /* pointer to end_z                             */
+	AA_p, /* pointer to end_a                       */ /*AA*/
+	BB_p, /* pointer to end_b                       */ /*BB*/
+	CC_p *float64) inc.STATUS { /* pointer to end_c                       */ /*CC*/
+
+	if cnc._setup.block1.g_modes[0] == inc.G_53 {
+		cnc.canon.COMMENT(("interpreter: offsets temporarily suspended"))
+	}
+	*px, *py, *pz, *AA_p, *BB_p, *CC_p = cnc.block_ends()
+	return inc.RS274NGC_OK
+}
+
+/****************************************************************************/
+
+/* block_ends
+
+   Returned Value: the X, Y, Z, A, B and C of the end point of the block
+
+   Side effects: none
+
+   Called by:
+   convert_motion
+   find_ends
+
    This finds the coordinates of a point, "end", in the currently
    active coordinate system, and sets the values of the pointers to the
    coordinates (which are the arguments to the function).
@@ -3137,77 +3774,78 @@ func (cnc *rs274ngc_t) convert_probe() inc.STATUS {
    block plus either (i) the programmed current position - when cutter
    radius compensation is in progress, or (2) the actual current position.
 
+   The programmed current position is kept (in program_1 and program_2)
+   only for the two coordinates of the selected plane; see plane_current.
+
 */
 
-func (cnc *rs274ngc_t) find_ends( /* ARGUMENTS                                    */
-	px, /* pointer to end_x                             */
-	py, /* pointer to end_y                             */
-	pz, /* pointer to end_z                             */
-	AA_p, /* pointer to end_a                       */ /*AA*/
-	BB_p, /* pointer to end_b                       */ /*BB*/
-	CC_p *float64) inc.STATUS { /* pointer to end_c                       */ /*CC*/
+func (cnc *rs274ngc_t) block_ends() (end_x, end_y, end_z, AA_end, BB_end, CC_end float64) {
 
 	mode := cnc._setup.distance_mode
-	middle := (cnc._setup.program_x != inc.UNKNOWN)
+	middle := (cnc._setup.program_1 != inc.UNKNOWN)
 	comp := (cnc._setup.cutter_comp_side != inc.CANON_SIDE_OFF)
 
-	if cnc._setup.block1.g_modes[0] == inc.G_53 { /* distance mode is absolute in this case */
-		cnc.canon.COMMENT(("interpreter: offsets temporarily suspended"))
+	/* programmed current point; differs from current point only with comp */
+	program_x := cnc._setup.current.X
+	program_y := cnc._setup.current.Y
+	program_z := cnc._setup.current.Z
+	if comp && middle {
+		_, _, current3 := cnc.plane_current()
+		program_x, program_y, program_z =
+			cnc.plane_xyz(cnc._setup.program_1, cnc._setup.program_2, *current3)
+	}
 
-		*px = inc.If(cnc._setup.block1.x_flag == ON, (cnc._setup.block1.x_number -
+	if cnc._setup.block1.g_modes[0] == inc.G_53 { /* distance mode is absolute in this case */
+		end_x = inc.If(cnc._setup.block1.x_flag == ON, (cnc._setup.block1.x_number -
 			(cnc._setup.origin_offset.X + cnc._setup.axis_offset.X)), cnc._setup.current.X).(float64)
 
-		*py = inc.If(cnc._setup.block1.y_flag == ON, (cnc._setup.block1.y_number -
+		end_y = inc.If(cnc._setup.block1.y_flag == ON, (cnc._setup.block1.y_number -
 			(cnc._setup.origin_offset.Y + cnc._setup.axis_offset.Y)), cnc._setup.current.Y).(float64)
-		*pz = inc.If(cnc._setup.block1.z_flag == ON, (cnc._setup.block1.z_number -
+		end_z = inc.If(cnc._setup.block1.z_flag == ON, (cnc._setup.block1.z_number -
 			(cnc._setup.tool_length_offset + cnc._setup.origin_offset.Z + cnc._setup.axis_offset.Z)), cnc._setup.current.Z).(float64)
 
-		*AA_p = inc.If(cnc._setup.block1.a_flag == ON, (cnc._setup.block1.a_number -
+		AA_end = inc.If(cnc._setup.block1.a_flag == ON, (cnc._setup.block1.a_number -
 
 			(cnc._setup.origin_offset.A + cnc._setup.axis_offset.A)), cnc._setup.current.A).(float64)
 
-		*BB_p = inc.If(cnc._setup.block1.b_flag == ON, (cnc._setup.block1.b_number -
+		BB_end = inc.If(cnc._setup.block1.b_flag == ON, (cnc._setup.block1.b_number -
 
 			(cnc._setup.origin_offset.B + cnc._setup.axis_offset.B)), cnc._setup.current.B).(float64)
 
-		*CC_p = inc.If(cnc._setup.block1.c_flag == ON, (cnc._setup.block1.c_number -
+		CC_end = inc.If(cnc._setup.block1.c_flag == ON, (cnc._setup.block1.c_number -
 			(cnc._setup.tool_length_offset + cnc._setup.origin_offset.C + cnc._setup.axis_offset.C)), cnc._setup.current.C).(float64)
 
 	} else if mode == inc.MODE_ABSOLUTE {
-		*px = inc.If(cnc._setup.block1.x_flag == ON, cnc._setup.block1.x_number,
-			inc.If(comp && middle, cnc._setup.program_x, cnc._setup.current.X).(float64)).(float64)
+		end_x = inc.If(cnc._setup.block1.x_flag == ON, cnc._setup.block1.x_number, program_x).(float64)
 
-		*py = inc.If(cnc._setup.block1.y_flag == ON, cnc._setup.block1.y_number,
-			inc.If(comp && middle, cnc._setup.program_y, cnc._setup.current.Y).(float64)).(float64)
+		end_y = inc.If(cnc._setup.block1.y_flag == ON, cnc._setup.block1.y_number, program_y).(float64)
 
-		*pz = inc.If(cnc._setup.block1.z_flag == ON, cnc._setup.block1.z_number, cnc._setup.current.Z).(float64)
+		end_z = inc.If(cnc._setup.block1.z_flag == ON, cnc._setup.block1.z_number, program_z).(float64)
 
-		*AA_p = inc.If(cnc._setup.block1.a_flag == ON, cnc._setup.block1.a_number, cnc._setup.current.A).(float64) /*AA*/
+		AA_end = inc.If(cnc._setup.block1.a_flag == ON, cnc._setup.block1.a_number, cnc._setup.current.A).(float64) /*AA*/
 
-		*BB_p = inc.If(cnc._setup.block1.b_flag == ON, cnc._setup.block1.b_number, cnc._setup.current.B).(float64) /*BB*/
+		BB_end = inc.If(cnc._setup.block1.b_flag == ON, cnc._setup.block1.b_number, cnc._setup.current.B).(float64) /*BB*/
 
-		*CC_p = inc.If(cnc._setup.block1.c_flag == ON, cnc._setup.block1.c_number, cnc._setup.current.C).(float64) /*CC*/
+		CC_end = inc.If(cnc._setup.block1.c_flag == ON, cnc._setup.block1.c_number, cnc._setup.current.C).(float64) /*CC*/
 
 	} else { /* mode is MODE_INCREMENTAL */
 
-		*px = inc.If(cnc._setup.block1.x_flag == ON,
-			inc.If(comp && middle, (cnc._setup.block1.x_number+cnc._setup.program_x), (cnc._setup.block1.x_number+cnc._setup.current.X)).(float64),
-			inc.If((comp && middle), cnc._setup.program_x, cnc._setup.current.X).(float64)).(float64)
+		end_x = inc.If(cnc._setup.block1.x_flag == ON,
+			(program_x + cnc._setup.block1.x_number), program_x).(float64)
 
-		*py = inc.If(cnc._setup.block1.y_flag == ON,
-			inc.If(comp && middle, (cnc._setup.block1.y_number+cnc._setup.program_y), (cnc._setup.block1.y_number+cnc._setup.current.Y)).(float64),
-			inc.If((comp && middle), cnc._setup.program_y, cnc._setup.current.Y).(float64)).(float64)
+		end_y = inc.If(cnc._setup.block1.y_flag == ON,
+			(program_y + cnc._setup.block1.y_number), program_y).(float64)
 
-		*pz = inc.If(cnc._setup.block1.z_flag == ON,
-			(cnc._setup.current.Z + cnc._setup.block1.z_number), cnc._setup.current.Z).(float64)
-		*AA_p = inc.If(cnc._setup.block1.a_flag == ON, /*AA*/
+		end_z = inc.If(cnc._setup.block1.z_flag == ON,
+			(program_z + cnc._setup.block1.z_number), program_z).(float64)
+		AA_end = inc.If(cnc._setup.block1.a_flag == ON, /*AA*/
 			(cnc._setup.current.A + cnc._setup.block1.a_number), cnc._setup.current.A).(float64)
-		*BB_p = inc.If(cnc._setup.block1.b_flag == ON, /*BB*/
+		BB_end = inc.If(cnc._setup.block1.b_flag == ON, /*BB*/
 			(cnc._setup.current.B + cnc._setup.block1.b_number), cnc._setup.current.B).(float64)
-		*CC_p = inc.If(cnc._setup.block1.c_flag == ON, /*CC*/
+		CC_end = inc.If(cnc._setup.block1.c_flag == ON, /*CC*/
 			(cnc._setup.current.C + cnc._setup.block1.c_number), cnc._setup.current.C).(float64)
 	}
-	return inc.RS274NGC_OK
+	return
 }
 
 /****************************************************************************/
@@ -3266,3 +3904,91 @@ func (cnc *rs274ngc_t) find_relative( /* ARGUMENTS                   */
 
 	return inc.RS274NGC_OK
 }
+
+/****************************************************************************/
+
+/* plane_current
+
+   Returned Value: pointers to the three coordinates of the current point
+
+   Side effects: none
+
+   Called by:
+   convert_arc
+   convert_straight
+   convert_straight_comp1
+   convert_straight_comp2
+   convert_arc_comp1
+   convert_arc_comp2
+   inverse_time_rate_as
+   inverse_time_rate_arc2
+
+   This returns pointers to the coordinates of the current point in the
+   order of the selected plane: the first and second coordinates of the
+   plane, then the coordinate along the axis perpendicular to it. This
+   is the order ARC_FEED uses, so it is XYZ for the XY-plane, YZX for
+   the YZ-plane, and ZXY for the XZ-plane. Cutter radius compensation
+   works in these coordinates, so the same code serves all three planes.
+
+*/
+
+func (cnc *rs274ngc_t) plane_current() (current1, current2, current3 *float64) {
+	if cnc._setup.plane == inc.CANON_PLANE_YZ {
+		return &(cnc._setup.current.Y), &(cnc._setup.current.Z), &(cnc._setup.current.X)
+	} else if cnc._setup.plane == inc.CANON_PLANE_XZ {
+		return &(cnc._setup.current.Z), &(cnc._setup.current.X), &(cnc._setup.current.Y)
+	}
+	return &(cnc._setup.current.X), &(cnc._setup.current.Y), &(cnc._setup.current.Z)
+}
+
+/****************************************************************************/
+
+/* plane_point
+
+   Returned Value: the point x, y, z in the order of the selected plane
+
+   Side effects: none
+
+   Called by:
+   convert_arc
+   convert_straight
+
+   This puts x, y, and z in the order used by plane_current.
+
+*/
+
+func (cnc *rs274ngc_t) plane_point(x, y, z float64) (float64, float64, float64) {
+	if cnc._setup.plane == inc.CANON_PLANE_YZ {
+		return y, z, x
+	} else if cnc._setup.plane == inc.CANON_PLANE_XZ {
+		return z, x, y
+	}
+	return x, y, z
+}
+
+/****************************************************************************/
+
+/* plane_xyz
+
+   Returned Value: the point given in the order of the selected plane,
+   put back in x, y, z order
+
+   Side effects: none
+
+   Called by:
+   convert_straight_comp1
+   convert_straight_comp2
+   find_ends
+
+   This is the inverse of plane_point.
+
+*/
+
+func (cnc *rs274ngc_t) plane_xyz(first, second, third float64) (x, y, z float64) {
+	if cnc._setup.plane == inc.CANON_PLANE_YZ {
+		return third, first, second
+	} else if cnc._setup.plane == inc.CANON_PLANE_XZ {
+		return second, third, first
+	}
+	return first, second, third
+}
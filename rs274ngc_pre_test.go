@@ -0,0 +1,518 @@
+package rs274ngc
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/kinematics"
+	"github.com/flyingyizi/rs274ngc/mux"
+	"github.com/flyingyizi/rs274ngc/record"
+)
+
+// motion_only_t has the motion functions and nothing else.
+type motion_only_t struct {
+	moves []string
+}
+
+func (m *motion_only_t) add(name string, x, y, z float64) {
+	m.moves = append(m.moves, fmt.Sprintf("%s(%.4f, %.4f, %.4f)", name, x, y, z))
+}
+
+func (m *motion_only_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	m.add("STRAIGHT_TRAVERSE", x, y, z)
+}
+
+func (m *motion_only_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	m.add("STRAIGHT_FEED", x, y, z)
+}
+
+func (m *motion_only_t) ARC_FEED(first_end, second_end, first_axis, second_axis float64,
+	rotation int, axis_end_point, a, b, c float64) {
+}
+
+func (m *motion_only_t) SELECT_PLANE(plane inc.CANON_PLANE) {
+}
+
+func (m *motion_only_t) SET_FEED_RATE(rate float64) {
+}
+
+func (m *motion_only_t) SET_FEED_REFERENCE(reference inc.CANON_FEED_REFERENCE) {
+}
+
+func (m *motion_only_t) SET_MOTION_CONTROL_MODE(mode inc.CANON_MOTION_MODE) {
+}
+
+func (m *motion_only_t) START_SPEED_FEED_SYNCH() {
+}
+
+func (m *motion_only_t) STOP_SPEED_FEED_SYNCH() {
+}
+
+func (m *motion_only_t) DWELL(seconds float64) {
+}
+
+func (m *motion_only_t) USE_LENGTH_UNITS(in_unit inc.CANON_UNITS) {
+}
+
+func (m *motion_only_t) SET_ORIGIN_OFFSETS(x, y, z, a, b, c float64) {
+}
+
+// clamps_t is a whole canon, doing nothing, which can clamp axes.
+type clamps_t struct {
+	inc.Canon_default_t
+	clamps []inc.CANON_AXIS
+}
+
+func (c *clamps_t) GET_EXTERNAL_PARAMETER_FILE_NAME() string {
+	return "example/rs274ngc.var"
+}
+
+func (c *clamps_t) CLAMP_AXIS(axis inc.CANON_AXIS) {
+	c.clamps = append(c.clamps, axis)
+}
+
+func (c *clamps_t) UNCLAMP_AXIS(axis inc.CANON_AXIS) {
+	c.clamps = append(c.clamps, -axis)
+}
+
+func TestCNC_SetCanon_parts(t *testing.T) {
+	motion := &motion_only_t{}
+	clamps := &clamps_t{}
+
+	var cnc rs274ngc_t
+	if err := cnc.SetCanonParts(motion, clamps); err != nil {
+		t.Fatalf("SetCanonParts: %v", err)
+	}
+	if _, ok := cnc.canon.(*inc.Canon_adapter_t); !ok {
+		t.Fatalf("canon is %T, want *inc.Canon_adapter_t", cnc.canon)
+	}
+	if cnc.canon_ext != clamps {
+		t.Errorf("canon_ext = %v, want the clamps part", cnc.canon_ext)
+	}
+	if cnc.canon_tcpc != nil {
+		t.Errorf("canon_tcpc = %v, want nil", cnc.canon_tcpc)
+	}
+
+	if status := cnc.Init(); status != inc.RS274NGC_OK {
+		t.Fatalf("Init() = %v", status)
+	}
+	for _, line := range []string{"g21 g0 x1 y2 z3", "m26", "g1 f100 x4 a5"} {
+		status := cnc.Read([]byte(line))
+		if status == inc.RS274NGC_OK {
+			status = cnc.Execute()
+		}
+		if status != inc.RS274NGC_OK {
+			t.Fatalf("%s: status = %v", line, status)
+		}
+	}
+
+	want := []string{"STRAIGHT_TRAVERSE(1.0000, 2.0000, 3.0000)", "STRAIGHT_FEED(4.0000, 2.0000, 3.0000)"}
+	if !reflect.DeepEqual(motion.moves, want) {
+		t.Errorf("moves = %v, want %v", motion.moves, want)
+	}
+	clamped := []inc.CANON_AXIS{inc.CANON_AXIS_A, -inc.CANON_AXIS_A, inc.CANON_AXIS_A}
+	if !reflect.DeepEqual(clamps.clamps, clamped) {
+		t.Errorf("clamps = %v, want %v", clamps.clamps, clamped)
+	}
+}
+
+func TestCNC_SetCanonParts_unknown(t *testing.T) {
+	// A part of no use is refused, and the canon left as it was.
+	whole := &comp_canon_t{}
+	var cnc rs274ngc_t
+	cnc.SetCanon(whole)
+	if err := cnc.SetCanonParts(&motion_only_t{}, "clamps"); err == nil {
+		t.Errorf("SetCanonParts with a string succeeded")
+	}
+	if cnc.canon != inc.Canon_i(whole) {
+		t.Errorf("canon is %T after a refused SetCanonParts, want it unchanged", cnc.canon)
+	}
+}
+
+func TestCNC_SetCanon_whole(t *testing.T) {
+	whole := &comp_canon_t{}
+
+	var cnc rs274ngc_t
+	cnc.SetCanon(whole)
+	if cnc.canon != inc.Canon_i(whole) {
+		t.Errorf("canon is %T, want the part itself", cnc.canon)
+	}
+	if cnc.canon_ext == nil {
+		t.Errorf("canon_ext is nil, want the part itself")
+	}
+}
+
+func TestCNC_SetCompPassthrough_refused(t *testing.T) {
+	// Passing comp through is refused for a canon without the comp
+	// functions, a middleware in front of one included, and turned off
+	// when such a canon is set.
+	var cnc rs274ngc_t
+	rec := record.New()
+	cnc.SetCanon(mux.Chain(rec, mux.Offset(1, 2, 3)))
+	if cnc.canon_comp != cnc.canon.(inc.Comp_i) {
+		t.Errorf("canon_comp = %T, want the middleware", cnc.canon_comp)
+	}
+	if err := cnc.SetCompPassthrough(true); err != nil {
+		t.Fatalf("SetCompPassthrough(true) in front of a recorder: %v", err)
+	}
+
+	for _, canon := range []inc.Canon_i{&clamps_t{}, mux.Chain(&clamps_t{}, mux.Offset(1, 2, 3))} {
+		cnc.SetCanon(canon)
+		if cnc.canon_comp != nil {
+			t.Errorf("%T: canon_comp = %T, want nil", canon, cnc.canon_comp)
+		}
+		if cnc._setup.comp_passthrough != OFF {
+			t.Errorf("%T: comp passed through after the canon was set", canon)
+		}
+		if err := cnc.SetCompPassthrough(true); err == nil {
+			t.Errorf("%T: SetCompPassthrough(true) succeeded", canon)
+		}
+		if cnc._setup.comp_passthrough != OFF {
+			t.Errorf("%T: comp passed through after a refused SetCompPassthrough", canon)
+		}
+		if err := cnc.SetCompPassthrough(false); err != nil {
+			t.Errorf("%T: SetCompPassthrough(false): %v", canon, err)
+		}
+	}
+}
+
+// run_suppress runs a program repeating itself with suppression set to
+// on and returns the names of the commands made after rs274ngc_init.
+func run_suppress(t *testing.T, on bool) []string {
+	rec := record.New()
+	rec.Parameter_file_name = "example/rs274ngc.var"
+
+	var cnc rs274ngc_t
+	cnc.SetCanon(rec)
+	cnc.SetSuppress(on)
+	if status := cnc.Init(); status != inc.RS274NGC_OK {
+		t.Fatalf("Init() = %v", status)
+	}
+	rec.Reset()
+	for _, line := range []string{
+		"g21 g17 g64 g1 f100 x1 m3 m8",
+		"g21 g17 g64 f100 x1 m3 m8",
+		"g0 x1",
+		"g18 g61 m9",
+		"g18 g61 m9 m5",
+		"g93 g1 x2 f10",
+		"x3 f10",
+	} {
+		status := cnc.Read([]byte(line))
+		if status == inc.RS274NGC_OK {
+			status = cnc.Execute()
+		}
+		if status != inc.RS274NGC_OK {
+			t.Fatalf("%s: status = %v", line, status)
+		}
+	}
+	return rec.Names()
+}
+
+func TestCNC_SetSuppress(t *testing.T) {
+	first := []string{"SET_FEED_RATE", "START_SPINDLE_CLOCKWISE", "FLOOD_ON",
+		"SELECT_PLANE", "USE_LENGTH_UNITS", "SET_MOTION_CONTROL_MODE", "STRAIGHT_FEED"}
+	second := []string{"MIST_OFF", "FLOOD_OFF", "SELECT_PLANE", "SET_MOTION_CONTROL_MODE"}
+	third := []string{"SET_FEED_RATE", "STRAIGHT_FEED"}
+
+	var want []string
+	want = append(want, first...)
+	want = append(want, first...)
+	want = append(want, "STRAIGHT_TRAVERSE")
+	want = append(want, second...)
+	want = append(want, "STOP_SPINDLE_TURNING")
+	want = append(want, second...)
+	want = append(want, "COMMENT")
+	want = append(want, third...)
+	want = append(want, third...)
+	if got := run_suppress(t, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("without suppression, commands = %v, want %v", got, want)
+	}
+
+	// The units, plane and control mode are those set by rs274ngc_init,
+	// the second line and the G0 change nothing, and the inverse time
+	// feed rates are the same.
+	want = []string{"SET_FEED_RATE", "START_SPINDLE_CLOCKWISE", "FLOOD_ON", "STRAIGHT_FEED",
+		"FLOOD_OFF", "SELECT_PLANE", "SET_MOTION_CONTROL_MODE", "STOP_SPINDLE_TURNING",
+		"COMMENT", "SET_FEED_RATE", "STRAIGHT_FEED", "STRAIGHT_FEED"}
+	if got := run_suppress(t, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("with suppression, commands = %v, want %v", got, want)
+	}
+}
+
+func TestCNC_SetSuppress_clamp(t *testing.T) {
+	// A move left out as going nowhere does not unclamp the A-axis.
+	rec := record.New()
+	cnc := start(t, rec, rec)
+	cnc.SetSuppress(true)
+	if status := cnc.execute("g21 m26", "g0 x1 a5"); status != inc.RS274NGC_OK {
+		t.Fatalf("status %v", status)
+	}
+	rec.Reset()
+	if status := cnc.execute("g0 x1 a5", "a5"); status != inc.RS274NGC_OK {
+		t.Fatalf("status %v", status)
+	}
+	if names := rec.Names(); len(names) != 0 {
+		t.Errorf("calls %v for moves to where the tool is, want none", names)
+	}
+	if status := cnc.execute("g0 a10"); status != inc.RS274NGC_OK {
+		t.Fatalf("status %v", status)
+	}
+	rec.Expect(t, "UNCLAMP_AXIS(CANON_AXIS_A)",
+		"STRAIGHT_TRAVERSE(1.0000, 0.0000, 0.0000, 10.0000, 0.0000, 0.0000)",
+		"CLAMP_AXIS(CANON_AXIS_A)")
+}
+
+func TestCNC_G53_comment_once(t *testing.T) {
+	// A G53 move says once that offsets are suspended, whether or not
+	// superfluous commands are suppressed or the A-axis is clamped.
+	for _, c := range []struct {
+		suppress bool
+		lines    []string
+	}{
+		{false, []string{"g21 g0 x5", "g53 g0 x1 y2"}},
+		{true, []string{"g21 g0 x5", "g53 g0 x1 y2"}},
+		{true, []string{"g21 m26 g0 x5", "g53 g0 x1 y2 a3"}},
+	} {
+		rec := record.New()
+		cnc := start(t, rec, rec)
+		cnc.SetSuppress(c.suppress)
+		if status := cnc.execute(c.lines...); status != inc.RS274NGC_OK {
+			t.Fatalf("%v: status %v", c.lines, status)
+		}
+		count := 0
+		for _, comment := range record.Strings(rec.Filter("COMMENT")) {
+			if comment == `COMMENT("interpreter: offsets temporarily suspended")` {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("%v with suppression %v: %d comments on offsets, want one", c.lines, c.suppress, count)
+		}
+	}
+}
+
+// start returns an interpreter initialized with canon, and rec, the
+// recorder at the end of it, with its calls so far dropped.
+func start(t *testing.T, canon inc.Canon_i, rec *record.Recorder_t) *rs274ngc_t {
+	rec.Parameter_file_name = "example/rs274ngc.var"
+	rec.Tool_max = 4
+	if rec.Tools == nil {
+		rec.Tools = make([]inc.CANON_TOOL_TABLE, 5)
+	}
+
+	cnc := &rs274ngc_t{}
+	cnc.SetCanon(canon)
+	if status := cnc.Init(); status != inc.RS274NGC_OK {
+		t.Fatalf("Init() = %v", status)
+	}
+	rec.Reset()
+	return cnc
+}
+
+// execute reads and executes lines, stopping at the first which does
+// not return RS274NGC_OK, and returns the status of the last one run.
+func (cnc *rs274ngc_t) execute(lines ...string) inc.STATUS {
+	status := inc.RS274NGC_OK
+	for _, line := range lines {
+		if status = cnc.Read([]byte(line)); status == inc.RS274NGC_OK {
+			status = cnc.Execute()
+		}
+		if status != inc.RS274NGC_OK {
+			break
+		}
+	}
+	return status
+}
+
+func TestCNC_G43_4(t *testing.T) {
+	rec := record.New()
+	cnc := start(t, rec, rec)
+	if status := cnc.execute("g21 g43.4 h1"); status != inc.NCE_CANNOT_USE_G43_4_WITHOUT_TCPC_KINEMATICS {
+		t.Errorf("G43.4 without TCPC kinematics: status %v, want NCE_CANNOT_USE_G43_4_WITHOUT_TCPC_KINEMATICS", status)
+	}
+
+	rec = record.New()
+	rec.Tools = make([]inc.CANON_TOOL_TABLE, 5)
+	rec.Tools[1].Length = 20
+	k := kinematics.New(rec, kinematics.Config_t{Type: kinematics.KINEMATICS_HEAD_BC, Pivot: 150})
+	cnc = start(t, k, rec)
+	cnc.execute("g21")
+	rec.Reset()
+	if status := cnc.execute("g43.4 h1"); status != inc.RS274NGC_OK {
+		t.Fatalf("G43.4: status %v", status)
+	}
+	if k.GET_EXTERNAL_TOOL_CENTER_POINT_CONTROL() == 0 {
+		t.Errorf("TCPC is off after G43.4")
+	}
+	if length := k.GET_EXTERNAL_TOOL_LENGTH_OFFSET(); length != 20 {
+		t.Errorf("tool length offset %g after G43.4 H1, want 20", length)
+	}
+	if mode := cnc._setup.length_offset_mode; mode != inc.G_43_4 {
+		t.Errorf("length offset mode %v after G43.4, want G_43_4", mode)
+	}
+	if mode := cnc._setup.active_g_codes[9]; mode != inc.G_43_4 {
+		t.Errorf("active G code %v for the length offset after G43.4, want G_43_4", mode)
+	}
+
+	// A block in error leaves TCPC on.
+	for _, c := range []struct {
+		line   string
+		status inc.STATUS
+	}{
+		{"g43.1", inc.NCE_Z_WORD_MISSING_WITH_G43_1},
+		{"g43.2", inc.NCE_OFFSET_INDEX_MISSING},
+	} {
+		if status := cnc.execute(c.line); status != c.status {
+			t.Errorf("%s: status %v, want %v", c.line, status, c.status)
+		}
+		if k.GET_EXTERNAL_TOOL_CENTER_POINT_CONTROL() == 0 {
+			t.Errorf("TCPC is off after %s in error", c.line)
+		}
+	}
+
+	if status := cnc.execute("g49"); status != inc.RS274NGC_OK {
+		t.Fatalf("G49: status %v", status)
+	}
+	if k.GET_EXTERNAL_TOOL_CENTER_POINT_CONTROL() != 0 {
+		t.Errorf("TCPC is on after G49")
+	}
+	rec.Expect(t, "USE_TOOL_LENGTH_OFFSET(20.0000)", "USE_TOOL_LENGTH_OFFSET(0.0000)",
+		"USE_TOOL_LENGTH_OFFSET(20.0000)", "USE_TOOL_LENGTH_OFFSET(0.0000)")
+}
+
+func TestCNC_tool_length_offset(t *testing.T) {
+	// Tool 1 is 10 long and tool 2 is 25.
+	cases := []struct {
+		lines  []string
+		status inc.STATUS
+		want   []string // the USE_TOOL_LENGTH_OFFSET calls
+	}{
+		{[]string{"g43.1 z5", "g43.1 z-2.5"}, inc.RS274NGC_OK,
+			[]string{"USE_TOOL_LENGTH_OFFSET(5.0000)", "USE_TOOL_LENGTH_OFFSET(-2.5000)"}},
+		{[]string{"g43.1"}, inc.NCE_Z_WORD_MISSING_WITH_G43_1, nil},
+		{[]string{"g43.1 x1 z5"}, inc.NCE_ONLY_Z_WORD_ALLOWED_WITH_G43_1_OR_G43_2, nil},
+		{[]string{"g43.2 h1 y1 z1"}, inc.NCE_ONLY_Z_WORD_ALLOWED_WITH_G43_1_OR_G43_2, nil},
+		{[]string{"g43 h1", "g43.2 h2", "g43.2 z1", "g49"}, inc.RS274NGC_OK,
+			[]string{"USE_TOOL_LENGTH_OFFSET(10.0000)", "USE_TOOL_LENGTH_OFFSET(35.0000)",
+				"USE_TOOL_LENGTH_OFFSET(36.0000)", "USE_TOOL_LENGTH_OFFSET(0.0000)"}},
+		{[]string{"g43.2"}, inc.NCE_OFFSET_INDEX_MISSING, nil},
+		{[]string{"t2 m6", "g43"}, inc.RS274NGC_OK, []string{"USE_TOOL_LENGTH_OFFSET(25.0000)"}},
+		{[]string{"t2 m6", "g43 h1"}, inc.RS274NGC_OK, []string{"USE_TOOL_LENGTH_OFFSET(10.0000)"}},
+	}
+	for _, c := range cases {
+		rec := record.New()
+		rec.Tools = make([]inc.CANON_TOOL_TABLE, 5)
+		rec.Tools[1].Length, rec.Tools[2].Length = 10, 25
+		cnc := start(t, rec, rec)
+		if status := cnc.execute(c.lines...); status != c.status {
+			t.Errorf("%v: status %v, want %v", c.lines, status, c.status)
+			continue
+		}
+		got := record.Strings(rec.Filter("USE_TOOL_LENGTH_OFFSET"))
+		if (len(got) != len(c.want)) || ((len(got) != 0) && !reflect.DeepEqual(got, c.want)) {
+			t.Errorf("%v: calls %v, want %v", c.lines, got, c.want)
+		}
+	}
+}
+
+func TestCNC_cutter_compensation_on(t *testing.T) {
+	// Tool 1 is 6 across; comp is passed through, so the radius in use
+	// is seen in SET_CUTTER_RADIUS_COMPENSATION.
+	cases := []struct {
+		line   string
+		status inc.STATUS
+		want   []string // the SET_ and START_CUTTER_RADIUS_COMPENSATION calls
+	}{
+		{"g41.1 d8", inc.RS274NGC_OK,
+			[]string{"SET_CUTTER_RADIUS_COMPENSATION(4.0000)", "START_CUTTER_RADIUS_COMPENSATION(CANON_SIDE_LEFT)"}},
+		{"g42.1 d2.5 l3", inc.RS274NGC_OK,
+			[]string{"SET_CUTTER_RADIUS_COMPENSATION(1.2500)", "START_CUTTER_RADIUS_COMPENSATION(CANON_SIDE_RIGHT)"}},
+		{"g41.1 d8 l9", inc.RS274NGC_OK,
+			[]string{"SET_CUTTER_RADIUS_COMPENSATION(4.0000)", "START_CUTTER_RADIUS_COMPENSATION(CANON_SIDE_LEFT)"}},
+		{"g41 d1", inc.RS274NGC_OK,
+			[]string{"SET_CUTTER_RADIUS_COMPENSATION(3.0000)", "START_CUTTER_RADIUS_COMPENSATION(CANON_SIDE_LEFT)"}},
+		{"g41.1 d8 l10", inc.NCE_TOOL_ORIENTATION_OUT_OF_RANGE, nil},
+		{"g42.1", inc.NCE_D_WORD_MISSING_WITH_G41_1_OR_G42_1, nil},
+		{"g41 d1.5", inc.NCE_NON_INTEGER_VALUE_FOR_INTEGER, nil},
+		{"g42 d2.5", inc.NCE_NON_INTEGER_VALUE_FOR_INTEGER, nil},
+	}
+	for _, c := range cases {
+		rec := record.New()
+		rec.Tools = make([]inc.CANON_TOOL_TABLE, 5)
+		rec.Tools[1].Diameter = 6
+		cnc := start(t, rec, rec)
+		if err := cnc.SetCompPassthrough(true); err != nil {
+			t.Fatal(err)
+		}
+		if status := cnc.execute(c.line); status != c.status {
+			t.Errorf("%s: status %v, want %v", c.line, status, c.status)
+			continue
+		}
+		got := record.Strings(rec.Filter("SET_CUTTER_RADIUS_COMPENSATION", "START_CUTTER_RADIUS_COMPENSATION"))
+		if (len(got) != len(c.want)) || ((len(got) != 0) && !reflect.DeepEqual(got, c.want)) {
+			t.Errorf("%s: calls %v, want %v", c.line, got, c.want)
+		}
+	}
+}
+
+func TestCNC_active_m_codes(t *testing.T) {
+	rec := record.New()
+	cnc := start(t, rec, rec)
+	codes := make([]int, inc.RS274NGC_ACTIVE_M_CODES)
+	for _, c := range []struct {
+		line string
+		want []int
+	}{
+		{"m3 m8", []int{1, -1, 3, -1, -1, 8, 48, 27}},
+		{"m26", []int{2, -1, 3, -1, -1, 8, 48, 26}},
+		{"m5 m9 m27", []int{3, -1, 5, -1, 9, -1, 48, 27}},
+	} {
+		if status := cnc.execute(c.line); status != inc.RS274NGC_OK {
+			t.Fatalf("%s: status %v", c.line, status)
+		}
+		cnc.active_m_codes(codes)
+		if !reflect.DeepEqual(codes, c.want) {
+			t.Errorf("%s: active M codes %v, want %v", c.line, codes, c.want)
+		}
+	}
+	// As in the C interpreter, M26 and M27 make no comment.
+	if comments := rec.Filter("COMMENT"); len(comments) != 0 {
+		t.Errorf("comments %v, want none", record.Strings(comments))
+	}
+}
+
+func TestCNC_arc_feed_rate(t *testing.T) {
+	// An arc in units per minute mode keeps the feed rate, with or
+	// without an F word; in inverse time mode the rate is the length of
+	// the arc, half a circle of radius 5, times F.
+	cases := []struct {
+		line string
+		want []string
+	}{
+		{"g94 g2 x0 y0 i-5 j0", nil},
+		{"g94 g2 x0 y0 i-5 j0 f50", []string{"SET_FEED_RATE(50.0000)"}},
+		{"g93 g2 x0 y0 i-5 j0 f2", []string{"SET_FEED_RATE(31.4159)"}},
+	}
+	for _, c := range cases {
+		rec := record.New()
+		cnc := start(t, rec, rec)
+		if status := cnc.execute("g21 g17 g1 x10 y0 f100"); status != inc.RS274NGC_OK {
+			t.Fatalf("status %v", status)
+		}
+		rec.Reset()
+		if status := cnc.execute(c.line); status != inc.RS274NGC_OK {
+			t.Errorf("%s: status %v", c.line, status)
+			continue
+		}
+		got := record.Strings(rec.Filter("SET_FEED_RATE"))
+		if (len(got) != len(c.want)) || ((len(got) != 0) && !reflect.DeepEqual(got, c.want)) {
+			t.Errorf("%s: calls %v, want %v", c.line, got, c.want)
+		}
+		if len(rec.Filter("ARC_FEED")) != 1 {
+			t.Errorf("%s: calls %v, want an ARC_FEED", c.line, rec.Strings())
+		}
+	}
+}
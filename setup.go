@@ -3,8 +3,6 @@ package rs274ngc
 import (
 	"bufio"
 	"os"
-	"regexp"
-	"strings"
 
 	"github.com/flyingyizi/rs274ngc/inc"
 )
@@ -22,15 +20,24 @@ type Setup_t struct {
 	current       inc.CANON_POSITION
 	origin_offset inc.CANON_POSITION
 
+	a_axis_clamping    ON_OFF                                  // ON means A is clamped when not moving
 	active_g_codes     [inc.RS274NGC_ACTIVE_G_CODES]inc.GCodes // array of active G codes
 	active_m_codes     [inc.RS274NGC_ACTIVE_M_CODES]int        // array of active M codes
 	active_settings    [inc.RS274NGC_ACTIVE_SETTINGS]float64   // array of feed, speed, etc.
 	block1             Block_t                                 // parsed next block
 	blocktext          string                                  // linetext downcased, white space gone
+	comp_block         int                                     // count of blocks moved with cutter comp
+	comp_error         string                                  // lines involved in the last cutter comp error
+	comp_line          string                                  // text of line of the last move with cutter comp
+	comp_lookahead     int                                     // motion blocks held back for cutter comp
+	comp_passthrough   ON_OFF                                  // ON means the machine does cutter comp
+	comp_queue         []comp_move_t                           // moves held back for cutter comp
 	control_mode       inc.CANON_MOTION_MODE                   // exact path or cutting mode
 	current_slot       int                                     // carousel slot number of current tool
-	cutter_comp_radius float64                                 // current cutter compensation radius
-	cutter_comp_side   inc.CANON_SIDE                          // current cutter compensation side
+	cutter_comp_dynamic     ON_OFF                             // radius given by G41.1/G42.1, not the tool table
+	cutter_comp_orientation int                                // tool orientation given by L with G41.1/G42.1
+	cutter_comp_radius      float64                            // current cutter compensation radius
+	cutter_comp_side        inc.CANON_SIDE                     // current cutter compensation side
 	cycle              struct {
 		cc float64 // cc-value (normal) for canned cycles
 		i  float64 // i-value for canned cycles
@@ -55,6 +62,7 @@ type Setup_t struct {
 		mist  ON_OFF // whether mist coolant is on
 	}
 	length_offset_index int             // for use with tool length offsets
+	length_offset_mode  inc.GCodes      // G_43, G_43_1, G_43_2, G_43_4 or G_49
 	length_units        inc.CANON_UNITS // millimeters or inches
 	line_length         uint            // length of line last read
 	linetext            string          // text of most recent line read
@@ -64,8 +72,8 @@ type Setup_t struct {
 
 	plane              inc.CANON_PLANE  // active plane, XY-, YZ-, or XZ-plane
 	probe_flag         ON_OFF           // flag indicating probing done
-	program_x          float64          // program x, used when cutter comp on
-	program_y          float64          // program y, used when cutter comp on
+	program_1          float64          // program first coordinate of plane, used when cutter comp on
+	program_2          float64          // program second coordinate of plane, used when cutter comp on
 	retract_mode       inc.RETRACT_MODE // for cycles, old_z or r_plane
 	selected_tool_slot int              // tool slot selected but not active
 	sequence_number    int              // sequence number of line last read
@@ -74,6 +82,8 @@ type Setup_t struct {
 	speed_feed_mode    inc.CANON_SPEED_FEED_MODE                    // independent or synched
 	speed_override     ON_OFF                                       // whether speed override is enabled
 	spindle_turning    inc.CANON_DIRECTION                          // direction spindle is turning
+	suppress           ON_OFF                                       // ON means superfluous commands are not made
+	tcpc               ON_OFF                                       // whether tool center point control (G43.4) is on
 	tool_length_offset float64                                      // current tool length offset
 	tool_max           uint                                         // highest number tool slot in carousel
 	tool_table         [inc.CANON_TOOL_MAX + 1]inc.CANON_TOOL_TABLE // index is slot number
@@ -114,8 +124,8 @@ type Setup_t struct {
    group 4  - no such group
    group 5  - gez[7]  g93, g94 - feed rate mode
    group 6  - gez[5]  g20, g21 - units
-   group 7  - gez[4]  g40, g41, g42 - cutter radius compensation
-   group 8  - gez[9]  g43, g49 - tool length offset
+   group 7  - gez[4]  g40, g41, g41.1, g42, g42.1 - cutter radius compensation
+   group 8  - gez[9]  g43, g43.1, g43.2, g43.4, g49 - tool length offset
    group 9  - no such group
    group 10 - gez[10] g98, g99 - return mode in canned cycles
    group 11 - no such group
@@ -141,8 +151,11 @@ func (settings *Setup_t) Write_g_codes(block *Block_t) int { /* pointer to a blo
 		inc.If(settings.plane == inc.CANON_PLANE_XY, inc.G_17,
 			inc.If(settings.plane == inc.CANON_PLANE_XZ, inc.G_18, inc.G_19).(inc.GCodes)).(inc.GCodes)
 	gez[4] =
-		inc.If(settings.cutter_comp_side == inc.CANON_SIDE_RIGHT, inc.G_42,
-			inc.If(settings.cutter_comp_side == inc.CANON_SIDE_LEFT, inc.G_41, inc.G_40).(inc.GCodes)).(inc.GCodes)
+		inc.If(settings.cutter_comp_side == inc.CANON_SIDE_RIGHT,
+			inc.If(settings.cutter_comp_dynamic == ON, inc.G_42_1, inc.G_42).(inc.GCodes),
+			inc.If(settings.cutter_comp_side == inc.CANON_SIDE_LEFT,
+				inc.If(settings.cutter_comp_dynamic == ON, inc.G_41_1, inc.G_41).(inc.GCodes),
+				inc.G_40).(inc.GCodes)).(inc.GCodes)
 	gez[5] =
 		inc.If(settings.length_units == inc.CANON_UNITS_INCHES, inc.G_20, inc.G_21).(inc.GCodes)
 	gez[6] =
@@ -152,8 +165,7 @@ func (settings *Setup_t) Write_g_codes(block *Block_t) int { /* pointer to a blo
 	gez[8] =
 		inc.If(settings.origin_index < 7, (530 + (10 * settings.origin_index)),
 			(584 + settings.origin_index)).(inc.GCodes)
-	gez[9] =
-		inc.If(settings.tool_length_offset == 0.0, inc.G_49, inc.G_43).(inc.GCodes)
+	gez[9] = settings.length_offset_mode
 	gez[10] =
 		inc.If(settings.retract_mode == inc.OLD_Z, inc.G_98, inc.G_99).(inc.GCodes)
 	gez[11] =
@@ -179,9 +191,12 @@ func (settings *Setup_t) Write_g_codes(block *Block_t) int { /* pointer to a blo
    This is testing only the feed override to see if overrides is on.
    Might add check of speed override.
 
+   The last code is m26 while automatic a-axis clamping is on, and m27
+   otherwise.
+
 */
 func (settings *Setup_t) Write_m_codes(block *Block_t) int { /* pointer to a block of RS274/NGC instructions */
-	emz := settings.active_m_codes
+	emz := &settings.active_m_codes
 	emz[0] = settings.sequence_number /* 0 seq number  */
 
 	/* 1 stopping    */
@@ -205,6 +220,7 @@ func (settings *Setup_t) Write_m_codes(block *Block_t) int { /* pointer to a blo
 		inc.If(settings.coolant.flood == ON, -1, 9).(int)).(int) /* 4 mist        */
 	emz[5] = inc.If(settings.coolant.flood == ON, 8, -1).(int)  /* 5 flood       */
 	emz[6] = inc.If(settings.feed_override == ON, 48, 49).(int) /* 6 overrides   */
+	emz[7] = inc.If(settings.a_axis_clamping == ON, 26, 27).(int) /* 7 clamping    */
 
 	return inc.RS274NGC_OK
 }
@@ -288,7 +304,7 @@ func (my *MyFile) Read_text() (raw_line string, line string, length uint, s inc.
 	)
 	s = inc.RS274NGC_OK
 
-	if raw_line, err = my.R.ReadString('\n'); err != nil {
+	if raw_line, err = my.R.ReadString('\n'); (err != nil) && (len(raw_line) == 0) {
 		if my.Percent_flag == ON {
 			s = inc.NCE_FILE_ENDED_WITH_NO_PERCENT_SIGN
 			return
@@ -298,31 +314,14 @@ func (my *MyFile) Read_text() (raw_line string, line string, length uint, s inc.
 		}
 	}
 
-	str := regexp.MustCompile("\\s+").ReplaceAllString(raw_line, "")
-	line = strings.ToLower(str)
+	line, s = close_and_downcase(raw_line)
 	length = uint(len(line))
-	if line[0] == '%' && my.Percent_flag == ON {
-		s = inc.RS274NGC_ENDFILE
+	if s != inc.RS274NGC_OK {
 		return
 	}
-
-	// confirm comment is valid
-	comment := false
-	for _, item := range line {
-		if comment {
-			if item == ')' {
-				comment = false
-			} else if item == '(' {
-				s = inc.NCE_NESTED_COMMENT_FOUND
-				return
-			}
-		} else if item == '(' { /* comment is starting */
-			comment = true
-		}
-	}
-
-	if comment {
-		s = inc.NCE_UNCLOSED_COMMENT_FOUND
+	if length != 0 && line[0] == '%' && my.Percent_flag == ON {
+		s = inc.RS274NGC_ENDFILE
+		return
 	}
 
 	return //raw_line, line, length, inc.If(executeFinish, inc.RS274NGC_EXECUTE_FINISH, inc.RS274NGC_OK).(inc.STATUS)
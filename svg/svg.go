@@ -0,0 +1,286 @@
+package svg
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+/* svg.go
+
+   Renderer_t is a Canon_i which draws the tool path of a program as an
+   SVG picture, for a look at the program before it is run. It keeps the
+   moves (see toolpath.Path_t) and draws them when Write is called.
+
+   The picture has one drawing for each view asked for, side by side:
+   top (X across, Y up), front (X across, Z up), side (Y across, Z up),
+   and isometric (from the front, right and above). Traverses are drawn
+   dashed, feeds solid, and arcs a little heavier. Moves are colored by
+   the tool in the spindle (see CHANGE_TOOL). An arc is drawn as a true
+   arc in the view looking along its axis, and as straight lines close
+   enough to it in the others.
+
+   All moves are drawn in the coordinates and length units of the first
+   move (see toolpath.Fold), and so is the stock outline, if any.
+
+*/
+
+type Options_t struct {
+	Views []toolpath.View // nil means toolpath.VIEW_TOP
+	Size  float64         // width or height of each drawing in pixels, 0 means 400
+	Stock *toolpath.Box_t // outline of the stock, nil for none
+}
+
+type Renderer_t struct {
+	toolpath.Path_t
+
+	Options Options_t
+}
+
+var _ inc.Canon_i = &Renderer_t{}
+
+const (
+	margin = 10.0 // pixels around a drawing
+	label  = 16.0 // pixels above a drawing for its name
+)
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Renderer_t with options, no moves, and the world
+   model of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New(options Options_t) *Renderer_t {
+	return &Renderer_t{Options: options}
+}
+
+/* Write
+
+   Returned Value: error (the first error writing to out, or nil)
+
+   Side effects: the picture of the moves made so far is written to out.
+
+   Called by: external programs
+
+*/
+
+func (r *Renderer_t) Write(out io.Writer) error {
+	return Render(out, r.Moves, r.Options)
+}
+
+/* true_plane
+
+   Returned Value: the plane whose arcs are drawn as true arcs in view,
+   0 if none
+
+   Side effects: none
+
+   Called by: drawing_t.move
+
+*/
+
+func true_plane(view toolpath.View) inc.CANON_PLANE {
+	switch view {
+	case toolpath.VIEW_TOP:
+		return inc.CANON_PLANE_XY
+	case toolpath.VIEW_FRONT:
+		return inc.CANON_PLANE_XZ
+	case toolpath.VIEW_SIDE:
+		return inc.CANON_PLANE_YZ
+	}
+	return 0
+}
+
+// drawing_t is one view being drawn.
+type drawing_t struct {
+	toolpath.Frame_t
+
+	path     strings.Builder
+	class    string // of the path being built
+	tool     int    // of the path being built
+	x, y     float64
+	building bool
+}
+
+/* Render
+
+   Returned Value: error (the first error writing to out, or nil)
+
+   Side effects: the picture of moves is written to out.
+
+   Called by: Renderer_t.Write, external programs
+
+*/
+
+func Render(out io.Writer, moves []toolpath.Move_t, options Options_t) error {
+	moves = toolpath.Fold(moves)
+	views := options.Views
+	if len(views) == 0 {
+		views = []toolpath.View{toolpath.VIEW_TOP}
+	}
+	size := inc.If(options.Size > 0.0, options.Size, 400.0).(float64)
+
+	var drawings []*drawing_t
+	width, height := 0.0, 0.0
+	for _, view := range views {
+		d := &drawing_t{Frame_t: toolpath.Fit(view, moves, options.Stock, size, size, margin)}
+		d.Top = label
+		width, height = width+d.Width, math.Max(height, d.Height+label)
+		drawings = append(drawings, d)
+	}
+
+	p := &printer_t{out: out}
+	p.printf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	p.printf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\">\n",
+		num(width), num(height), num(width), num(height))
+	p.printf("<style>\n" +
+		"path{fill:none;stroke-linecap:round;stroke-linejoin:round}\n" +
+		".rapid{stroke-width:0.75;stroke-dasharray:4 3;opacity:0.6}\n" +
+		".feed{stroke-width:1}\n" +
+		".arc{stroke-width:1.5}\n" +
+		".stock{stroke:#888888;stroke-dasharray:2 2}\n" +
+		"text{font:12px sans-serif}\n" +
+		"</style>\n")
+	left := 0.0
+	for _, d := range drawings {
+		p.printf("<g class=\"%s\" transform=\"translate(%s 0)\">\n", d.View, num(left))
+		p.printf("<text x=\"%s\" y=\"%s\">%s</text>\n", num(margin), num(label-4.0), d.View)
+		if options.Stock != nil {
+			d.stock(p, options.Stock)
+		}
+		for n := range moves {
+			d.move(p, &moves[n])
+		}
+		d.flush(p)
+		p.printf("</g>\n")
+		left = left + d.Width
+	}
+	p.printf("</svg>\n")
+	return p.err
+}
+
+// stock draws the twelve edges of the stock box.
+func (d *drawing_t) stock(p *printer_t, box *toolpath.Box_t) {
+	var path strings.Builder
+	corners := box.Corners()
+	for _, edge := range box.Edges() {
+		x1, y1 := d.Point(corners[edge[0]])
+		x2, y2 := d.Point(corners[edge[1]])
+		fmt.Fprintf(&path, "M%s %sL%s %s", num(x1), num(y1), num(x2), num(y2))
+	}
+	p.printf("<path class=\"stock\" d=\"%s\"/>\n", path.String())
+}
+
+/* move
+
+   Returned Value: none
+
+   Side effects: the move is added to the path being built, which is
+   first written and a new one started if the move has a different
+   class or tool.
+
+   Called by: Render
+
+*/
+
+func (d *drawing_t) move(p *printer_t, move *toolpath.Move_t) {
+	class := map[toolpath.Kind]string{toolpath.MOVE_TRAVERSE: "rapid",
+		toolpath.MOVE_ARC: "arc"}[move.Kind]
+	class = inc.If(class == "", "feed", class).(string)
+	if d.building && ((class != d.class) || (move.Tool != d.tool)) {
+		d.flush(p)
+	}
+	d.class, d.tool, d.building = class, move.Tool, true
+
+	x, y := d.Point(move.Start)
+	if (d.path.Len() == 0) || (x != d.x) || (y != d.y) {
+		fmt.Fprintf(&d.path, "M%s %s", num(x), num(y))
+	}
+	if (move.Kind == toolpath.MOVE_ARC) && (move.Plane == true_plane(d.View)) {
+		d.arc(move)
+		return
+	}
+	for _, point := range move.Points(0.25 / d.Scale)[1:] {
+		d.x, d.y = d.Point(point)
+		fmt.Fprintf(&d.path, "L%s %s", num(d.x), num(d.y))
+	}
+}
+
+/* arc
+
+   Returned Value: none
+
+   Side effects: the arc is added to the path being built as SVG arcs.
+
+   Called by: drawing_t.move
+
+   An SVG arc cannot turn through a half circle or more without
+   ambiguity, so the arc is cut into equal pieces each less than a half
+   circle. Across and up are the first and second coordinates of the
+   plane in the top and side views, and the second and first in the
+   front view, so the direction of turn is reversed in the front view.
+   The drawing has y down, so a turn counterclockwise across and up is a
+   turn in the negative direction for SVG (sweep flag 0).
+
+*/
+
+func (d *drawing_t) arc(move *toolpath.Move_t) {
+	turn := move.Turn()
+	counterclockwise := (turn > 0.0) != (d.View == toolpath.VIEW_FRONT)
+	sweep := inc.If(counterclockwise, 0, 1).(int)
+	radius := num(move.Radius() * d.Scale)
+	pieces := int(math.Floor(math.Abs(turn)/math.Pi)) + 1
+	for n := 1; n <= pieces; n++ {
+		d.x, d.y = d.Point(move.Point_at(float64(n) / float64(pieces)))
+		fmt.Fprintf(&d.path, "A%s %s 0 0 %d %s %s", radius, radius, sweep, num(d.x), num(d.y))
+	}
+}
+
+// flush writes the path being built, if any.
+func (d *drawing_t) flush(p *printer_t) {
+	if d.building {
+		p.printf("<path class=\"%s\" stroke=\"%s\" data-tool=\"%d\" d=\"%s\"/>\n",
+			d.class, hex(toolpath.Tool_color(d.tool)), d.tool, d.path.String())
+	}
+	d.path.Reset()
+	d.building = false
+}
+
+/***********************************************************************/
+
+// printer_t writes to out until the first error, which it keeps.
+type printer_t struct {
+	out io.Writer
+	err error
+}
+
+func (p *printer_t) printf(format string, args ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.out, format, args...)
+	}
+}
+
+// num formats a number of pixels with at most two decimals.
+func num(value float64) string {
+	text := strconv.FormatFloat(value, 'f', 2, 64)
+	text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	return inc.If(text == "-0", "0", text).(string)
+}
+
+// hex formats a color as #rrggbb.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
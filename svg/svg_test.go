@@ -0,0 +1,121 @@
+package svg_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/svg"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+var program = []string{
+	"g21 g0 x0 y0 z10",
+	"t1 m6",
+	"g1 z-1 f100",
+	"g2 x20 y0 i10 j0",
+	"g18 g3 x30 z-1 i5 k0",
+	"g17 t2 m6",
+	"g0 z10",
+}
+
+// draw runs program on a Renderer_t with options and returns the picture.
+func draw(t *testing.T, options svg.Options_t) string {
+	renderer := svg.New(options)
+	renderer.Tool_max = 4
+	cnctest.Run(t, cnctest.Start(t, renderer, &renderer.Canon_world_t), program...)
+	var out bytes.Buffer
+	if err := renderer.Write(&out); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	return out.String()
+}
+
+// paths returns the class, tool and d of each tool path in the group of
+// view.
+func paths(picture string, view toolpath.View) [][]string {
+	start := strings.Index(picture, `<g class="`+view.String()+`"`)
+	if start < 0 {
+		return nil
+	}
+	group := picture[start:]
+	group = group[:strings.Index(group, "</g>")]
+	pattern := regexp.MustCompile(`<path class="(\w+)" stroke="[^"]*" data-tool="(\d+)" d="([^"]*)"/>`)
+	var found [][]string
+	for _, match := range pattern.FindAllStringSubmatch(group, -1) {
+		found = append(found, match[1:])
+	}
+	return found
+}
+
+func TestRender_top(t *testing.T) {
+	picture := draw(t, svg.Options_t{Size: 220})
+	if !strings.HasPrefix(picture, "<?xml") || !strings.HasSuffix(picture, "</svg>\n") {
+		t.Fatalf("picture:\n%s\nis not a whole SVG file", picture)
+	}
+	// The path spans X 0 to 30 and Y -10 to 10, so there are 200 pixels
+	// for 30 millimeters, and Y=10 is 10+16 pixels down. The arc in the
+	// XZ-plane is seen edge on, as lines.
+	want := [][]string{
+		{"rapid", "0", "M10 92.67L10 92.67"},
+		{"feed", "1", "M10 92.67L10 92.67"},
+		{"arc", "1", "M10 92.67A66.67 66.67 0 0 1 76.67 26A66.67 66.67 0 0 1 143.33 92.67L"},
+		{"rapid", "2", "M210 92.67L210 92.67"},
+	}
+	got := paths(picture, toolpath.VIEW_TOP)
+	if len(got) != len(want) {
+		t.Fatalf("paths = %q, want %q", got, want)
+	}
+	for n := range want {
+		if (got[n][0] != want[n][0]) || (got[n][1] != want[n][1]) || !strings.HasPrefix(got[n][2], want[n][2]) {
+			t.Errorf("path %d = %q, want %q", n, got[n], want[n])
+		}
+	}
+	if !strings.HasSuffix(got[2][2], "L210 92.67") {
+		t.Errorf("arc path %q does not end at X30", got[2][2])
+	}
+	if strings.Contains(picture, `class="front"`) || strings.Contains(picture, `class="stock"`) {
+		t.Errorf("picture:\n%s\nhas more than the top view", picture)
+	}
+}
+
+func TestRender_views(t *testing.T) {
+	views, err := toolpath.Parse_views("all")
+	if err != nil {
+		t.Fatalf("Parse_views(all) = %v", err)
+	}
+	stock := &toolpath.Box_t{Min: inc.CANON_POSITION{X: -5, Y: -15, Z: -5}, Max: inc.CANON_POSITION{X: 35, Y: 15}}
+	picture := draw(t, svg.Options_t{Views: views, Stock: stock})
+
+	for _, view := range views {
+		got := paths(picture, view)
+		if len(got) != 4 {
+			t.Errorf("%v: paths = %q, want 4", view, got)
+			continue
+		}
+		arcs := strings.Count(got[2][2], "A")
+		switch view {
+		case toolpath.VIEW_TOP:
+			if arcs != 2 {
+				t.Errorf("%v: arc path %q, want two SVG arcs and a line", view, got[2][2])
+			}
+		case toolpath.VIEW_FRONT:
+			if (arcs != 2) || !strings.Contains(got[2][2], "L") {
+				t.Errorf("%v: arc path %q, want lines and two SVG arcs", view, got[2][2])
+			}
+		default:
+			if arcs != 0 {
+				t.Errorf("%v: arc path %q, want only lines", view, got[2][2])
+			}
+		}
+	}
+	if n := strings.Count(picture, `<path class="stock"`); n != 4 {
+		t.Errorf("picture has %d stock outlines, want 4", n)
+	}
+	if !strings.Contains(picture, `width="1600"`) {
+		t.Errorf("picture:\n%s\nis not four 400 pixel views wide", picture)
+	}
+}
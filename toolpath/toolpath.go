@@ -0,0 +1,440 @@
+package toolpath
+
+import (
+	"math"
+
+	"github.com/flyingyizi/rs274ngc/arc"
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* toolpath.go
+
+   Path_t is a Canon_i which keeps the moves a program makes as Move_t's,
+   so the tool path can be drawn, measured or simulated after the
+   program has been interpreted. Everything else is only passed to the
+   world model (see inc.Canon_world_t).
+
+   A move is kept in the program coordinates and length units in force
+   when it was made, along with the origin offsets and tool length
+   offset then in force, so the tip of the tool is at the program
+   coordinates plus Origin, and the machine axes (see Machine_position)
+   are there with Z raised by Length_offset. Fold puts a list of moves
+   into a single coordinate system.
+
+   If Source is set, each move is stamped with the sequence number of
+   the line the interpreter was on when it made the move, and, if Source
+   also has an OriginIndex method, with the coordinate system in use.
+   An interpreter (rs274ngc.Rs274ngc_t) serves as a Source.
+
+*/
+
+// Kind is the sort of a move.
+type Kind int
+
+const (
+	MOVE_TRAVERSE Kind = iota + 1 // STRAIGHT_TRAVERSE
+	MOVE_FEED                     // STRAIGHT_FEED
+	MOVE_ARC                      // ARC_FEED
+	MOVE_PROBE                    // STRAIGHT_PROBE
+)
+
+func (k Kind) String() string {
+	switch k {
+	case MOVE_TRAVERSE:
+		return "traverse"
+	case MOVE_FEED:
+		return "feed"
+	case MOVE_ARC:
+		return "arc"
+	case MOVE_PROBE:
+		return "probe"
+	}
+	return "unknown"
+}
+
+// Move_t is one move of the tool.
+type Move_t struct {
+	Kind          Kind
+	Line          int                // sequence number of the line, 0 if no Source
+	System        int                // coordinate system, 1 (G54) to 9 (G59.3), 0 if unknown
+	Tool          int                // pocket of the tool in the spindle
+	Units         inc.CANON_UNITS    // length units of the positions
+	Feed_rate     float64            // 0 for a traverse
+	Origin        inc.CANON_POSITION // origin offsets in force
+	Length_offset float64            // tool length offset in force
+	Start         inc.CANON_POSITION // program coordinates
+	End           inc.CANON_POSITION // program coordinates
+
+	/* for MOVE_ARC only */
+	Plane    inc.CANON_PLANE // plane of the arc
+	Center1  float64         // center, first coordinate of the plane
+	Center2  float64         // center, second coordinate of the plane
+	Rotation int             // as given to ARC_FEED
+}
+
+type Path_t struct {
+	inc.Canon_world_t
+
+	Source inc.Source_i
+	Moves  []Move_t
+}
+
+var _ inc.Canon_i = &Path_t{}
+
+/***********************************************************************/
+
+/* New
+
+   Returned Value: a Path_t with no moves, no Source, and the world model
+   of a machine at rest at the origin
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func New() *Path_t {
+	return &Path_t{}
+}
+
+/* add
+
+   Returned Value: the move added, for the caller to finish
+
+   Side effects: a move of the given kind from the current position to
+   end is added to p.Moves.
+
+   Called by: the motion functions of Path_t, before the world model is
+   moved.
+
+*/
+
+func (p *Path_t) add(kind Kind, end inc.CANON_POSITION) *Move_t {
+	move := Move_t{
+		Kind:          kind,
+		Tool:          p.Tool_slot,
+		Units:         p.GET_EXTERNAL_LENGTH_UNIT_TYPE(),
+		Feed_rate:     inc.If(kind == MOVE_TRAVERSE, 0.0, p.Feed_rate).(float64),
+		Origin:        p.Origin,
+		Length_offset: p.Tool_length_offset,
+		Start:         p.Position,
+		End:           end,
+	}
+	if p.Source != nil {
+		move.Line = p.Source.SequenceNumber()
+	}
+	if system, ok := p.Source.(inc.System_i); ok {
+		move.System = system.OriginIndex()
+	}
+	p.Moves = append(p.Moves, move)
+	return &p.Moves[len(p.Moves)-1]
+}
+
+func (p *Path_t) STRAIGHT_TRAVERSE(x, y, z, a, b, c float64) {
+	p.add(MOVE_TRAVERSE, inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c})
+	p.Canon_world_t.STRAIGHT_TRAVERSE(x, y, z, a, b, c)
+}
+
+func (p *Path_t) STRAIGHT_FEED(x, y, z, a, b, c float64) {
+	p.add(MOVE_FEED, inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c})
+	p.Canon_world_t.STRAIGHT_FEED(x, y, z, a, b, c)
+}
+
+func (p *Path_t) STRAIGHT_PROBE(x, y, z, a, b, c float64) {
+	p.add(MOVE_PROBE, inc.CANON_POSITION{X: x, Y: y, Z: z, A: a, B: b, C: c})
+	p.Canon_world_t.STRAIGHT_PROBE(x, y, z, a, b, c)
+}
+
+func (p *Path_t) ARC_FEED(first_end, second_end, first_axis,
+	second_axis float64, rotation int, axis_end_point, a, b, c float64) {
+
+	plane := p.GET_EXTERNAL_PLANE()
+	end := Plane_position(plane, first_end, second_end, axis_end_point)
+	end.A, end.B, end.C = a, b, c
+	move := p.add(MOVE_ARC, end)
+	move.Plane = plane
+	move.Center1, move.Center2 = first_axis, second_axis
+	move.Rotation = rotation
+	p.Canon_world_t.ARC_FEED(first_end, second_end, first_axis,
+		second_axis, rotation, axis_end_point, a, b, c)
+}
+
+/***********************************************************************/
+
+/* Plane_point
+
+   Returned Value: the first and second coordinates of point in plane,
+   and the coordinate along the axis of plane
+
+   Side effects: none
+
+   Called by: external programs
+
+   These are the coordinates ARC_FEED is given: X, Y, Z in the XY-plane,
+   Y, Z, X in the YZ-plane, and Z, X, Y in the XZ-plane.
+
+*/
+
+func Plane_point(plane inc.CANON_PLANE, point inc.CANON_POSITION) (first, second, axis float64) {
+	switch plane {
+	case inc.CANON_PLANE_YZ:
+		return point.Y, point.Z, point.X
+	case inc.CANON_PLANE_XZ:
+		return point.Z, point.X, point.Y
+	}
+	return point.X, point.Y, point.Z
+}
+
+/* Plane_position
+
+   Returned Value: the position (with A, B and C zero) whose coordinates
+   in plane are first, second and axis
+
+   Side effects: none
+
+   Called by: external programs
+
+   This undoes Plane_point.
+
+*/
+
+func Plane_position(plane inc.CANON_PLANE, first, second, axis float64) inc.CANON_POSITION {
+	switch plane {
+	case inc.CANON_PLANE_YZ:
+		return inc.CANON_POSITION{X: axis, Y: first, Z: second}
+	case inc.CANON_PLANE_XZ:
+		return inc.CANON_POSITION{X: second, Y: axis, Z: first}
+	}
+	return inc.CANON_POSITION{X: first, Y: second, Z: axis}
+}
+
+/***********************************************************************/
+
+/* Machine_position
+
+   Returned Value: point, in program coordinates, moved to machine
+   coordinates with the origin offsets and tool length offset given
+
+   Side effects: none
+
+   Called by: Move_t.Machine, external programs
+
+   Machine coordinates are those of the machine axes, which carry the
+   spindle, so Z is raised by the tool length offset from the tip of the
+   tool. Travel limits are in machine coordinates (see limits.Limits_t).
+   The units of point, origin and length are the same.
+
+*/
+
+func Machine_position(point, origin inc.CANON_POSITION, length float64) inc.CANON_POSITION {
+	point = plus(point, origin)
+	point.Z = point.Z + length
+	return point
+}
+
+/* Machine
+
+   Returned Value: point, in the program coordinates of m, moved to
+   machine coordinates (see Machine_position)
+
+   Side effects: none
+
+   Called by: extents.Measure, external programs
+
+*/
+
+func (m *Move_t) Machine(point inc.CANON_POSITION) inc.CANON_POSITION {
+	return Machine_position(point, m.Origin, m.Length_offset)
+}
+
+/* Radius
+
+   Returned Value: the radius of an arc at its start, 0 for other moves
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (m *Move_t) Radius() float64 {
+	if m.Kind != MOVE_ARC {
+		return 0.0
+	}
+	first, second, _ := Plane_point(m.Plane, m.Start)
+	return math.Hypot(first-m.Center1, second-m.Center2)
+}
+
+/* Turn
+
+   Returned Value: the angle in radians an arc turns through, positive
+   for counterclockwise, 0 for other moves
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (m *Move_t) Turn() float64 {
+	if m.Kind != MOVE_ARC {
+		return 0.0
+	}
+	first1, second1, _ := Plane_point(m.Plane, m.Start)
+	first2, second2, _ := Plane_point(m.Plane, m.End)
+	return arc.Find_turn(first1, second1, m.Center1, m.Center2, m.Rotation, first2, second2)
+}
+
+/* Length
+
+   Returned Value: the length of the path of the move
+
+   Side effects: none
+
+   Called by: external programs
+
+   This is the length used for feed rates (see arc.Find_arc_length and
+   arc.Find_straight_length).
+
+*/
+
+func (m *Move_t) Length() float64 {
+	if m.Kind == MOVE_ARC {
+		first1, second1, axis1 := Plane_point(m.Plane, m.Start)
+		first2, second2, axis2 := Plane_point(m.Plane, m.End)
+		return arc.Find_arc_length(first1, second1, axis1, m.Center1, m.Center2,
+			m.Rotation, first2, second2, axis2)
+	}
+	return arc.Find_straight_length(m.End.X, m.End.Y, m.End.Z, m.End.A, m.End.B, m.End.C,
+		m.Start.X, m.Start.Y, m.Start.Z, m.Start.A, m.Start.B, m.Start.C)
+}
+
+/* Point_at
+
+   Returned Value: the point a fraction t (0 to 1) of the way along the
+   move
+
+   Side effects: none
+
+   Called by: external programs
+
+   Along an arc, the angle, the coordinate along the axis, and A, B and
+   C all change in proportion. The radius may change too, if the arc
+   does not end as far from the center as it starts.
+
+*/
+
+func (m *Move_t) Point_at(t float64) inc.CANON_POSITION {
+	between := func(from, to float64) float64 { return from + ((to - from) * t) }
+	point := inc.CANON_POSITION{
+		X: between(m.Start.X, m.End.X), Y: between(m.Start.Y, m.End.Y),
+		Z: between(m.Start.Z, m.End.Z), A: between(m.Start.A, m.End.A),
+		B: between(m.Start.B, m.End.B), C: between(m.Start.C, m.End.C),
+	}
+	if m.Kind != MOVE_ARC {
+		return point
+	}
+
+	first1, second1, axis1 := Plane_point(m.Plane, m.Start)
+	first2, second2, axis2 := Plane_point(m.Plane, m.End)
+	radius := between(math.Hypot(first1-m.Center1, second1-m.Center2),
+		math.Hypot(first2-m.Center1, second2-m.Center2))
+	angle := math.Atan2(second1-m.Center2, first1-m.Center1) + (m.Turn() * t)
+	position := Plane_position(m.Plane, m.Center1+(radius*math.Cos(angle)),
+		m.Center2+(radius*math.Sin(angle)), between(axis1, axis2))
+	position.A, position.B, position.C = point.A, point.B, point.C
+	return position
+}
+
+/* Points
+
+   Returned Value: points along the move, from Start to End, such that
+   the path between any two in a row is within tolerance of a straight
+   line
+
+   Side effects: none
+
+   Called by: external programs
+
+   A straight move has just its ends. An arc is cut into equal chords
+   (see arc.Find_chord_count); the last point is End itself.
+
+*/
+
+func (m *Move_t) Points(tolerance float64) []inc.CANON_POSITION {
+	count := 1
+	if m.Kind == MOVE_ARC {
+		count = arc.Find_chord_count(m.Radius(), m.Turn(), tolerance)
+	}
+	points := make([]inc.CANON_POSITION, 0, count+1)
+	points = append(points, m.Start)
+	for n := 1; n < count; n++ {
+		points = append(points, m.Point_at(float64(n)/float64(count)))
+	}
+	return append(points, m.End)
+}
+
+/***********************************************************************/
+
+/* Fold
+
+   Returned Value: a copy of moves in which every move is in the
+   coordinate system and length units of the first move
+
+   Side effects: none
+
+   Called by: external programs
+
+   A move made after the origin offsets or the length units changed is
+   converted, so that all the moves can be drawn or measured together as
+   the work piece sees them.
+
+*/
+
+func Fold(moves []Move_t) []Move_t {
+	folded := make([]Move_t, len(moves))
+	copy(folded, moves)
+	if len(moves) == 0 {
+		return folded
+	}
+	base, units := moves[0].Origin, moves[0].Units
+	for n := range folded {
+		m := &folded[n]
+		factor := 1.0
+		if (m.Units == inc.CANON_UNITS_INCHES) && (units == inc.CANON_UNITS_MM) {
+			factor = 25.4
+		} else if (m.Units == inc.CANON_UNITS_MM) && (units == inc.CANON_UNITS_INCHES) {
+			factor = 1.0 / 25.4
+		}
+		m.Start = minus(scale(plus(m.Start, m.Origin), factor), base)
+		m.End = minus(scale(plus(m.End, m.Origin), factor), base)
+		if m.Kind == MOVE_ARC {
+			center := Plane_position(m.Plane, m.Center1, m.Center2, 0.0)
+			center = minus(scale(plus(center, m.Origin), factor), base)
+			m.Center1, m.Center2, _ = Plane_point(m.Plane, center)
+		}
+		m.Feed_rate = m.Feed_rate * factor
+		m.Length_offset = m.Length_offset * factor
+		m.Origin, m.Units = base, units
+	}
+	return folded
+}
+
+// plus returns the sum of two positions.
+func plus(point, offset inc.CANON_POSITION) inc.CANON_POSITION {
+	return inc.CANON_POSITION{X: point.X + offset.X, Y: point.Y + offset.Y, Z: point.Z + offset.Z,
+		A: point.A + offset.A, B: point.B + offset.B, C: point.C + offset.C}
+}
+
+// minus returns the difference of two positions.
+func minus(point, offset inc.CANON_POSITION) inc.CANON_POSITION {
+	return inc.CANON_POSITION{X: point.X - offset.X, Y: point.Y - offset.Y, Z: point.Z - offset.Z,
+		A: point.A - offset.A, B: point.B - offset.B, C: point.C - offset.C}
+}
+
+// scale returns point with X, Y and Z multiplied by factor.
+func scale(point inc.CANON_POSITION, factor float64) inc.CANON_POSITION {
+	point.X, point.Y, point.Z = (point.X * factor), (point.Y * factor), (point.Z * factor)
+	return point
+}
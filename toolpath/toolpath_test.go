@@ -0,0 +1,105 @@
+package toolpath_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+	"github.com/flyingyizi/rs274ngc/internal/cnctest"
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+// run runs lines on an interpreter keeping its moves in a Path_t.
+func run(t *testing.T, lines ...string) *toolpath.Path_t {
+	path := toolpath.New()
+	path.Tool_max = 4
+	cnc := cnctest.Start(t, path, &path.Canon_world_t)
+	path.Source = cnc
+	cnctest.Run(t, cnc, lines...)
+	return path
+}
+
+// near reports whether two positions are the same to within 1e-9.
+func near(p, q inc.CANON_POSITION) bool {
+	return (math.Abs(p.X-q.X) < 1e-9) && (math.Abs(p.Y-q.Y) < 1e-9) && (math.Abs(p.Z-q.Z) < 1e-9) &&
+		(math.Abs(p.A-q.A) < 1e-9) && (math.Abs(p.B-q.B) < 1e-9) && (math.Abs(p.C-q.C) < 1e-9)
+}
+
+func TestPath(t *testing.T) {
+	path := run(t, "g21 g0 x1 y2", "t3 m6", "g1 z-1 f100", "g2 x3 y2 i1 j0", "g92 x0 y0", "g1 x1")
+
+	kinds := []toolpath.Kind{toolpath.MOVE_TRAVERSE, toolpath.MOVE_FEED, toolpath.MOVE_ARC, toolpath.MOVE_FEED}
+	if len(path.Moves) != len(kinds) {
+		t.Fatalf("moves = %+v, want %d", path.Moves, len(kinds))
+	}
+	for n, kind := range kinds {
+		if path.Moves[n].Kind != kind {
+			t.Errorf("move %d is a %v, want a %v", n, path.Moves[n].Kind, kind)
+		}
+	}
+
+	feed, arc, last := path.Moves[1], path.Moves[2], path.Moves[3]
+	if (feed.Tool != 3) || (feed.Line != 3) || (feed.Feed_rate != 100) || (path.Moves[0].Tool != 0) {
+		t.Errorf("feed = %+v, want tool 3 at line 3 at 100", feed)
+	}
+	if (arc.Plane != inc.CANON_PLANE_XY) || (arc.Center1 != 2) || (arc.Center2 != 2) || (arc.Rotation != -1) {
+		t.Errorf("arc = %+v, want centered at 2, 2 clockwise", arc)
+	}
+	if (arc.Radius() != 1) || (arc.Turn() != -math.Pi) || (math.Abs(arc.Length()-math.Pi) > 1e-9) {
+		t.Errorf("arc radius %v, turn %v, length %v; want 1, -pi, pi", arc.Radius(), arc.Turn(), arc.Length())
+	}
+	want := inc.CANON_POSITION{X: 1, Z: -1}
+	if !near(last.End, want) || (last.Origin.X != 3) || !near(last.Machine(last.End), inc.CANON_POSITION{X: 4, Y: 2, Z: -1}) {
+		t.Errorf("last = %+v, want to %+v with origin X 3", last, want)
+	}
+}
+
+func TestMove_Points(t *testing.T) {
+	// A helical half circle in the XZ-plane from Z=1 to Z=-1 about
+	// X=0, Z=0, rising 2 along Y and turning A by 90.
+	move := toolpath.Move_t{
+		Kind:     toolpath.MOVE_ARC,
+		Start:    inc.CANON_POSITION{Z: 1},
+		End:      inc.CANON_POSITION{Y: 2, Z: -1, A: 90},
+		Plane:    inc.CANON_PLANE_XZ,
+		Rotation: 1,
+	}
+	if turn := move.Turn(); turn != math.Pi {
+		t.Errorf("Turn() = %v, want pi", turn)
+	}
+	middle := move.Point_at(0.5)
+	if !near(middle, inc.CANON_POSITION{X: 1, Y: 1, A: 45}) {
+		t.Errorf("Point_at(0.5) = %+v, want X1 Y1 A45", middle)
+	}
+	points := move.Points(0.01)
+	if (len(points) != 13) || (points[0] != move.Start) || (points[12] != move.End) {
+		t.Errorf("Points(0.01) = %d points from %+v to %+v, want 13 from start to end",
+			len(points), points[0], points[len(points)-1])
+	}
+	for _, point := range points {
+		if math.Abs(math.Hypot(point.X, point.Z)-1) > 1e-9 {
+			t.Errorf("point %+v is off the circle", point)
+		}
+	}
+
+	straight := toolpath.Move_t{Kind: toolpath.MOVE_FEED, End: inc.CANON_POSITION{X: 3, Y: 4}}
+	if points := straight.Points(0.01); (len(points) != 2) || (straight.Length() != 5) {
+		t.Errorf("straight: Points = %v, Length = %v; want its ends and 5", points, straight.Length())
+	}
+}
+
+func TestFold(t *testing.T) {
+	path := run(t, "g21 g0 x10 y0", "g92 x0", "g2 x0 y-10 i0 j-5 f100", "g20 g1 x1")
+	moves := toolpath.Fold(path.Moves)
+
+	arc, last := moves[1], moves[2]
+	if !near(arc.End, inc.CANON_POSITION{X: 10, Y: -10}) || (arc.Center1 != 10) || (arc.Center2 != -5) {
+		t.Errorf("arc = %+v, want to 10, -10 about 10, -5", arc)
+	}
+	if !near(last.End, inc.CANON_POSITION{X: 35.4, Y: -10}) || (last.Units != inc.CANON_UNITS_MM) || (last.Origin.X != 0) {
+		t.Errorf("last = %+v, want to 35.4, -10 in millimeters", last)
+	}
+	if path.Moves[2].Units != inc.CANON_UNITS_INCHES {
+		t.Errorf("Fold changed the moves given it")
+	}
+}
@@ -0,0 +1,268 @@
+package toolpath
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/flyingyizi/rs274ngc/inc"
+)
+
+/* view.go
+
+   This has what the renderers share for drawing a tool path: the views
+   it may be drawn from, the box of the stock, a Frame_t placing a view
+   in a picture, and the colors of the tools.
+
+*/
+
+// View is a direction the tool path is drawn from.
+type View int
+
+const (
+	VIEW_TOP   View = iota + 1 // looking down Z, X across, Y up
+	VIEW_FRONT                 // looking along +Y, X across, Z up
+	VIEW_SIDE                  // looking along -X, Y across, Z up
+	VIEW_ISO                   // looking down from the front right
+)
+
+var view_names = map[View]string{
+	VIEW_TOP: "top", VIEW_FRONT: "front", VIEW_SIDE: "side", VIEW_ISO: "iso",
+}
+
+func (v View) String() string {
+	if name, ok := view_names[v]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+/* Parse_views
+
+   Returned Value: the views named in text, or an error
+   The names are those of View.String, separated by commas; "all" is
+   all four.
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func Parse_views(text string) ([]View, error) {
+	if text == "all" {
+		return []View{VIEW_TOP, VIEW_FRONT, VIEW_SIDE, VIEW_ISO}, nil
+	}
+	var views []View
+next:
+	for _, name := range strings.Split(text, ",") {
+		for view := VIEW_TOP; view <= VIEW_ISO; view++ {
+			if view.String() == strings.TrimSpace(name) {
+				views = append(views, view)
+				continue next
+			}
+		}
+		return nil, fmt.Errorf("unknown view %q", name)
+	}
+	return views, nil
+}
+
+/* Project
+
+   Returned Value: the coordinates of point across and up a drawing
+   from view, in the units of point
+
+   Side effects: none
+
+   Called by: external programs
+
+   The isometric view has +X toward the lower right, +Y toward the upper
+   right, and +Z up, all drawn to the same scale.
+
+*/
+
+func Project(view View, point inc.CANON_POSITION) (across, up float64) {
+	switch view {
+	case VIEW_FRONT:
+		return point.X, point.Z
+	case VIEW_SIDE:
+		return point.Y, point.Z
+	case VIEW_ISO:
+		return (point.X + point.Y) * math.Cos(math.Pi/6),
+			point.Z + ((point.Y - point.X) * math.Sin(math.Pi/6))
+	}
+	return point.X, point.Y
+}
+
+/***********************************************************************/
+
+// Box_t is a box with sides parallel to the axes.
+type Box_t struct {
+	Min inc.CANON_POSITION // the corner with the least X, Y and Z
+	Max inc.CANON_POSITION // the corner with the greatest X, Y and Z
+}
+
+/* Corners
+
+   Returned Value: the eight corners of the box; corner n has the
+   greater X if bit 0 of n is set, the greater Y if bit 1 is set, and
+   the greater Z if bit 2 is set.
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (box *Box_t) Corners() []inc.CANON_POSITION {
+	corners := make([]inc.CANON_POSITION, 8)
+	for n := range corners {
+		corners[n] = box.Min
+		if (n & 1) != 0 {
+			corners[n].X = box.Max.X
+		}
+		if (n & 2) != 0 {
+			corners[n].Y = box.Max.Y
+		}
+		if (n & 4) != 0 {
+			corners[n].Z = box.Max.Z
+		}
+	}
+	return corners
+}
+
+/* Edges
+
+   Returned Value: the twelve edges of the box, as pairs of indexes into
+   Corners
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (box *Box_t) Edges() [][2]int {
+	var edges [][2]int
+	for n := 0; n < 8; n++ {
+		for _, bit := range []int{1, 2, 4} {
+			if (n & bit) == 0 {
+				edges = append(edges, [2]int{n, n | bit})
+			}
+		}
+	}
+	return edges
+}
+
+/***********************************************************************/
+
+// Frame_t places the drawing of a view in a picture, in pixels with y
+// down.
+type Frame_t struct {
+	View       View
+	Scale      float64 // pixels per unit
+	Left       float64 // pixels left of the frame in the picture
+	Top        float64 // pixels above the frame in the picture
+	Margin     float64 // pixels inside the frame around the drawing
+	Min_across float64 // least coordinate across drawn
+	Max_up     float64 // greatest coordinate up drawn
+	Width      float64 // pixels, margins included
+	Height     float64 // pixels, margins included
+}
+
+/* Fit
+
+   Returned Value: a Frame_t for view at Left and Top zero
+
+   Side effects: none
+
+   Called by: external programs
+
+   The frame holds the moves and the stock, if not nil, at the largest
+   scale letting it be no wider than width and no higher than height.
+   The frame is only as large as the drawing needs, so one of its width
+   and height is usually less than asked for.
+
+*/
+
+func Fit(view View, moves []Move_t, stock *Box_t, width, height, margin float64) Frame_t {
+	min_a, min_u := math.Inf(1), math.Inf(1)
+	max_a, max_u := math.Inf(-1), math.Inf(-1)
+	extend := func(point inc.CANON_POSITION) {
+		across, up := Project(view, point)
+		min_a, max_a = math.Min(min_a, across), math.Max(max_a, across)
+		min_u, max_u = math.Min(min_u, up), math.Max(max_u, up)
+	}
+	for n := range moves {
+		for _, point := range moves[n].Points(moves[n].Radius() * 0.001) {
+			extend(point)
+		}
+	}
+	if stock != nil {
+		for _, point := range stock.Corners() {
+			extend(point)
+		}
+	}
+	if math.IsInf(min_a, 1) {
+		min_a, max_a, min_u, max_u = 0.0, 0.0, 0.0, 0.0
+	}
+
+	scale := math.Inf(1)
+	if span := max_a - min_a; span > 0.0 {
+		scale = (width - (2 * margin)) / span
+	}
+	if span := max_u - min_u; span > 0.0 {
+		scale = math.Min(scale, (height-(2*margin))/span)
+	}
+	scale = inc.If(math.IsInf(scale, 1), 1.0, scale).(float64)
+	return Frame_t{
+		View:       view,
+		Scale:      scale,
+		Margin:     margin,
+		Min_across: min_a,
+		Max_up:     max_u,
+		Width:      ((max_a - min_a) * scale) + (2 * margin),
+		Height:     ((max_u - min_u) * scale) + (2 * margin),
+	}
+}
+
+/* Point
+
+   Returned Value: where point is drawn in the picture
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func (f *Frame_t) Point(point inc.CANON_POSITION) (x, y float64) {
+	across, up := Project(f.View, point)
+	return f.Left + f.Margin + ((across - f.Min_across) * f.Scale),
+		f.Top + f.Margin + ((f.Max_up - up) * f.Scale)
+}
+
+/***********************************************************************/
+
+// colors are those of tools 0, 1, 2 and so on, over again.
+var colors = []color.RGBA{
+	{0x1f, 0x77, 0xb4, 0xff}, {0xd6, 0x27, 0x28, 0xff}, {0x2c, 0xa0, 0x2c, 0xff},
+	{0x94, 0x67, 0xbd, 0xff}, {0xff, 0x7f, 0x0e, 0xff}, {0x8c, 0x56, 0x4b, 0xff},
+	{0xe3, 0x77, 0xc2, 0xff}, {0x17, 0xbe, 0xcf, 0xff},
+}
+
+/* Tool_color
+
+   Returned Value: the color the moves made with the tool in slot are
+   drawn in
+
+   Side effects: none
+
+   Called by: external programs
+
+*/
+
+func Tool_color(slot int) color.RGBA {
+	return colors[((slot%len(colors))+len(colors))%len(colors)]
+}
@@ -0,0 +1,17 @@
+package toolpath_test
+
+import (
+	"testing"
+
+	"github.com/flyingyizi/rs274ngc/toolpath"
+)
+
+func TestParse_views(t *testing.T) {
+	views, err := toolpath.Parse_views("iso, front")
+	if (err != nil) || (len(views) != 2) || (views[0] != toolpath.VIEW_ISO) || (views[1] != toolpath.VIEW_FRONT) {
+		t.Errorf("Parse_views(iso, front) = %v, %v", views, err)
+	}
+	if _, err := toolpath.Parse_views("top,back"); (err == nil) || (err.Error() != `unknown view "back"`) {
+		t.Errorf("Parse_views(top,back) = %v, want an unknown view", err)
+	}
+}